@@ -23,6 +23,7 @@ package keystore
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -34,13 +35,21 @@ import (
 	"time"
 
 	"mjoy.io/utils/crypto"
+	"mjoy.io/common/math"
 	"github.com/pborman/uuid"
 	"mjoy.io/accounts"
 	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/transaction"
+	"math/big"
 )
 
 const (
 	version = 3
+
+	// schemeEd25519 marks key files holding an Ed25519 seed instead of a
+	// secp256k1 private key, which have no scheme field.
+	schemeEd25519 = "ed25519"
 )
 
 type Key struct {
@@ -50,6 +59,8 @@ type Key struct {
 	// we only store privkey as pubkey/address can be derived from it
 	// privkey in this struct is always in plaintext
 	PrivateKey *ecdsa.PrivateKey
+	// Ed25519Key is set instead of PrivateKey for Ed25519 keys
+	Ed25519Key ed25519.PrivateKey
 }
 
 type keyStore interface {
@@ -66,6 +77,7 @@ type plainKeyJSON struct {
 	PrivateKey string `json:"privatekey"`
 	Id         string `json:"id"`
 	Version    int    `json:"version"`
+	Scheme     string `json:"scheme,omitempty"`
 }
 
 type encryptedKeyJSONV3 struct {
@@ -73,6 +85,7 @@ type encryptedKeyJSONV3 struct {
 	Crypto  cryptoJSON `json:"crypto"`
 	Id      string     `json:"id"`
 	Version int        `json:"version"`
+	Scheme  string     `json:"scheme,omitempty"`
 }
 
 type encryptedKeyJSONV1 struct {
@@ -98,9 +111,10 @@ type cipherparamsJSON struct {
 func (k *Key) MarshalJSON() (j []byte, err error) {
 	jStruct := plainKeyJSON{
 		hex.EncodeToString(k.Address[:]),
-		hex.EncodeToString(crypto.FromECDSA(k.PrivateKey)),
+		hex.EncodeToString(k.keyBytes()),
 		k.Id.String(),
 		version,
+		k.scheme(),
 	}
 	j, err = json.Marshal(jStruct)
 	return j, err
@@ -120,17 +134,79 @@ func (k *Key) UnmarshalJSON(j []byte) (err error) {
 	if err != nil {
 		return err
 	}
+	k.Address = types.BytesToAddress(addr)
+	if keyJSON.Scheme == schemeEd25519 {
+		seed, err := hex.DecodeString(keyJSON.PrivateKey)
+		if err != nil {
+			return err
+		}
+		if len(seed) != ed25519.SeedSize {
+			return fmt.Errorf("invalid ed25519 seed length %d", len(seed))
+		}
+		k.Ed25519Key = ed25519.NewKeyFromSeed(seed)
+		return nil
+	}
 	privkey, err := crypto.HexToECDSA(keyJSON.PrivateKey)
 	if err != nil {
 		return err
 	}
-
-	k.Address = types.BytesToAddress(addr)
 	k.PrivateKey = privkey
 
 	return nil
 }
 
+// scheme returns the scheme name stored in key files, empty for secp256k1.
+func (k *Key) scheme() string {
+	if k.Ed25519Key != nil {
+		return schemeEd25519
+	}
+	return ""
+}
+
+// keyBytes returns the secret stored in key files, the seed for Ed25519 keys.
+func (k *Key) keyBytes() []byte {
+	if k.Ed25519Key != nil {
+		return k.Ed25519Key.Seed()
+	}
+	return math.PaddedBigBytes(k.PrivateKey.D, 32)
+}
+
+// signHash signs the hash with the key. secp256k1 signatures are in the
+// [R || S || V] format where V is 0 or 1, Ed25519 signatures in the [R || S] one.
+func (k *Key) signHash(hash []byte) ([]byte, error) {
+	if k.Ed25519Key != nil {
+		return ed25519.Sign(k.Ed25519Key, hash), nil
+	}
+	return crypto.Sign(hash, k.PrivateKey)
+}
+
+// signTx signs the transaction with the scheme of the key.
+func (k *Key) signTx(tx *transaction.Transaction, chainID *big.Int) (*transaction.Transaction, error) {
+	if k.Ed25519Key != nil {
+		return transaction.SignTxEd25519(tx, transaction.NewMSigner(chainID), k.Ed25519Key)
+	}
+	return transaction.SignTx(tx, transaction.NewMSigner(chainID), k.PrivateKey)
+}
+
+// signHeader signs the header with the scheme of the key.
+func (k *Key) signHeader(h *block.Header, chainID *big.Int) (*block.Header, error) {
+	if k.Ed25519Key != nil {
+		return block.SignHeaderEd25519(h, block.NewBlockSigner(chainID), k.Ed25519Key)
+	}
+	return block.SignHeader(h, block.NewBlockSigner(chainID), k.PrivateKey)
+}
+
+// zero zeroes the private key in memory.
+func (k *Key) zero() {
+	if k.Ed25519Key != nil {
+		for i := range k.Ed25519Key {
+			k.Ed25519Key[i] = 0
+		}
+		return
+	}
+	zeroKey(k.PrivateKey)
+}
+
 func newKeyFromECDSA(privateKeyECDSA *ecdsa.PrivateKey) *Key {
 	id := uuid.NewRandom()
 	key := &Key{
@@ -170,17 +246,47 @@ func newKey(rand io.Reader) (*Key, error) {
 	return newKeyFromECDSA(privateKeyECDSA), nil
 }
 
+func newKeyFromEd25519(privateKeyEd25519 ed25519.PrivateKey) *Key {
+	id := uuid.NewRandom()
+	key := &Key{
+		Id:         id,
+		Address:    crypto.Ed25519PubkeyToAddress(privateKeyEd25519.Public().(ed25519.PublicKey)),
+		Ed25519Key: privateKeyEd25519,
+	}
+	return key
+}
+
+func newEd25519Key(rand io.Reader) (*Key, error) {
+	_, privateKeyEd25519, err := ed25519.GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyFromEd25519(privateKeyEd25519), nil
+}
+
 func storeNewKey(ks keyStore, rand io.Reader, auth string) (*Key, accounts.Account, error) {
 	key, err := newKey(rand)
 	if err != nil {
 		return nil, accounts.Account{}, err
 	}
+	return storeGeneratedKey(ks, key, auth)
+}
+
+func storeNewEd25519Key(ks keyStore, rand io.Reader, auth string) (*Key, accounts.Account, error) {
+	key, err := newEd25519Key(rand)
+	if err != nil {
+		return nil, accounts.Account{}, err
+	}
+	return storeGeneratedKey(ks, key, auth)
+}
+
+func storeGeneratedKey(ks keyStore, key *Key, auth string) (*Key, accounts.Account, error) {
 	a := accounts.Account{Address: key.Address, URL: accounts.URL{Scheme: KeyStoreScheme, Path: ks.JoinPath(keyFileName(key.Address))}}
 	if err := ks.StoreKey(a.URL.Path, key, auth); err != nil {
-		zeroKey(key.PrivateKey)
+		key.zero()
 		return nil, a, err
 	}
-	return key, a, err
+	return key, a, nil
 }
 
 func writeKeyFile(file string, content []byte) error {
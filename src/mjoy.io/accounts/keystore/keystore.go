@@ -38,7 +38,6 @@ import (
 
 	"mjoy.io/accounts"
 	"mjoy.io/common/types"
-	"mjoy.io/utils/crypto"
 	"mjoy.io/utils/event"
 	"mjoy.io/core/transaction"
 	"mjoy.io/core/blockchain/block"
@@ -49,6 +48,7 @@ var (
 	ErrNoMatch = errors.New("no key for given address or file")
 	ErrDecrypt = errors.New("could not decrypt key with given passphrase")
 	ErrChainId = errors.New("ChainID should not be nil")
+	ErrNotECDSA = errors.New("key is not a secp256k1 key")
 )
 
 // KeyStoreType is the reflect type of a keystore backend.
@@ -241,7 +241,7 @@ func (ks *KeyStore) Delete(a accounts.Account, passphrase string) error {
 	// immediately afterwards.
 	a, key, err := ks.getDecryptedKey(a, passphrase)
 	if key != nil {
-		zeroKey(key.PrivateKey)
+		key.zero()
 	}
 	if err != nil {
 		return err
@@ -268,8 +268,8 @@ func (ks *KeyStore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
 	if !found {
 		return nil, ErrLocked
 	}
-	// Sign the hash using plain ECDSA operations
-	return crypto.Sign(hash, unlockedKey.PrivateKey)
+	// Sign the hash with the scheme of the key
+	return unlockedKey.signHash(hash)
 }
 
 // SignTx signs the given transaction with the requested account.
@@ -285,7 +285,7 @@ func (ks *KeyStore) SignTx(a accounts.Account, tx *transaction.Transaction, chai
 	// Depending on the presence of the chain ID, sign with EIP155 or homestead
 	if chainID != nil {
 
-		return transaction.SignTx(tx, transaction.NewMSigner(chainID), unlockedKey.PrivateKey)
+		return unlockedKey.signTx(tx, chainID)
 	}
 	return nil,ErrChainId
 }
@@ -311,7 +311,7 @@ func (ks *KeyStore) SignHeader( h *block.Header,chainID *big.Int) (*block.Header
 		return nil, ErrLocked
 	}
 
-	return block.SignHeader(h , block.NewBlockSigner(chainID),unlockedKey.PrivateKey)
+	return unlockedKey.signHeader(h, chainID)
 }
 
 // SignHashWithPassphrase signs hash if the private key matching the given address
@@ -322,8 +322,8 @@ func (ks *KeyStore) SignHashWithPassphrase(a accounts.Account, passphrase string
 	if err != nil {
 		return nil, err
 	}
-	defer zeroKey(key.PrivateKey)
-	return crypto.Sign(hash, key.PrivateKey)
+	defer key.zero()
+	return key.signHash(hash)
 }
 
 func (ks *KeyStore) GetKeyWithPassphrase(a accounts.Account, auth string) ( *ecdsa.PrivateKey, error) {
@@ -332,7 +332,13 @@ func (ks *KeyStore) GetKeyWithPassphrase(a accounts.Account, auth string) ( *ecd
 		return nil, err
 	}
 	key, err := ks.storage.GetKey(a.Address, a.URL.Path, auth)
-	return key.PrivateKey, err
+	if err != nil {
+		return nil, err
+	}
+	if key.PrivateKey == nil {
+		return nil, ErrNotECDSA
+	}
+	return key.PrivateKey, nil
 }
 
 // SignTxWithPassphrase signs the transaction if the private key matching the
@@ -342,11 +348,11 @@ func (ks *KeyStore) SignTxWithPassphrase(a accounts.Account, passphrase string,
 	if err != nil {
 		return nil, err
 	}
-	defer zeroKey(key.PrivateKey)
+	defer key.zero()
 
 	// Depending on the presence of the chain ID, sign with EIP155 or homestead
 	if chainID != nil {
-		return transaction.SignTx(tx, transaction.NewMSigner(chainID), key.PrivateKey)
+		return key.signTx(tx, chainID)
 	}
 	return nil,ErrChainId
 }
@@ -388,7 +394,7 @@ func (ks *KeyStore) TimedUnlock(a accounts.Account, passphrase string, timeout t
 		if u.abort == nil {
 			// The address was unlocked indefinitely, so unlocking
 			// it with a timeout would be confusing.
-			zeroKey(key.PrivateKey)
+			key.zero()
 			return nil
 		}
 		// Terminate the expire goroutine and replace it below.
@@ -435,7 +441,7 @@ func (ks *KeyStore) expire(addr types.Address, u *unlocked, timeout time.Duratio
 		// because the map stores a new pointer every time the key is
 		// unlocked.
 		if ks.unlocked[addr] == u {
-			zeroKey(u.PrivateKey)
+			u.zero()
 			delete(ks.unlocked, addr)
 		}
 		ks.mu.Unlock()
@@ -456,6 +462,18 @@ func (ks *KeyStore) NewAccount(passphrase string) (accounts.Account, error) {
 	return account, nil
 }
 
+// NewEd25519Account generates a new Ed25519 key and stores it into the key
+// directory, encrypting it with the passphrase.
+func (ks *KeyStore) NewEd25519Account(passphrase string) (accounts.Account, error) {
+	_, account, err := storeNewEd25519Key(ks.storage, crand.Reader, passphrase)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	ks.cache.add(account)
+	ks.refreshWallets()
+	return account, nil
+}
+
 // Export exports as a JSON key, encrypted with newPassphrase.
 func (ks *KeyStore) Export(a accounts.Account, passphrase, newPassphrase string) (keyJSON []byte, err error) {
 	_, key, err := ks.getDecryptedKey(a, passphrase)
@@ -474,8 +492,8 @@ func (ks *KeyStore) Export(a accounts.Account, passphrase, newPassphrase string)
 // Import stores the given encrypted JSON key into the key directory.
 func (ks *KeyStore) Import(keyJSON []byte, passphrase, newPassphrase string) (accounts.Account, error) {
 	key, err := DecryptKey(keyJSON, passphrase)
-	if key != nil && key.PrivateKey != nil {
-		defer zeroKey(key.PrivateKey)
+	if key != nil && (key.PrivateKey != nil || key.Ed25519Key != nil) {
+		defer key.zero()
 	}
 	if err != nil {
 		return accounts.Account{}, err
@@ -30,6 +30,7 @@ package keystore
 import (
 	"bytes"
 	"crypto/aes"
+	"crypto/ed25519"
 	crand "crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
@@ -37,7 +38,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
-	"mjoy.io/common/math"
 	"mjoy.io/common/types"
 	"mjoy.io/utils/crypto"
 	"mjoy.io/utils/crypto/randentropy"
@@ -124,7 +124,7 @@ func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
 		return nil, err
 	}
 	encryptKey := derivedKey[:16]
-	keyBytes := math.PaddedBigBytes(key.PrivateKey.D, 32)
+	keyBytes := key.keyBytes()
 
 	iv := randentropy.GetEntropyCSPRNG(aes.BlockSize) // 16
 	cipherText, err := aesCTRXOR(encryptKey, keyBytes, iv)
@@ -157,6 +157,7 @@ func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
 		cryptoStruct,
 		key.Id.String(),
 		version,
+		key.scheme(),
 	}
 	return json.Marshal(encryptedKeyJSONV3)
 }
@@ -171,6 +172,7 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 	// Depending on the version try to parse one way or another
 	var (
 		keyBytes, keyId []byte
+		scheme          string
 		err             error
 	)
 	if version, ok := m["version"].(string); ok && version == "1" {
@@ -185,11 +187,20 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 			return nil, err
 		}
 		keyBytes, keyId, err = decryptKeyV3(k, auth)
+		scheme = k.Scheme
 	}
 	// Handle any decryption errors and return the key
 	if err != nil {
 		return nil, err
 	}
+	if scheme == schemeEd25519 {
+		if len(keyBytes) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid ed25519 seed length %d", len(keyBytes))
+		}
+		key := newKeyFromEd25519(ed25519.NewKeyFromSeed(keyBytes))
+		key.Id = uuid.UUID(keyId)
+		return key, nil
+	}
 	key := crypto.ToECDSAUnsafe(keyBytes)
 
 	return &Key{
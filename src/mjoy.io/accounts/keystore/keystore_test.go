@@ -33,6 +33,9 @@ import (
 	"mjoy.io/common"
 	"mjoy.io/utils/event"
 	"mjoy.io/common/types"
+	"math/big"
+	"mjoy.io/core/transaction"
+	"mjoy.io/utils/crypto"
 )
 
 var testSigData = make([]byte, 32)
@@ -117,6 +120,40 @@ func TestSignWithPassphrase(t *testing.T) {
 	}
 }
 
+func TestEd25519Account(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	pass := "passwd"
+	acc, err := ks.NewEd25519Account(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The key must survive the encrypted key file
+	if err := ks.Unlock(acc, pass); err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(1)
+	tx := transaction.NewTransaction(0, []transaction.Action{transaction.MakeAction(acc.Address, []byte{1})})
+	signed, err := ks.SignTx(acc, tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.SigScheme() != crypto.SigEd25519 {
+		t.Fatalf("signature scheme mismatch: have %d, want %d", signed.SigScheme(), crypto.SigEd25519)
+	}
+	from, err := transaction.Sender(transaction.NewMSigner(chainID), signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != acc.Address {
+		t.Fatalf("sender mismatch: have %x, want %x", from, acc.Address)
+	}
+	if _, err := ks.GetKeyWithPassphrase(acc, pass); err != ErrNotECDSA {
+		t.Fatalf("GetKeyWithPassphrase error mismatch: have %v, want %v", err, ErrNotECDSA)
+	}
+}
+
 func TestTimedUnlock(t *testing.T) {
 	dir, ks := tmpKeyStore(t, true)
 	defer os.RemoveAll(dir)
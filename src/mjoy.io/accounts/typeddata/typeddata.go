@@ -0,0 +1,459 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: typeddata.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+// Package typeddata implements signing of typed structured data, so that off-chain
+// messages like orders and login challenges can be shown field by field by a
+// wallet and a signature can not be replayed on another chain, app or contract.
+//
+// The signed hash is
+//   keccak256("\x19\x01" || domainSeparator || hashStruct(message))
+// where hashStruct(s) = keccak256(typeHash(s) || encodeData(s)) and the type hash
+// is the hash of the schema, e.g. "Order(address player,uint256 amount)".
+package typeddata
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mjoy.io/common/math"
+	"mjoy.io/common/types"
+	"mjoy.io/common/types/util/hex"
+	"mjoy.io/utils/crypto"
+)
+
+// DomainType is the schema of the domain separator.
+const DomainType = "MjoyDomain"
+
+var domainFields = []Type{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "contract", Type: "address"},
+}
+
+var (
+	ErrMissingChainId = errors.New("typed data domain has no chain id")
+	ErrWrongChainId   = errors.New("typed data domain is for another chain")
+	ErrSignerMismatch = errors.New("typed data signed by another account")
+)
+
+var (
+	identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	intTypeRe    = regexp.MustCompile(`^u?int([0-9]*)$`)
+	bytesTypeRe  = regexp.MustCompile(`^bytes([0-9]+)$`)
+)
+
+// Type is a named field of a struct type.
+type Type struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Types maps struct type names to their fields.
+type Types map[string][]Type
+
+// Domain separates the signatures of different chains, apps and contracts.
+type Domain struct {
+	Name     string        `json:"name"`
+	Version  string        `json:"version"`
+	ChainId  *hex.Big      `json:"chainId"`
+	Contract types.Address `json:"contract"`
+}
+
+// TypedData is a message together with its schema and domain, as presented to
+// a wallet for signing.
+type TypedData struct {
+	Types       Types                  `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      Domain                 `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// SigningHash returns the hash to be signed for the typed data.
+func (td *TypedData) SigningHash() (types.Hash, error) {
+	if err := td.Validate(); err != nil {
+		return types.Hash{}, err
+	}
+	domain, err := td.DomainSeparator()
+	if err != nil {
+		return types.Hash{}, err
+	}
+	message, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return types.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain[:], message[:]), nil
+}
+
+// DomainSeparator returns the hash of the domain.
+func (td *TypedData) DomainSeparator() (types.Hash, error) {
+	if td.Domain.ChainId == nil {
+		return types.Hash{}, ErrMissingChainId
+	}
+	domain := map[string]interface{}{
+		"name":     td.Domain.Name,
+		"version":  td.Domain.Version,
+		"chainId":  td.Domain.ChainId.ToInt(),
+		"contract": td.Domain.Contract,
+	}
+	all := Types{DomainType: domainFields}
+	return all.hashStruct(DomainType, domain)
+}
+
+// HashStruct returns the hash of a value of the given struct type.
+func (td *TypedData) HashStruct(primaryType string, data map[string]interface{}) (types.Hash, error) {
+	return td.Types.hashStruct(primaryType, data)
+}
+
+// TypeHash returns the hash of the schema of the given struct type.
+func (td *TypedData) TypeHash(primaryType string) types.Hash {
+	return crypto.Keccak256Hash([]byte(td.Types.encodeType(primaryType)))
+}
+
+// Validate checks that the schema is well formed and contains the primary type.
+func (td *TypedData) Validate() error {
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return fmt.Errorf("primary type %q is not defined", td.PrimaryType)
+	}
+	if _, ok := td.Types[DomainType]; ok {
+		return fmt.Errorf("type %q is reserved", DomainType)
+	}
+	for name, fields := range td.Types {
+		if !identifierRe.MatchString(name) {
+			return fmt.Errorf("invalid type name %q", name)
+		}
+		seen := make(map[string]bool)
+		for _, field := range fields {
+			if !identifierRe.MatchString(field.Name) {
+				return fmt.Errorf("invalid field name %q in type %s", field.Name, name)
+			}
+			if seen[field.Name] {
+				return fmt.Errorf("duplicate field %q in type %s", field.Name, name)
+			}
+			seen[field.Name] = true
+			if err := td.Types.checkType(field.Type); err != nil {
+				return fmt.Errorf("field %s.%s: %v", name, field.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Format returns the message as indented JSON, for showing it to the user.
+func (td *TypedData) Format() (string, error) {
+	out, err := json.MarshalIndent(td.Message, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s (chain %v, contract %x)\n%s %s", td.Domain.Name, td.Domain.Version,
+		(*big.Int)(td.Domain.ChainId), td.Domain.Contract, td.PrimaryType, out), nil
+}
+
+// checkType checks that a field type is atomic, dynamic, an array of those or a
+// defined struct.
+func (t Types) checkType(typ string) error {
+	if strings.HasSuffix(typ, "[]") {
+		return t.checkType(strings.TrimSuffix(typ, "[]"))
+	}
+	switch typ {
+	case "address", "bool", "string", "bytes":
+		return nil
+	}
+	if m := intTypeRe.FindStringSubmatch(typ); m != nil {
+		if m[1] == "" {
+			return nil
+		}
+		bits, _ := strconv.Atoi(m[1])
+		if bits == 0 || bits > 256 || bits%8 != 0 {
+			return fmt.Errorf("invalid integer type %q", typ)
+		}
+		return nil
+	}
+	if m := bytesTypeRe.FindStringSubmatch(typ); m != nil {
+		size, _ := strconv.Atoi(m[1])
+		if size == 0 || size > 32 {
+			return fmt.Errorf("invalid bytes type %q", typ)
+		}
+		return nil
+	}
+	if _, ok := t[typ]; ok {
+		return nil
+	}
+	return fmt.Errorf("unknown type %q", typ)
+}
+
+// dependencies collects the struct types referenced by primaryType, itself
+// included.
+func (t Types) dependencies(primaryType string, found map[string]bool) {
+	primaryType = strings.TrimRight(primaryType, "[]")
+	if found[primaryType] {
+		return
+	}
+	if _, ok := t[primaryType]; !ok {
+		return
+	}
+	found[primaryType] = true
+	for _, field := range t[primaryType] {
+		t.dependencies(field.Type, found)
+	}
+}
+
+// encodeType returns the schema of the type, followed by the schemas of the
+// types it references in alphabetical order.
+func (t Types) encodeType(primaryType string) string {
+	found := make(map[string]bool)
+	t.dependencies(primaryType, found)
+	delete(found, primaryType)
+
+	deps := make([]string, 0, len(found))
+	for dep := range found {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+	deps = append([]string{primaryType}, deps...)
+
+	var buf bytes.Buffer
+	for _, dep := range deps {
+		buf.WriteString(dep)
+		buf.WriteString("(")
+		for i, field := range t[dep] {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(field.Type)
+			buf.WriteString(" ")
+			buf.WriteString(field.Name)
+		}
+		buf.WriteString(")")
+	}
+	return buf.String()
+}
+
+func (t Types) hashStruct(primaryType string, data map[string]interface{}) (types.Hash, error) {
+	fields := t[primaryType]
+	if len(data) > len(fields) {
+		return types.Hash{}, fmt.Errorf("%s has undeclared fields", primaryType)
+	}
+	buf := crypto.Keccak256([]byte(t.encodeType(primaryType)))
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return types.Hash{}, fmt.Errorf("%s is missing field %q", primaryType, field.Name)
+		}
+		enc, err := t.encodeValue(field.Type, value)
+		if err != nil {
+			return types.Hash{}, fmt.Errorf("%s.%s: %v", primaryType, field.Name, err)
+		}
+		buf = append(buf, enc...)
+	}
+	return crypto.Keccak256Hash(buf), nil
+}
+
+// encodeValue encodes a value to 32 bytes, hashing dynamic values, arrays and
+// structs.
+func (t Types) encodeValue(typ string, value interface{}) ([]byte, error) {
+	if strings.HasSuffix(typ, "[]") {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected array, got %T", value)
+		}
+		var buf []byte
+		for _, item := range items {
+			enc, err := t.encodeValue(strings.TrimSuffix(typ, "[]"), item)
+			if err != nil {
+				return nil, err
+			}
+			buf = append(buf, enc...)
+		}
+		return crypto.Keccak256(buf), nil
+	}
+	if _, ok := t[typ]; ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected %s object, got %T", typ, value)
+		}
+		h, err := t.hashStruct(typ, data)
+		return h[:], err
+	}
+	switch typ {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", value)
+		}
+		return crypto.Keccak256([]byte(s)), nil
+	case "bytes":
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected bool, got %T", value)
+		}
+		if b {
+			return math.PaddedBigBytes(big.NewInt(1), 32), nil
+		}
+		return make([]byte, 32), nil
+	case "address":
+		addr, err := toAddress(value)
+		if err != nil {
+			return nil, err
+		}
+		return math.PaddedBigBytes(addr.Big(), 32), nil
+	}
+	if m := bytesTypeRe.FindStringSubmatch(typ); m != nil {
+		size, _ := strconv.Atoi(m[1])
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != size {
+			return nil, fmt.Errorf("expected %d bytes, got %d", size, len(b))
+		}
+		enc := make([]byte, 32)
+		copy(enc, b)
+		return enc, nil
+	}
+	if m := intTypeRe.FindStringSubmatch(typ); m != nil {
+		bits := 256
+		if m[1] != "" {
+			bits, _ = strconv.Atoi(m[1])
+		}
+		n, err := toBigInt(value)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(typ, "u") {
+			if n.Sign() < 0 || n.BitLen() > bits {
+				return nil, fmt.Errorf("%v out of range for %s", n, typ)
+			}
+		} else if n.BitLen() > bits-1 && !(n.Sign() < 0 && new(big.Int).Add(n, big1).BitLen() <= bits-1) {
+			return nil, fmt.Errorf("%v out of range for %s", n, typ)
+		}
+		return math.PaddedBigBytes(math.U256(new(big.Int).Set(n)), 32), nil
+	}
+	return nil, fmt.Errorf("unknown type %q", typ)
+}
+
+var big1 = big.NewInt(1)
+
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case float64:
+		// JSON numbers are only exact up to 2^53
+		if v != float64(int64(v)) || v > 1<<53 || v < -(1<<53) {
+			return nil, fmt.Errorf("inexact number %v, use a string", v)
+		}
+		return big.NewInt(int64(v)), nil
+	case json.Number:
+		return toBigInt(string(v))
+	case string:
+		n, ok := new(big.Int).SetString(v, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("expected integer, got %T", value)
+}
+
+func toBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return hex.Decode(v)
+	}
+	return nil, fmt.Errorf("expected hex bytes, got %T", value)
+}
+
+func toAddress(value interface{}) (types.Address, error) {
+	switch v := value.(type) {
+	case types.Address:
+		return v, nil
+	case string:
+		b, err := hex.Decode(v)
+		if err != nil {
+			return types.Address{}, err
+		}
+		if len(b) != types.AddressLength {
+			return types.Address{}, fmt.Errorf("invalid address %q", v)
+		}
+		return types.BytesToAddress(b), nil
+	}
+	return types.Address{}, fmt.Errorf("expected address, got %T", value)
+}
+
+// Verify checks that sig is a signature of the typed data by addr. secp256k1
+// signatures are 65 bytes with V being 27 or 28, Ed25519 ones are 64 bytes and
+// need the public key of the signer.
+func Verify(td *TypedData, sig []byte, addr types.Address, pub []byte) error {
+	hash, err := td.SigningHash()
+	if err != nil {
+		return err
+	}
+	var signer types.Address
+	switch len(sig) {
+	case 65:
+		if sig[64] != 27 && sig[64] != 28 {
+			return errors.New("invalid signature (V is not 27 or 28)")
+		}
+		raw := make([]byte, 65)
+		copy(raw, sig)
+		raw[64] -= 27
+		scheme, _ := crypto.GetSigScheme(crypto.SigSecp256k1)
+		signer, err = scheme.Recover(hash[:], raw, nil)
+	case 64:
+		scheme, _ := crypto.GetSigScheme(crypto.SigEd25519)
+		signer, err = scheme.Recover(hash[:], sig, pub)
+	default:
+		return crypto.ErrInvalidSignature
+	}
+	if err != nil {
+		return err
+	}
+	if signer != addr {
+		return ErrSignerMismatch
+	}
+	return nil
+}
+
+// CheckChainId checks that the typed data is bound to the given chain.
+func (td *TypedData) CheckChainId(chainId *big.Int) error {
+	if td.Domain.ChainId == nil {
+		return ErrMissingChainId
+	}
+	if td.Domain.ChainId.ToInt().Cmp(chainId) != 0 {
+		return ErrWrongChainId
+	}
+	return nil
+}
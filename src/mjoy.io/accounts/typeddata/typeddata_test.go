@@ -0,0 +1,143 @@
+package typeddata
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"mjoy.io/utils/crypto"
+)
+
+const testOrder = `{
+	"types": {
+		"Order": [
+			{"name": "player", "type": "address"},
+			{"name": "item", "type": "Item"},
+			{"name": "price", "type": "uint256"},
+			{"name": "tags", "type": "string[]"}
+		],
+		"Item": [
+			{"name": "id", "type": "uint64"},
+			{"name": "name", "type": "string"}
+		]
+	},
+	"primaryType": "Order",
+	"domain": {
+		"name": "arena",
+		"version": "1",
+		"chainId": "0x65",
+		"contract": "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	},
+	"message": {
+		"player": "0x1f7b5e4a2b0c1d1e5c3a7f5e4f0e9d8c7b6a5948",
+		"item": {"id": 7, "name": "sword"},
+		"price": "1000000000000000000000",
+		"tags": ["rare", "pvp"]
+	}
+}`
+
+func newTestOrder(t *testing.T) *TypedData {
+	td := new(TypedData)
+	if err := json.Unmarshal([]byte(testOrder), td); err != nil {
+		t.Fatalf("failed to decode typed data: %v", err)
+	}
+	return td
+}
+
+func TestEncodeType(t *testing.T) {
+	td := newTestOrder(t)
+	want := "Order(address player,Item item,uint256 price,string[] tags)Item(uint64 id,string name)"
+	if have := td.Types.encodeType("Order"); have != want {
+		t.Errorf("encodeType mismatch:\nhave %s\nwant %s", have, want)
+	}
+}
+
+func TestDomainSeparation(t *testing.T) {
+	td := newTestOrder(t)
+	hash, err := td.SigningHash()
+	if err != nil {
+		t.Fatalf("SigningHash error: %v", err)
+	}
+	// Every domain field must change the signed hash
+	other := newTestOrder(t)
+	other.Domain.ChainId.ToInt().SetInt64(102)
+	otherChain, _ := other.SigningHash()
+
+	other = newTestOrder(t)
+	other.Domain.Name = "lobby"
+	otherApp, _ := other.SigningHash()
+
+	other = newTestOrder(t)
+	other.Domain.Contract[0] ^= 1
+	otherContract, _ := other.SigningHash()
+
+	for i, h := range [][32]byte{otherChain, otherApp, otherContract} {
+		if h == hash {
+			t.Errorf("domain change %d does not change the signing hash", i)
+		}
+	}
+	if err := td.CheckChainId(big.NewInt(101)); err != nil {
+		t.Errorf("CheckChainId error: %v", err)
+	}
+	if err := td.CheckChainId(big.NewInt(1)); err != ErrWrongChainId {
+		t.Errorf("CheckChainId error mismatch: have %v, want %v", err, ErrWrongChainId)
+	}
+}
+
+func TestInvalidMessages(t *testing.T) {
+	tests := []struct {
+		field string
+		value interface{}
+	}{
+		{"price", "-1"},
+		{"price", 1.5},
+		{"player", "0x1234"},
+		{"tags", "rare"},
+		{"item", map[string]interface{}{"id": float64(7)}},
+	}
+	for i, tt := range tests {
+		td := newTestOrder(t)
+		td.Message[tt.field] = tt.value
+		if _, err := td.SigningHash(); err == nil {
+			t.Errorf("test %d: invalid %s accepted", i, tt.field)
+		}
+	}
+	td := newTestOrder(t)
+	td.Types["Item"][0].Type = "uint7"
+	if _, err := td.SigningHash(); err == nil {
+		t.Errorf("invalid schema accepted")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	td := newTestOrder(t)
+	hash, err := td.SigningHash()
+	if err != nil {
+		t.Fatalf("SigningHash error: %v", err)
+	}
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+	sig[64] += 27
+	if err := Verify(td, sig, addr, nil); err != nil {
+		t.Errorf("secp256k1 Verify error: %v", err)
+	}
+	td.Message["price"] = "1000000000000000000001"
+	if err := Verify(td, sig, addr, nil); err == nil {
+		t.Errorf("tampered message verified")
+	}
+
+	td = newTestOrder(t)
+	prv, _ := crypto.GenerateEd25519Key()
+	pub := prv.Public().(ed25519.PublicKey)
+	if err := Verify(td, ed25519.Sign(prv, hash[:]), crypto.Ed25519PubkeyToAddress(pub), pub); err != nil {
+		t.Errorf("ed25519 Verify error: %v", err)
+	}
+	if err := Verify(td, ed25519.Sign(prv, hash[:]), addr, pub); err != ErrSignerMismatch {
+		t.Errorf("ed25519 Verify error mismatch: have %v, want %v", err, ErrSignerMismatch)
+	}
+}
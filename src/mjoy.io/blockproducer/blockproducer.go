@@ -71,16 +71,16 @@ type Blockproducer struct {
 	shouldStart int32 // should start indicates whether we should start after sync
 }
 
-func New(mjoy Backend,inter Interpreter, config *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine) *Blockproducer {
+func New(mjoy Backend,inter Interpreter, config *params.ChainConfig, produce *Config, mux *event.TypeMux, engine consensus.Engine) *Blockproducer {
 	blockproducer := &Blockproducer{
 		mjoy:      mjoy,
 		inter:      inter,
 		mux:      mux,
 		engine:   engine,
-		producer:   newProducer(config, engine, types.Address{}, mjoy,inter, mux),
+		producer:   newProducer(config, produce, engine, types.Address{}, mjoy,inter, mux),
 		canStart: 1,
 	}
-	blockproducer.Register(NewCpuAgent(mjoy.BlockChain(), engine))
+	blockproducer.Register(NewCpuAgent(mjoy.BlockChain(), engine, produce.Instant))
 	go blockproducer.update()
 
 	return blockproducer
@@ -0,0 +1,62 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: config.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockproducer
+
+import (
+	"mjoy.io/params"
+)
+
+// Config are the producing settings of the local node. Its limits can only
+// tighten the consensus limits of the chain config, a zero limit leaving the
+// consensus one in effect.
+type Config struct {
+	Period     uint64 // Target number of seconds between blocks, zero producing as soon as the engine allows
+	MaxSize    uint64 // Maximum encoded size of a produced block in bytes
+	MaxTxs     uint64 // Maximum number of transactions of a produced block, the reward one included
+	MaxActions uint64 // Maximum number of contract actions run by a produced block
+	WaitForTxs bool   // Wait for transactions instead of producing empty blocks
+	Instant    bool   // Hand sealed blocks over at once, skipping the simulated production delay
+}
+
+// DefaultConfig produces blocks as fast as the consensus engine allows, filled
+// up to the consensus limits, empty ones included.
+var DefaultConfig = Config{}
+
+// limits returns the limits of the produced blocks, the tightest of the local
+// and the consensus ones.
+func (c *Config) limits(chain *params.ChainConfig) params.BlockLimits {
+	limits := params.BlockLimits{MaxSize: c.MaxSize, MaxTxs: c.MaxTxs, MaxActions: c.MaxActions}
+	if chain.Limits == nil {
+		return limits
+	}
+	limits.MaxSize = tighter(limits.MaxSize, chain.Limits.MaxSize)
+	limits.MaxTxs = tighter(limits.MaxTxs, chain.Limits.MaxTxs)
+	limits.MaxActions = tighter(limits.MaxActions, chain.Limits.MaxActions)
+	return limits
+}
+
+// tighter returns the lower of two limits, zero meaning unlimited.
+func tighter(a, b uint64) uint64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
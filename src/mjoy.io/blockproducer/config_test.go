@@ -0,0 +1,45 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: config_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockproducer
+
+import (
+	"testing"
+
+	"mjoy.io/params"
+)
+
+func TestConfigLimits(t *testing.T) {
+	tests := []struct {
+		local, chain *params.BlockLimits
+		want         params.BlockLimits
+	}{
+		{&params.BlockLimits{}, nil, params.BlockLimits{}},
+		{&params.BlockLimits{MaxSize: 1000}, nil, params.BlockLimits{MaxSize: 1000}},
+		{&params.BlockLimits{}, &params.BlockLimits{MaxTxs: 10, MaxActions: 20}, params.BlockLimits{MaxTxs: 10, MaxActions: 20}},
+		{&params.BlockLimits{MaxTxs: 5, MaxActions: 50}, &params.BlockLimits{MaxTxs: 10, MaxActions: 20}, params.BlockLimits{MaxTxs: 5, MaxActions: 20}},
+	}
+	for i, tt := range tests {
+		config := &Config{MaxSize: tt.local.MaxSize, MaxTxs: tt.local.MaxTxs, MaxActions: tt.local.MaxActions}
+		if have := config.limits(&params.ChainConfig{Limits: tt.chain}); have != tt.want {
+			t.Errorf("test %d: limits mismatch: have %+v, want %+v", i, have, tt.want)
+		}
+	}
+}
@@ -43,14 +43,16 @@ type CpuAgent struct {
 	engine consensus.Engine
 
 	isProducing int32 // isProducing indicates whether the agent is currently producing
+	instant     bool  // instant hands sealed blocks over without the simulated delay
 }
 
-func NewCpuAgent(chain consensus.ChainReader, engine consensus.Engine) *CpuAgent {
+func NewCpuAgent(chain consensus.ChainReader, engine consensus.Engine, instant bool) *CpuAgent {
 	cpuBlockproducer := &CpuAgent{
-		chain:  chain,
-		engine: engine,
-		stop:   make(chan struct{}, 1),
-		workCh: make(chan *Work, 1),
+		chain:   chain,
+		engine:  engine,
+		stop:    make(chan struct{}, 1),
+		workCh:  make(chan *Work, 1),
+		instant: instant,
 	}
 	return cpuBlockproducer
 }
@@ -113,7 +115,9 @@ func (self *CpuAgent) blockproducer(work *Work, stop <-chan struct{}) {
 
 		logger.Infof("Successfully sealed new block number: %d  hash:0x%x\n" , result.Number() , result.Hash())
 		//fmt.Println("ProduceBlock: num:" , result.Number().String(),"  Hash:",result.Hash().String())
-		time.Sleep(time.Duration(rand.Intn(20))*time.Second)
+		if !self.instant {
+			time.Sleep(time.Duration(rand.Intn(20))*time.Second)
+		}
 		self.returnCh <- &Result{work, result}
 		//fmt.Printf("!!!!!Return Produce work......")
 
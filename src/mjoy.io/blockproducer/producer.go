@@ -57,6 +57,9 @@ const (
 	chainHeadChanSize = 10
 	// chainSideChanSize is the size of channel listening to ChainSideEvent.
 	chainSideChanSize = 10
+	// headerReserve is the room left in the size of a block for the header
+	// signature, which is added after the transactions are committed.
+	headerReserve = 128
 )
 
 
@@ -82,6 +85,8 @@ type Work struct {
 	ancestors *set.Set       // ancestor set
 	family    *set.Set       // family set
 	tcount    int            // tx count in cycle
+	size      uint64         // estimated encoded size of the block
+	actions   uint64         // contract actions run by the committed transactions
 	Block *block.Block // the new block
 	header   *block.Header
 	txs      []*transaction.Transaction
@@ -101,6 +106,10 @@ type producer struct {
 	config *params.ChainConfig
 	engine consensus.Engine
 
+	period     uint64             // target number of seconds between blocks
+	limits     params.BlockLimits // limits of the produced blocks
+	waitForTxs bool               // wait for transactions instead of producing empty blocks
+
 	mu sync.Mutex
 
 	// update loop
@@ -128,17 +137,23 @@ type producer struct {
 	currentMu sync.Mutex
 	current   *Work
 
+	workTimer *time.Timer // commits the new work put off for the block period
+
 	unconfirmed *unconfirmedBlocks // set of locally produced blocks pending canonicalness confirmations
 
 	// atomic status counters
 	producing int32
 	atWork int32
+	waiting int32 // set while waiting for transactions to produce a block
 }
 
-func newProducer(config *params.ChainConfig, engine consensus.Engine, coinbase types.Address, mjoy Backend,inter Interpreter ,  mux *event.TypeMux) *producer {
+func newProducer(config *params.ChainConfig, produce *Config, engine consensus.Engine, coinbase types.Address, mjoy Backend,inter Interpreter ,  mux *event.TypeMux) *producer {
 	producer := &producer{
 		config:         config,
 		engine:         engine,
+		period:         produce.Period,
+		limits:         produce.limits(config),
+		waitForTxs:     produce.WaitForTxs,
 		mjoy:            mjoy,
 		inter:          inter,
 		mux:            mux,
@@ -228,6 +243,10 @@ func (self *producer) stop() {
 			agent.Stop()
 		}
 	}
+	if self.workTimer != nil {
+		self.workTimer.Stop()
+		self.workTimer = nil
+	}
 	atomic.StoreInt32(&self.producing, 0)
 	atomic.StoreInt32(&self.atWork, 0)
 }
@@ -261,6 +280,10 @@ func (self *producer) update() {
 		// Handle TxPreEvent
 		case ev := <-self.txCh:
 			_ = ev
+			// Produce the block put off for lack of transactions
+			if atomic.LoadInt32(&self.producing) == 1 && atomic.CompareAndSwapInt32(&self.waiting, 1, 0) {
+				self.commitNewWork()
+			}
 			// Apply transaction to the pending state if we're not producing
 			//if atomic.LoadInt32(&self.producing) == 0 {
 			//	self.currentMu.Lock()
@@ -414,7 +437,7 @@ func (self *producer) addTestTransactions(num *big.Int){
 	//sign tx
 	txSign , err := w0.SignTxWithPassphrase(w0.Accounts()[0] , "123" ,tx , self.config.ChainId)
 	if err != nil {
-		logger.Errorf("w0.SignTxWithPassphrase err :" , err.Error())
+		logger.Error("w0.SignTxWithPassphrase err :" , err.Error())
 		return
 	}
 
@@ -424,6 +447,17 @@ func (self *producer) addTestTransactions(num *big.Int){
 
 }
 
+// scheduleNewWork commits new work after the given delay, replacing the commit
+// scheduled before if any.
+//
+// Note, this method assumes the producer lock is held!
+func (self *producer) scheduleNewWork(delay time.Duration) {
+	if self.workTimer != nil {
+		self.workTimer.Stop()
+	}
+	self.workTimer = time.AfterFunc(delay, self.commitNewWork)
+}
+
 func (self *producer) commitNewWork() {
 
 	self.mu.Lock()
@@ -438,6 +472,16 @@ func (self *producer) commitNewWork() {
 	if parent.Time().Cmp(new(big.Int).SetInt64(tstamp)) >= 0 {
 		tstamp = parent.Time().Int64() + 1
 	}
+	// Keep the target interval after the parent
+	if self.period > 0 && tstamp < parent.Time().Int64()+int64(self.period) {
+		tstamp = parent.Time().Int64() + int64(self.period)
+		if wait := time.Until(time.Unix(tstamp, 0)); wait > 0 {
+			// Come back once the period is over instead of sleeping on the locks
+			logger.Debug("Waiting for the block period", "wait", common.PrettyDuration(wait))
+			self.scheduleNewWork(wait)
+			return
+		}
+	}
 
 	// this will ensure we're not going off too far in the future
 	if now := time.Now().Unix(); tstamp > now+1 {
@@ -481,11 +525,22 @@ func (self *producer) commitNewWork() {
 		return
 	}
 	logger.Info(">>>>>>>>>PendingTx Len:" , len(pending))
+	if len(pending) == 0 && self.waitForTxs {
+		logger.Debug("Waiting for transactions to produce a block", "number", header.Number.IntVal.Uint64())
+		work.Block = block.NewBlock(header, nil, nil)
+		atomic.StoreInt32(&self.waiting, 1)
+		return
+	}
+	atomic.StoreInt32(&self.waiting, 0)
+	work.size = uint64(block.NewBlock(header, nil, nil).Size()) + headerReserve
 
 
 	//txs := transaction.NewTransactionsForProducing(self.current.signer, pending)
 	actions := transaction.ActionSlice{}
 	action := transaction.Action{&types.Address{},balancetransfer.MakeActionParamsReword(header.BlockProducer)}
+	if rewarder, ok := self.engine.(consensus.Rewarder); ok {
+		action = rewarder.RewardAction(header)
+	}
 	actions = append(actions, action)
 
 	tx := transaction.NewTransaction(num.Uint64() - 1 , actions)
@@ -496,12 +551,15 @@ func (self *producer) commitNewWork() {
 		return
 	}
 	txReword.Priority = big.NewInt(10)
-	txs := transaction.NewTransactionsByPriorityAndNonce(self.current.signer , pending, txReword)
+	txs := transaction.NewTransactionsByPriorityAndNonce(self.current.signer , pending, txReword, self.mjoy.TxPool().OrderingPolicy())
 
 	sdkHandler := sdk.NewTmpStatusManager(self.chain.GetDb(), work.state,self.coinbase)
 	vmHandler := interpreter.NewVm()
 	sysparam := intertypes.MakeSystemParams(sdkHandler,vmHandler )
-	work.commitTransactions(self.mux, txs, self.chain, self.coinbase , sysparam)
+	sysparam.BlockNumber = header.Number.IntVal.Uint64()
+	sysparam.ChainId = self.config.ChainId
+	sysparam.Staking = self.config.Dpos != nil
+	work.commitTransactions(self.mux, txs, self.chain, self.coinbase , sysparam, &self.limits)
 
 
 	// Create the new block to seal with the consensus engine
@@ -518,7 +576,7 @@ func (self *producer) commitNewWork() {
 	self.push(work)
 }
 
-func (env *Work) commitTransactions(mux *event.TypeMux, txs *transaction.TransactionsByPriorityAndNonce, bc *blockchain.BlockChain, coinbase types.Address , sysparam *intertypes.SystemParams) {
+func (env *Work) commitTransactions(mux *event.TypeMux, txs *transaction.TransactionsByPriorityAndNonce, bc *blockchain.BlockChain, coinbase types.Address , sysparam *intertypes.SystemParams, limits *params.BlockLimits) {
 
 	var coalescedLogs []*transaction.Log
 
@@ -553,6 +611,24 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *transaction.Transac
 		//	txs.Shift()
 		//}
 
+		// Stop once the block is full, skipping the accounts whose next transaction
+		// doesn't fit in what is left of it
+		if limits.MaxTxs > 0 && uint64(env.tcount) >= limits.MaxTxs {
+			logger.Debug("Block transaction limit reached", "count", env.tcount)
+			break
+		}
+		size, actions := uint64(tx.Size()), uint64(len(tx.Data.Actions))
+		if limits.MaxSize > 0 && env.size+size > limits.MaxSize {
+			logger.Debug("Skipping account with transaction over the block size", "sender", from, "size", size)
+			txs.Pop()
+			continue
+		}
+		if limits.MaxActions > 0 && env.actions+actions > limits.MaxActions {
+			logger.Debug("Skipping account with transaction over the execution budget", "sender", from, "actions", actions)
+			txs.Pop()
+			continue
+		}
+
 		// Start executing the transaction
 		env.state.Prepare(tx.Hash(), types.Hash{}, env.tcount)
 
@@ -568,10 +644,23 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *transaction.Transac
 			logger.Info("Skipping account with hight nonce", "sender", from, "nonce", tx.Nonce())
 			txs.Pop()
 
+		case transaction.ErrTxNotYetValid:
+			// Transaction window not open yet, the account's later nonces can't go either
+			logger.Info("Skipping account with not yet valid transaction", "sender", from, "nonce", tx.Nonce())
+			txs.Pop()
+
+		case transaction.ErrTxExpired:
+			// Expiry race between the transaction pool and blockproducer, shift
+			logger.Info("Skipping expired transaction", "sender", from, "nonce", tx.Nonce())
+			txs.Shift()
+
 		case nil:
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
 			env.tcount++
+			env.size += size
+			env.actions += actions
+			txs.Packed()
 			txs.Shift()
 
 		default:
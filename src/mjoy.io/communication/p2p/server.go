@@ -171,6 +171,7 @@ type Server struct {
 	quit          chan struct{}
 	addstatic     chan *discover.Node
 	removestatic  chan *discover.Node
+	banpeer       chan peerBan
 	posthandshake chan *conn
 	addpeer       chan *conn
 	delpeer       chan peerDrop
@@ -180,6 +181,11 @@ type Server struct {
 
 type peerOpFunc func(map[discover.NodeID]*Peer)
 
+type peerBan struct {
+	id    discover.NodeID
+	until time.Time
+}
+
 type peerDrop struct {
 	*Peer
 	err       error
@@ -301,6 +307,15 @@ func (srv *Server) RemovePeer(node *discover.Node) {
 	}
 }
 
+// BanPeer disconnects the given node and refuses its connections for the given
+// duration.
+func (srv *Server) BanPeer(id discover.NodeID, duration time.Duration) {
+	select {
+	case srv.banpeer <- peerBan{id, time.Now().Add(duration)}:
+	case <-srv.quit:
+	}
+}
+
 // SubscribePeers subscribes the given channel to peer events
 func (srv *Server) SubscribeEvents(ch chan *PeerEvent) event.Subscription {
 	return srv.peerFeed.Subscribe(ch)
@@ -381,6 +396,7 @@ func (srv *Server) Start() (err error) {
 	srv.posthandshake = make(chan *conn)
 	srv.addstatic = make(chan *discover.Node)
 	srv.removestatic = make(chan *discover.Node)
+	srv.banpeer = make(chan peerBan)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
 	//fmt.Printf(">>>>>Src.ListenAddr:%s\n" , srv.ListenAddr)
@@ -471,6 +487,7 @@ func (srv *Server) run(dialstate dialer) {
 	var (
 		peers        = make(map[discover.NodeID]*Peer)
 		trusted      = make(map[discover.NodeID]bool, len(srv.TrustedNodes))
+		banned       = make(map[discover.NodeID]time.Time)
 		taskdone     = make(chan task, maxActiveDialTasks)
 		runningTasks []task
 		queuedTasks  []task // tasks that can't run yet
@@ -535,6 +552,14 @@ running:
 			if p, ok := peers[n.ID]; ok {
 				p.Disconnect(DiscRequested)
 			}
+		case b := <-srv.banpeer:
+			// This channel is used by BanPeer to disconnect a
+			// misbehaving peer and keep it away for a while.
+			logger.Debug("Banning node.", "id", b.id, "until", b.until)
+			banned[b.id] = b.until
+			if p, ok := peers[b.id]; ok {
+				p.Disconnect(DiscUselessPeer)
+			}
 		case op := <-srv.peerOp:
 			// This channel is used by Peers and PeerCount.
 			op(peers)
@@ -553,16 +578,19 @@ running:
 				// Ensure that the trusted flag is set before checking against MaxPeers.
 				c.flags |= trustedConn
 			}
+			if until, ok := banned[c.id]; ok && time.Now().After(until) {
+				delete(banned, c.id)
+			}
 			// TODO: track in-progress inbound node IDs (pre-Peer) to avoid dialing them.
 			select {
-			case c.cont <- srv.encHandshakeChecks(peers, c):
+			case c.cont <- srv.banChecks(banned, c, srv.encHandshakeChecks(peers, c)):
 			case <-srv.quit:
 				break running
 			}
 		case c := <-srv.addpeer:
 			// At this point the connection is past the protocol handshake.
 			// Its capabilities are known and the remote identity is verified.
-			err := srv.protoHandshakeChecks(peers, c)
+			err := srv.banChecks(banned, c, srv.protoHandshakeChecks(peers, c))
 			if err == nil {
 				// The handshakes are done and it passed all checks.
 				p := newPeer(c, srv.Protocols)
@@ -638,6 +666,17 @@ func (srv *Server) encHandshakeChecks(peers map[discover.NodeID]*Peer, c *conn)
 	}
 }
 
+// banChecks refuses connections of banned nodes that passed the other checks.
+func (srv *Server) banChecks(banned map[discover.NodeID]time.Time, c *conn, err error) error {
+	if err != nil {
+		return err
+	}
+	if _, ok := banned[c.id]; ok && !c.is(trustedConn) {
+		return DiscUselessPeer
+	}
+	return nil
+}
+
 type tempError interface {
 	Temporary() bool
 }
@@ -126,6 +126,51 @@ func TestServerListen(t *testing.T) {
 	}
 }
 
+// Tests that a banned node is disconnected and refused on redial until its ban
+// expires.
+func TestServerBanPeer(t *testing.T) {
+	connected := make(chan *Peer, 4)
+	remid := randomID()
+	srv := startTestServer(t, remid, func(p *Peer) { connected <- p })
+	defer srv.Stop()
+
+	dial := func() (net.Conn, *Peer) {
+		conn, err := net.DialTimeout("tcp", srv.ListenAddr, 5*time.Second)
+		if err != nil {
+			t.Fatalf("could not dial: %v", err)
+		}
+		select {
+		case peer := <-connected:
+			return conn, peer
+		case <-time.After(500 * time.Millisecond):
+			return conn, nil
+		}
+	}
+	conn, peer := dial()
+	defer conn.Close()
+	if peer == nil {
+		t.Fatal("server did not accept within the timeout")
+	}
+	srv.BanPeer(remid, time.Hour)
+	for start := time.Now(); srv.PeerCount() != 0; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("banned peer not disconnected")
+		}
+	}
+	conn, peer = dial()
+	defer conn.Close()
+	if peer != nil {
+		t.Fatal("banned peer accepted on redial")
+	}
+	// An expired ban lets the node back in
+	srv.BanPeer(remid, -time.Second)
+	conn, peer = dial()
+	defer conn.Close()
+	if peer == nil {
+		t.Fatal("peer with an expired ban refused")
+	}
+}
+
 func TestServerDial(t *testing.T) {
 	// run a one-shot TCP server to handle the connection.
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
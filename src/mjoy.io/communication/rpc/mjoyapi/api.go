@@ -30,6 +30,7 @@ import (
 
 	"mjoy.io/accounts"
 	"mjoy.io/accounts/keystore"
+	"mjoy.io/accounts/typeddata"
 	"mjoy.io/common/types/util/hex"
 	"mjoy.io/core/transaction"
 	"mjoy.io/common/types"
@@ -42,6 +43,9 @@ import (
 	"mjoy.io/core/sdk"
 	"mjoy.io/core/interpreter"
 	"mjoy.io/core/interpreter/intertypes"
+	"mjoy.io/core/interpreter/staking"
+	"mjoy.io/core"
+	"mjoy.io/core/txprocessor"
 )
 
 
@@ -62,6 +66,34 @@ func (s *PublicMjoyAPI) ProtocolVersion() hex.Uint {
 	return hex.Uint(s.b.ProtocolVersion())
 }
 
+// SuggestFee returns the suggested fee per byte, in 1/1000 fee units, based on
+// the fees paid in recent blocks. Multiply it by the signed transaction size to
+// get the fee to pay.
+func (s *PublicMjoyAPI) SuggestFee(ctx context.Context) (*hex.Big, error) {
+	rate, err := s.b.SuggestFeePerByte(ctx)
+	return (*hex.Big)(rate), err
+}
+
+// VerifyTypedData checks that sig is a signature of the typed data by addr, as
+// produced by personal_signTypedData. Ed25519 signatures need the public key of
+// the signer. The typed data must be bound to this chain.
+func (s *PublicMjoyAPI) VerifyTypedData(ctx context.Context, data typeddata.TypedData, sig hex.Bytes, addr types.Address, pubKey *hex.Bytes) (bool, error) {
+	if err := data.CheckChainId(s.b.ChainConfig().ChainId); err != nil {
+		return false, err
+	}
+	var pub []byte
+	if pubKey != nil {
+		pub = *pubKey
+	}
+	if err := typeddata.Verify(&data, sig, addr, pub); err != nil {
+		if err == typeddata.ErrSignerMismatch || err == crypto.ErrInvalidSignature {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // Syncing returns false in case the node is currently not syncing with the network. It can be up to date or has not
 // yet received the latest block headers from its pears. In case it is synchronizing:
 // - startingBlock: block number this node started to synchronise from
@@ -123,13 +155,113 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 	return content
 }
 
-// Status returns the number of pending and queued transaction in the pool.
-func (s *PublicTxPoolAPI) Status() map[string]hex.Uint {
-	pending, queue := s.b.Stats()
-	return map[string]hex.Uint{
-		"pending": hex.Uint(pending),
-		"queued":  hex.Uint(queue),
+// ContentFrom returns the transactions of an account contained within the
+// transaction pool.
+func (s *PublicTxPoolAPI) ContentFrom(addr types.Address) map[string]map[string]*RPCTransaction {
+	content := map[string]map[string]*RPCTransaction{
+		"pending": make(map[string]*RPCTransaction),
+		"queued":  make(map[string]*RPCTransaction),
+	}
+	pending, queue := s.b.TxPoolContentFrom(addr)
+
+	for _, tx := range pending {
+		content["pending"][fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+	}
+	for _, tx := range queue {
+		content["queued"][fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
 	}
+	return content
+}
+
+// Status returns the number of pending and queued transaction in the pool. If
+// transaction hashes are given, it returns the status of each of them instead:
+// unknown, queued, pending or included.
+func (s *PublicTxPoolAPI) Status(hashes *[]types.Hash) interface{} {
+	if hashes == nil {
+		pending, queue := s.b.Stats()
+		return map[string]hex.Uint{
+			"pending": hex.Uint(pending),
+			"queued":  hex.Uint(queue),
+		}
+	}
+	statuses := s.b.TxPoolStatus(*hashes)
+	result := make([]string, len(statuses))
+	for i, status := range statuses {
+		// Transactions unknown to the pool may have left it in a block
+		if status == txprocessor.TxStatusUnknown {
+			if tx, _, _, _ := blockchain.GetTransaction(s.b.ChainDb(), (*hashes)[i]); tx != nil {
+				status = txprocessor.TxStatusIncluded
+			}
+		}
+		result[i] = status.String()
+	}
+	return result
+}
+
+// RPCTxLifecycle is a lifecycle event of a transaction of the pool.
+type RPCTxLifecycle struct {
+	Hash   types.Hash `json:"hash"`
+	Status string     `json:"status"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// TransactionLifecycle creates a subscription that is triggered each time a
+// transaction of the pool is queued, promoted to pending, included, dropped or
+// replaced. If hashes are given, only the events of those transactions are sent.
+func (s *PublicTxPoolAPI) TransactionLifecycle(ctx context.Context, hashes *[]types.Hash) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	var watched map[types.Hash]bool
+	if hashes != nil {
+		watched = make(map[types.Hash]bool, len(*hashes))
+		for _, hash := range *hashes {
+			watched[hash] = true
+		}
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan core.TxLifecycleEvent, 128)
+		lifecycleSub := s.b.SubscribeTxLifecycleEvent(events)
+		defer lifecycleSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-events:
+				if watched != nil && !watched[ev.Hash] {
+					continue
+				}
+				notifier.Notify(rpcSub.ID, &RPCTxLifecycle{Hash: ev.Hash, Status: ev.Status, Reason: ev.Reason})
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// PrivateTxPoolAPI offers the management of the transaction pool to the node
+// operator.
+type PrivateTxPoolAPI struct {
+	b Backend
+}
+
+// NewPrivateTxPoolAPI creates a new tx pool service managing the transaction pool.
+func NewPrivateTxPoolAPI(b Backend) *PrivateTxPoolAPI {
+	return &PrivateTxPoolAPI{b}
+}
+
+// Remove drops a transaction from the pool, locals included, and reports
+// whether it was there. Later transactions of the same account are moved back
+// to the queue.
+func (s *PrivateTxPoolAPI) Remove(hash types.Hash) bool {
+	return s.b.RemovePoolTx(hash)
 }
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
@@ -291,6 +423,16 @@ func (s *PrivateAccountAPI) NewAccount(password string) (types.Address, error) {
 	return types.Address{}, err
 }
 
+// NewEd25519Account will create a new account backed by an Ed25519 key and returns
+// the address for the new account.
+func (s *PrivateAccountAPI) NewEd25519Account(password string) (types.Address, error) {
+	acc, err := fetchKeystore(s.am).NewEd25519Account(password)
+	if err == nil {
+		return acc.Address, nil
+	}
+	return types.Address{}, err
+}
+
 // fetchKeystore retrives the encrypted keystore from the account manager.
 func fetchKeystore(am *accounts.Manager) *keystore.KeyStore {
 	return am.Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
@@ -363,6 +505,83 @@ func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs
 	return submitTransaction(ctx, s.b, signed)
 }
 
+// SignMultiSigTransaction creates a multisig transaction for the given arguments
+// and adds the partial signature of the owner, whose key is decrypted with the
+// given password. The other owners sign the same transaction, with the nonce and
+// fee filled in, and the partial signatures are then merged with
+// CombineMultiSigTransactions.
+//
+// The sending account is args.From, which must have registered the key set with
+// the multisig inner contract.
+func (s *PrivateAccountAPI) SignMultiSigTransaction(ctx context.Context, args SendTxArgs, owner types.Address, passwd string) (*SignTransactionResult, error) {
+	if args.MultiSig == nil {
+		return nil, transaction.ErrNotMultiSig
+	}
+	account := accounts.Account{Address: owner}
+
+	wallet, err := s.am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	if err := args.setDefaults(ctx, s.b); err != nil {
+		return nil, err
+	}
+	tx := args.toTransaction()
+	if !tx.Data.MultiSig.IsOwner(owner) {
+		return nil, transaction.ErrNotMultiSigOwner
+	}
+	signed, err := wallet.SignTxWithPassphrase(account, passwd, tx, s.b.ChainConfig().ChainId)
+	if err != nil {
+		return nil, err
+	}
+	return newSignTransactionResult(signed)
+}
+
+// AddMultiSigSignature adds the partial signature of the owner to a Msgp encoded
+// multisig transaction.
+func (s *PrivateAccountAPI) AddMultiSigSignature(ctx context.Context, encodedTx hex.Bytes, owner types.Address, passwd string) (*SignTransactionResult, error) {
+	tx := new(transaction.Transaction)
+	if err := msgp.Decode(bytes.NewBuffer(encodedTx), tx); err != nil {
+		return nil, err
+	}
+	if !tx.IsMultiSig() {
+		return nil, transaction.ErrNotMultiSig
+	}
+	if !tx.Data.MultiSig.IsOwner(owner) {
+		return nil, transaction.ErrNotMultiSigOwner
+	}
+	account := accounts.Account{Address: owner}
+
+	wallet, err := s.am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := wallet.SignTxWithPassphrase(account, passwd, tx, s.b.ChainConfig().ChainId)
+	if err != nil {
+		return nil, err
+	}
+	return newSignTransactionResult(signed)
+}
+
+// CombineMultiSigTransactions merges the partial signatures of Msgp encoded
+// copies of the same multisig transaction. Once enough owners signed, the result
+// can be submitted with sendRawTransaction.
+func (s *PrivateAccountAPI) CombineMultiSigTransactions(ctx context.Context, encodedTxs []hex.Bytes) (*SignTransactionResult, error) {
+	txs := make([]*transaction.Transaction, len(encodedTxs))
+	for i, encodedTx := range encodedTxs {
+		txs[i] = new(transaction.Transaction)
+		if err := msgp.Decode(bytes.NewBuffer(encodedTx), txs[i]); err != nil {
+			return nil, err
+		}
+	}
+	signer := transaction.NewMSigner(s.b.ChainConfig().ChainId)
+	combined, err := transaction.CombineSignatures(signer, txs...)
+	if err != nil {
+		return nil, err
+	}
+	return newSignTransactionResult(combined)
+}
+
 // signHash is a helper function that calculates a hash for the given message that can be
 // safely used to calculate a signature from.
 //
@@ -379,7 +598,8 @@ func signHash(data []byte) []byte {
 // keccack256("\x19mjoy Signed Message:\n" + len(message) + message))
 //
 // Note, the produced signature conforms to the secp256k1 curve R, S and V values,
-// where the V value will be 27 or 28 for legacy reasons.
+// where the V value will be 27 or 28 for legacy reasons. Ed25519 accounts produce
+// a plain 64 byte Ed25519 signature.
 //
 // The key used to calculate the signature is decrypted with the given password.
 //
@@ -396,7 +616,40 @@ func (s *PrivateAccountAPI) Sign(ctx context.Context, data hex.Bytes, addr types
 	if err != nil {
 		return nil, err
 	}
-	signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	if len(signature) == 65 {
+		signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	}
+	return signature, nil
+}
+
+// SignTypedData signs typed structured data, as described in package typeddata,
+// with the key of addr decrypted with the given password. The domain of the data
+// must be bound to this chain.
+//
+// Like Sign, secp256k1 signatures have a V value of 27 or 28, Ed25519 accounts
+// produce a plain 64 byte signature.
+func (s *PrivateAccountAPI) SignTypedData(ctx context.Context, addr types.Address, data typeddata.TypedData, passwd string) (hex.Bytes, error) {
+	if err := data.CheckChainId(s.b.ChainConfig().ChainId); err != nil {
+		return nil, err
+	}
+	hash, err := data.SigningHash()
+	if err != nil {
+		return nil, err
+	}
+	// Look up the wallet containing the requested signer
+	account := accounts.Account{Address: addr}
+
+	wallet, err := s.b.AccountManager().Find(account)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := wallet.SignHashWithPassphrase(account, passwd, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(signature) == 65 {
+		signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	}
 	return signature, nil
 }
 
@@ -509,6 +762,46 @@ func (s *PublicBlockChainAPI) GetBlockByHash(ctx context.Context, blockHash type
 	return nil, err
 }
 
+// GetFinalizedBlock returns the last block proven final by a commit certificate,
+// along with the certificate. The genesis block is returned while no block was
+// finalized.
+func (s *PublicBlockChainAPI) GetFinalizedBlock(ctx context.Context, fullTx bool) (map[string]interface{}, error) {
+	b, cert := s.b.FinalizedBlock()
+	if b == nil {
+		return nil, nil
+	}
+	fields, err := s.rpcOutputBlock(b, true, fullTx)
+	if err != nil {
+		return nil, err
+	}
+	if cert != nil {
+		fields["certificate"] = rpcOutputCertificate(cert)
+	}
+	return fields, nil
+}
+
+// GetEvidence returns the evidence of a producer signing two blocks at the given
+// height, with the params of the staking call slashing it. Nil is returned if
+// the producer was not caught.
+func (s *PublicBlockChainAPI) GetEvidence(ctx context.Context, producer types.Address, number hex.Uint64) (map[string]interface{}, error) {
+	evidence := s.b.GetEvidence(producer, uint64(number))
+	if evidence == nil {
+		return nil, nil
+	}
+	params, err := staking.MakeSlashParam(evidence)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"producer":    producer,
+		"number":      number,
+		"first":       evidence.First.Hash(),
+		"second":      evidence.Second.Hash(),
+		"contract":    staking.StakingAddress,
+		"slashParams": hex.Bytes(params),
+	}, nil
+}
+
 // GetCode returns the code stored at the given address in the state for the given block number.
 func (s *PublicBlockChainAPI) GetCode(ctx context.Context, address types.Address, blockNr rpc.BlockNumber) (hex.Bytes, error) {
 	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
@@ -531,6 +824,26 @@ func (s *PublicBlockChainAPI) GetStorageAt(ctx context.Context, address types.Ad
 	return res[:], state.Error()
 }
 
+// RPCPrecommit is the RPC output of a precommit of a commit certificate.
+type RPCPrecommit struct {
+	Voter     types.Address `json:"voter"`
+	Signature hex.Bytes     `json:"signature"`
+}
+
+// rpcOutputCertificate converts a commit certificate to its RPC output.
+func rpcOutputCertificate(cert *block.Certificate) map[string]interface{} {
+	precommits := make([]RPCPrecommit, 0, len(cert.Precommits))
+	for _, vote := range cert.Precommits {
+		voter, _ := vote.Voter()
+		precommits = append(precommits, RPCPrecommit{Voter: voter, Signature: vote.Signature})
+	}
+	return map[string]interface{}{
+		"number":     hex.Uint64(cert.Number),
+		"hash":       cert.Hash,
+		"precommits": precommits,
+	}
+}
+
 type ConsensusDataHex struct {
 	Id      string         `json:"id"`
 	Para    *hex.Bytes     `json:"data"`
@@ -611,11 +924,15 @@ type RPCTransaction struct {
 	From             types.Address  			`json:"from"`
 	Hash             types.Hash     			`json:"hash"`
 	Nonce            hex.Uint64     			`json:"nonce"`
+	Fee              hex.Uint64     			`json:"fee"`
+	ValidAfter       hex.Uint64     			`json:"validAfter"`
+	ValidUntil       hex.Uint64     			`json:"validUntil"`
 	TransactionIndex hex.Uint       			`json:"transactionIndex"`
 	Actions          []*SendTxAction			`json:"actions"`
 	V                *hex.Big       			`json:"v"`
 	R                *hex.Big       			`json:"r"`
 	S                *hex.Big       			`json:"s"`
+	MultiSig         *transaction.MultiSig		`json:"multiSig,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -639,10 +956,14 @@ func newRPCTransaction(tx *transaction.Transaction, blockHash types.Hash, blockN
 		From:     from,
 		Hash:     tx.Hash(),
 		Nonce:    hex.Uint64(tx.Nonce()),
+		Fee:      hex.Uint64(tx.Fee()),
+		ValidAfter: hex.Uint64(tx.ValidAfter()),
+		ValidUntil: hex.Uint64(tx.ValidUntil()),
 		V:        (*hex.Big)(v),
 		R:        (*hex.Big)(r),
 		S:        (*hex.Big)(s),
 		Actions:  actions,
+		MultiSig: tx.Data.MultiSig,
 	}
 	if blockHash != (types.Hash{}) {
 		result.BlockHash = blockHash
@@ -861,12 +1182,33 @@ type SendTxAction struct {
 type SendTxArgs struct {
 	From     types.Address  `json:"from"`
 	Nonce    *hex.Uint64    `json:"nonce"`
+	Fee      *hex.Uint64    `json:"fee"`
+
+	// Optional validity window, block numbers below transaction.LockTimeThreshold
+	// and unix timestamps otherwise
+	ValidAfter *hex.Uint64  `json:"validAfter"`
+	ValidUntil *hex.Uint64  `json:"validUntil"`
 
 	Actions  []SendTxAction    `json:"actions"`
+
+	// Optional key set of a multisig sending account
+	MultiSig *MultiSigArgs  `json:"multiSig"`
+}
+
+// MultiSigArgs is the m-of-n key set authorizing a multisig transaction.
+type MultiSigArgs struct {
+	Threshold hex.Uint64      `json:"threshold"`
+	Owners    []types.Address `json:"owners"`
 }
 
 // setDefaults is a helper function that fills in default values for unspecified tx fields.
 func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
+	if args.MultiSig != nil {
+		// The sender of a multisig transaction is the account of its key set
+		if _, err := transaction.NewMultiSig(args.From, uint64(args.MultiSig.Threshold), args.MultiSig.Owners); err != nil {
+			return err
+		}
+	}
 	if args.Nonce == nil {
 		nonce, err := b.GetPoolNonce(ctx, args.From)
 		if err != nil {
@@ -877,6 +1219,19 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 	if len(args.Actions) == 0 {
 		return errors.New("no actions in transaction !!")
 	}
+	if args.ValidAfter != nil && args.ValidUntil != nil && *args.ValidUntil != 0 {
+		after, until := uint64(*args.ValidAfter), uint64(*args.ValidUntil)
+		if (after < transaction.LockTimeThreshold) == (until < transaction.LockTimeThreshold) && after > until {
+			return errors.New("validity window ends before it starts")
+		}
+	}
+	if args.Fee == nil {
+		fee, err := b.SuggestFee(ctx, args.toTransaction())
+		if err != nil {
+			return err
+		}
+		args.Fee = (*hex.Uint64)(&fee)
+	}
 
 	return nil
 }
@@ -887,9 +1242,24 @@ func (args *SendTxArgs) toTransaction() *transaction.Transaction {
 		action := transaction.Action{argAction.Address, *argAction.Params}
 		actions = append(actions, action)
 	}
-	return transaction.NewTransaction(uint64(*args.Nonce), actions)
+	tx := transaction.NewTransaction(uint64(*args.Nonce), actions)
 
-	return nil
+	if args.MultiSig != nil {
+		// Key set is checked by setDefaults
+		ms, _ := transaction.NewMultiSig(args.From, uint64(args.MultiSig.Threshold), args.MultiSig.Owners)
+		tx = tx.WithMultiSig(ms)
+	}
+	if args.Fee != nil {
+		tx = tx.WithFee(uint64(*args.Fee))
+	}
+	var validAfter, validUntil uint64
+	if args.ValidAfter != nil {
+		validAfter = uint64(*args.ValidAfter)
+	}
+	if args.ValidUntil != nil {
+		validUntil = uint64(*args.ValidUntil)
+	}
+	return tx.WithValidityWindow(validAfter, validUntil)
 }
 
 // submitTransaction is a helper function that submits tx to txPool and logs a message.
@@ -975,7 +1345,7 @@ func (s *PublicTransactionPoolAPI) Sign(addr types.Address, data hex.Bytes) (hex
 	}
 	// Sign the requested hash with the wallet
 	signature, err := wallet.SignHash(account, signHash(data))
-	if err == nil {
+	if err == nil && len(signature) == 65 {
 		signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
 	}
 	return signature, err
@@ -1004,9 +1374,13 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Sen
 	if err != nil {
 		return nil, err
 	}
+	return newSignTransactionResult(tx)
+}
+
+// newSignTransactionResult encodes a signed transaction for the RPC.
+func newSignTransactionResult(tx *transaction.Transaction) (*SignTransactionResult, error) {
 	var buf bytes.Buffer
-	err = msgp.Encode(&buf, tx)
-	if err != nil {
+	if err := msgp.Encode(&buf, tx); err != nil {
 		return nil, err
 	}
 	return &SignTransactionResult{buf.Bytes(), tx}, nil
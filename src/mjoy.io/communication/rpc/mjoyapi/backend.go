@@ -23,6 +23,7 @@ package mjoyapi
 
 import (
 	"context"
+	"math/big"
 
 	"mjoy.io/node/services/mjoy/downloader"
 	"mjoy.io/utils/event"
@@ -35,6 +36,7 @@ import (
 	"mjoy.io/core/blockchain/block"
 	"mjoy.io/common/types"
 	"mjoy.io/core/transaction"
+	"mjoy.io/core/txprocessor"
 )
 
 // Backend interface provides the common API services (that are provided by
@@ -53,6 +55,8 @@ type Backend interface {
 	BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*block.Block, error)
 	StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *block.Header, error)
 	GetBlock(ctx context.Context, blockHash types.Hash) (*block.Block, error)
+	FinalizedBlock() (*block.Block, *block.Certificate)
+	GetEvidence(producer types.Address, number uint64) *block.Evidence
 	GetReceipts(ctx context.Context, blockHash types.Hash) (transaction.Receipts, error)
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
 	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
@@ -65,7 +69,13 @@ type Backend interface {
 	GetPoolNonce(ctx context.Context, addr types.Address) (uint64, error)
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[types.Address]transaction.Transactions, map[types.Address]transaction.Transactions)
+	TxPoolContentFrom(addr types.Address) (transaction.Transactions, transaction.Transactions)
+	TxPoolStatus(hashes []types.Hash) []txprocessor.TxStatus
+	RemovePoolTx(hash types.Hash) bool
 	SubscribeTxPreEvent(chan<- core.TxPreEvent) event.Subscription
+	SubscribeTxLifecycleEvent(chan<- core.TxLifecycleEvent) event.Subscription
+	SuggestFeePerByte(ctx context.Context) (*big.Int, error)
+	SuggestFee(ctx context.Context, tx *transaction.Transaction) (uint64, error)
 
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *block.Block
@@ -94,6 +104,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPublicTxPoolAPI(apiBackend),
 			Public:    true,
+		}, {
+			Namespace: "txpool",
+			Version:   "1.0",
+			Service:   NewPrivateTxPoolAPI(apiBackend),
+			Public:    false,
 		}, {
 			Namespace: "mjoy",
 			Version:   "1.0",
@@ -16,8 +16,8 @@ import (
 type Engine_basic struct {
 	//todo: need interpreter information
 
-	//key for sign header
-	prv *ecdsa.PrivateKey
+	//signer of the header
+	signer ProducerSigner
 }
 
 var (
@@ -26,13 +26,20 @@ var (
 )
 
 func NewBasicEngine(prv *ecdsa.PrivateKey)  (*Engine_basic){
-	return &Engine_basic{
-		prv,
+	basic := &Engine_basic{}
+	if prv != nil {
+		basic.signer = NewKeySigner(prv)
 	}
+	return basic
 }
 
 func (basic *Engine_basic)SetKey(prv *ecdsa.PrivateKey)  {
-	basic.prv = prv
+	basic.SetSigner(NewKeySigner(prv))
+}
+
+//SetSigner sets the signer of the header, which may hold the key out of process
+func (basic *Engine_basic)SetSigner(signer ProducerSigner)  {
+	basic.signer = signer
 }
 
 func (basic *Engine_basic) Author(chain ChainReader, header *block.Header) (types.Address, error) {
@@ -194,12 +201,12 @@ func (basic *Engine_basic) Finalize(chain ChainReader, header *block.Header, sta
 
 	//sign header
 	if sign {
-		if basic.prv == nil {
+		if basic.signer == nil {
 			return nil, errors.New("No key found fo sign header")
 		}
 		blk := block.NewBlock(header, txs, receipts)
 
-		err := block.SignHeaderInner(blk.B_header, block.NewBlockSigner(chain.Config().ChainId), basic.prv)
+		err := basic.signer.SignHeader(block.NewBlockSigner(chain.Config().ChainId), blk.B_header)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,497 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: gadget.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+// Package bft implements a finality gadget on top of the producer based
+// consensus engines. The producers of a block prevote it once it becomes their
+// head and precommit it once more than two thirds of them prevoted it. The
+// precommits of more than two thirds of the producers form a commit
+// certificate, which is stored with the block and marks it final.
+package bft
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"sync"
+
+	"mjoy.io/common/types"
+	"mjoy.io/consensus"
+	"mjoy.io/core"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/utils/event"
+)
+
+const (
+	maxFutureVotes    = 64   // Heights above the head for which votes are accepted
+	maxPendingVotes   = 1024 // Votes kept while their block is not imported yet
+	chainHeadChanSize = 10   // Size of the channel listening to ChainHeadEvent
+)
+
+var (
+	// errInvalidVote is returned if a vote is neither a prevote nor a precommit.
+	errInvalidVote = errors.New("invalid vote type")
+
+	// errStaleVote is returned if a vote or a certificate is for a block not
+	// above the finalized one.
+	errStaleVote = errors.New("vote below the finalized block")
+
+	// errFutureVote is returned if a vote is for a block too far above the head.
+	errFutureVote = errors.New("vote too far ahead of the head")
+
+	// errUnknownBlock is returned if a vote or a certificate is for a block not
+	// imported yet.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errNotValidator is returned if a vote is signed by an account not producing
+	// the block voted on.
+	errNotValidator = errors.New("voter not a validator")
+
+	// errKnownVote is returned if a vote was already tallied.
+	errKnownVote = errors.New("vote already known")
+
+	// errConflictingVote is returned if a voter already cast a vote of the same
+	// type for another block at the same height.
+	errConflictingVote = errors.New("conflicting vote")
+
+	// errInvalidCertificate is returned if a certificate doesn't hold the
+	// precommits of a quorum of the producers of its block.
+	errInvalidCertificate = errors.New("invalid certificate")
+)
+
+// Chain is the blockchain finalized by the gadget.
+type Chain interface {
+	consensus.ChainReader
+
+	// CurrentFinalizedBlock retrieves the last finalized block.
+	CurrentFinalizedBlock() *block.Block
+
+	// GetCertificate retrieves the certificate of a finalized block.
+	GetCertificate(hash types.Hash, number uint64) *block.Certificate
+
+	// SetFinalized stores a verified certificate and marks its block final.
+	SetFinalized(cert *block.Certificate) error
+
+	// SubscribeChainHeadEvent subscribes to the new heads of the chain.
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// round holds the votes cast at one height.
+type round struct {
+	validators   map[types.Hash][]types.Address   // Producers of the blocks voted on
+	votes        [2]map[types.Address]*block.Vote // First vote of each voter, by vote type
+	prevoted     bool                             // Whether the local producer prevoted
+	precommitted bool                             // Whether the local producer precommitted
+}
+
+func newRound() *round {
+	return &round{
+		validators: make(map[types.Hash][]types.Address),
+		votes:      [2]map[types.Address]*block.Vote{make(map[types.Address]*block.Vote), make(map[types.Address]*block.Vote)},
+	}
+}
+
+// tally returns the votes of the given type for a block.
+func (r *round) tally(voteType uint8, hash types.Hash) []*block.Vote {
+	var votes []*block.Vote
+	for _, vote := range r.votes[voteType] {
+		if vote.Hash == hash {
+			votes = append(votes, vote)
+		}
+	}
+	return votes
+}
+
+// quorum reports whether the given number of votes is more than two thirds of
+// the validators.
+func quorum(votes int, validators []types.Address) bool {
+	return 3*votes > 2*len(validators)
+}
+
+func contains(addrs []types.Address, addr types.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// outbox collects the messages to send to the peers once the gadget is
+// unlocked.
+type outbox struct {
+	votes []*block.Vote
+	cert  *block.Certificate
+}
+
+// Gadget is the finality gadget, collecting the votes of the producers and
+// casting the votes of the local one.
+type Gadget struct {
+	chain      Chain
+	validators consensus.Validators
+
+	signer consensus.ProducerSigner // Signer of the local votes, nil if not voting
+	voter  types.Address            // Address of the local producer
+
+	rounds   map[uint64]*round            // Votes of the heights not finalized yet
+	pending  map[types.Hash][]*block.Vote // Votes waiting for their block
+	npending int                          // Number of votes in pending
+	lock     *block.Header                // Block of the last local precommit
+	mu       sync.Mutex                   // Protects the voting state
+
+	peers   map[string]*peer // Peers speaking the finality protocol
+	peersMu sync.RWMutex
+
+	headCh  chan core.ChainHeadEvent
+	headSub event.Subscription
+	wg      sync.WaitGroup
+}
+
+// New creates a finality gadget for a chain produced by the given validators.
+func New(chain Chain, validators consensus.Validators) *Gadget {
+	return &Gadget{
+		chain:      chain,
+		validators: validators,
+		rounds:     make(map[uint64]*round),
+		pending:    make(map[types.Hash][]*block.Vote),
+		peers:      make(map[string]*peer),
+	}
+}
+
+// SetKey sets the key the local votes are signed with.
+func (g *Gadget) SetKey(prv *ecdsa.PrivateKey) {
+	g.SetSigner(consensus.NewKeySigner(prv))
+}
+
+// SetSigner sets the signer of the local votes, which may hold the key out of
+// process.
+func (g *Gadget) SetSigner(signer consensus.ProducerSigner) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.signer = signer
+	g.voter = signer.Address()
+}
+
+// Start starts voting on the new heads of the chain.
+func (g *Gadget) Start() {
+	g.headCh = make(chan core.ChainHeadEvent, chainHeadChanSize)
+	g.headSub = g.chain.SubscribeChainHeadEvent(g.headCh)
+
+	g.wg.Add(1)
+	go g.loop()
+}
+
+// Stop stops the gadget.
+func (g *Gadget) Stop() {
+	g.headSub.Unsubscribe()
+	g.wg.Wait()
+	logger.Info("Finality gadget stopped")
+}
+
+func (g *Gadget) loop() {
+	defer g.wg.Done()
+
+	for {
+		select {
+		case ev := <-g.headCh:
+			g.NewHead(ev.Block.Header())
+
+		case <-g.headSub.Err():
+			return
+		}
+	}
+}
+
+// NewHead tallies the votes waiting for blocks now imported and prevotes the
+// new head if the local producer produces it.
+func (g *Gadget) NewHead(header *block.Header) {
+	out := new(outbox)
+
+	g.mu.Lock()
+	for hash, votes := range g.pending {
+		if g.chain.GetHeaderByHash(hash) == nil {
+			continue
+		}
+		delete(g.pending, hash)
+		g.npending -= len(votes)
+		for _, vote := range votes {
+			g.addVote(vote, out)
+		}
+	}
+	g.prevote(header, out)
+	g.mu.Unlock()
+
+	g.send(out)
+}
+
+// AddVote verifies a vote received from the network and tallies it, casting the
+// local votes it leads to. Valid votes are relayed to the peers.
+func (g *Gadget) AddVote(vote *block.Vote) error {
+	out := new(outbox)
+
+	g.mu.Lock()
+	err := g.addVote(vote, out)
+	g.mu.Unlock()
+
+	g.send(out)
+	return err
+}
+
+// AddCertificate verifies a certificate received from the network and marks its
+// block final.
+func (g *Gadget) AddCertificate(cert *block.Certificate) error {
+	if cert.Number <= g.chain.CurrentFinalizedBlock().NumberU64() {
+		return errStaleVote
+	}
+	header := g.chain.GetHeader(cert.Hash, cert.Number)
+	if header == nil {
+		return errUnknownBlock
+	}
+	if err := g.verifyCertificate(header, cert); err != nil {
+		return err
+	}
+	if err := g.chain.SetFinalized(cert); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.prune(cert.Number)
+	g.mu.Unlock()
+
+	g.send(&outbox{cert: cert})
+	return nil
+}
+
+// verifyCertificate checks that the certificate holds the precommits of a
+// quorum of the producers of its block.
+func (g *Gadget) verifyCertificate(header *block.Header, cert *block.Certificate) error {
+	validators, err := g.validators.Validators(g.chain, header)
+	if err != nil {
+		return err
+	}
+	voters := make(map[types.Address]bool)
+	for _, vote := range cert.Precommits {
+		if vote.Type != block.Precommit || vote.Number != cert.Number || vote.Hash != cert.Hash {
+			return errInvalidCertificate
+		}
+		voter, err := vote.Voter()
+		if err != nil {
+			return err
+		}
+		if !contains(validators, voter) {
+			return errNotValidator
+		}
+		voters[voter] = true
+	}
+	if !quorum(len(voters), validators) {
+		return errInvalidCertificate
+	}
+	return nil
+}
+
+// round returns the votes cast at the given height. It assumes that the gadget
+// mutex is held.
+func (g *Gadget) round(number uint64) *round {
+	r := g.rounds[number]
+	if r == nil {
+		r = newRound()
+		g.rounds[number] = r
+	}
+	return r
+}
+
+// validatorsOf returns the producers of a block. It assumes that the gadget
+// mutex is held.
+func (g *Gadget) validatorsOf(r *round, header *block.Header) ([]types.Address, error) {
+	hash := header.Hash()
+	if validators, ok := r.validators[hash]; ok {
+		return validators, nil
+	}
+	validators, err := g.validators.Validators(g.chain, header)
+	if err != nil {
+		return nil, err
+	}
+	r.validators[hash] = validators
+	return validators, nil
+}
+
+// addVote verifies and tallies a vote, casting a precommit on a prevote quorum
+// and finalizing the block on a precommit quorum. It assumes that the gadget
+// mutex is held.
+func (g *Gadget) addVote(vote *block.Vote, out *outbox) error {
+	if vote.Type != block.Prevote && vote.Type != block.Precommit {
+		return errInvalidVote
+	}
+	if vote.Number <= g.chain.CurrentFinalizedBlock().NumberU64() {
+		return errStaleVote
+	}
+	if vote.Number > g.chain.CurrentHeader().Number.IntVal.Uint64()+maxFutureVotes {
+		return errFutureVote
+	}
+	voter, err := vote.Voter()
+	if err != nil {
+		return err
+	}
+	header := g.chain.GetHeader(vote.Hash, vote.Number)
+	if header == nil {
+		// Keep the vote until the block is imported
+		if g.npending < maxPendingVotes {
+			g.pending[vote.Hash] = append(g.pending[vote.Hash], vote)
+			g.npending++
+		}
+		return errUnknownBlock
+	}
+	r := g.round(vote.Number)
+	validators, err := g.validatorsOf(r, header)
+	if err != nil {
+		return err
+	}
+	if !contains(validators, voter) {
+		return errNotValidator
+	}
+	if prev := r.votes[vote.Type][voter]; prev != nil {
+		if prev.Hash == vote.Hash {
+			return errKnownVote
+		}
+		logger.Warn("Conflicting vote", "voter", voter, "type", vote.Type, "number", vote.Number, "hash", vote.Hash, "previous", prev.Hash)
+		return errConflictingVote
+	}
+	r.votes[vote.Type][voter] = vote
+	out.votes = append(out.votes, vote)
+
+	votes := r.tally(vote.Type, vote.Hash)
+	if !quorum(len(votes), validators) {
+		return nil
+	}
+	switch vote.Type {
+	case block.Prevote:
+		// A prevote quorum above the lock on another branch releases it
+		if g.lock != nil && !g.compatible(header, g.lock) {
+			if header.Number.IntVal.Cmp(&g.lock.Number.IntVal) <= 0 {
+				return nil
+			}
+			g.lock = nil
+		}
+		g.precommit(header, r, validators, out)
+
+	case block.Precommit:
+		g.finalize(header, votes, out)
+	}
+	return nil
+}
+
+// prevote casts the local prevote for a new head, unless the local producer
+// already prevoted at its height or precommitted a block on another branch. It assumes that the gadget mutex is held.
+func (g *Gadget) prevote(header *block.Header, out *outbox) {
+	number := header.Number.IntVal.Uint64()
+	if g.signer == nil || number <= g.chain.CurrentFinalizedBlock().NumberU64() {
+		return
+	}
+	if g.lock != nil && !g.compatible(header, g.lock) {
+		return
+	}
+	r := g.round(number)
+	if r.prevoted {
+		return
+	}
+	validators, err := g.validatorsOf(r, header)
+	if err != nil || !contains(validators, g.voter) {
+		return
+	}
+	r.prevoted = true
+	g.cast(block.Prevote, header, out)
+}
+
+// precommit casts the local precommit for a block prevoted by a quorum, locking
+// the local producer on it. It assumes that the gadget mutex is held.
+func (g *Gadget) precommit(header *block.Header, r *round, validators []types.Address, out *outbox) {
+	if g.signer == nil || r.precommitted || !contains(validators, g.voter) {
+		return
+	}
+	r.precommitted = true
+	if g.lock == nil || header.Number.IntVal.Cmp(&g.lock.Number.IntVal) > 0 {
+		g.lock = header
+	}
+	g.cast(block.Precommit, header, out)
+}
+
+// cast signs and tallies a local vote. It assumes that the gadget mutex is held.
+func (g *Gadget) cast(voteType uint8, header *block.Header, out *outbox) {
+	vote := &block.Vote{Type: voteType, Number: header.Number.IntVal.Uint64(), Hash: header.Hash()}
+	if err := g.signer.SignVote(vote); err != nil {
+		logger.Error("Failed to sign vote", "err", err)
+		return
+	}
+	if err := g.addVote(vote, out); err != nil {
+		logger.Warn("Local vote rejected", "type", voteType, "number", vote.Number, "err", err)
+	}
+}
+
+// finalize marks a block precommitted by a quorum final. It assumes that the
+// gadget mutex is held.
+func (g *Gadget) finalize(header *block.Header, precommits []*block.Vote, out *outbox) {
+	cert := &block.Certificate{Number: header.Number.IntVal.Uint64(), Hash: header.Hash(), Precommits: precommits}
+	if err := g.chain.SetFinalized(cert); err != nil {
+		logger.Warn("Failed to finalize block", "number", cert.Number, "hash", cert.Hash, "err", err)
+		return
+	}
+	logger.Info("Finalized block", "number", cert.Number, "hash", cert.Hash, "precommits", len(precommits))
+	out.cert = cert
+	g.prune(cert.Number)
+}
+
+// prune drops the voting state at and below a finalized height. It assumes that
+// the gadget mutex is held.
+func (g *Gadget) prune(number uint64) {
+	for n := range g.rounds {
+		if n <= number {
+			delete(g.rounds, n)
+		}
+	}
+	for hash, votes := range g.pending {
+		if votes[0].Number <= number {
+			delete(g.pending, hash)
+			g.npending -= len(votes)
+		}
+	}
+	if g.lock != nil && g.lock.Number.IntVal.Uint64() <= number {
+		g.lock = nil
+	}
+}
+
+// compatible reports whether two blocks are on the same branch.
+func (g *Gadget) compatible(a, b *block.Header) bool {
+	if a.Number.IntVal.Cmp(&b.Number.IntVal) < 0 {
+		a, b = b, a
+	}
+	return g.descends(a, b)
+}
+
+// descends reports whether a header descends from, or is, the given ancestor.
+func (g *Gadget) descends(header, ancestor *block.Header) bool {
+	number, hash := header.Number.IntVal.Uint64(), header.Hash()
+	target := ancestor.Number.IntVal.Uint64()
+	for number > target {
+		parent := g.chain.GetHeader(header.ParentHash, number-1)
+		if parent == nil {
+			return false
+		}
+		header, number, hash = parent, number-1, parent.Hash()
+	}
+	return number == target && hash == ancestor.Hash()
+}
@@ -0,0 +1,290 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: gadget_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package bft
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/consensus"
+	"mjoy.io/core"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/params"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/event"
+)
+
+// testerChain is a Chain over a plain list of canonical headers and a set of
+// side ones.
+type testerChain struct {
+	headers []*block.Header
+	side    []*block.Header
+	final   *block.Certificate
+	feed    event.Feed
+}
+
+func (c *testerChain) Config() *params.ChainConfig {
+	return &params.ChainConfig{ChainId: big.NewInt(1)}
+}
+func (c *testerChain) CurrentHeader() *block.Header {
+	return c.headers[len(c.headers)-1]
+}
+func (c *testerChain) GetHeader(hash types.Hash, number uint64) *block.Header {
+	if header := c.GetHeaderByHash(hash); header != nil && header.Number.IntVal.Uint64() == number {
+		return header
+	}
+	return nil
+}
+func (c *testerChain) GetHeaderByNumber(number uint64) *block.Header {
+	if number < uint64(len(c.headers)) {
+		return c.headers[number]
+	}
+	return nil
+}
+func (c *testerChain) GetHeaderByHash(hash types.Hash) *block.Header {
+	for _, header := range append(c.headers, c.side...) {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
+func (c *testerChain) GetBlock(hash types.Hash, number uint64) *block.Block { return nil }
+
+func (c *testerChain) CurrentFinalizedBlock() *block.Block {
+	if c.final == nil {
+		return block.NewBlockWithHeader(c.headers[0])
+	}
+	return block.NewBlockWithHeader(c.headers[c.final.Number])
+}
+func (c *testerChain) GetCertificate(hash types.Hash, number uint64) *block.Certificate {
+	if c.final != nil && c.final.Hash == hash {
+		return c.final
+	}
+	return nil
+}
+func (c *testerChain) SetFinalized(cert *block.Certificate) error {
+	c.final = cert
+	return nil
+}
+func (c *testerChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+// extend appends a header to the canonical chain, or to the side headers.
+func (c *testerChain) extend(parent *block.Header, time int64, canonical bool) *block.Header {
+	header := &block.Header{
+		ParentHash: parent.Hash(),
+		Number:     types.NewBigInt(*new(big.Int).Add(&parent.Number.IntVal, big.NewInt(1))),
+		Time:       types.NewBigInt(*big.NewInt(time)),
+	}
+	if canonical {
+		c.headers = append(c.headers, header)
+	} else {
+		c.side = append(c.side, header)
+	}
+	return header
+}
+
+// testerValidators is a fixed producer set.
+type testerValidators []types.Address
+
+func (v testerValidators) Validators(chain consensus.ChainReader, header *block.Header) ([]types.Address, error) {
+	return v, nil
+}
+
+// testerAccounts are the signing keys used by the tests, by name.
+type testerAccounts map[string]*ecdsa.PrivateKey
+
+func (a testerAccounts) key(name string) *ecdsa.PrivateKey {
+	if a[name] == nil {
+		a[name], _ = crypto.GenerateKey()
+	}
+	return a[name]
+}
+
+func (a testerAccounts) address(name string) types.Address {
+	return crypto.PubkeyToAddress(a.key(name).PublicKey)
+}
+
+func (a testerAccounts) vote(t *testing.T, name string, voteType uint8, header *block.Header) *block.Vote {
+	vote := &block.Vote{Type: voteType, Number: header.Number.IntVal.Uint64(), Hash: header.Hash()}
+	if err := vote.Sign(a.key(name)); err != nil {
+		t.Fatalf("failed to sign vote: %v", err)
+	}
+	return vote
+}
+
+// newTester creates a gadget voting with the key of A, among the producers A,
+// B, C and D.
+func newTester(accounts testerAccounts) (*testerChain, *Gadget) {
+	genesis := &block.Header{Number: types.NewBigInt(*big.NewInt(0)), Time: types.NewBigInt(*big.NewInt(1000))}
+	chain := &testerChain{headers: []*block.Header{genesis}}
+
+	var validators testerValidators
+	for _, name := range []string{"A", "B", "C", "D"} {
+		validators = append(validators, accounts.address(name))
+	}
+	gadget := New(chain, validators)
+	gadget.SetKey(accounts.key("A"))
+	return chain, gadget
+}
+
+func TestGadgetFinality(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, gadget := newTester(accounts)
+	head := chain.extend(chain.CurrentHeader(), 1010, true)
+	fork := chain.extend(chain.headers[0], 1011, false)
+
+	gadget.NewHead(head)
+	if gadget.rounds[1].votes[block.Prevote][accounts.address("A")] == nil {
+		t.Fatalf("local producer did not prevote its head")
+	}
+	tests := []struct {
+		vote *block.Vote
+		err  error
+	}{
+		{accounts.vote(t, "B", block.Prevote, head), nil},
+		{accounts.vote(t, "B", block.Prevote, head), errKnownVote},
+		{accounts.vote(t, "B", block.Prevote, fork), errConflictingVote},
+		{accounts.vote(t, "E", block.Prevote, head), errNotValidator},
+		{&block.Vote{Type: 2, Number: 1, Hash: head.Hash()}, errInvalidVote},
+	}
+	for i, tt := range tests {
+		if err := gadget.AddVote(tt.vote); err != tt.err {
+			t.Errorf("test %d: vote error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	if gadget.rounds[1].votes[block.Precommit][accounts.address("A")] != nil {
+		t.Fatalf("local producer precommitted without a prevote quorum")
+	}
+	// The third prevote makes the quorum, the local producer precommits
+	if err := gadget.AddVote(accounts.vote(t, "C", block.Prevote, head)); err != nil {
+		t.Fatalf("failed to add prevote: %v", err)
+	}
+	if gadget.rounds[1].votes[block.Precommit][accounts.address("A")] == nil {
+		t.Fatalf("local producer did not precommit on a prevote quorum")
+	}
+	for _, name := range []string{"B", "D"} {
+		if err := gadget.AddVote(accounts.vote(t, name, block.Precommit, head)); err != nil {
+			t.Fatalf("failed to add precommit: %v", err)
+		}
+	}
+	if chain.final == nil || chain.final.Hash != head.Hash() || len(chain.final.Precommits) != 3 {
+		t.Fatalf("block not finalized by a precommit quorum: %v", chain.final)
+	}
+	if len(gadget.rounds) != 0 {
+		t.Errorf("finalized rounds not pruned: %d left", len(gadget.rounds))
+	}
+	if err := gadget.AddVote(accounts.vote(t, "D", block.Prevote, head)); err != errStaleVote {
+		t.Errorf("vote for a finalized height: have %v, want %v", err, errStaleVote)
+	}
+}
+
+func TestGadgetPendingVotes(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, gadget := newTester(accounts)
+
+	// Votes arriving before their block are tallied once it is imported
+	header := &block.Header{
+		ParentHash: chain.CurrentHeader().Hash(),
+		Number:     types.NewBigInt(*big.NewInt(1)),
+		Time:       types.NewBigInt(*big.NewInt(1010)),
+	}
+	for _, name := range []string{"B", "C"} {
+		if err := gadget.AddVote(accounts.vote(t, name, block.Prevote, header)); err != errUnknownBlock {
+			t.Fatalf("vote for an unknown block: have %v, want %v", err, errUnknownBlock)
+		}
+	}
+	chain.headers = append(chain.headers, header)
+	gadget.NewHead(header)
+
+	if gadget.rounds[1].votes[block.Precommit][accounts.address("A")] == nil {
+		t.Fatalf("pending prevotes not tallied")
+	}
+	if gadget.npending != 0 {
+		t.Errorf("pending votes left: %d", gadget.npending)
+	}
+}
+
+func TestGadgetLock(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, gadget := newTester(accounts)
+	head := chain.extend(chain.CurrentHeader(), 1010, true)
+	fork := chain.extend(chain.headers[0], 1011, false)
+	forkChild := chain.extend(fork, 1020, false)
+
+	// Precommit the head without finalizing it
+	gadget.NewHead(head)
+	for _, name := range []string{"B", "C"} {
+		gadget.AddVote(accounts.vote(t, name, block.Prevote, head))
+	}
+	// The local producer doesn't prevote a block of another branch
+	gadget.NewHead(forkChild)
+	if gadget.rounds[2] != nil && gadget.rounds[2].votes[block.Prevote][accounts.address("A")] != nil {
+		t.Fatalf("locked producer prevoted another branch")
+	}
+	// Until a prevote quorum above the lock releases it
+	for _, name := range []string{"B", "C", "D"} {
+		gadget.AddVote(accounts.vote(t, name, block.Prevote, forkChild))
+	}
+	if gadget.rounds[2].votes[block.Precommit][accounts.address("A")] == nil {
+		t.Fatalf("lock not released by a prevote quorum above it")
+	}
+	if gadget.lock.Hash() != forkChild.Hash() {
+		t.Errorf("lock mismatch: have %x, want %x", gadget.lock.Hash(), forkChild.Hash())
+	}
+}
+
+func TestCertificateVerification(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, gadget := newTester(accounts)
+	head := chain.extend(chain.CurrentHeader(), 1010, true)
+
+	cert := &block.Certificate{Number: 1, Hash: head.Hash()}
+	for _, name := range []string{"B", "C"} {
+		cert.Precommits = append(cert.Precommits, accounts.vote(t, name, block.Precommit, head))
+	}
+	if err := gadget.AddCertificate(cert); err != errInvalidCertificate {
+		t.Fatalf("certificate without quorum: have %v, want %v", err, errInvalidCertificate)
+	}
+	// Duplicated precommits don't count twice
+	cert.Precommits = append(cert.Precommits, cert.Precommits[0])
+	if err := gadget.AddCertificate(cert); err != errInvalidCertificate {
+		t.Fatalf("certificate with duplicates: have %v, want %v", err, errInvalidCertificate)
+	}
+	cert.Precommits[2] = accounts.vote(t, "D", block.Prevote, head)
+	if err := gadget.AddCertificate(cert); err != errInvalidCertificate {
+		t.Fatalf("certificate with a prevote: have %v, want %v", err, errInvalidCertificate)
+	}
+	cert.Precommits[2] = accounts.vote(t, "D", block.Precommit, head)
+	if err := gadget.AddCertificate(cert); err != nil {
+		t.Fatalf("failed to add certificate: %v", err)
+	}
+	if chain.final != cert {
+		t.Errorf("certificate not applied")
+	}
+	if err := gadget.AddCertificate(cert); err != errStaleVote {
+		t.Errorf("known certificate: have %v, want %v", err, errStaleVote)
+	}
+}
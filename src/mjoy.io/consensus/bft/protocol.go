@@ -0,0 +1,186 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: protocol.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package bft
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/fatih/set.v0"
+	"mjoy.io/communication/p2p"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/utils/crypto"
+)
+
+// Official short name of the finality protocol used during capability
+// negotiation.
+const ProtocolName = "bft"
+
+// Version and number of messages of the finality protocol.
+const (
+	ProtocolVersion = 1
+	ProtocolLength  = 2
+)
+
+const ProtocolMaxMsgSize = 1024 * 1024 // Maximum cap on the size of a protocol message
+
+// Finality protocol message codes
+const (
+	VoteMsg        = 0x00
+	CertificateMsg = 0x01
+)
+
+const maxKnownVotes = 4096 // Maximum votes to keep in the known list of a peer (prevent DOS)
+
+var (
+	errMsgTooLarge    = errors.New("message too long")
+	errInvalidMsgCode = errors.New("invalid message code")
+)
+
+// peer is a remote node speaking the finality protocol.
+type peer struct {
+	id string
+
+	*p2p.Peer
+	rw p2p.MsgReadWriter
+
+	knownVotes *set.Set // Signature hashes of the votes known to the peer
+}
+
+func newPeer(p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
+	id := p.ID()
+
+	return &peer{
+		Peer:       p,
+		rw:         rw,
+		id:         fmt.Sprintf("%x", id[:8]),
+		knownVotes: set.New(),
+	}
+}
+
+// markVote marks a vote as known for the peer, so it won't be sent back.
+func (p *peer) markVote(vote *block.Vote) {
+	for p.knownVotes.Size() >= maxKnownVotes {
+		p.knownVotes.Pop()
+	}
+	p.knownVotes.Add(crypto.Keccak256Hash(vote.Signature))
+}
+
+// Protocols returns the finality sub-protocol to run next to the mjoy one.
+func (g *Gadget) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:    ProtocolName,
+		Version: ProtocolVersion,
+		Length:  ProtocolLength,
+		Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+			return g.handle(newPeer(p, rw))
+		},
+	}}
+}
+
+// handle is the callback invoked to manage the life cycle of a finality peer.
+// When this function terminates, the peer is disconnected.
+func (g *Gadget) handle(p *peer) error {
+	g.peersMu.Lock()
+	g.peers[p.id] = p
+	g.peersMu.Unlock()
+
+	defer func() {
+		g.peersMu.Lock()
+		delete(g.peers, p.id)
+		g.peersMu.Unlock()
+	}()
+	// Tell the peer about the last finalized block
+	final := g.chain.CurrentFinalizedBlock()
+	if cert := g.chain.GetCertificate(final.Hash(), final.NumberU64()); cert != nil {
+		if err := p2p.Send(p.rw, CertificateMsg, cert); err != nil {
+			return err
+		}
+	}
+	for {
+		if err := g.handleMsg(p); err != nil {
+			logger.Debug("Finality message handling failed", "peer", p.id, "err", err)
+			return err
+		}
+	}
+}
+
+// handleMsg is invoked whenever an inbound message is received from a remote
+// peer. The remote connection is torn down upon returning any error.
+func (g *Gadget) handleMsg(p *peer) error {
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Size > ProtocolMaxMsgSize {
+		return errMsgTooLarge
+	}
+	defer msg.Discard()
+
+	switch msg.Code {
+	case VoteMsg:
+		vote := new(block.Vote)
+		if err := msg.Decode(vote); err != nil {
+			return err
+		}
+		p.markVote(vote)
+		if err := g.AddVote(vote); err != nil {
+			logger.Trace("Vote not tallied", "peer", p.id, "number", vote.Number, "hash", vote.Hash, "err", err)
+		}
+
+	case CertificateMsg:
+		cert := new(block.Certificate)
+		if err := msg.Decode(cert); err != nil {
+			return err
+		}
+		if err := g.AddCertificate(cert); err != nil {
+			logger.Trace("Certificate not applied", "peer", p.id, "number", cert.Number, "hash", cert.Hash, "err", err)
+		}
+
+	default:
+		return errInvalidMsgCode
+	}
+	return nil
+}
+
+// send relays the votes and certificate of the outbox to the peers not knowing
+// them yet.
+func (g *Gadget) send(out *outbox) {
+	g.peersMu.RLock()
+	defer g.peersMu.RUnlock()
+
+	for _, p := range g.peers {
+		for _, vote := range out.votes {
+			if p.knownVotes.Has(crypto.Keccak256Hash(vote.Signature)) {
+				continue
+			}
+			p.markVote(vote)
+			if err := p2p.Send(p.rw, VoteMsg, vote); err != nil {
+				logger.Debug("Failed to send vote", "peer", p.id, "err", err)
+			}
+		}
+		if out.cert != nil {
+			if err := p2p.Send(p.rw, CertificateMsg, out.cert); err != nil {
+				logger.Debug("Failed to send certificate", "peer", p.id, "err", err)
+			}
+		}
+	}
+}
@@ -92,6 +92,21 @@ type Engine interface {
 }
 
 
+// Rewarder is implemented by the engines paying the block reward through their
+// own inner contract rather than the balance transfer one.
+type Rewarder interface {
+	// RewardAction returns the action of the reward transaction of the block.
+	RewardAction(header *block.Header) transaction.Action
+}
+
+// Validators is implemented by the engines with a known set of producers, which
+// vote to finalize the blocks they produce.
+type Validators interface {
+	// Validators returns the producers allowed to vote on the given block.
+	Validators(chain ChainReader, header *block.Header) ([]types.Address, error)
+}
+
+
 var (
 	// ErrUnknownAncestor is returned when validating a block requires an ancestor
 	// that is unknown.
@@ -0,0 +1,64 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: api.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package dpos
+
+import (
+	"mjoy.io/common/types"
+	"mjoy.io/communication/rpc"
+	"mjoy.io/consensus"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/interpreter/staking"
+	"mjoy.io/core/sdk"
+	"mjoy.io/core/state"
+)
+
+// API is a user facing RPC API exposing the elected producers of the delegated
+// proof-of-stake scheme.
+type API struct {
+	chain consensus.ChainReader
+	dpos  *Dpos
+}
+
+// GetProducers retrieves the producers, in slot order, in effect after the
+// specified block.
+func (api *API) GetProducers(number *rpc.BlockNumber) ([]types.Address, error) {
+	var header *block.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.dpos.producerSet(api.chain, header.Number.IntVal.Uint64(), header.Hash(), nil)
+}
+
+// GetCandidates retrieves the vote tallies of the candidates at the current
+// block, the most voted first.
+func (api *API) GetCandidates() ([]staking.CandidateTally, error) {
+	header := api.chain.CurrentHeader()
+	statedb, err := state.New(header.StateRootHash, state.NewDatabase(api.dpos.db))
+	if err != nil {
+		return nil, err
+	}
+	return staking.Tallies(sdk.NewTmpStatusManager(api.dpos.db, statedb, types.Address{})), nil
+}
@@ -0,0 +1,80 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: data.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package dpos
+
+import (
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/params"
+)
+
+//go:generate msgp
+
+// ConsensusId identifies the delegated proof-of-stake data in
+// Header.ConsensusData.
+const ConsensusId = "dpos"
+
+// DposData is the content of the consensus data of a delegated proof-of-stake
+// header. Only the epoch blocks carry the producers elected for the next epoch,
+// in slot order.
+type DposData struct {
+	Producers []types.Address
+}
+
+// NewConsensusData encodes the delegated proof-of-stake data for a header.
+func NewConsensusData(data *DposData) (block.ConsensusData, error) {
+	para, err := data.MarshalMsg(nil)
+	if err != nil {
+		return block.ConsensusData{}, err
+	}
+	return block.ConsensusData{Id: ConsensusId, Para: para}, nil
+}
+
+// DecodeConsensusData decodes the delegated proof-of-stake data of a header.
+func DecodeConsensusData(header *block.Header) (*DposData, error) {
+	if header.ConsensusData.Id != ConsensusId {
+		return nil, errInvalidConsensusData
+	}
+	data := new(DposData)
+	if _, err := data.UnmarshalMsg(header.ConsensusData.Para); err != nil {
+		return nil, errInvalidConsensusData
+	}
+	return data, nil
+}
+
+// GenesisConsensusData returns the consensus data of the genesis block, holding
+// the initial producers of the config.
+func GenesisConsensusData(config *params.DposConfig) (block.ConsensusData, error) {
+	return NewConsensusData(&DposData{Producers: config.Producers})
+}
+
+// equalProducers reports whether two producer lists are the same, in order.
+func equalProducers(a, b []types.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
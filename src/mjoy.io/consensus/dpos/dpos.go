@@ -0,0 +1,501 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: dpos.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+// Package dpos implements the delegated proof-of-stake consensus engine.
+package dpos
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru"
+	"mjoy.io/common/types"
+	"mjoy.io/communication/rpc"
+	"mjoy.io/consensus"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/interpreter/staking"
+	"mjoy.io/core/sdk"
+	"mjoy.io/core/state"
+	"mjoy.io/core/transaction"
+	"mjoy.io/params"
+	"mjoy.io/utils/database"
+)
+
+const (
+	inmemoryProducers  = 1024 // Number of recent producer sets to keep in memory
+	inmemorySignatures = 4096 // Number of recent block signatures to keep in memory
+
+	allowedFutureBlockTime = 15 // Seconds a block may be ahead of the local clock
+)
+
+// Delegated proof-of-stake protocol constants.
+var (
+	epochLength  = params.DposEpochLength // Default number of blocks after which the producers are elected again
+	blockPeriod  = uint64(3)              // Default seconds of a producer slot
+	maxProducers = uint64(21)             // Default number of elected producers
+)
+
+// Various error messages to mark blocks invalid. These should be private to
+// prevent engine specific errors from being referenced in the remainder of the
+// codebase, inherently breaking if the engine is swapped out. Please put common
+// error types into the consensus package.
+var (
+	// errUnknownBlock is returned when the producers are requested for a block
+	// that is not part of the local blockchain.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errInvalidConsensusData is returned if the consensus data of a header is not
+	// delegated proof-of-stake data.
+	errInvalidConsensusData = errors.New("invalid consensus data")
+
+	// errInvalidProducers is returned if an epoch block does not carry the
+	// elected producers, or another block carries any.
+	errInvalidProducers = errors.New("invalid producer list")
+
+	// errInvalidTimestamp is returned if the timestamp of a block is not the
+	// start of a slot.
+	errInvalidTimestamp = errors.New("timestamp not at a slot start")
+
+	// errUnscheduled is returned if a header is signed by another producer than
+	// the one scheduled for its slot.
+	errUnscheduled = errors.New("producer not scheduled for the slot")
+
+	// errInvalidBlockProducer is returned if the producer named by a header, who
+	// is paid its reward, is not the signer of the header.
+	errInvalidBlockProducer = errors.New("block producer differs from the signer")
+
+	// errUnauthorized is returned if the local key is not an elected producer.
+	errUnauthorized = errors.New("unauthorized")
+
+	// errMissingKey is returned if a block is prepared or signed before the
+	// signing key is set.
+	errMissingKey = errors.New("no key found to sign header")
+)
+
+// Dpos is the delegated proof-of-stake consensus engine. Token holders stake
+// and vote through the staking inner contract, and every epoch the most voted
+// candidates are elected as producers. Time is split in slots given to the
+// producers in turn.
+type Dpos struct {
+	config *params.DposConfig // Consensus engine configuration parameters
+	db     database.IDatabase // Database to read the vote tallies from
+
+	producers  *lru.Cache // Producer sets in effect after recent blocks
+	signatures *lru.Cache // Signatures of recent blocks to speed up producing
+
+	signFn consensus.ProducerSigner // Signer of the headers, holding the key
+	signer types.Address            // Mjoy address of the signing key
+	lock   sync.RWMutex             // Protects the signer fields
+}
+
+// New creates a delegated proof-of-stake consensus engine with the initial
+// producers set to the ones provided by the genesis block.
+func New(config *params.DposConfig, db database.IDatabase) *Dpos {
+	// Set any missing consensus parameters to their defaults
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = epochLength
+	}
+	if conf.Period == 0 {
+		conf.Period = blockPeriod
+	}
+	if conf.MaxProducers == 0 {
+		conf.MaxProducers = maxProducers
+	}
+	producers, _ := lru.New(inmemoryProducers)
+	signatures, _ := lru.New(inmemorySignatures)
+
+	return &Dpos{
+		config:     &conf,
+		db:         db,
+		producers:  producers,
+		signatures: signatures,
+	}
+}
+
+// SetKey sets the key the headers are signed with.
+func (d *Dpos) SetKey(prv *ecdsa.PrivateKey) {
+	d.SetSigner(consensus.NewKeySigner(prv))
+}
+
+// SetSigner sets the signer of the headers, which may hold the key out of
+// process.
+func (d *Dpos) SetSigner(signer consensus.ProducerSigner) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.signFn = signer
+	d.signer = signer.Address()
+}
+
+// ecrecover extracts the Mjoy account address from a signed header.
+func (d *Dpos) ecrecover(chain consensus.ChainReader, header *block.Header) (types.Address, error) {
+	hash := header.Hash()
+	if address, known := d.signatures.Get(hash); known {
+		return address.(types.Address), nil
+	}
+	address, err := block.NewBlockSigner(chain.Config().ChainId).Sender(header)
+	if err != nil {
+		return types.Address{}, consensus.ErrSignature
+	}
+	d.signatures.Add(hash, address)
+	return address, nil
+}
+
+// scheduled returns the producer of the slot starting at the given time.
+func (d *Dpos) scheduled(producers []types.Address, time uint64) types.Address {
+	return producers[(time/d.config.Period)%uint64(len(producers))]
+}
+
+// Author implements consensus.Engine, returning the producer scheduled for the
+// slot of the header.
+func (d *Dpos) Author(chain consensus.ChainReader, header *block.Header) (types.Address, error) {
+	number := header.Number.IntVal.Uint64()
+	if number == 0 {
+		return types.Address{}, errUnknownBlock
+	}
+	signer, err := d.ecrecover(chain, header)
+	if err != nil {
+		return types.Address{}, err
+	}
+	producers, err := d.producerSet(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return types.Address{}, err
+	}
+	if d.scheduled(producers, header.Time.IntVal.Uint64()) != signer {
+		return types.Address{}, errUnscheduled
+	}
+	return signer, nil
+}
+
+// VerifyHeader checks whether a header conforms to the consensus rules.
+func (d *Dpos) VerifyHeader(chain consensus.ChainReader, header *block.Header, seal bool) error {
+	return d.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. The
+// method returns a quit channel to abort the operations and a results channel to
+// retrieve the async verifications (the order is that of the input slice).
+func (d *Dpos) VerifyHeaders(chain consensus.ChainReader, headers []*block.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := d.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader checks whether a header conforms to the consensus rules. The
+// caller may optionally pass in a batch of parents (ascending order) to avoid
+// looking those up from the database. Whether an epoch block carries the
+// right election result depends on state, it is checked by Finalize.
+func (d *Dpos) verifyHeader(chain consensus.ChainReader, header *block.Header, parents []*block.Header) error {
+	number := header.Number.IntVal.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	// If the header is known, verify success
+	if chain.GetHeader(header.Hash(), number) != nil {
+		return nil
+	}
+	// Don't waste time checking blocks from the future
+	if header.Time.IntVal.Cmp(big.NewInt(time.Now().Unix()+allowedFutureBlockTime)) > 0 {
+		return consensus.ErrFutureBlock
+	}
+	// Only epoch blocks carry producers
+	data, err := DecodeConsensusData(header)
+	if err != nil {
+		return err
+	}
+	if (number%d.config.Epoch == 0) != (len(data.Producers) > 0) {
+		return errInvalidProducers
+	}
+	// Ensure that the block's parent is the one we expect
+	var parent *block.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.IntVal.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if header.Time.IntVal.Cmp(&parent.Time.IntVal) <= 0 {
+		return consensus.ErrBlockTime
+	}
+	producers, err := d.producerSet(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	return d.verifySlot(chain, header, producers)
+}
+
+// verifySlot checks the header is signed by the producer of its slot.
+func (d *Dpos) verifySlot(chain consensus.ChainReader, header *block.Header, producers []types.Address) error {
+	if header.Time.IntVal.Uint64()%d.config.Period != 0 {
+		return errInvalidTimestamp
+	}
+	signer, err := d.ecrecover(chain, header)
+	if err != nil {
+		return err
+	}
+	if d.scheduled(producers, header.Time.IntVal.Uint64()) != signer {
+		return errUnscheduled
+	}
+	if header.BlockProducer != signer {
+		return errInvalidBlockProducer
+	}
+	return nil
+}
+
+// producerSet retrieves the producers elected for the blocks after the given
+// one, walking back to the last epoch block.
+func (d *Dpos) producerSet(chain consensus.ChainReader, number uint64, hash types.Hash, parents []*block.Header) ([]types.Address, error) {
+	var (
+		visited   []types.Hash
+		producers []types.Address
+	)
+	for producers == nil {
+		if cached, ok := d.producers.Get(hash); ok {
+			producers = cached.([]types.Address)
+			break
+		}
+		var header *block.Header
+		if len(parents) > 0 {
+			// If we have explicit parents, pick from there (enforced)
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.IntVal.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			// No explicit parents (or no more left), reach out to the database
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		visited = append(visited, hash)
+		if number%d.config.Epoch == 0 {
+			// The genesis block may predate the engine, fall back to the config
+			data, err := DecodeConsensusData(header)
+			switch {
+			case number == 0 && (err != nil || len(data.Producers) == 0):
+				producers = d.config.Producers
+			case err != nil:
+				return nil, err
+			default:
+				producers = data.Producers
+			}
+			if len(producers) == 0 {
+				return nil, errInvalidProducers
+			}
+			break
+		}
+		number, hash = number-1, header.ParentHash
+	}
+	for _, hash := range visited {
+		d.producers.Add(hash, producers)
+	}
+	return producers, nil
+}
+
+// elect returns the producers elected by the vote tallies in the state of the
+// given block. Without any vote the producers in effect are kept.
+func (d *Dpos) elect(chain consensus.ChainReader, parent *block.Header) ([]types.Address, error) {
+	statedb, err := state.New(parent.StateRootHash, state.NewDatabase(d.db))
+	if err != nil {
+		return nil, err
+	}
+	tallies := staking.Tallies(sdk.NewTmpStatusManager(d.db, statedb, types.Address{}))
+	if len(tallies) == 0 {
+		return d.producerSet(chain, parent.Number.IntVal.Uint64(), parent.Hash(), nil)
+	}
+	if uint64(len(tallies)) > d.config.MaxProducers {
+		tallies = tallies[:d.config.MaxProducers]
+	}
+	producers := make([]types.Address, len(tallies))
+	for i, tally := range tallies {
+		producers[i] = tally.Address
+	}
+	return producers, nil
+}
+
+// VerifySeal implements consensus.Engine, checking whether the signature
+// contained in the header is the one of the producer scheduled for its slot.
+func (d *Dpos) VerifySeal(chain consensus.ChainReader, header *block.Header) error {
+	number := header.Number.IntVal.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	producers, err := d.producerSet(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	return d.verifySlot(chain, header, producers)
+}
+
+// Prepare implements consensus.Engine, moving the timestamp of the header to
+// the next slot of the local producer and filling the election result into
+// epoch blocks.
+func (d *Dpos) Prepare(chain consensus.ChainReader, header *block.Header) error {
+	number := header.Number.IntVal.Uint64()
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	producers, err := d.producerSet(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	d.lock.RLock()
+	signer, signFn := d.signer, d.signFn
+	d.lock.RUnlock()
+
+	if signFn == nil {
+		return errMissingKey
+	}
+	// Find the first slot of the local producer after the parent
+	earliest := parent.Time.IntVal.Uint64() + 1
+	if t := header.Time.IntVal.Uint64(); t > earliest {
+		earliest = t
+	}
+	slot := (earliest + d.config.Period - 1) / d.config.Period
+	found := false
+	for i := 0; i < len(producers); i++ {
+		if d.scheduled(producers, (slot+uint64(i))*d.config.Period) == signer {
+			slot, found = slot+uint64(i), true
+			break
+		}
+	}
+	if !found {
+		return errUnauthorized
+	}
+	header.Time = types.NewBigInt(*new(big.Int).SetUint64(slot * d.config.Period))
+	header.BlockProducer = signer
+
+	data := new(DposData)
+	if number%d.config.Epoch == 0 {
+		if data.Producers, err = d.elect(chain, parent); err != nil {
+			return err
+		}
+	}
+	header.ConsensusData, err = NewConsensusData(data)
+	return err
+}
+
+// Finalize implements consensus.Engine, setting the final state and assembling
+// the block, signing it when asked to. Blocks not signed here are imported ones,
+// whose election result is checked against the vote tallies.
+func (d *Dpos) Finalize(chain consensus.ChainReader, header *block.Header, state *state.StateDB, txs []*transaction.Transaction, receipts []*transaction.Receipt, sign bool) (*block.Block, error) {
+	number := header.Number.IntVal.Uint64()
+	if !sign && number > 0 && number%d.config.Epoch == 0 {
+		parent := chain.GetHeader(header.ParentHash, number-1)
+		if parent == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+		elected, err := d.elect(chain, parent)
+		if err != nil {
+			return nil, err
+		}
+		data, err := DecodeConsensusData(header)
+		if err != nil {
+			return nil, err
+		}
+		if !equalProducers(data.Producers, elected) {
+			return nil, errInvalidProducers
+		}
+	}
+	header.StateRootHash = state.IntermediateRoot()
+
+	blk := block.NewBlock(header, txs, receipts)
+	if !sign {
+		return blk, nil
+	}
+	d.lock.RLock()
+	signFn := d.signFn
+	d.lock.RUnlock()
+
+	if signFn == nil {
+		return nil, errMissingKey
+	}
+	if err := signFn.SignHeader(block.NewBlockSigner(chain.Config().ChainId), blk.B_header); err != nil {
+		return nil, err
+	}
+	return blk, nil
+}
+
+// Seal implements consensus.Engine, waiting for the slot of the signed block
+// before releasing it.
+func (d *Dpos) Seal(chain consensus.ChainReader, blk *block.Block, stop <-chan struct{}) (*block.Block, error) {
+	header := blk.Header()
+
+	// Sealing the genesis block is not supported
+	if header.Number.IntVal.Sign() == 0 {
+		return nil, errUnknownBlock
+	}
+	delay := time.Unix(header.Time.IntVal.Int64(), 0).Sub(time.Now())
+	logger.Trace("Waiting for slot to sign and propagate", "number", header.Number.IntVal.Uint64(), "delay", delay)
+
+	select {
+	case <-stop:
+		return nil, nil
+	case <-time.After(delay):
+	}
+	return blk.WithSeal(header), nil
+}
+
+// RewardAction implements consensus.Rewarder, paying the block reward through
+// the staking contract which splits it between the producer and its voters.
+func (d *Dpos) RewardAction(header *block.Header) transaction.Action {
+	return transaction.MakeAction(staking.StakingAddress, staking.MakeActionParamsReword(header.BlockProducer))
+}
+
+// Validators implements consensus.Validators, returning the producers elected
+// for the epoch of the given block.
+func (d *Dpos) Validators(chain consensus.ChainReader, header *block.Header) ([]types.Address, error) {
+	number := header.Number.IntVal.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	return d.producerSet(chain, number-1, header.ParentHash, nil)
+}
+
+// APIs returns the user facing RPC API exposing the producers.
+func (d *Dpos) APIs(chain consensus.ChainReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "dpos",
+		Version:   "1.0",
+		Service:   &API{chain: chain, dpos: d},
+		Public:    true,
+	}}
+}
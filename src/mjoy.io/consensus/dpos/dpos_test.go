@@ -0,0 +1,240 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: dpos_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package dpos
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/consensus"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/interpreter/staking"
+	"mjoy.io/params"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/database"
+)
+
+// testerChain is a consensus.ChainReader over a plain list of headers.
+type testerChain struct {
+	config  *params.ChainConfig
+	headers []*block.Header
+}
+
+func (c *testerChain) Config() *params.ChainConfig { return c.config }
+func (c *testerChain) CurrentHeader() *block.Header {
+	return c.headers[len(c.headers)-1]
+}
+func (c *testerChain) GetHeader(hash types.Hash, number uint64) *block.Header {
+	if header := c.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+func (c *testerChain) GetHeaderByNumber(number uint64) *block.Header {
+	if number < uint64(len(c.headers)) {
+		return c.headers[number]
+	}
+	return nil
+}
+func (c *testerChain) GetHeaderByHash(hash types.Hash) *block.Header {
+	for _, header := range c.headers {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
+func (c *testerChain) GetBlock(hash types.Hash, number uint64) *block.Block { return nil }
+
+// testerAccounts are the signing keys used by the tests, by name.
+type testerAccounts map[string]*ecdsa.PrivateKey
+
+func (a testerAccounts) key(name string) *ecdsa.PrivateKey {
+	if a[name] == nil {
+		a[name], _ = crypto.GenerateKey()
+	}
+	return a[name]
+}
+
+func (a testerAccounts) address(name string) types.Address {
+	return crypto.PubkeyToAddress(a.key(name).PublicKey)
+}
+
+func (a testerAccounts) addresses(names ...string) []types.Address {
+	addrs := make([]types.Address, len(names))
+	for i, name := range names {
+		addrs[i] = a.address(name)
+	}
+	return addrs
+}
+
+// newTester creates a chain with a genesis block electing the given producers,
+// with epochs of four blocks and slots of three seconds.
+func newTester(t *testing.T, accounts testerAccounts, producers ...string) (*testerChain, *Dpos) {
+	config := &params.DposConfig{Period: 3, Epoch: 4, Producers: accounts.addresses(producers...)}
+	data, err := GenesisConsensusData(config)
+	if err != nil {
+		t.Fatalf("failed to create genesis data: %v", err)
+	}
+	genesis := &block.Header{Number: types.NewBigInt(*big.NewInt(0)), Time: types.NewBigInt(*big.NewInt(999)), ConsensusData: data}
+	db, _ := database.OpenMemDB()
+
+	chain := &testerChain{config: &params.ChainConfig{ChainId: big.NewInt(1), Dpos: config}, headers: []*block.Header{genesis}}
+	return chain, New(config, db)
+}
+
+// seal creates the next header of the chain, signed by the given account at
+// the given timestamp.
+func seal(t *testing.T, chain *testerChain, accounts testerAccounts, signer string, time int64, producers ...string) *block.Header {
+	parent := chain.CurrentHeader()
+	consensusData, err := NewConsensusData(&DposData{Producers: accounts.addresses(producers...)})
+	if err != nil {
+		t.Fatalf("failed to encode consensus data: %v", err)
+	}
+	header := &block.Header{
+		ParentHash:    parent.Hash(),
+		Number:        types.NewBigInt(*new(big.Int).Add(&parent.Number.IntVal, big.NewInt(1))),
+		Time:          types.NewBigInt(*big.NewInt(time)),
+		BlockProducer: accounts.address(signer),
+		ConsensusData: consensusData,
+	}
+	if err := block.SignHeaderInner(header, block.NewBlockSigner(chain.config.ChainId), accounts.key(signer)); err != nil {
+		t.Fatalf("failed to sign header: %v", err)
+	}
+	return header
+}
+
+func TestDposScheduling(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, engine := newTester(t, accounts, "A", "B", "C")
+
+	// Slot 334 starts at 1002 and belongs to the second producer
+	tests := []struct {
+		signer    string
+		time      int64
+		producers []string
+		err       error
+	}{
+		{"B", 999, nil, consensus.ErrBlockTime},         // Not after the parent
+		{"B", 1001, nil, errInvalidTimestamp},           // Not at a slot start
+		{"A", 1002, nil, errUnscheduled},                // Slot of another producer
+		{"D", 1002, nil, errUnscheduled},                // Not a producer
+		{"B", 1002, []string{"A"}, errInvalidProducers}, // Producers outside an epoch block
+		{"B", 1002, nil, nil},
+		{"C", 1005, nil, nil},
+	}
+	for i, tt := range tests {
+		header := seal(t, chain, accounts, tt.signer, tt.time, tt.producers...)
+		if err := engine.VerifyHeader(chain, header, true); err != tt.err {
+			t.Errorf("test %d: verification error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// The block reward goes to the named producer, who must be the signer
+	header := seal(t, chain, accounts, "B", 1002)
+	header.BlockProducer = accounts.address("A")
+	if err := block.SignHeaderInner(header, block.NewBlockSigner(chain.config.ChainId), accounts.key("B")); err != nil {
+		t.Fatalf("failed to sign header: %v", err)
+	}
+	if err := engine.VerifyHeader(chain, header, true); err != errInvalidBlockProducer {
+		t.Errorf("verification error mismatch: have %v, want %v", err, errInvalidBlockProducer)
+	}
+	// Fill the first epoch, its last block electing C and A
+	for i, signer := range []string{"B", "C", "A"} {
+		chain.headers = append(chain.headers, seal(t, chain, accounts, signer, int64(1002+3*i)))
+	}
+	if header := seal(t, chain, accounts, "B", 1011); engine.VerifyHeader(chain, header, true) != errInvalidProducers {
+		t.Errorf("epoch block without producers accepted")
+	}
+	chain.headers = append(chain.headers, seal(t, chain, accounts, "B", 1011, "C", "A"))
+
+	// Slot 338 now belongs to C, which the previous producers gave to B
+	if header := seal(t, chain, accounts, "B", 1014); engine.VerifyHeader(chain, header, true) != errUnscheduled {
+		t.Errorf("producer of the previous epoch accepted out of its slot")
+	}
+	header = seal(t, chain, accounts, "C", 1014)
+	_, results := engine.VerifyHeaders(chain, []*block.Header{header}, []bool{true})
+	if err := <-results; err != nil {
+		t.Fatalf("failed to verify header of the new epoch: %v", err)
+	}
+	chain.headers = append(chain.headers, header)
+
+	if author, err := engine.Author(chain, header); err != nil || author != accounts.address("C") {
+		t.Errorf("author mismatch: have %x (%v), want %x", author, err, accounts.address("C"))
+	}
+	producers, err := (&API{chain: chain, dpos: engine}).GetProducers(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve producers: %v", err)
+	}
+	if !equalProducers(producers, accounts.addresses("C", "A")) {
+		t.Errorf("producers mismatch: have %x, want %x", producers, accounts.addresses("C", "A"))
+	}
+}
+
+func TestDposPrepare(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, engine := newTester(t, accounts, "A", "B", "C")
+
+	header := &block.Header{
+		ParentHash: chain.CurrentHeader().Hash(),
+		Number:     types.NewBigInt(*big.NewInt(1)),
+		Time:       types.NewBigInt(*big.NewInt(1000)),
+	}
+	if err := engine.Prepare(chain, header); err != errMissingKey {
+		t.Fatalf("prepare without key: have %v, want %v", err, errMissingKey)
+	}
+	engine.SetKey(accounts.key("D"))
+	if err := engine.Prepare(chain, header); err != errUnauthorized {
+		t.Fatalf("prepare by non-producer: have %v, want %v", err, errUnauthorized)
+	}
+	// The next slot of A after the genesis is slot 336
+	engine.SetKey(accounts.key("A"))
+	if err := engine.Prepare(chain, header); err != nil {
+		t.Fatalf("failed to prepare header: %v", err)
+	}
+	if have := header.Time.IntVal.Uint64(); have != 1008 {
+		t.Errorf("timestamp mismatch: have %d, want %d", have, 1008)
+	}
+	if err := block.SignHeaderInner(header, block.NewBlockSigner(chain.config.ChainId), accounts.key("A")); err != nil {
+		t.Fatalf("failed to sign header: %v", err)
+	}
+	if err := engine.VerifyHeader(chain, header, true); err != nil {
+		t.Errorf("prepared header rejected: %v", err)
+	}
+}
+
+// Tests that the block reward is paid through the staking contract to the
+// producer named by the header, verified to be its signer.
+func TestDposRewardAction(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, engine := newTester(t, accounts, "A", "B", "C")
+
+	header := seal(t, chain, accounts, "B", 1002)
+	action := engine.RewardAction(header)
+	if action.Address == nil || *action.Address != staking.StakingAddress {
+		t.Fatalf("reward paid to %x, want the staking contract", action.Address)
+	}
+	if want := staking.MakeActionParamsReword(accounts.address("B")); !bytes.Equal(action.Params, want) {
+		t.Errorf("reward params mismatch: have %s, want %s", action.Params, want)
+	}
+}
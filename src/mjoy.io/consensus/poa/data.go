@@ -0,0 +1,71 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: data.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package poa
+
+import (
+	"sort"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/params"
+)
+
+//go:generate msgp
+
+// ConsensusId identifies the proof-of-authority data in Header.ConsensusData.
+const ConsensusId = "poa"
+
+// PoaData is the content of the consensus data of a proof-of-authority header.
+// Checkpoint blocks carry the full signer list and no vote.
+type PoaData struct {
+	Signers   []types.Address // Authorized signers, sorted, only in checkpoint blocks
+	Candidate types.Address   // Address voted on, the zero address for no vote
+	Authorize bool            // Whether the vote is to authorize or deauthorize the candidate
+}
+
+// NewConsensusData encodes the proof-of-authority data for a header.
+func NewConsensusData(data *PoaData) (block.ConsensusData, error) {
+	para, err := data.MarshalMsg(nil)
+	if err != nil {
+		return block.ConsensusData{}, err
+	}
+	return block.ConsensusData{Id: ConsensusId, Para: para}, nil
+}
+
+// DecodeConsensusData decodes the proof-of-authority data of a header.
+func DecodeConsensusData(header *block.Header) (*PoaData, error) {
+	if header.ConsensusData.Id != ConsensusId {
+		return nil, errInvalidConsensusData
+	}
+	data := new(PoaData)
+	if _, err := data.UnmarshalMsg(header.ConsensusData.Para); err != nil {
+		return nil, errInvalidConsensusData
+	}
+	return data, nil
+}
+
+// GenesisConsensusData returns the consensus data of the genesis block, holding
+// the initial signers of the config.
+func GenesisConsensusData(config *params.PoaConfig) (block.ConsensusData, error) {
+	signers := append([]types.Address(nil), config.Signers...)
+	sort.Sort(addressesAscending(signers))
+	return NewConsensusData(&PoaData{Signers: signers})
+}
@@ -0,0 +1,191 @@
+package poa
+
+// NOTE: THIS FILE WAS PRODUCED BY THE
+// MSGP CODE GENERATION TOOL (github.com/tinylib/msgp)
+// DO NOT EDIT
+
+import (
+	"github.com/tinylib/msgp/msgp"
+	"mjoy.io/common/types"
+)
+
+// DecodeMsg implements msgp.Decodable
+func (z *PoaData) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Signers":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				return
+			}
+			if cap(z.Signers) >= int(zb0002) {
+				z.Signers = (z.Signers)[:zb0002]
+			} else {
+				z.Signers = make([]types.Address, zb0002)
+			}
+			for za0001 := range z.Signers {
+				err = z.Signers[za0001].DecodeMsg(dc)
+				if err != nil {
+					return
+				}
+			}
+		case "Candidate":
+			err = z.Candidate.DecodeMsg(dc)
+			if err != nil {
+				return
+			}
+		case "Authorize":
+			z.Authorize, err = dc.ReadBool()
+			if err != nil {
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *PoaData) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "Signers"
+	err = en.Append(0x83, 0xa7, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Signers)))
+	if err != nil {
+		return
+	}
+	for za0001 := range z.Signers {
+		err = z.Signers[za0001].EncodeMsg(en)
+		if err != nil {
+			return
+		}
+	}
+	// write "Candidate"
+	err = en.Append(0xa9, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65)
+	if err != nil {
+		return
+	}
+	err = z.Candidate.EncodeMsg(en)
+	if err != nil {
+		return
+	}
+	// write "Authorize"
+	err = en.Append(0xa9, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.Authorize)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *PoaData) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "Signers"
+	o = append(o, 0x83, 0xa7, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Signers)))
+	for za0001 := range z.Signers {
+		o, err = z.Signers[za0001].MarshalMsg(o)
+		if err != nil {
+			return
+		}
+	}
+	// string "Candidate"
+	o = append(o, 0xa9, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65)
+	o, err = z.Candidate.MarshalMsg(o)
+	if err != nil {
+		return
+	}
+	// string "Authorize"
+	o = append(o, 0xa9, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65)
+	o = msgp.AppendBool(o, z.Authorize)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *PoaData) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Signers":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				return
+			}
+			if cap(z.Signers) >= int(zb0002) {
+				z.Signers = (z.Signers)[:zb0002]
+			} else {
+				z.Signers = make([]types.Address, zb0002)
+			}
+			for za0001 := range z.Signers {
+				bts, err = z.Signers[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					return
+				}
+			}
+		case "Candidate":
+			bts, err = z.Candidate.UnmarshalMsg(bts)
+			if err != nil {
+				return
+			}
+		case "Authorize":
+			z.Authorize, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *PoaData) Msgsize() (s int) {
+	s = 1 + 8 + msgp.ArrayHeaderSize
+	for za0001 := range z.Signers {
+		s += z.Signers[za0001].Msgsize()
+	}
+	s += 10 + z.Candidate.Msgsize() + 10 + msgp.BoolSize
+	return
+}
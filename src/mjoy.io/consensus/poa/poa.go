@@ -0,0 +1,549 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: poa.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+// Package poa implements the round-robin proof-of-authority consensus engine.
+package poa
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru"
+	"mjoy.io/common/types"
+	"mjoy.io/communication/rpc"
+	"mjoy.io/consensus"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/state"
+	"mjoy.io/core/transaction"
+	"mjoy.io/params"
+	"mjoy.io/utils/database"
+)
+
+const (
+	checkpointInterval = 1024 // Number of blocks after which to save the vote snapshot to the database
+	inmemorySnapshots  = 128  // Number of recent vote snapshots to keep in memory
+	inmemorySignatures = 4096 // Number of recent block signatures to keep in memory
+
+	allowedFutureBlockTime = 15 // Seconds a block may be ahead of the local clock
+)
+
+// Proof-of-authority protocol constants.
+var (
+	epochLength = params.PoaEpochLength // Default number of blocks after which to checkpoint and reset the pending votes
+	blockPeriod = uint64(5)             // Default minimum seconds between two blocks
+	slotDelay   = uint64(2)             // Default seconds an out-of-turn signer waits per slot
+)
+
+// Various error messages to mark blocks invalid. These should be private to
+// prevent engine specific errors from being referenced in the remainder of the
+// codebase, inherently breaking if the engine is swapped out. Please put common
+// error types into the consensus package.
+var (
+	// errUnknownBlock is returned when the list of signers is requested for a block
+	// that is not part of the local blockchain.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errInvalidConsensusData is returned if the consensus data of a header is not
+	// proof-of-authority data.
+	errInvalidConsensusData = errors.New("invalid consensus data")
+
+	// errInvalidCheckpointVote is returned if a checkpoint block contains a vote.
+	errInvalidCheckpointVote = errors.New("vote in checkpoint block")
+
+	// errInvalidCheckpointSigners is returned if a checkpoint block contains an
+	// invalid list of signers, or a non-checkpoint block contains any.
+	errInvalidCheckpointSigners = errors.New("invalid signer list on checkpoint block")
+
+	// errInvalidVotingChain is returned if an authorization list is attempted to
+	// be modified via out-of-range or non-contiguous headers.
+	errInvalidVotingChain = errors.New("invalid voting chain")
+
+	// errUnauthorized is returned if a header is signed by a non-authorized entity.
+	errUnauthorized = errors.New("unauthorized")
+
+	// errRecentlySigned is returned if a header is signed by an authorized entity
+	// that already signed a header recently, thus is temporarily not allowed to.
+	errRecentlySigned = errors.New("recently signed")
+
+	// errInvalidTimestamp is returned if the timestamp of a block is earlier
+	// than the slot of its signer.
+	errInvalidTimestamp = errors.New("timestamp before the signer's slot")
+
+	// errMissingKey is returned if a block is prepared or signed before the
+	// signing key is set.
+	errMissingKey = errors.New("no key found to sign header")
+)
+
+// ecrecover extracts the Mjoy account address from a signed header.
+func ecrecover(header *block.Header, signer block.Signer, sigcache *lru.Cache) (types.Address, error) {
+	// If the signature's already cached, return that
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(types.Address), nil
+	}
+	address, err := signer.Sender(header)
+	if err != nil {
+		return types.Address{}, consensus.ErrSignature
+	}
+	sigcache.Add(hash, address)
+	return address, nil
+}
+
+// Poa is the round-robin proof-of-authority consensus engine. The blocks are
+// signed by a set of authorized signers, taking turns by block number. A signer
+// out of turn may only seal after a delay growing with its distance to the
+// in-turn one.
+type Poa struct {
+	config *params.PoaConfig  // Consensus engine configuration parameters
+	db     database.IDatabase // Database to store and retrieve snapshot checkpoints
+
+	recents    *lru.Cache // Snapshots for recent block to speed up reorgs
+	signatures *lru.Cache // Signatures of recent blocks to speed up producing
+
+	proposals map[types.Address]bool // Current list of proposals we are pushing
+
+	signFn consensus.ProducerSigner // Signer of the headers, holding the key
+	signer types.Address            // Mjoy address of the signing key
+	lock   sync.RWMutex             // Protects the signer and proposals fields
+}
+
+// New creates a proof-of-authority consensus engine with the initial signers
+// set to the ones provided by the genesis block.
+func New(config *params.PoaConfig, db database.IDatabase) *Poa {
+	// Set any missing consensus parameters to their defaults
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = epochLength
+	}
+	if conf.Period == 0 {
+		conf.Period = blockPeriod
+	}
+	if conf.Delay == 0 {
+		conf.Delay = slotDelay
+	}
+	recents, _ := lru.New(inmemorySnapshots)
+	signatures, _ := lru.New(inmemorySignatures)
+
+	return &Poa{
+		config:     &conf,
+		db:         db,
+		recents:    recents,
+		signatures: signatures,
+		proposals:  make(map[types.Address]bool),
+	}
+}
+
+// SetKey sets the key the headers are signed with.
+func (p *Poa) SetKey(prv *ecdsa.PrivateKey) {
+	p.SetSigner(consensus.NewKeySigner(prv))
+}
+
+// SetSigner sets the signer of the headers, which may hold the key out of
+// process.
+func (p *Poa) SetSigner(signer consensus.ProducerSigner) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.signFn = signer
+	p.signer = signer.Address()
+}
+
+// Author implements consensus.Engine, returning the authorized signer of the
+// header.
+func (p *Poa) Author(chain consensus.ChainReader, header *block.Header) (types.Address, error) {
+	number := header.Number.IntVal.Uint64()
+	if number == 0 {
+		return types.Address{}, errUnknownBlock
+	}
+	signer, err := ecrecover(header, block.NewBlockSigner(chain.Config().ChainId), p.signatures)
+	if err != nil {
+		return types.Address{}, err
+	}
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return types.Address{}, err
+	}
+	if _, ok := snap.Signers[signer]; !ok {
+		return types.Address{}, errUnauthorized
+	}
+	return signer, nil
+}
+
+// VerifyHeader checks whether a header conforms to the consensus rules.
+func (p *Poa) VerifyHeader(chain consensus.ChainReader, header *block.Header, seal bool) error {
+	return p.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. The
+// method returns a quit channel to abort the operations and a results channel to
+// retrieve the async verifications (the order is that of the input slice).
+func (p *Poa) VerifyHeaders(chain consensus.ChainReader, headers []*block.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := p.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader checks whether a header conforms to the consensus rules. The
+// caller may optionally pass in a batch of parents (ascending order) to avoid
+// looking those up from the database.
+func (p *Poa) verifyHeader(chain consensus.ChainReader, header *block.Header, parents []*block.Header) error {
+	number := header.Number.IntVal.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	// If the header is known, verify success
+	if chain.GetHeader(header.Hash(), number) != nil {
+		return nil
+	}
+	// Don't waste time checking blocks from the future
+	if header.Time.IntVal.Cmp(big.NewInt(time.Now().Unix()+allowedFutureBlockTime)) > 0 {
+		return consensus.ErrFutureBlock
+	}
+	// Checkpoint blocks need to enforce zero votes, others an empty signer list
+	data, err := DecodeConsensusData(header)
+	if err != nil {
+		return err
+	}
+	checkpoint := number%p.config.Epoch == 0
+	if checkpoint && data.Candidate != (types.Address{}) {
+		return errInvalidCheckpointVote
+	}
+	if !checkpoint && len(data.Signers) != 0 {
+		return errInvalidCheckpointSigners
+	}
+	return p.verifyCascadingFields(chain, header, data, parents)
+}
+
+// verifyCascadingFields verifies all the header fields that depend on a batch
+// of previous headers.
+func (p *Poa) verifyCascadingFields(chain consensus.ChainReader, header *block.Header, data *PoaData, parents []*block.Header) error {
+	number := header.Number.IntVal.Uint64()
+
+	// Ensure that the block's parent is the one we expect
+	var parent *block.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.IntVal.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	// Retrieve the snapshot needed to verify this header and cache it
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	// If the block is a checkpoint block, verify the signer list
+	if number%p.config.Epoch == 0 {
+		signers := snap.signers()
+		if len(signers) != len(data.Signers) {
+			return errInvalidCheckpointSigners
+		}
+		for i, signer := range signers {
+			if signer != data.Signers[i] {
+				return errInvalidCheckpointSigners
+			}
+		}
+	}
+	return p.verifySeal(chain, header, parent, snap)
+}
+
+// snapshot retrieves the authorization snapshot at a given point in time.
+func (p *Poa) snapshot(chain consensus.ChainReader, number uint64, hash types.Hash, parents []*block.Header) (*Snapshot, error) {
+	// Search for a snapshot in memory or on disk for checkpoints
+	var (
+		headers []*block.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		// If an in-memory snapshot was found, use that
+		if s, ok := p.recents.Get(hash); ok {
+			snap = s.(*Snapshot)
+			break
+		}
+		// If an on-disk checkpoint snapshot can be found, use that
+		if number%checkpointInterval == 0 {
+			if s, err := loadSnapshot(p.config, p.signatures, p.db, hash); err == nil {
+				logger.Trace("Loaded voting snapshot from disk", "number", number, "hash", hash)
+				snap = s
+				break
+			}
+		}
+		// If we're at the genesis, snapshot the initial state
+		if number == 0 {
+			genesis := chain.GetHeaderByNumber(0)
+			if genesis == nil || genesis.Hash() != hash {
+				return nil, errUnknownBlock
+			}
+			signers := p.config.Signers
+			if data, err := DecodeConsensusData(genesis); err == nil && len(data.Signers) > 0 {
+				signers = data.Signers
+			}
+			if len(signers) == 0 {
+				return nil, errInvalidCheckpointSigners
+			}
+			snap = newSnapshot(p.config, p.signatures, 0, genesis.Hash(), signers)
+			if err := snap.store(p.db); err != nil {
+				return nil, err
+			}
+			logger.Trace("Stored genesis voting snapshot to disk")
+			break
+		}
+		// A checkpoint without its parent is the base of a state snapshot, trust
+		// the signers it lists
+		if number%p.config.Epoch == 0 && len(parents) == 0 && chain.GetHeaderByNumber(number-1) == nil {
+			if checkpoint := chain.GetHeader(hash, number); checkpoint != nil {
+				data, err := DecodeConsensusData(checkpoint)
+				if err != nil {
+					return nil, err
+				}
+				if len(data.Signers) == 0 {
+					return nil, errInvalidCheckpointSigners
+				}
+				snap = newSnapshot(p.config, p.signatures, number, hash, data.Signers)
+				if err := snap.store(p.db); err != nil {
+					return nil, err
+				}
+				logger.Trace("Stored checkpoint voting snapshot to disk", "number", number, "hash", hash)
+				break
+			}
+		}
+		// No snapshot for this header, gather the header and move backward
+		var header *block.Header
+		if len(parents) > 0 {
+			// If we have explicit parents, pick from there (enforced)
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.IntVal.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			// No explicit parents (or no more left), reach out to the database
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+	// Previous snapshot found, apply any pending headers on top of it
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	snap, err := snap.apply(block.NewBlockSigner(chain.Config().ChainId), headers)
+	if err != nil {
+		return nil, err
+	}
+	p.recents.Add(snap.Hash, snap)
+
+	// If we've generated a new checkpoint snapshot, save to disk
+	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
+		if err = snap.store(p.db); err != nil {
+			return nil, err
+		}
+		logger.Trace("Stored voting snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+	}
+	return snap, err
+}
+
+// VerifySeal implements consensus.Engine, checking whether the signature
+// contained in the header satisfies the consensus protocol requirements.
+func (p *Poa) VerifySeal(chain consensus.ChainReader, header *block.Header) error {
+	number := header.Number.IntVal.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	return p.verifySeal(chain, header, parent, snap)
+}
+
+// verifySeal checks the header is signed by an authorized signer, which did
+// not sign recently and waited for its slot.
+func (p *Poa) verifySeal(chain consensus.ChainReader, header, parent *block.Header, snap *Snapshot) error {
+	number := header.Number.IntVal.Uint64()
+
+	// Resolve the authorization key and check against signers
+	signer, err := ecrecover(header, block.NewBlockSigner(chain.Config().ChainId), p.signatures)
+	if err != nil {
+		return err
+	}
+	if _, ok := snap.Signers[signer]; !ok {
+		return errUnauthorized
+	}
+	if snap.recentlySigned(number, signer) {
+		return errRecentlySigned
+	}
+	// Ensure the signer waited for its slot
+	if header.Time.IntVal.Cmp(p.slotTime(parent, snap, number, signer)) < 0 {
+		return errInvalidTimestamp
+	}
+	return nil
+}
+
+// slotTime returns the earliest timestamp the signer may seal the block at,
+// the in-turn signer being allowed a period after the parent and every other
+// signer a delay later per slot it is away.
+func (p *Poa) slotTime(parent *block.Header, snap *Snapshot, number uint64, signer types.Address) *big.Int {
+	slot := p.config.Period + snap.distance(number, signer)*p.config.Delay
+	return new(big.Int).Add(&parent.Time.IntVal, new(big.Int).SetUint64(slot))
+}
+
+// Prepare implements consensus.Engine, preparing all the consensus fields of the
+// header for running the transactions on top. The local signer has to be
+// authorized, and the timestamp is moved to its slot.
+func (p *Poa) Prepare(chain consensus.ChainReader, header *block.Header) error {
+	number := header.Number.IntVal.Uint64()
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.signFn == nil {
+		return errMissingKey
+	}
+	if _, ok := snap.Signers[p.signer]; !ok {
+		return errUnauthorized
+	}
+	if snap.recentlySigned(number, p.signer) {
+		return errRecentlySigned
+	}
+	data := new(PoaData)
+	if number%p.config.Epoch == 0 {
+		data.Signers = snap.signers()
+	} else {
+		// Cast a random vote out of the valid proposals
+		addresses := make([]types.Address, 0, len(p.proposals))
+		for address, authorize := range p.proposals {
+			if snap.validVote(address, authorize) {
+				addresses = append(addresses, address)
+			}
+		}
+		if len(addresses) > 0 {
+			data.Candidate = addresses[rand.Intn(len(addresses))]
+			data.Authorize = p.proposals[data.Candidate]
+		}
+	}
+	if header.ConsensusData, err = NewConsensusData(data); err != nil {
+		return err
+	}
+	// Move the timestamp to the slot of the signer
+	if slot := p.slotTime(parent, snap, number, p.signer); header.Time.IntVal.Cmp(slot) < 0 {
+		header.Time = types.NewBigInt(*slot)
+	}
+	return nil
+}
+
+// Finalize implements consensus.Engine, setting the final state and assembling
+// the block, signing it when asked to.
+func (p *Poa) Finalize(chain consensus.ChainReader, header *block.Header, state *state.StateDB, txs []*transaction.Transaction, receipts []*transaction.Receipt, sign bool) (*block.Block, error) {
+	header.StateRootHash = state.IntermediateRoot()
+
+	blk := block.NewBlock(header, txs, receipts)
+	if !sign {
+		return blk, nil
+	}
+	p.lock.RLock()
+	signFn := p.signFn
+	p.lock.RUnlock()
+
+	if signFn == nil {
+		return nil, errMissingKey
+	}
+	if err := signFn.SignHeader(block.NewBlockSigner(chain.Config().ChainId), blk.B_header); err != nil {
+		return nil, err
+	}
+	return blk, nil
+}
+
+// Seal implements consensus.Engine, waiting for the slot of the signed block
+// before releasing it.
+func (p *Poa) Seal(chain consensus.ChainReader, blk *block.Block, stop <-chan struct{}) (*block.Block, error) {
+	header := blk.Header()
+
+	// Sealing the genesis block is not supported
+	if header.Number.IntVal.Sign() == 0 {
+		return nil, errUnknownBlock
+	}
+	delay := time.Unix(header.Time.IntVal.Int64(), 0).Sub(time.Now())
+	logger.Trace("Waiting for slot to sign and propagate", "number", header.Number.IntVal.Uint64(), "delay", delay)
+
+	select {
+	case <-stop:
+		return nil, nil
+	case <-time.After(delay):
+	}
+	return blk.WithSeal(header), nil
+}
+
+// Validators implements consensus.Validators, returning the signers authorized
+// to seal the given block.
+func (p *Poa) Validators(chain consensus.ChainReader, header *block.Header) ([]types.Address, error) {
+	number := header.Number.IntVal.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	return snap.signers(), nil
+}
+
+// APIs implements consensus.Engine, returning the user facing RPC API to allow
+// controlling the signer voting.
+func (p *Poa) APIs(chain consensus.ChainReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "poa",
+		Version:   "1.0",
+		Service:   &API{chain: chain, poa: p},
+		Public:    false,
+	}}
+}
@@ -0,0 +1,255 @@
+package poa
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/params"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/database"
+)
+
+// testerChain is a consensus.ChainReader over a plain list of headers.
+type testerChain struct {
+	config  *params.ChainConfig
+	headers []*block.Header
+}
+
+func (c *testerChain) Config() *params.ChainConfig { return c.config }
+func (c *testerChain) CurrentHeader() *block.Header {
+	return c.headers[len(c.headers)-1]
+}
+func (c *testerChain) GetHeader(hash types.Hash, number uint64) *block.Header {
+	if header := c.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+func (c *testerChain) GetHeaderByNumber(number uint64) *block.Header {
+	if number < uint64(len(c.headers)) {
+		return c.headers[number]
+	}
+	return nil
+}
+func (c *testerChain) GetHeaderByHash(hash types.Hash) *block.Header {
+	for _, header := range c.headers {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
+func (c *testerChain) GetBlock(hash types.Hash, number uint64) *block.Block { return nil }
+
+// testerAccounts are the signing keys used by the tests, by name.
+type testerAccounts map[string]*ecdsa.PrivateKey
+
+func (a testerAccounts) key(name string) *ecdsa.PrivateKey {
+	if a[name] == nil {
+		a[name], _ = crypto.GenerateKey()
+	}
+	return a[name]
+}
+
+func (a testerAccounts) address(name string) types.Address {
+	return crypto.PubkeyToAddress(a.key(name).PublicKey)
+}
+
+// newTester creates a chain with a genesis block authorizing the given signers.
+func newTester(t *testing.T, accounts testerAccounts, signers ...string) (*testerChain, *Poa) {
+	config := &params.PoaConfig{Period: 10, Epoch: 30000, Delay: 5}
+	for _, name := range signers {
+		config.Signers = append(config.Signers, accounts.address(name))
+	}
+	data, err := GenesisConsensusData(config)
+	if err != nil {
+		t.Fatalf("failed to create genesis data: %v", err)
+	}
+	genesis := &block.Header{Number: types.NewBigInt(*big.NewInt(0)), Time: types.NewBigInt(*big.NewInt(1000)), ConsensusData: data}
+	db, _ := database.OpenMemDB()
+
+	chain := &testerChain{config: &params.ChainConfig{ChainId: big.NewInt(1), Poa: config}, headers: []*block.Header{genesis}}
+	return chain, New(config, db)
+}
+
+// seal creates the next header of the chain, signed by the given account at
+// the given number of seconds after its parent.
+func seal(t *testing.T, chain *testerChain, accounts testerAccounts, signer string, elapsed int64, data *PoaData) *block.Header {
+	parent := chain.CurrentHeader()
+	consensusData, err := NewConsensusData(data)
+	if err != nil {
+		t.Fatalf("failed to encode consensus data: %v", err)
+	}
+	header := &block.Header{
+		ParentHash:    parent.Hash(),
+		Number:        types.NewBigInt(*new(big.Int).Add(&parent.Number.IntVal, big.NewInt(1))),
+		Time:          types.NewBigInt(*new(big.Int).Add(&parent.Time.IntVal, big.NewInt(elapsed))),
+		ConsensusData: consensusData,
+	}
+	if err := block.SignHeaderInner(header, block.NewBlockSigner(chain.config.ChainId), accounts.key(signer)); err != nil {
+		t.Fatalf("failed to sign header: %v", err)
+	}
+	return header
+}
+
+func TestPoaScheduling(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, engine := newTester(t, accounts, "A", "B", "C")
+
+	signers := []string{"A", "B", "C"}
+	addrs := map[types.Address]string{}
+	for _, name := range signers {
+		addrs[accounts.address(name)] = name
+	}
+	snap, err := engine.snapshot(chain, 0, chain.CurrentHeader().Hash(), nil)
+	if err != nil {
+		t.Fatalf("failed to create genesis snapshot: %v", err)
+	}
+	order := snap.signers()
+	inturn, next, last := addrs[order[1]], addrs[order[2]], addrs[order[0]]
+
+	tests := []struct {
+		signer  string
+		elapsed int64
+		err     error
+	}{
+		{"D", 10, errUnauthorized},       // Not a signer
+		{inturn, 9, errInvalidTimestamp}, // In-turn signer before the period
+		{next, 14, errInvalidTimestamp},  // Out-of-turn signer before its delay
+		{last, 19, errInvalidTimestamp},  // Out-of-turn signer before its delay
+		{next, 15, nil},                  // Out-of-turn signer after its delay
+	}
+	for i, tt := range tests {
+		header := seal(t, chain, accounts, tt.signer, tt.elapsed, new(PoaData))
+		if err := engine.VerifyHeader(chain, header, true); err != tt.err {
+			t.Errorf("test %d: verification error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	chain.headers = append(chain.headers, seal(t, chain, accounts, inturn, 10, new(PoaData)))
+
+	// The signer of block 1 may not seal block 2, even in turn
+	if header := seal(t, chain, accounts, inturn, 100, new(PoaData)); engine.VerifyHeader(chain, header, true) != errRecentlySigned {
+		t.Errorf("recent signer allowed to seal again")
+	}
+	if author, err := engine.Author(chain, chain.CurrentHeader()); err != nil || author != accounts.address(inturn) {
+		t.Errorf("author mismatch: have %x (%v), want %x", author, err, accounts.address(inturn))
+	}
+}
+
+func TestPoaVoting(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, engine := newTester(t, accounts, "A", "B", "C")
+
+	// Two of three signers vote D in, then three of four vote C out
+	votes := []struct {
+		signer    string
+		candidate string
+		authorize bool
+	}{
+		{"A", "D", true},
+		{"B", "D", true},
+		{"C", "", false},
+		{"A", "C", false},
+		{"D", "C", false},
+		{"B", "C", false},
+	}
+	for i, vote := range votes {
+		data := new(PoaData)
+		if vote.candidate != "" {
+			data.Candidate, data.Authorize = accounts.address(vote.candidate), vote.authorize
+		}
+		header := seal(t, chain, accounts, vote.signer, 100, data)
+
+		_, results := engine.VerifyHeaders(chain, []*block.Header{header}, []bool{true})
+		if err := <-results; err != nil {
+			t.Fatalf("vote %d: failed to verify header: %v", i, err)
+		}
+		chain.headers = append(chain.headers, header)
+	}
+	signers, err := (&API{chain: chain, poa: engine}).GetSigners(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve signers: %v", err)
+	}
+	want := map[types.Address]bool{accounts.address("A"): true, accounts.address("B"): true, accounts.address("D"): true}
+	if len(signers) != len(want) {
+		t.Fatalf("signer count mismatch: have %d, want %d", len(signers), len(want))
+	}
+	for _, signer := range signers {
+		if !want[signer] {
+			t.Errorf("unexpected signer %x", signer)
+		}
+	}
+}
+
+func TestPoaPrepare(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, engine := newTester(t, accounts, "A")
+
+	header := &block.Header{
+		ParentHash: chain.CurrentHeader().Hash(),
+		Number:     types.NewBigInt(*big.NewInt(1)),
+		Time:       types.NewBigInt(*big.NewInt(1001)),
+	}
+	if err := engine.Prepare(chain, header); err != errMissingKey {
+		t.Fatalf("prepare without key: have %v, want %v", err, errMissingKey)
+	}
+	engine.SetKey(accounts.key("B"))
+	if err := engine.Prepare(chain, header); err != errUnauthorized {
+		t.Fatalf("prepare by non-signer: have %v, want %v", err, errUnauthorized)
+	}
+	engine.SetKey(accounts.key("A"))
+	if err := engine.Prepare(chain, header); err != nil {
+		t.Fatalf("failed to prepare header: %v", err)
+	}
+	if header.Time.IntVal.Int64() != 1010 {
+		t.Errorf("timestamp mismatch: have %d, want %d", header.Time.IntVal.Int64(), 1010)
+	}
+	if err := block.SignHeaderInner(header, block.NewBlockSigner(chain.config.ChainId), accounts.key("A")); err != nil {
+		t.Fatalf("failed to sign header: %v", err)
+	}
+	if err := engine.VerifyHeader(chain, header, true); err != nil {
+		t.Errorf("failed to verify prepared header: %v", err)
+	}
+}
+
+// Tests that a checkpoint without its parent, the base of a state snapshot,
+// authorizes the signers it lists.
+func TestPoaCheckpointBase(t *testing.T) {
+	accounts := make(testerAccounts)
+	chain, engine := newTester(t, accounts, "A")
+
+	epoch := engine.config.Epoch
+	data, err := NewConsensusData(&PoaData{Signers: []types.Address{accounts.address("B"), accounts.address("C")}})
+	if err != nil {
+		t.Fatalf("failed to encode consensus data: %v", err)
+	}
+	checkpoint := &block.Header{
+		ParentHash:    types.Hash{1},
+		Number:        types.NewBigInt(*new(big.Int).SetUint64(epoch)),
+		Time:          types.NewBigInt(*big.NewInt(1000)),
+		ConsensusData: data,
+	}
+	if err := block.SignHeaderInner(checkpoint, block.NewBlockSigner(chain.config.ChainId), accounts.key("B")); err != nil {
+		t.Fatalf("failed to sign header: %v", err)
+	}
+	chain.headers = make([]*block.Header, epoch+1)
+	chain.headers[epoch] = checkpoint
+
+	tests := []struct {
+		signer string
+		err    error
+	}{
+		{"A", errUnauthorized},
+		{"B", nil},
+		{"C", nil},
+	}
+	for _, tt := range tests {
+		header := seal(t, chain, accounts, tt.signer, 100, new(PoaData))
+		if err := engine.VerifyHeader(chain, header, true); err != tt.err {
+			t.Errorf("signer %s: verification error mismatch: have %v, want %v", tt.signer, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: snapshot.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package poa
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/hashicorp/golang-lru"
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/params"
+	"mjoy.io/utils/database"
+)
+
+// Vote represents a single vote that an authorized signer made to modify the
+// list of authorizations.
+type Vote struct {
+	Signer    types.Address `json:"signer"`    // Authorized signer that cast this vote
+	Block     uint64        `json:"block"`     // Block number the vote was cast in (expire old votes)
+	Address   types.Address `json:"address"`   // Account being voted on to change its authorization
+	Authorize bool          `json:"authorize"` // Whether to authorize or deauthorize the voted account
+}
+
+// Tally is a simple vote tally to keep the current score of votes. Votes that
+// go against the proposal aren't counted since it's equivalent to not voting.
+type Tally struct {
+	Authorize bool `json:"authorize"` // Whether the vote is about authorizing or kicking someone
+	Votes     int  `json:"votes"`     // Number of votes until now wanting to pass the proposal
+}
+
+// Snapshot is the state of the authorization voting at a given point in time.
+type Snapshot struct {
+	config   *params.PoaConfig // Consensus engine parameters to fine tune behavior
+	sigcache *lru.Cache        // Cache of recent block signatures to speed up recovery
+
+	Number  uint64                     `json:"number"`  // Block number where the snapshot was created
+	Hash    types.Hash                 `json:"hash"`    // Block hash where the snapshot was created
+	Signers map[types.Address]struct{} `json:"signers"` // Set of authorized signers at this moment
+	Recents map[uint64]types.Address   `json:"recents"` // Set of recent signers for spam protections
+	Votes   []*Vote                    `json:"votes"`   // List of votes cast in chronological order
+	Tally   map[types.Address]Tally    `json:"tally"`   // Current vote tally to avoid recalculating
+}
+
+// newSnapshot creates a new snapshot with the specified startup parameters. This
+// method does not initialize the set of recent signers, so only ever use it for
+// the genesis block or checkpoints.
+func newSnapshot(config *params.PoaConfig, sigcache *lru.Cache, number uint64, hash types.Hash, signers []types.Address) *Snapshot {
+	snap := &Snapshot{
+		config:   config,
+		sigcache: sigcache,
+		Number:   number,
+		Hash:     hash,
+		Signers:  make(map[types.Address]struct{}),
+		Recents:  make(map[uint64]types.Address),
+		Tally:    make(map[types.Address]Tally),
+	}
+	for _, signer := range signers {
+		snap.Signers[signer] = struct{}{}
+	}
+	return snap
+}
+
+// loadSnapshot loads an existing snapshot from the database.
+func loadSnapshot(config *params.PoaConfig, sigcache *lru.Cache, db database.IDatabaseGetter, hash types.Hash) (*Snapshot, error) {
+	blob, err := db.Get(append([]byte("poa-"), hash[:]...))
+	if err != nil {
+		return nil, err
+	}
+	snap := new(Snapshot)
+	if err := json.Unmarshal(blob, snap); err != nil {
+		return nil, err
+	}
+	snap.config = config
+	snap.sigcache = sigcache
+
+	return snap, nil
+}
+
+// store inserts the snapshot into the database.
+func (s *Snapshot) store(db database.IDatabasePutter) error {
+	blob, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return db.Put(append([]byte("poa-"), s.Hash[:]...), blob)
+}
+
+// copy creates a deep copy of the snapshot, though not the individual votes.
+func (s *Snapshot) copy() *Snapshot {
+	cpy := &Snapshot{
+		config:   s.config,
+		sigcache: s.sigcache,
+		Number:   s.Number,
+		Hash:     s.Hash,
+		Signers:  make(map[types.Address]struct{}),
+		Recents:  make(map[uint64]types.Address),
+		Votes:    make([]*Vote, len(s.Votes)),
+		Tally:    make(map[types.Address]Tally),
+	}
+	for signer := range s.Signers {
+		cpy.Signers[signer] = struct{}{}
+	}
+	for block, signer := range s.Recents {
+		cpy.Recents[block] = signer
+	}
+	for address, tally := range s.Tally {
+		cpy.Tally[address] = tally
+	}
+	copy(cpy.Votes, s.Votes)
+
+	return cpy
+}
+
+// validVote returns whether it makes sense to cast the specified vote in the
+// given snapshot context (e.g. don't try to add an already authorized signer).
+func (s *Snapshot) validVote(address types.Address, authorize bool) bool {
+	_, signer := s.Signers[address]
+	return (signer && !authorize) || (!signer && authorize)
+}
+
+// cast adds a new vote into the tally.
+func (s *Snapshot) cast(address types.Address, authorize bool) bool {
+	// Ensure the vote is meaningful
+	if !s.validVote(address, authorize) {
+		return false
+	}
+	// Cast the vote into an existing or new tally
+	if old, ok := s.Tally[address]; ok {
+		old.Votes++
+		s.Tally[address] = old
+	} else {
+		s.Tally[address] = Tally{Authorize: authorize, Votes: 1}
+	}
+	return true
+}
+
+// uncast removes a previously cast vote from the tally.
+func (s *Snapshot) uncast(address types.Address, authorize bool) bool {
+	// If there's no tally, it's a dangling vote, just drop
+	tally, ok := s.Tally[address]
+	if !ok {
+		return false
+	}
+	// Ensure we only revert counted votes
+	if tally.Authorize != authorize {
+		return false
+	}
+	// Otherwise revert the vote
+	if tally.Votes > 1 {
+		tally.Votes--
+		s.Tally[address] = tally
+	} else {
+		delete(s.Tally, address)
+	}
+	return true
+}
+
+// apply creates a new authorization snapshot by applying the given headers to
+// the original one.
+func (s *Snapshot) apply(blockSigner block.Signer, headers []*block.Header) (*Snapshot, error) {
+	// Allow passing in no headers for cleaner code
+	if len(headers) == 0 {
+		return s, nil
+	}
+	// Sanity check that the headers can be applied
+	for i := 0; i < len(headers)-1; i++ {
+		if headers[i+1].Number.IntVal.Uint64() != headers[i].Number.IntVal.Uint64()+1 {
+			return nil, errInvalidVotingChain
+		}
+	}
+	if headers[0].Number.IntVal.Uint64() != s.Number+1 {
+		return nil, errInvalidVotingChain
+	}
+	// Iterate through the headers and create a new snapshot
+	snap := s.copy()
+
+	for _, header := range headers {
+		// Remove any votes on checkpoint blocks
+		number := header.Number.IntVal.Uint64()
+		if number%s.config.Epoch == 0 {
+			snap.Votes = nil
+			snap.Tally = make(map[types.Address]Tally)
+		}
+		// Delete the oldest signer from the recent list to allow it signing again
+		if limit := uint64(len(snap.Signers)/2 + 1); number >= limit {
+			delete(snap.Recents, number-limit)
+		}
+		// Resolve the authorization key and check against signers
+		signer, err := ecrecover(header, blockSigner, s.sigcache)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := snap.Signers[signer]; !ok {
+			return nil, errUnauthorized
+		}
+		if snap.recentlySigned(number, signer) {
+			return nil, errRecentlySigned
+		}
+		snap.Recents[number] = signer
+
+		data, err := DecodeConsensusData(header)
+		if err != nil {
+			return nil, err
+		}
+		if data.Candidate == (types.Address{}) {
+			continue
+		}
+		// Discard any previous votes from the signer on the same candidate
+		for i, vote := range snap.Votes {
+			if vote.Signer == signer && vote.Address == data.Candidate {
+				// Uncast the vote from the cached tally
+				snap.uncast(vote.Address, vote.Authorize)
+
+				// Uncast the vote from the chronological list
+				snap.Votes = append(snap.Votes[:i], snap.Votes[i+1:]...)
+				break // only one vote allowed
+			}
+		}
+		// Tally up the new vote from the signer
+		if snap.cast(data.Candidate, data.Authorize) {
+			snap.Votes = append(snap.Votes, &Vote{
+				Signer:    signer,
+				Block:     number,
+				Address:   data.Candidate,
+				Authorize: data.Authorize,
+			})
+		}
+		// If the vote passed, update the list of signers
+		if tally := snap.Tally[data.Candidate]; tally.Votes > len(snap.Signers)/2 {
+			if tally.Authorize {
+				snap.Signers[data.Candidate] = struct{}{}
+			} else {
+				delete(snap.Signers, data.Candidate)
+
+				// Signer list shrunk, delete any leftover recent caches
+				if limit := uint64(len(snap.Signers)/2 + 1); number >= limit {
+					delete(snap.Recents, number-limit)
+				}
+				// Discard any previous votes the deauthorized signer cast
+				for i := 0; i < len(snap.Votes); i++ {
+					if snap.Votes[i].Signer == data.Candidate {
+						// Uncast the vote from the cached tally
+						snap.uncast(snap.Votes[i].Address, snap.Votes[i].Authorize)
+
+						// Uncast the vote from the chronological list
+						snap.Votes = append(snap.Votes[:i], snap.Votes[i+1:]...)
+
+						i--
+					}
+				}
+			}
+			// Discard any previous votes around the just changed account
+			for i := 0; i < len(snap.Votes); i++ {
+				if snap.Votes[i].Address == data.Candidate {
+					snap.Votes = append(snap.Votes[:i], snap.Votes[i+1:]...)
+					i--
+				}
+			}
+			delete(snap.Tally, data.Candidate)
+		}
+	}
+	snap.Number = headers[len(headers)-1].Number.IntVal.Uint64()
+	snap.Hash = headers[len(headers)-1].Hash()
+
+	return snap, nil
+}
+
+// signers retrieves the list of authorized signers in ascending order.
+func (s *Snapshot) signers() []types.Address {
+	signers := make([]types.Address, 0, len(s.Signers))
+	for signer := range s.Signers {
+		signers = append(signers, signer)
+	}
+	sort.Sort(addressesAscending(signers))
+	return signers
+}
+
+// distance returns how many slots the signer is away from the in-turn signer
+// of the given block, 0 meaning it is in-turn.
+func (s *Snapshot) distance(number uint64, signer types.Address) uint64 {
+	signers := s.signers()
+	for offset, addr := range signers {
+		if addr == signer {
+			return (uint64(offset) + uint64(len(signers)) - number%uint64(len(signers))) % uint64(len(signers))
+		}
+	}
+	return uint64(len(signers))
+}
+
+// inturn returns if a signer at a given block height is in-turn or not.
+func (s *Snapshot) inturn(number uint64, signer types.Address) bool {
+	return s.distance(number, signer) == 0
+}
+
+// addressesAscending implements the sort interface to allow sorting a list of
+// addresses.
+type addressesAscending []types.Address
+
+func (s addressesAscending) Len() int           { return len(s) }
+func (s addressesAscending) Less(i, j int) bool { return bytes.Compare(s[i][:], s[j][:]) < 0 }
+func (s addressesAscending) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// recentlySigned returns whether the signer sealed one of the blocks before
+// the given one too recently to seal it.
+func (s *Snapshot) recentlySigned(number uint64, signer types.Address) bool {
+	for seen, recent := range s.Recents {
+		if recent == signer {
+			// Signer is among recents, only fail if the current block doesn't shift it out
+			if limit := uint64(len(s.Signers)/2 + 1); number < limit || seen > number-limit {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: client.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package remotesigner
+
+import (
+	"fmt"
+
+	"mjoy.io/common/types"
+	"mjoy.io/common/types/util/hex"
+	"mjoy.io/communication/rpc"
+	"mjoy.io/core/blockchain/block"
+)
+
+// Client signs the headers and finality votes of the local producer through a
+// remote signer. It implements consensus.ProducerSigner.
+type Client struct {
+	client  *rpc.Client
+	address types.Address
+}
+
+// Dial connects to the signer at the given endpoint, which is either an
+// http:// URL or the path of an IPC socket.
+func Dial(endpoint string) (*Client, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClient(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewClient creates a signer client using the given RPC connection, asking the
+// signer for the address of its key.
+func NewClient(client *rpc.Client) (*Client, error) {
+	var address types.Address
+	if err := client.Call(&address, Namespace+"_address"); err != nil {
+		return nil, err
+	}
+	return &Client{client: client, address: address}, nil
+}
+
+// Close closes the connection to the signer.
+func (c *Client) Close() {
+	c.client.Close()
+}
+
+// Address returns the address of the remote key.
+func (c *Client) Address() types.Address {
+	return c.address
+}
+
+// SignHeader has the remote signer sign the header, then fills in its
+// signature values, checking that they recover to the remote key.
+func (c *Client) SignHeader(signer block.Signer, header *block.Header) error {
+	data, err := header.MarshalMsg(nil)
+	if err != nil {
+		return err
+	}
+	var sig hex.Bytes
+	if err := c.client.Call(&sig, Namespace+"_signHeader", hex.Bytes(data)); err != nil {
+		return err
+	}
+	if err := header.AddSignature(signer, sig); err != nil {
+		return err
+	}
+	producer, err := signer.Sender(header)
+	if err != nil {
+		return err
+	}
+	if producer != c.address {
+		return fmt.Errorf("remote signer returned a signature of %x, want %x", producer, c.address)
+	}
+	return nil
+}
+
+// SignVote has the remote signer sign the finality vote.
+func (c *Client) SignVote(vote *block.Vote) error {
+	data, err := vote.MarshalMsg(nil)
+	if err != nil {
+		return err
+	}
+	var sig hex.Bytes
+	if err := c.client.Call(&sig, Namespace+"_signVote", hex.Bytes(data)); err != nil {
+		return err
+	}
+	vote.Signature = sig
+	voter, err := vote.Voter()
+	if err != nil {
+		return err
+	}
+	if voter != c.address {
+		return fmt.Errorf("remote signer returned a signature of %x, want %x", voter, c.address)
+	}
+	return nil
+}
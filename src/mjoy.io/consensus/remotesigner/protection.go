@@ -0,0 +1,203 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: protection.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package remotesigner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"mjoy.io/common/types"
+)
+
+const (
+	protectionFile = "protection.json" // Last signed messages, per kind
+	auditFile      = "audit.log"       // Append-only log of the signing requests
+)
+
+// Kinds of the messages guarded by the slashing protection.
+const (
+	KindHeader    = "header"
+	KindPrevote   = "prevote"
+	KindPrecommit = "precommit"
+)
+
+var (
+	// errDoubleSign is returned when signing would produce a second message of
+	// a kind at a height already signed.
+	errDoubleSign = errors.New("refusing to sign a second message at a signed height")
+
+	// errStaleHeight is returned when signing a message below the last height
+	// signed for its kind.
+	errStaleHeight = errors.New("refusing to sign below the last signed height")
+)
+
+// signed is the last message of a kind released by the signer.
+type signed struct {
+	Number uint64     `json:"number"`
+	Hash   types.Hash `json:"hash"`
+}
+
+// auditEntry is a line of the audit log, recording a signing request and
+// whether it was served.
+type auditEntry struct {
+	Time   time.Time  `json:"time"`
+	Kind   string     `json:"kind"`
+	Number uint64     `json:"number"`
+	Hash   types.Hash `json:"hash"`
+	Signed bool       `json:"signed"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// Protection enforces the slashing protection rules of the signer: for every
+// kind of message it never signs two different messages at one height, nor a
+// message below the last signed height. Signing the very same message again
+// is allowed, so a producer may retry a request it lost the answer of.
+type Protection struct {
+	datadir string            // Directory of the protection state, empty if kept in memory
+	last    map[string]signed // Last signed message of every kind
+	audit   *os.File          // Audit log, nil if kept in memory
+	lock    sync.Mutex
+}
+
+// NewProtection loads the slashing protection state kept in datadir, opening
+// its audit log. An empty datadir keeps the state in memory only, which is
+// meant for tests.
+func NewProtection(datadir string) (*Protection, error) {
+	p := &Protection{datadir: datadir, last: make(map[string]signed)}
+	if datadir == "" {
+		return p, nil
+	}
+	if err := os.MkdirAll(datadir, 0700); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(datadir, protectionFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &p.last); err != nil {
+			return nil, fmt.Errorf("corrupt protection state: %v", err)
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+	if p.audit, err = os.OpenFile(filepath.Join(datadir, auditFile), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Close closes the audit log.
+func (p *Protection) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.audit == nil {
+		return nil
+	}
+	err := p.audit.Close()
+	p.audit = nil
+	return err
+}
+
+// Allow checks a message of the given kind against the protection rules. An
+// allowed message is recorded as signed, and persisted before returning, so it
+// is safe to release its signature. Every request is written to the audit log.
+func (p *Protection) Allow(kind string, number uint64, hash types.Hash) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	err := p.check(kind, number, hash)
+	if err == nil {
+		p.last[kind] = signed{Number: number, Hash: hash}
+		if err = p.save(); err != nil {
+			err = fmt.Errorf("failed to persist protection state: %v", err)
+		}
+	}
+	p.log(kind, number, hash, err)
+	return err
+}
+
+// check applies the protection rules to a message. It assumes that the lock is
+// held.
+func (p *Protection) check(kind string, number uint64, hash types.Hash) error {
+	last, ok := p.last[kind]
+	if !ok {
+		return nil
+	}
+	switch {
+	case number < last.Number:
+		return errStaleHeight
+	case number == last.Number && hash != last.Hash:
+		return errDoubleSign
+	}
+	return nil
+}
+
+// save writes the protection state to disk, replacing the previous one
+// atomically. It assumes that the lock is held.
+func (p *Protection) save() error {
+	if p.datadir == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(p.last, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(p.datadir, protectionFile)
+	f, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// log appends a signing request to the audit log. It assumes that the lock is
+// held.
+func (p *Protection) log(kind string, number uint64, hash types.Hash, err error) {
+	if err != nil {
+		logger.Warn("Refused to sign", "kind", kind, "number", number, "hash", hash, "err", err)
+	}
+	if p.audit == nil {
+		return
+	}
+	entry := auditEntry{Time: time.Now().UTC(), Kind: kind, Number: number, Hash: hash, Signed: err == nil}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	data, _ := json.Marshal(entry)
+	if _, err := p.audit.Write(append(data, '\n')); err != nil {
+		logger.Error("Failed to write audit log", "err", err)
+	}
+}
@@ -0,0 +1,162 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: remotesigner_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package remotesigner
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/utils/crypto"
+)
+
+func TestProtection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "remotesigner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p, err := NewProtection(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := types.Hash{1}, types.Hash{2}
+	tests := []struct {
+		kind    string
+		number  uint64
+		hash    types.Hash
+		allowed bool
+	}{
+		{KindHeader, 10, a, true},
+		{KindHeader, 10, a, true},  // Signing the same header again
+		{KindHeader, 10, b, false}, // A second header at the height
+		{KindHeader, 9, b, false},  // Below the last signed height
+		{KindPrevote, 10, b, true}, // Other kinds are guarded apart
+		{KindPrecommit, 10, b, true},
+		{KindPrecommit, 10, a, false},
+		{KindHeader, 11, b, true},
+	}
+	for i, tt := range tests {
+		if err := p.Allow(tt.kind, tt.number, tt.hash); (err == nil) != tt.allowed {
+			t.Errorf("test %d: allowed mismatch: have %v, want %v", i, err, tt.allowed)
+		}
+	}
+	p.Close()
+
+	// The state must survive a restart
+	if p, err = NewProtection(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := p.Allow(KindHeader, 11, a); err != errDoubleSign {
+		t.Errorf("double sign after restart: have %v, want %v", err, errDoubleSign)
+	}
+	// Every request must have been audited
+	f, err := os.Open(filepath.Join(dir, auditFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []auditEntry
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var entry auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid audit entry %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != len(tests)+1 {
+		t.Fatalf("audit entries mismatch: have %d, want %d", len(entries), len(tests)+1)
+	}
+	for i, tt := range tests {
+		if entries[i].Kind != tt.kind || entries[i].Number != tt.number || entries[i].Signed != tt.allowed {
+			t.Errorf("audit entry %d mismatch: have %+v", i, entries[i])
+		}
+	}
+}
+
+func TestStubSigner(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	client, err := NewStub(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if have, want := client.Address(), crypto.PubkeyToAddress(key.PublicKey); have != want {
+		t.Fatalf("address mismatch: have %x, want %x", have, want)
+	}
+	signer := block.NewBlockSigner(big.NewInt(101))
+	header := func(time int64) *block.Header {
+		return &block.Header{Number: types.NewBigInt(*big.NewInt(10)), Time: types.NewBigInt(*big.NewInt(time))}
+	}
+	first := header(1000)
+	if err := client.SignHeader(signer, first); err != nil {
+		t.Fatalf("failed to sign header: %v", err)
+	}
+	if producer, err := signer.Sender(first); err != nil || producer != client.Address() {
+		t.Errorf("producer mismatch: have %x, %v", producer, err)
+	}
+	if err := client.SignHeader(signer, header(1000)); err != nil {
+		t.Errorf("failed to sign the header again: %v", err)
+	}
+	if err := client.SignHeader(signer, header(1001)); err == nil {
+		t.Errorf("signed a second header at the height")
+	}
+
+	vote := &block.Vote{Type: block.Prevote, Number: 10, Hash: first.Hash()}
+	if err := client.SignVote(vote); err != nil {
+		t.Fatalf("failed to sign vote: %v", err)
+	}
+	if voter, err := vote.Voter(); err != nil || voter != client.Address() {
+		t.Errorf("voter mismatch: have %x, %v", voter, err)
+	}
+	if err := client.SignVote(&block.Vote{Type: block.Prevote, Number: 10, Hash: types.Hash{1}}); err == nil {
+		t.Errorf("signed a second prevote at the height")
+	}
+}
+
+func TestListenLoopback(t *testing.T) {
+	for _, endpoint := range []string{"http://0.0.0.0:0", "http://:0", "http://192.0.2.1:0", "http://example.com:0"} {
+		if listener, err := Listen(endpoint); err != errPublicEndpoint {
+			if listener != nil {
+				listener.Close()
+			}
+			t.Errorf("%s: error mismatch: have %v, want %v", endpoint, err, errPublicEndpoint)
+		}
+	}
+	for _, endpoint := range []string{"http://127.0.0.1:0", "http://localhost:0"} {
+		listener, err := Listen(endpoint)
+		if err != nil {
+			t.Errorf("%s: failed to listen: %v", endpoint, err)
+			continue
+		}
+		listener.Close()
+	}
+}
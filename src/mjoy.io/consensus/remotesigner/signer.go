@@ -0,0 +1,157 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: signer.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+// Package remotesigner implements a signer process holding the key of a block
+// producer, and the client the producer signs its headers and finality votes
+// through. The signer applies slashing protection, refusing to sign two
+// messages of a kind at one height, and keeps an audit log of the requests.
+package remotesigner
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	"mjoy.io/common/types"
+	"mjoy.io/common/types/util/hex"
+	"mjoy.io/communication/rpc"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/utils/crypto"
+)
+
+// Namespace is the RPC namespace the signer is served in.
+const Namespace = "signer"
+
+var (
+	// errUnsupportedScheme is returned when asked to sign a header with another
+	// signature scheme than the one of the signer key.
+	errUnsupportedScheme = errors.New("unsupported header signature scheme")
+
+	// errUnknownVoteType is returned when asked to sign a vote of an unknown type.
+	errUnknownVoteType = errors.New("unknown vote type")
+
+	// errPublicEndpoint is returned when an http:// endpoint would be reachable
+	// from other hosts, the requests being unauthenticated.
+	errPublicEndpoint = errors.New("http signer endpoint must listen on a loopback address")
+)
+
+// Signer holds the key of a block producer, signing the headers and finality
+// votes allowed by its slashing protection. Its exported methods are served
+// over RPC in the signer namespace.
+type Signer struct {
+	prv        *ecdsa.PrivateKey
+	address    types.Address
+	protection *Protection
+}
+
+// NewSigner creates a signer for the given key, guarded by the protection.
+func NewSigner(prv *ecdsa.PrivateKey, protection *Protection) *Signer {
+	return &Signer{
+		prv:        prv,
+		address:    crypto.PubkeyToAddress(prv.PublicKey),
+		protection: protection,
+	}
+}
+
+// Address returns the address of the producer key.
+func (s *Signer) Address() types.Address {
+	return s.address
+}
+
+// SignHeader signs the hash of a msgp encoded header, returning the 65 byte
+// [R || S || V] signature the producer derives the signature values from.
+func (s *Signer) SignHeader(data hex.Bytes) (hex.Bytes, error) {
+	header := new(block.Header)
+	if _, err := header.UnmarshalMsg(data); err != nil {
+		return nil, fmt.Errorf("invalid header: %v", err)
+	}
+	if header.SigScheme != crypto.SigSecp256k1 {
+		return nil, errUnsupportedScheme
+	}
+	hash := header.HashNoSig()
+	if err := s.protection.Allow(KindHeader, header.Number.IntVal.Uint64(), hash); err != nil {
+		return nil, err
+	}
+	return crypto.Sign(hash[:], s.prv)
+}
+
+// SignVote signs a msgp encoded finality vote, returning its signature.
+func (s *Signer) SignVote(data hex.Bytes) (hex.Bytes, error) {
+	vote := new(block.Vote)
+	if _, err := vote.UnmarshalMsg(data); err != nil {
+		return nil, fmt.Errorf("invalid vote: %v", err)
+	}
+	var kind string
+	switch vote.Type {
+	case block.Prevote:
+		kind = KindPrevote
+	case block.Precommit:
+		kind = KindPrecommit
+	default:
+		return nil, errUnknownVoteType
+	}
+	if err := s.protection.Allow(kind, vote.Number, vote.Hash); err != nil {
+		return nil, err
+	}
+	hash := vote.SigHash()
+	return crypto.Sign(hash[:], s.prv)
+}
+
+// NewServer creates an RPC server serving the signer.
+func NewServer(signer *Signer) (*rpc.Server, error) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(Namespace, signer); err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
+
+// Listen opens the listener of a signer endpoint, which is either an http://
+// URL or the path of an IPC socket. The requests are not authenticated, so an
+// http:// endpoint must be on a loopback address.
+func Listen(endpoint string) (net.Listener, error) {
+	if u, err := url.Parse(endpoint); err == nil && u.Scheme == "http" {
+		if !isLoopback(u.Hostname()) {
+			return nil, errPublicEndpoint
+		}
+		return net.Listen("tcp", u.Host)
+	}
+	return rpc.CreateIPCListener(endpoint)
+}
+
+// isLoopback reports whether host only names the loopback interface.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Serve serves the signer on a listener opened by Listen for the endpoint,
+// until the listener is closed.
+func Serve(endpoint string, listener net.Listener, srv *rpc.Server) error {
+	if u, err := url.Parse(endpoint); err == nil && u.Scheme == "http" {
+		return rpc.NewHTTPServer(nil, srv).Serve(listener)
+	}
+	return srv.ServeListener(listener)
+}
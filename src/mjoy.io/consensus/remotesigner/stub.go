@@ -0,0 +1,42 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: stub.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package remotesigner
+
+import (
+	"crypto/ecdsa"
+
+	"mjoy.io/communication/rpc"
+)
+
+// NewStub starts an in-process signer for the given key, keeping its slashing
+// protection state in memory, and returns a client connected to it. It speaks
+// the same protocol as a remote signer and is meant for tests.
+func NewStub(prv *ecdsa.PrivateKey) (*Client, error) {
+	protection, err := NewProtection("")
+	if err != nil {
+		return nil, err
+	}
+	srv, err := NewServer(NewSigner(prv, protection))
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(rpc.DialInProc(srv))
+}
@@ -0,0 +1,69 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: signer.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package consensus
+
+import (
+	"crypto/ecdsa"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/utils/crypto"
+)
+
+// ProducerSigner signs the headers and the finality votes of the local
+// producer. The key may be held in process memory, or by an external signer
+// applying its own protection rules.
+type ProducerSigner interface {
+	// Address returns the Mjoy address of the producer.
+	Address() types.Address
+
+	// SignHeader signs the header, filling in its signature values.
+	SignHeader(signer block.Signer, header *block.Header) error
+
+	// SignVote signs the finality vote, filling in its signature.
+	SignVote(vote *block.Vote) error
+}
+
+// KeySigner is a producer signer holding the private key in process memory.
+type KeySigner struct {
+	prv     *ecdsa.PrivateKey
+	address types.Address
+}
+
+// NewKeySigner creates a producer signer signing with the given key.
+func NewKeySigner(prv *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{prv: prv, address: crypto.PubkeyToAddress(prv.PublicKey)}
+}
+
+// Address implements ProducerSigner, returning the address of the key.
+func (s *KeySigner) Address() types.Address {
+	return s.address
+}
+
+// SignHeader implements ProducerSigner.
+func (s *KeySigner) SignHeader(signer block.Signer, header *block.Header) error {
+	return block.SignHeaderInner(header, signer, s.prv)
+}
+
+// SignVote implements ProducerSigner.
+func (s *KeySigner) SignVote(vote *block.Vote) error {
+	return vote.Sign(s.prv)
+}
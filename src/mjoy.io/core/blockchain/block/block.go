@@ -74,6 +74,9 @@ type Header struct {
 	//BlockProducer is not used in protocol.
 	BlockProducer   	types.Address         `json:"blockProducer" msg:"-"`
 	ConsensusData     	ConsensusData         `json:"consensusData" `
+	//Signature scheme, and the public key for schemes without key recovery
+	SigScheme         	uint8                 `json:"sigScheme"`
+	PubKey            	[]byte                `json:"pubKey"`
 	//Signature values
 	V                 	*types.BigInt         `json:"v"`
 	R                 	*types.BigInt         `json:"r"`
@@ -167,6 +170,11 @@ func CopyHeader(h *Header) *Header {
 		cpy.S.Put(h.S.IntVal)
 	}
 
+	if len(h.PubKey) > 0 {
+		cpy.PubKey = make([]byte, len(h.PubKey))
+		copy(cpy.PubKey, h.PubKey)
+	}
+
 	return &cpy
 }
 
@@ -185,6 +193,8 @@ func (header *Header) HashNoSig() types.Hash {
 		header.Time,
 		header.BlockProducer,
 		header.ConsensusData,
+		header.SigScheme,
+		header.PubKey,
 	}
 	return v.Hash()
 }
@@ -358,6 +368,8 @@ type HeaderNoSig struct {
 	//BlockProducer is not used in protocol.
 	BlockProducer   	types.Address         `json:"blockProducer" msg:"-" `
 	ConsensusData     	ConsensusData         `json:"consensusData" `
+	SigScheme         	uint8                 `json:"sigScheme"`
+	PubKey            	[]byte                `json:"pubKey"`
 }
 func (h *HeaderNoSig) Hash() types.Hash {
 	hash, err := common.MsgpHash(h)
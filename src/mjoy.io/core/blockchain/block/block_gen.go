@@ -794,6 +794,16 @@ func (z *Header) DecodeMsg(dc *msgp.Reader) (err error) {
 					}
 				}
 			}
+		case "SigScheme":
+			z.SigScheme, err = dc.ReadUint8()
+			if err != nil {
+				return
+			}
+		case "PubKey":
+			z.PubKey, err = dc.ReadBytes(z.PubKey)
+			if err != nil {
+				return
+			}
 		case "V":
 			if dc.IsNil() {
 				err = dc.ReadNil()
@@ -854,9 +864,9 @@ func (z *Header) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *Header) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 11
+	// map header, size 13
 	// write "ParentHash"
-	err = en.Append(0x8b, 0xaa, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68)
+	err = en.Append(0x8d, 0xaa, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68)
 	if err != nil {
 		return
 	}
@@ -952,6 +962,24 @@ func (z *Header) EncodeMsg(en *msgp.Writer) (err error) {
 	if err != nil {
 		return
 	}
+	// write "SigScheme"
+	err = en.Append(0xa9, 0x53, 0x69, 0x67, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint8(z.SigScheme)
+	if err != nil {
+		return
+	}
+	// write "PubKey"
+	err = en.Append(0xa6, 0x50, 0x75, 0x62, 0x4b, 0x65, 0x79)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.PubKey)
+	if err != nil {
+		return
+	}
 	// write "V"
 	err = en.Append(0xa1, 0x56)
 	if err != nil {
@@ -1006,9 +1034,9 @@ func (z *Header) EncodeMsg(en *msgp.Writer) (err error) {
 // MarshalMsg implements msgp.Marshaler
 func (z *Header) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 11
+	// map header, size 13
 	// string "ParentHash"
-	o = append(o, 0x8b, 0xaa, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68)
+	o = append(o, 0x8d, 0xaa, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68)
 	o, err = z.ParentHash.MarshalMsg(o)
 	if err != nil {
 		return
@@ -1065,6 +1093,12 @@ func (z *Header) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "Para"
 	o = append(o, 0xa4, 0x50, 0x61, 0x72, 0x61)
 	o = msgp.AppendBytes(o, z.ConsensusData.Para)
+	// string "SigScheme"
+	o = append(o, 0xa9, 0x53, 0x69, 0x67, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65)
+	o = msgp.AppendUint8(o, z.SigScheme)
+	// string "PubKey"
+	o = append(o, 0xa6, 0x50, 0x75, 0x62, 0x4b, 0x65, 0x79)
+	o = msgp.AppendBytes(o, z.PubKey)
 	// string "V"
 	o = append(o, 0xa1, 0x56)
 	if z.V == nil {
@@ -1201,6 +1235,16 @@ func (z *Header) UnmarshalMsg(bts []byte) (o []byte, err error) {
 					}
 				}
 			}
+		case "SigScheme":
+			z.SigScheme, bts, err = msgp.ReadUint8Bytes(bts)
+			if err != nil {
+				return
+			}
+		case "PubKey":
+			z.PubKey, bts, err = msgp.ReadBytesBytes(bts, z.PubKey)
+			if err != nil {
+				return
+			}
 		case "V":
 			if msgp.IsNil(bts) {
 				bts, err = msgp.ReadNilBytes(bts)
@@ -1274,7 +1318,7 @@ func (z *Header) Msgsize() (s int) {
 	} else {
 		s += z.Time.Msgsize()
 	}
-	s += 14 + 1 + 3 + msgp.StringPrefixSize + len(z.ConsensusData.Id) + 5 + msgp.BytesPrefixSize + len(z.ConsensusData.Para) + 2
+	s += 14 + 1 + 3 + msgp.StringPrefixSize + len(z.ConsensusData.Id) + 5 + msgp.BytesPrefixSize + len(z.ConsensusData.Para) + 10 + msgp.Uint8Size + 7 + msgp.BytesPrefixSize + len(z.PubKey) + 2
 	if z.V == nil {
 		s += msgp.NilSize
 	} else {
@@ -1398,6 +1442,16 @@ func (z *HeaderNoSig) DecodeMsg(dc *msgp.Reader) (err error) {
 					}
 				}
 			}
+		case "SigScheme":
+			z.SigScheme, err = dc.ReadUint8()
+			if err != nil {
+				return
+			}
+		case "PubKey":
+			z.PubKey, err = dc.ReadBytes(z.PubKey)
+			if err != nil {
+				return
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -1410,9 +1464,9 @@ func (z *HeaderNoSig) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *HeaderNoSig) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 8
+	// map header, size 10
 	// write "ParentHash"
-	err = en.Append(0x88, 0xaa, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68)
+	err = en.Append(0x8a, 0xaa, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68)
 	if err != nil {
 		return
 	}
@@ -1508,15 +1562,33 @@ func (z *HeaderNoSig) EncodeMsg(en *msgp.Writer) (err error) {
 	if err != nil {
 		return
 	}
+	// write "SigScheme"
+	err = en.Append(0xa9, 0x53, 0x69, 0x67, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint8(z.SigScheme)
+	if err != nil {
+		return
+	}
+	// write "PubKey"
+	err = en.Append(0xa6, 0x50, 0x75, 0x62, 0x4b, 0x65, 0x79)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.PubKey)
+	if err != nil {
+		return
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *HeaderNoSig) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 8
+	// map header, size 10
 	// string "ParentHash"
-	o = append(o, 0x88, 0xaa, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68)
+	o = append(o, 0x8a, 0xaa, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68)
 	o, err = z.ParentHash.MarshalMsg(o)
 	if err != nil {
 		return
@@ -1573,6 +1645,12 @@ func (z *HeaderNoSig) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "Para"
 	o = append(o, 0xa4, 0x50, 0x61, 0x72, 0x61)
 	o = msgp.AppendBytes(o, z.ConsensusData.Para)
+	// string "SigScheme"
+	o = append(o, 0xa9, 0x53, 0x69, 0x67, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65)
+	o = msgp.AppendUint8(o, z.SigScheme)
+	// string "PubKey"
+	o = append(o, 0xa6, 0x50, 0x75, 0x62, 0x4b, 0x65, 0x79)
+	o = msgp.AppendBytes(o, z.PubKey)
 	return
 }
 
@@ -1679,6 +1757,16 @@ func (z *HeaderNoSig) UnmarshalMsg(bts []byte) (o []byte, err error) {
 					}
 				}
 			}
+		case "SigScheme":
+			z.SigScheme, bts, err = msgp.ReadUint8Bytes(bts)
+			if err != nil {
+				return
+			}
+		case "PubKey":
+			z.PubKey, bts, err = msgp.ReadBytesBytes(bts, z.PubKey)
+			if err != nil {
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -1704,7 +1792,7 @@ func (z *HeaderNoSig) Msgsize() (s int) {
 	} else {
 		s += z.Time.Msgsize()
 	}
-	s += 14 + 1 + 3 + msgp.StringPrefixSize + len(z.ConsensusData.Id) + 5 + msgp.BytesPrefixSize + len(z.ConsensusData.Para)
+	s += 14 + 1 + 3 + msgp.StringPrefixSize + len(z.ConsensusData.Id) + 5 + msgp.BytesPrefixSize + len(z.ConsensusData.Para) + 10 + msgp.Uint8Size + 7 + msgp.BytesPrefixSize + len(z.PubKey)
 	return
 }
 
@@ -28,6 +28,7 @@ import (
 	"fmt"
 	"mjoy.io/common"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 )
 
 var (
@@ -55,6 +56,16 @@ func SignHeaderInner(h *Header, s Signer, prv *ecdsa.PrivateKey) (error) {
 	return h.AddSignature(s, sig)
 }
 
+// SignHeaderEd25519 signs the header using the given signer and Ed25519 private
+// key. The public key is carried in the header.
+func SignHeaderEd25519(h *Header, s Signer, prv ed25519.PrivateKey) (*Header, error) {
+	cpy := CopyHeader(h)
+	cpy.SigScheme = crypto.SigEd25519
+	cpy.PubKey = append([]byte(nil), prv.Public().(ed25519.PublicKey)...)
+	hash := s.Hash(cpy)
+	return cpy.WithSignature(s, ed25519.Sign(prv, hash[:]))
+}
+
 // Signer encapsulates transaction signature handling. Note that this interface is not a
 // stable API and may change at any time to accommodate new protocol rules.
 type Signer interface {
@@ -109,7 +120,15 @@ func (s BlockSigner) Sender(h *Header) (types.Address, error) {
 	} else{
 		V = V.Sub(&h.V.IntVal, common.Big27)
 	}
-	address, err :=  recoverPlain(h.HashNoSig(), &h.R.IntVal, &h.S.IntVal, V, true)
+	var (
+		address types.Address
+		err     error
+	)
+	if h.SigScheme != crypto.SigSecp256k1 {
+		address, err = crypto.RecoverScheme(h.SigScheme, h.HashNoSig().Bytes(), &h.R.IntVal, &h.S.IntVal, V, h.PubKey)
+	} else {
+		address, err = recoverPlain(h.HashNoSig(), &h.R.IntVal, &h.S.IntVal, V, true)
+	}
 	h.BlockProducer = address
 	return address, err
 }
@@ -120,6 +139,18 @@ func (s BlockSigner) VerifySignature(h *Header) (bool,error) {
 		return false, ErrInvalidChainId
 	}
 
+	//schemes without key recovery are checked against the carried public key
+	if h.SigScheme != crypto.SigSecp256k1 {
+		V := new(big.Int).SetUint64(h.V.IntVal.Uint64() - s.chainIdMul.Uint64() - 35)
+		if s.chainId.Sign() == 0 {
+			V.SetUint64(h.V.IntVal.Uint64() - 27)
+		}
+		if _, err := crypto.RecoverScheme(h.SigScheme, s.Hash(h).Bytes(), &h.R.IntVal, &h.S.IntVal, V, h.PubKey); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
 	//R S V check
 	var V uint64
 	if s.chainId.Sign() != 0 {
@@ -157,6 +188,17 @@ func (s BlockSigner) VerifySignature(h *Header) (bool,error) {
 // SignatureValues returns a header's R S V based given signature. This signature
 // needs to be in the [R || S || V] format where V is 0 or 1.
 func (s BlockSigner) SignatureValues(h *Header, sig []byte) (R, S, V *big.Int, err error) {
+	//schemes without a recovery id sign [R || S] only
+	if h.SigScheme != crypto.SigSecp256k1 {
+		scheme, err := crypto.GetSigScheme(h.SigScheme)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(sig) != scheme.SigLength() || len(sig) != 64 {
+			return nil, nil, nil, fmt.Errorf("wrong size for %s signature: got %d, want %d", scheme.Name(), len(sig), scheme.SigLength())
+		}
+		sig = append(sig[:64:64], 0)
+	}
 	if len(sig) != 65 {
 		errStr:=fmt.Sprintf("wrong size for signature: got %d, want 65", len(sig))
 		err = errors.New(errStr)
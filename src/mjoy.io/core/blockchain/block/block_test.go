@@ -1,6 +1,8 @@
 package block
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"testing"
 	"mjoy.io/common/types"
 	"math/big"
@@ -65,4 +67,67 @@ func TestHeaderSigantureFixkey(t *testing.T) {
 	if !bytes.Equal(getaddress.Bytes(),address.Bytes())  {
 		t.Fatalf("address is not same got:%v, want:%v",getaddress.Hex(), address.Hex())
 	}
-}
\ No newline at end of file
+}
+func TestHeaderSignatureEd25519(t *testing.T) {
+	header := &Header{Number:types.NewBigInt(*big.NewInt(335)), Time:types.NewBigInt(*big.NewInt(1212121))}
+	singner := NewBlockSigner(big.NewInt(101))
+
+	key, err := crypto.GenerateEd25519Key()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key fail %v", err)
+	}
+	signHeader, err := SignHeaderEd25519(header, singner, key)
+	if err != nil {
+		t.Fatalf("SignHeaderEd25519 fail %v", err)
+	}
+	if ok, err := singner.VerifySignature(signHeader); !ok || err != nil {
+		t.Fatalf("VerifySignature fail %v", err)
+	}
+	getaddress, err := singner.Sender(CopyHeader(signHeader))
+	if err != nil {
+		t.Fatalf("cann't get senser form header %v", err)
+	}
+	address := crypto.Ed25519PubkeyToAddress(key.Public().(ed25519.PublicKey))
+	if getaddress != address {
+		t.Fatalf("address is not same got:%v, want:%v", getaddress.Hex(), address.Hex())
+	}
+
+	signHeader.Number = types.NewBigInt(*big.NewInt(336))
+	if ok, _ := singner.VerifySignature(signHeader); ok {
+		t.Fatalf("modified header passed verification")
+	}
+}
+
+func TestEvidenceProducer(t *testing.T) {
+	singner := NewBlockSigner(big.NewInt(101))
+	key , _ := crypto.GenerateKey()
+	other , _ := crypto.GenerateKey()
+
+	sign := func(number int64, time int64, key *ecdsa.PrivateKey) *Header {
+		header, err := SignHeader(&Header{Number:types.NewBigInt(*big.NewInt(number)), Time:types.NewBigInt(*big.NewInt(time))}, singner, key)
+		if err != nil {
+			t.Fatalf("SignHeader fail %v", err)
+		}
+		return header
+	}
+	first := sign(10, 1000, key)
+
+	tests := []struct {
+		second *Header
+		valid  bool
+	}{
+		{sign(10, 1001, key), true},    // Two blocks at the same height
+		{sign(10, 1000, key), false},   // The same block signed twice
+		{sign(11, 1001, key), false},   // Blocks at different heights
+		{sign(10, 1001, other), false}, // Blocks of different producers
+	}
+	for i, tt := range tests {
+		producer, err := (&Evidence{First:first, Second:tt.second}).Producer(singner)
+		if tt.valid && (err != nil || producer != crypto.PubkeyToAddress(key.PublicKey)) {
+			t.Errorf("test %d: valid evidence rejected: %x, %v", i, producer, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("test %d: invalid evidence accepted", i)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: certificate.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package block
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/tinylib/msgp/msgp"
+	"mjoy.io/common/types"
+	"mjoy.io/utils/crypto"
+)
+
+// Types of the votes exchanged by the producers to finalize blocks.
+const (
+	Prevote   uint8 = iota // First round vote, for a block seen as the head
+	Precommit              // Second round vote, for a block prevoted by a quorum
+)
+
+var ErrInvalidVoteSig = errors.New("invalid vote signature")
+
+//go:generate msgp
+
+// Vote is a signed prevote or precommit of a producer for a block.
+type Vote struct {
+	Type      uint8
+	Number    uint64
+	Hash      types.Hash
+	Signature []byte
+}
+
+// SigHash returns the hash signed by the voter.
+func (v *Vote) SigHash() types.Hash {
+	b := msgp.AppendUint8(nil, v.Type)
+	b = msgp.AppendUint64(b, v.Number)
+	b = msgp.AppendBytes(b, v.Hash[:])
+	return crypto.Keccak256Hash(b)
+}
+
+// Sign fills the signature of the vote with the given key.
+func (v *Vote) Sign(prv *ecdsa.PrivateKey) error {
+	hash := v.SigHash()
+	sig, err := crypto.Sign(hash[:], prv)
+	if err != nil {
+		return err
+	}
+	v.Signature = sig
+	return nil
+}
+
+// Voter recovers the address of the producer which signed the vote.
+func (v *Vote) Voter() (types.Address, error) {
+	if len(v.Signature) != 65 {
+		return types.Address{}, ErrInvalidVoteSig
+	}
+	hash := v.SigHash()
+	pub, err := crypto.SigToPub(hash[:], v.Signature)
+	if err != nil {
+		return types.Address{}, ErrInvalidVoteSig
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Certificate proves a block final, holding the precommits of more than two
+// thirds of its producers.
+type Certificate struct {
+	Number     uint64
+	Hash       types.Hash
+	Precommits []*Vote
+}
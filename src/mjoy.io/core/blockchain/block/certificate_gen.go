@@ -0,0 +1,389 @@
+package block
+
+// NOTE: THIS FILE WAS PRODUCED BY THE
+// MSGP CODE GENERATION TOOL (github.com/tinylib/msgp)
+// DO NOT EDIT
+
+import (
+	"github.com/tinylib/msgp/msgp"
+)
+
+// DecodeMsg implements msgp.Decodable
+func (z *Certificate) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Number":
+			z.Number, err = dc.ReadUint64()
+			if err != nil {
+				return
+			}
+		case "Hash":
+			err = z.Hash.DecodeMsg(dc)
+			if err != nil {
+				return
+			}
+		case "Precommits":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				return
+			}
+			if cap(z.Precommits) >= int(zb0002) {
+				z.Precommits = (z.Precommits)[:zb0002]
+			} else {
+				z.Precommits = make([]*Vote, zb0002)
+			}
+			for za0001 := range z.Precommits {
+				if dc.IsNil() {
+					err = dc.ReadNil()
+					if err != nil {
+						return
+					}
+					z.Precommits[za0001] = nil
+				} else {
+					if z.Precommits[za0001] == nil {
+						z.Precommits[za0001] = new(Vote)
+					}
+					err = z.Precommits[za0001].DecodeMsg(dc)
+					if err != nil {
+						return
+					}
+				}
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *Certificate) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "Number"
+	err = en.Append(0x83, 0xa6, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Number)
+	if err != nil {
+		return
+	}
+	// write "Hash"
+	err = en.Append(0xa4, 0x48, 0x61, 0x73, 0x68)
+	if err != nil {
+		return
+	}
+	err = z.Hash.EncodeMsg(en)
+	if err != nil {
+		return
+	}
+	// write "Precommits"
+	err = en.Append(0xaa, 0x50, 0x72, 0x65, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Precommits)))
+	if err != nil {
+		return
+	}
+	for za0001 := range z.Precommits {
+		if z.Precommits[za0001] == nil {
+			err = en.WriteNil()
+			if err != nil {
+				return
+			}
+		} else {
+			err = z.Precommits[za0001].EncodeMsg(en)
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *Certificate) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "Number"
+	o = append(o, 0x83, 0xa6, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72)
+	o = msgp.AppendUint64(o, z.Number)
+	// string "Hash"
+	o = append(o, 0xa4, 0x48, 0x61, 0x73, 0x68)
+	o, err = z.Hash.MarshalMsg(o)
+	if err != nil {
+		return
+	}
+	// string "Precommits"
+	o = append(o, 0xaa, 0x50, 0x72, 0x65, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Precommits)))
+	for za0001 := range z.Precommits {
+		if z.Precommits[za0001] == nil {
+			o = msgp.AppendNil(o)
+		} else {
+			o, err = z.Precommits[za0001].MarshalMsg(o)
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *Certificate) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Number":
+			z.Number, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				return
+			}
+		case "Hash":
+			bts, err = z.Hash.UnmarshalMsg(bts)
+			if err != nil {
+				return
+			}
+		case "Precommits":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				return
+			}
+			if cap(z.Precommits) >= int(zb0002) {
+				z.Precommits = (z.Precommits)[:zb0002]
+			} else {
+				z.Precommits = make([]*Vote, zb0002)
+			}
+			for za0001 := range z.Precommits {
+				if msgp.IsNil(bts) {
+					bts, err = msgp.ReadNilBytes(bts)
+					if err != nil {
+						return
+					}
+					z.Precommits[za0001] = nil
+				} else {
+					if z.Precommits[za0001] == nil {
+						z.Precommits[za0001] = new(Vote)
+					}
+					bts, err = z.Precommits[za0001].UnmarshalMsg(bts)
+					if err != nil {
+						return
+					}
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *Certificate) Msgsize() (s int) {
+	s = 1 + 7 + msgp.Uint64Size + 5 + z.Hash.Msgsize() + 11 + msgp.ArrayHeaderSize
+	for za0001 := range z.Precommits {
+		if z.Precommits[za0001] == nil {
+			s += msgp.NilSize
+		} else {
+			s += z.Precommits[za0001].Msgsize()
+		}
+	}
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *Vote) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Type":
+			z.Type, err = dc.ReadUint8()
+			if err != nil {
+				return
+			}
+		case "Number":
+			z.Number, err = dc.ReadUint64()
+			if err != nil {
+				return
+			}
+		case "Hash":
+			err = z.Hash.DecodeMsg(dc)
+			if err != nil {
+				return
+			}
+		case "Signature":
+			z.Signature, err = dc.ReadBytes(z.Signature)
+			if err != nil {
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *Vote) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 4
+	// write "Type"
+	err = en.Append(0x84, 0xa4, 0x54, 0x79, 0x70, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint8(z.Type)
+	if err != nil {
+		return
+	}
+	// write "Number"
+	err = en.Append(0xa6, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Number)
+	if err != nil {
+		return
+	}
+	// write "Hash"
+	err = en.Append(0xa4, 0x48, 0x61, 0x73, 0x68)
+	if err != nil {
+		return
+	}
+	err = z.Hash.EncodeMsg(en)
+	if err != nil {
+		return
+	}
+	// write "Signature"
+	err = en.Append(0xa9, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.Signature)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *Vote) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 4
+	// string "Type"
+	o = append(o, 0x84, 0xa4, 0x54, 0x79, 0x70, 0x65)
+	o = msgp.AppendUint8(o, z.Type)
+	// string "Number"
+	o = append(o, 0xa6, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72)
+	o = msgp.AppendUint64(o, z.Number)
+	// string "Hash"
+	o = append(o, 0xa4, 0x48, 0x61, 0x73, 0x68)
+	o, err = z.Hash.MarshalMsg(o)
+	if err != nil {
+		return
+	}
+	// string "Signature"
+	o = append(o, 0xa9, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65)
+	o = msgp.AppendBytes(o, z.Signature)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *Vote) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Type":
+			z.Type, bts, err = msgp.ReadUint8Bytes(bts)
+			if err != nil {
+				return
+			}
+		case "Number":
+			z.Number, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				return
+			}
+		case "Hash":
+			bts, err = z.Hash.UnmarshalMsg(bts)
+			if err != nil {
+				return
+			}
+		case "Signature":
+			z.Signature, bts, err = msgp.ReadBytesBytes(bts, z.Signature)
+			if err != nil {
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *Vote) Msgsize() (s int) {
+	s = 1 + 5 + msgp.Uint8Size + 7 + msgp.Uint64Size + 5 + z.Hash.Msgsize() + 10 + msgp.BytesPrefixSize + len(z.Signature)
+	return
+}
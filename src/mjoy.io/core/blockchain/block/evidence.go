@@ -0,0 +1,70 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: evidence.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package block
+
+import (
+	"errors"
+
+	"mjoy.io/common/types"
+)
+
+var ErrInvalidEvidence = errors.New("invalid equivocation evidence")
+
+//go:generate msgp
+
+// Evidence proves that a producer signed two different headers at the same
+// height.
+type Evidence struct {
+	First  *Header
+	Second *Header
+}
+
+// Number returns the height the producer equivocated at.
+func (e *Evidence) Number() uint64 {
+	return e.First.Number.IntVal.Uint64()
+}
+
+// Producer verifies the evidence, returning the producer which signed both
+// headers.
+func (e *Evidence) Producer(signer Signer) (types.Address, error) {
+	if e.First == nil || e.Second == nil || e.First.Number == nil || e.Second.Number == nil {
+		return types.Address{}, ErrInvalidEvidence
+	}
+	if e.First.Number.IntVal.Cmp(&e.Second.Number.IntVal) != 0 {
+		return types.Address{}, ErrInvalidEvidence
+	}
+	// Compare the signed content, a header signed twice is no equivocation
+	if signer.Hash(e.First) == signer.Hash(e.Second) {
+		return types.Address{}, ErrInvalidEvidence
+	}
+	first, err := signer.Sender(e.First)
+	if err != nil {
+		return types.Address{}, err
+	}
+	second, err := signer.Sender(e.Second)
+	if err != nil {
+		return types.Address{}, err
+	}
+	if first != second {
+		return types.Address{}, ErrInvalidEvidence
+	}
+	return first, nil
+}
@@ -0,0 +1,208 @@
+package block
+
+// NOTE: THIS FILE WAS PRODUCED BY THE
+// MSGP CODE GENERATION TOOL (github.com/tinylib/msgp)
+// DO NOT EDIT
+
+import (
+	"github.com/tinylib/msgp/msgp"
+)
+
+// DecodeMsg implements msgp.Decodable
+func (z *Evidence) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "First":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					return
+				}
+				z.First = nil
+			} else {
+				if z.First == nil {
+					z.First = new(Header)
+				}
+				err = z.First.DecodeMsg(dc)
+				if err != nil {
+					return
+				}
+			}
+		case "Second":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					return
+				}
+				z.Second = nil
+			} else {
+				if z.Second == nil {
+					z.Second = new(Header)
+				}
+				err = z.Second.DecodeMsg(dc)
+				if err != nil {
+					return
+				}
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *Evidence) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "First"
+	err = en.Append(0x82, 0xa5, 0x46, 0x69, 0x72, 0x73, 0x74)
+	if err != nil {
+		return
+	}
+	if z.First == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.First.EncodeMsg(en)
+		if err != nil {
+			return
+		}
+	}
+	// write "Second"
+	err = en.Append(0xa6, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64)
+	if err != nil {
+		return
+	}
+	if z.Second == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.Second.EncodeMsg(en)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *Evidence) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "First"
+	o = append(o, 0x82, 0xa5, 0x46, 0x69, 0x72, 0x73, 0x74)
+	if z.First == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.First.MarshalMsg(o)
+		if err != nil {
+			return
+		}
+	}
+	// string "Second"
+	o = append(o, 0xa6, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64)
+	if z.Second == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.Second.MarshalMsg(o)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *Evidence) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "First":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.First = nil
+			} else {
+				if z.First == nil {
+					z.First = new(Header)
+				}
+				bts, err = z.First.UnmarshalMsg(bts)
+				if err != nil {
+					return
+				}
+			}
+		case "Second":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.Second = nil
+			} else {
+				if z.Second == nil {
+					z.Second = new(Header)
+				}
+				bts, err = z.Second.UnmarshalMsg(bts)
+				if err != nil {
+					return
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *Evidence) Msgsize() (s int) {
+	s = 1 + 6
+	if z.First == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.First.Msgsize()
+	}
+	s += 7
+	if z.Second == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.Second.Msgsize()
+	}
+	return
+}
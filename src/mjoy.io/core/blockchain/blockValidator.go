@@ -68,6 +68,42 @@ func (v *BlockValidator) ValidateBody(blk *block.Block) error {
 	if hash := block.DeriveSha(blk.Transactions()); hash != header.TxRootHash {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash.String(), header.TxRootHash.String())
 	}
+	if err := v.validateLimits(blk); err != nil {
+		return err
+	}
+	// Every transaction must be inside its validity window at this block
+	number, time := header.Number.IntVal.Uint64(), header.Time.IntVal.Uint64()
+	for i, tx := range blk.Transactions() {
+		if err := tx.CheckValidity(number, time); err != nil {
+			return fmt.Errorf("transaction %d (%x) invalid at block %d: %v", i, tx.Hash(), number, err)
+		}
+	}
+	return nil
+}
+
+// validateLimits checks that the block keeps within the consensus limits on its
+// size, transaction count and execution budget.
+func (v *BlockValidator) validateLimits(blk *block.Block) error {
+	limits := v.config.Limits
+	if limits == nil {
+		return nil
+	}
+	txs := blk.Transactions()
+	if limits.MaxTxs > 0 && uint64(len(txs)) > limits.MaxTxs {
+		return fmt.Errorf("too many transactions: have %d, max %d", len(txs), limits.MaxTxs)
+	}
+	if size := uint64(blk.Size()); limits.MaxSize > 0 && size > limits.MaxSize {
+		return fmt.Errorf("block too large: have %d bytes, max %d", size, limits.MaxSize)
+	}
+	if limits.MaxActions > 0 {
+		actions := uint64(0)
+		for _, tx := range txs {
+			actions += uint64(len(tx.Data.Actions))
+		}
+		if actions > limits.MaxActions {
+			return fmt.Errorf("execution budget exceeded: have %d actions, max %d", actions, limits.MaxActions)
+		}
+	}
 	return nil
 }
 
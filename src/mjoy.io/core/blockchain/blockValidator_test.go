@@ -0,0 +1,72 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: blockValidator_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/transaction"
+	"mjoy.io/params"
+)
+
+// limitsBlock creates a block of the given transactions, each with the given
+// number of actions.
+func limitsBlock(txs, actions int) *block.Block {
+	var list transaction.Transactions
+	for i := 0; i < txs; i++ {
+		var acts transaction.ActionSlice
+		for j := 0; j < actions; j++ {
+			acts = append(acts, transaction.MakeAction(types.Address{byte(j)}, []byte{byte(i), byte(j)}))
+		}
+		list = append(list, transaction.NewTransaction(uint64(i), acts))
+	}
+	return block.NewBlock(&block.Header{Number: types.NewBigInt(*big.NewInt(1))}, list, nil)
+}
+
+// Tests that blocks are refused past any of the consensus limits on their
+// transaction count, size and actions, and that zero limits are unlimited.
+func TestBlockValidatorLimits(t *testing.T) {
+	size := uint64(limitsBlock(3, 2).Size())
+
+	tests := []struct {
+		limits *params.BlockLimits
+		txs    int
+		fail   bool
+	}{
+		{nil, 3, false},
+		{&params.BlockLimits{}, 3, false},
+		{&params.BlockLimits{MaxTxs: 3}, 3, false},
+		{&params.BlockLimits{MaxTxs: 3}, 4, true},
+		{&params.BlockLimits{MaxSize: size}, 3, false},
+		{&params.BlockLimits{MaxSize: size}, 4, true},
+		{&params.BlockLimits{MaxActions: 6}, 3, false},
+		{&params.BlockLimits{MaxActions: 6}, 4, true},
+	}
+	for i, tt := range tests {
+		validator := NewBlockValidator(&params.ChainConfig{ChainId: big.NewInt(1), Limits: tt.limits}, nil, nil)
+		if err := validator.validateLimits(limitsBlock(tt.txs, 2)); (err != nil) != tt.fail {
+			t.Errorf("test %d: limits error mismatch: have %v, want failure %v", i, err, tt.fail)
+		}
+	}
+}
@@ -37,7 +37,6 @@ import (
 	"io"
 	"mjoy.io/common/mclock"
 	"mjoy.io/common/types"
-	"github.com/tinylib/msgp/msgp"
 	"mjoy.io/core/blockchain/block"
 	"mjoy.io/core/transaction"
 	"mjoy.io/consensus"
@@ -51,6 +50,10 @@ var (
 	blockInsertTimer = metrics.NewRegisteredTimer("chain/inserts", nil)
 
 	ErrNoGenesis = errors.New("Genesis not found in chain")
+
+	// ErrNotCanonical is returned when a block not on the canonical chain is
+	// being finalized.
+	ErrNotCanonical = errors.New("finalized block not canonical")
 )
 
 const (
@@ -59,18 +62,33 @@ const (
 	maxFutureBlocks     = 256
 	maxTimeFutureBlocks = 30
 	badBlockLimit       = 10
+	signedHeaderLimit   = 4096
 	triesInMemory       = 128
+	pruneInterval       = 16384
+	freezeDepth         = 90000
 
-	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
+	// BlockChainVersion is the oldest database schema version migrated from, an
+	// older database is resynced from scratch. The migrations registered
+	// bring the schema to the latest SchemaVersion.
 	BlockChainVersion = 3
 )
 
-// CacheConfig contains the configuration values for the trie caching/pruning
-// that's resident in a blockchain.
+// CacheConfig contains the configuration values for the state pruning and the
+// block freezing that's resident in a blockchain. Zero values are replaced by
+// the defaults.
 type CacheConfig struct {
-	Disabled      bool          // Whether to disable trie write caching (archive node)
-	TrieNodeLimit int           // Memory limit (MB) at which to flush the current in-memory trie to disk
-	TrieTimeLimit time.Duration // Time limit after which to flush the current in-memory trie to disk
+	Disabled      bool   // Whether to disable state pruning and keep every state (archive node)
+	TriesKept     uint64 // Number of recent canonical states kept on disk
+	PruneInterval uint64 // Number of blocks written between two prunings, each stalling block import
+	FreezeDepth   uint64 // Number of recent blocks kept out of the ancient store
+}
+
+// DefaultCacheConfig keeps the last 128 states, pruning every 16384 blocks, and
+// moves the blocks 90000 deep to the ancient store.
+var DefaultCacheConfig = &CacheConfig{
+	TriesKept:     triesInMemory,
+	PruneInterval: pruneInterval,
+	FreezeDepth:   freezeDepth,
 }
 
 
@@ -115,7 +133,8 @@ type Processor interface {
 // included in the canonical one where as GetBlockByNumber always represents the
 // canonical chain.
 type BlockChain struct {
-	config *params.ChainConfig // chain & network configuration
+	config      *params.ChainConfig // chain & network configuration
+	cacheConfig *CacheConfig        // state pruning configuration
 
 	hc            *HeaderChain
 	chainDb       database.IDatabase
@@ -123,6 +142,7 @@ type BlockChain struct {
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
+	equivocationFeed event.Feed
 	logsFeed      event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *block.Block
@@ -134,6 +154,8 @@ type BlockChain struct {
 	checkpoint       int          // checkpoint counts towards the new checkpoint
 	currentBlock     *block.Block // Current head of the block chain
 	currentFastBlock *block.Block // Current head of the fast-sync chain (may be above the block chain!)
+	currentFinalized *block.Header // Last block proven final by a commit certificate (nil if none)
+	lastPruned       uint64        // Number of the head block when the state was last pruned
 
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
 	bodyCache    *lru.Cache     // Cache for the most recent block bodies
@@ -153,21 +175,43 @@ type BlockChain struct {
 	//vmConfig  vm.Config //todo for future vm
 
 	badBlocks *lru.Cache // Bad block cache
+
+	signedHeaders *lru.Cache // Hashes of the headers seen per producer and height, to catch equivocations
 }
 
 
 // NewBlockChain returns a fully initialised block chain using information
 // available in the database. It initialises the default mjoy Validator and
 // Processor.
-func NewBlockChain(chainDb database.IDatabase, config *params.ChainConfig, engine consensus.Engine) (*BlockChain, error) {
+func NewBlockChain(chainDb database.IDatabase, cacheConfig *CacheConfig, config *params.ChainConfig, engine consensus.Engine) (*BlockChain, error) {
+	if cacheConfig == nil {
+		cacheConfig = DefaultCacheConfig
+	}
+	cacheConfig = &CacheConfig{
+		Disabled:      cacheConfig.Disabled,
+		TriesKept:     cacheConfig.TriesKept,
+		PruneInterval: cacheConfig.PruneInterval,
+		FreezeDepth:   cacheConfig.FreezeDepth,
+	}
+	if cacheConfig.TriesKept == 0 {
+		cacheConfig.TriesKept = DefaultCacheConfig.TriesKept
+	}
+	if cacheConfig.PruneInterval == 0 {
+		cacheConfig.PruneInterval = DefaultCacheConfig.PruneInterval
+	}
+	if cacheConfig.FreezeDepth == 0 {
+		cacheConfig.FreezeDepth = DefaultCacheConfig.FreezeDepth
+	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyMsgpCache, _ := lru.New(bodyCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
 	futureBlocks, _ := lru.New(maxFutureBlocks)
 	badBlocks, _ := lru.New(badBlockLimit)
+	signedHeaders, _ := lru.New(signedHeaderLimit)
 
 	bc := &BlockChain{
 		config:       config,
+		cacheConfig:  cacheConfig,
 		chainDb:      chainDb,
 		stateCache:   state.NewDatabase(chainDb),
 		quit:         make(chan struct{}),
@@ -177,6 +221,7 @@ func NewBlockChain(chainDb database.IDatabase, config *params.ChainConfig, engin
 		futureBlocks: futureBlocks,
 		engine:       engine,
 		badBlocks:    badBlocks,
+		signedHeaders: signedHeaders,
 	}
 	bc.SetValidator(NewBlockValidator(config, bc, engine))
 	bc.SetProcessor(stateprocessor.NewStateProcessor(config,bc, engine))
@@ -193,6 +238,13 @@ func NewBlockChain(chainDb database.IDatabase, config *params.ChainConfig, engin
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	if err := bc.checkNodeMode(); err != nil {
+		return nil, err
+	}
+	if err := bc.repairAncients(); err != nil {
+		return nil, err
+	}
+	bc.lastPruned = bc.currentBlock.NumberU64()
 
 	// Take ownership of this particular state
 	go bc.update()
@@ -247,6 +299,11 @@ func (bc *BlockChain) loadLastState() error {
 		}
 	}
 
+	// Restore the last finalized block
+	if hash := GetFinalizedBlockHash(bc.chainDb); hash != (types.Hash{}) {
+		bc.currentFinalized = bc.GetHeaderByHash(hash)
+	}
+
 	// Issue a status log for the user
 
 	logger.Info("Loaded most recent local header", "number", currentHeader.Number.IntVal.String(), "hash", currentHeader.Hash().String())
@@ -273,6 +330,12 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.hc.SetHead(head, delFn)
 	currentHeader := bc.hc.CurrentHeader()
 
+	// Drop the rewound blocks from the ancient store too
+	if err := bc.repairAncients(); err != nil {
+		logger.Critical("Failed to truncate ancient store", "err", err)
+		return err
+	}
+
 	// Clear out any stale content from the caches
 	bc.bodyCache.Purge()
 	bc.bodyMsgpCache.Purge()
@@ -300,6 +363,13 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	if bc.currentFastBlock == nil {
 		bc.currentFastBlock = bc.genesisBlock
 	}
+	// Forget the finalized block if it was rewound
+	if bc.currentFinalized != nil && bc.currentFinalized.Number.IntVal.Uint64() > bc.currentBlock.NumberU64() {
+		bc.currentFinalized = nil
+		if err := WriteFinalizedBlockHash(bc.chainDb, types.Hash{}); err != nil {
+			logger.Critical("Failed to reset finalized block", "err", err)
+		}
+	}
 	if err := WriteHeadBlockHash(bc.chainDb, bc.currentBlock.Hash()); err != nil {
 		logger.Critical("Failed to reset head full block", "err", err)
 	}
@@ -355,6 +425,67 @@ func (bc *BlockChain) CurrentFastBlock() *block.Block {
 	return bc.currentFastBlock
 }
 
+// CurrentFinalizedBlock retrieves the last block of the canonical chain proven
+// final by a commit certificate, or the genesis block if none was.
+func (bc *BlockChain) CurrentFinalizedBlock() *block.Block {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	if bc.currentFinalized == nil {
+		return bc.genesisBlock
+	}
+	return bc.GetBlock(bc.currentFinalized.Hash(), bc.currentFinalized.Number.IntVal.Uint64())
+}
+
+// GetCertificate retrieves the commit certificate proving a block final, or nil
+// if the block was not finalized.
+func (bc *BlockChain) GetCertificate(hash types.Hash, number uint64) *block.Certificate {
+	return GetCertificate(bc.chainDb, hash, number)
+}
+
+// SetFinalized stores a commit certificate with its block and marks the block
+// final, the chain won't reorganise below it anymore. The certificate must have
+// been verified by the caller. Certificates of blocks older than the current
+// finalized one are stored without moving it back.
+func (bc *BlockChain) SetFinalized(cert *block.Certificate) error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if GetCanonicalHash(bc.chainDb, cert.Number) != cert.Hash {
+		return ErrNotCanonical
+	}
+	if err := WriteCertificate(bc.chainDb, cert); err != nil {
+		return err
+	}
+	if bc.currentFinalized != nil && bc.currentFinalized.Number.IntVal.Uint64() >= cert.Number {
+		return nil
+	}
+	if err := WriteFinalizedBlockHash(bc.chainDb, cert.Hash); err != nil {
+		return err
+	}
+	bc.currentFinalized = bc.GetHeader(cert.Hash, cert.Number)
+	logger.Debug("Finalized block", "number", cert.Number, "hash", cert.Hash)
+	return nil
+}
+
+// extendsFinalized reports whether the block descends from the last finalized
+// block. It assumes that the chain manager mutex is held.
+func (bc *BlockChain) extendsFinalized(b *block.Block) bool {
+	if bc.currentFinalized == nil {
+		return true
+	}
+	number, hash := b.NumberU64(), b.Hash()
+	final := bc.currentFinalized.Number.IntVal.Uint64()
+	for number > final {
+		header := bc.GetHeader(hash, number)
+		if header == nil {
+			return false
+		}
+		number, hash = number-1, header.ParentHash
+	}
+	return number == final && hash == bc.currentFinalized.Hash()
+}
+
 // Status returns status information about the current chain such as the HEAD Number,
 // the HEAD hash and the hash of the genesis block.
 func (bc *BlockChain) Status() (numnber *big.Int, currentBlock types.Hash, genesisBlock types.Hash) {
@@ -397,6 +528,14 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 	return bc.StateAt(bc.CurrentBlock().Root())
 }
 
+// NodeMode returns whether the chain prunes the old states.
+func (bc *BlockChain) NodeMode() NodeMode {
+	if bc.cacheConfig.Disabled {
+		return ArchiveMode
+	}
+	return FullMode
+}
+
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root types.Hash) (*state.StateDB, error) {
 	return state.New(root, bc.stateCache)
@@ -435,7 +574,8 @@ func (bc *BlockChain) Export(w io.Writer) error {
 	return bc.ExportN(w, uint64(0), bc.currentBlock.NumberU64())
 }
 
-// ExportN writes a subset of the active chain to the given writer.
+// ExportN writes a subset of the active chain to the given writer, in the
+// chain export file format read by Import.
 func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 	bc.mu.RLock()
 	defer bc.mu.RUnlock()
@@ -445,14 +585,37 @@ func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 	}
 	logger.Info("Exporting batch of blocks", "count", last-first+1)
 
+	header := &ExportHeader{
+		Version: ExportVersion,
+		ChainId: chainId(bc.config),
+		Genesis: bc.genesisBlock.Hash(),
+		First:   first,
+		Last:    last,
+	}
+	if err := header.write(w, exportMagic); err != nil {
+		return err
+	}
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		blob     []byte
+		err      error
+	)
 	for nr := first; nr <= last; nr++ {
 		blk := bc.GetBlockByNumber(nr)
 		if blk == nil {
 			return fmt.Errorf("export failed on #%d: not found", nr)
 		}
-		if err := msgp.Encode(w, blk); err != nil {
+		if blob, err = blk.MarshalMsg(blob[:0]); err != nil {
+			return err
+		}
+		if err := writeExportItem(w, blob); err != nil {
 			return err
 		}
+		if time.Since(reported) >= statsReportLimit {
+			logger.Info("Exporting blocks", "number", nr, "exported", nr-first+1, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
 	}
 
 	return nil
@@ -527,7 +690,7 @@ func (bc *BlockChain) GetBodyMsgp(hash types.Hash) []byte {
 
 // HasBlock checks if a block is fully present in the database or not.
 func (bc *BlockChain) HasBlock(hash types.Hash, number uint64) bool {
-	if bc.blockCache.Contains(hash) {
+	if bc.blockCache.Contains(hash) || hasAncientBlock(bc.chainDb, hash, number) {
 		return true
 	}
 	ok, _ := bc.chainDb.Has(blockBodyKey(hash, number))
@@ -822,6 +985,12 @@ func (bc *BlockChain) WriteBlockAndState(block *block.Block, receipts []*transac
 	//if new bigger block number coming, need reorg the chain
 	reorg := blockNumber > bcCurrentBlockNumber
 
+	// Never reorganise away from a finalized block, keep the fork as a side chain
+	if reorg && block.ParentHash() != bc.currentBlock.Hash() && !bc.extendsFinalized(block) {
+		logger.Warn("Refused reorg below the finalized block", "number", blockNumber, "hash", block.Hash(), "finalized", bc.currentFinalized.Number.IntVal.Uint64())
+		reorg = false
+	}
+
 	if reorg {
 		// Reorganise the chain if the parent is not the head block
 		if block.ParentHash() != bc.currentBlock.Hash() {
@@ -957,6 +1126,8 @@ func (bc *BlockChain) insertChain(chain block.Blocks) (int, []interface{}, []*tr
 			bc.reportBlock(blk, nil, err)
 			return i, events, coalescedLogs, err
 		}
+		bc.CheckEquivocation(blk.Header())
+
 		// Create a new statedb using the parent block and report an
 		// error if it fails.
 		var parent *block.Block
@@ -1196,6 +1367,8 @@ func (bc *BlockChain) update() {
 		select {
 		case <-futureTimer.C:
 			bc.procFutureBlocks()
+			bc.pruneState()
+			bc.freeze()
 		case <-bc.quit:
 			return
 		}
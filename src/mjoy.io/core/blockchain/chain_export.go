@@ -0,0 +1,308 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: chain_export.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"mjoy.io/common"
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/params"
+)
+
+const (
+	// ExportVersion is the version of the chain export file format written.
+	ExportVersion = 1
+
+	// exportMagic starts every chain export file.
+	exportMagic = "MJOYCHN\x00"
+
+	// maxExportItem bounds the size of a block read from a chain export file.
+	maxExportItem = 64 * 1024 * 1024
+
+	// importBatch is the maximum number of blocks inserted at once by an import.
+	importBatch = 2500
+)
+
+var (
+	// ErrExportFormat is returned when reading a file not written by an export.
+	ErrExportFormat = errors.New("not a chain export file")
+
+	// ErrExportChecksum is returned when a block of a chain export file does
+	// not match its checksum.
+	ErrExportChecksum = errors.New("block checksum mismatch")
+
+	// ErrExportTruncated is returned when a chain export file ends before the
+	// last block its header announces.
+	ErrExportTruncated = errors.New("chain export file truncated")
+
+	// ErrImportAborted is returned when an import is stopped before its end.
+	ErrImportAborted = errors.New("import aborted")
+)
+
+// ExportHeader starts a chain export or state snapshot file. It identifies the
+// chain the blocks belong to and the range of their numbers.
+//
+// The header is followed by the blocks in order, each one written as its
+// msgp encoding preceded by the 4 bytes big-endian length and CRC-32
+// (Castagnoli) checksum of the encoding.
+type ExportHeader struct {
+	Version uint32
+	ChainId uint64
+	Genesis types.Hash
+	First   uint64
+	Last    uint64
+}
+
+var exportTable = crc32.MakeTable(crc32.Castagnoli)
+
+// chainId returns the chain id of config, 0 if it has none.
+func chainId(config *params.ChainConfig) uint64 {
+	if config == nil || config.ChainId == nil {
+		return 0
+	}
+	return config.ChainId.Uint64()
+}
+
+// write writes the header to w, after the magic of the file type.
+func (h *ExportHeader) write(w io.Writer, magic string) error {
+	buf := make([]byte, 0, len(magic)+4+8+types.HashLength+8+8)
+	buf = append(buf, magic...)
+	buf = appendUint32(buf, h.Version)
+	buf = appendUint64(buf, h.ChainId)
+	buf = append(buf, h.Genesis[:]...)
+	buf = appendUint64(buf, h.First)
+	buf = appendUint64(buf, h.Last)
+	_, err := w.Write(buf)
+	return err
+}
+
+// read reads the header from r, checking the magic of the file type and the
+// version.
+func (h *ExportHeader) read(r io.Reader, magic string) error {
+	buf := make([]byte, len(magic)+4+8+types.HashLength+8+8)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrExportFormat
+		}
+		return err
+	}
+	if string(buf[:len(magic)]) != magic {
+		return ErrExportFormat
+	}
+	buf = buf[len(magic):]
+	h.Version = binary.BigEndian.Uint32(buf)
+	h.ChainId = binary.BigEndian.Uint64(buf[4:])
+	copy(h.Genesis[:], buf[12:])
+	h.First = binary.BigEndian.Uint64(buf[12+types.HashLength:])
+	h.Last = binary.BigEndian.Uint64(buf[20+types.HashLength:])
+
+	if h.Version == 0 || h.Version > ExportVersion {
+		return fmt.Errorf("unsupported file version %d", h.Version)
+	}
+	if h.First > h.Last {
+		return fmt.Errorf("invalid block range #%d-#%d", h.First, h.Last)
+	}
+	return nil
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var enc [4]byte
+	binary.BigEndian.PutUint32(enc[:], v)
+	return append(b, enc[:]...)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], v)
+	return append(b, enc[:]...)
+}
+
+// writeExportItem writes the encoded block blob to w, preceded by its length
+// and checksum.
+func writeExportItem(w io.Writer, blob []byte) error {
+	prefix := appendUint32(make([]byte, 0, 8), uint32(len(blob)))
+	prefix = appendUint32(prefix, crc32.Checksum(blob, exportTable))
+	if _, err := w.Write(prefix); err != nil {
+		return err
+	}
+	_, err := w.Write(blob)
+	return err
+}
+
+// readExportItem reads an item written by writeExportItem from r into buf,
+// reallocated if too small, and returns it.
+func readExportItem(r io.Reader, buf []byte) ([]byte, error) {
+	var prefix [8]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrExportTruncated
+		}
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(prefix[:])
+	if size > maxExportItem {
+		return nil, fmt.Errorf("item size %d over the limit", size)
+	}
+	if cap(buf) < int(size) {
+		buf = make([]byte, size)
+	}
+	blob := buf[:size]
+	if _, err := io.ReadFull(r, blob); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrExportTruncated
+		}
+		return nil, err
+	}
+	if crc32.Checksum(blob, exportTable) != binary.BigEndian.Uint32(prefix[4:]) {
+		return nil, ErrExportChecksum
+	}
+	return blob, nil
+}
+
+// ExportReader reads the blocks of a chain export file, checking their
+// checksums and that they come in the order of the header range.
+type ExportReader struct {
+	r      io.Reader
+	header ExportHeader
+	next   uint64
+	blob   []byte
+}
+
+// NewExportReader reads the header of the chain export file read by r.
+func NewExportReader(r io.Reader) (*ExportReader, error) {
+	er := &ExportReader{r: r}
+	if err := er.header.read(r, exportMagic); err != nil {
+		return nil, err
+	}
+	er.next = er.header.First
+	return er, nil
+}
+
+// Header returns the header of the file.
+func (er *ExportReader) Header() ExportHeader {
+	return er.header
+}
+
+// Next returns the next block of the file, io.EOF once the last one is read.
+func (er *ExportReader) Next() (*block.Block, error) {
+	if er.next > er.header.Last {
+		return nil, io.EOF
+	}
+	blob, err := readExportItem(er.r, er.blob)
+	if err != nil {
+		return nil, fmt.Errorf("block #%d: %v", er.next, err)
+	}
+	er.blob = blob
+	blk := new(block.Block)
+	if _, err := blk.UnmarshalMsg(blob); err != nil {
+		return nil, fmt.Errorf("block #%d: failed to parse: %v", er.next, err)
+	}
+	if blk.NumberU64() != er.next {
+		return nil, fmt.Errorf("block #%d: found block #%d instead", er.next, blk.NumberU64())
+	}
+	er.next++
+	return blk, nil
+}
+
+// Import inserts the blocks of the chain export file read from r into the
+// chain, in batches. The blocks already in the chain are passed over without
+// being validated again if skipKnown is set. Closing abort stops the import
+// once the batch being inserted is done, the batches inserted until then are
+// kept so that running the import again resumes it.
+//
+// Import returns the number of blocks inserted and passed over.
+func (bc *BlockChain) Import(r io.Reader, skipKnown bool, abort <-chan struct{}) (int, int, error) {
+	er, err := NewExportReader(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	header := er.Header()
+	if id := chainId(bc.config); header.ChainId != id {
+		return 0, 0, fmt.Errorf("blocks of chain %d, want chain %d", header.ChainId, id)
+	}
+	if genesis := bc.Genesis().Hash(); header.Genesis != genesis {
+		return 0, 0, fmt.Errorf("blocks of genesis %x, want genesis %x", header.Genesis[:8], genesis[:8])
+	}
+	logger.Info("Importing blocks", "first", header.First, "last", header.Last)
+
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		imported int
+		skipped  int
+		blocks   = make(block.Blocks, 0, importBatch)
+	)
+	for done := false; !done; {
+		select {
+		case <-abort:
+			logger.Info("Import aborted", "imported", imported, "skipped", skipped)
+			return imported, skipped, ErrImportAborted
+		default:
+		}
+		// Load a batch of blocks, passing over the known ones if requested
+		for len(blocks) < cap(blocks) {
+			blk, err := er.Next()
+			if err == io.EOF {
+				done = true
+				break
+			}
+			if err != nil {
+				return imported, skipped, err
+			}
+			// The genesis block has no parent to be inserted onto
+			if blk.NumberU64() == 0 {
+				if blk.Hash() != header.Genesis {
+					return imported, skipped, fmt.Errorf("block #0: hash %x, want genesis %x", blk.Hash().Bytes()[:8], header.Genesis[:8])
+				}
+				skipped++
+				continue
+			}
+			if skipKnown && bc.HasBlock(blk.Hash(), blk.NumberU64()) {
+				skipped++
+				continue
+			}
+			blocks = append(blocks, blk)
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if index, err := bc.InsertChain(blocks); err != nil {
+			if index < len(blocks) {
+				return imported + index, skipped, fmt.Errorf("block #%d: failed to insert: %v", blocks[index].NumberU64(), err)
+			}
+			return imported, skipped, fmt.Errorf("failed to insert: %v", err)
+		}
+		imported += len(blocks)
+		if done || time.Since(reported) >= statsReportLimit {
+			logger.Info("Imported blocks", "number", blocks[len(blocks)-1].NumberU64(), "imported", imported, "skipped", skipped, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+		blocks = blocks[:0]
+	}
+	return imported, skipped, nil
+}
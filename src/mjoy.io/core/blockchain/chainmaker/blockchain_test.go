@@ -53,7 +53,7 @@ func newTestBlockChain(fake bool) *blockchain.BlockChain {
 		engine = &consensus.Engine_empty{}
 	}
 
-	blockchain, err := blockchain.NewBlockChain(db, gspec.Config, engine)
+	blockchain, err := blockchain.NewBlockChain(db, nil, gspec.Config, engine)
 	if err != nil {
 		panic(err)
 	}
@@ -145,7 +145,7 @@ func testBlockChainImport(chain block.Blocks, bc *blockchain.BlockChain) error {
 		if err != nil {
 			return err
 		}
-		receipts, _, err := bc.Processor().Process(block, statedb)
+		cache, receipts, _, err := bc.Processor().Process(block, statedb, bc.GetDb(), bc.Config())
 		if err != nil {
 			bc.ReportBlock(block, receipts, err)
 			return err
@@ -158,6 +158,7 @@ func testBlockChainImport(chain block.Blocks, bc *blockchain.BlockChain) error {
 		bc.MuLock()
 		blockchain.WriteBlock(bc.GetDb(), block)
 		statedb.CommitTo(bc.GetDb(), false)
+		bc.WriteCacheDb(cache)
 		bc.MuUnLock()
 	}
 	return nil
@@ -450,6 +451,55 @@ func testReorg(t *testing.T, first, second  int, num uint64, full bool) {
 	}
 }
 
+// Tests that a longer header chain forking below the finalized block is kept
+// as a side chain, while one extending the finalized block is still adopted.
+func TestReorgBelowFinalizedHeaders(t *testing.T) {
+	bc := newTestBlockChain(false)
+	defer bc.Stop()
+
+	canonical := makeHeaderChainWithDiff(bc.GetGenesis(), 3, 11)
+	if _, err := bc.InsertHeaderChain(canonical, 1); err != nil {
+		t.Fatalf("failed to insert canonical headers: %v", err)
+	}
+	if err := bc.SetFinalized(&block.Certificate{Number: 2, Hash: canonical[1].Hash()}); err != nil {
+		t.Fatalf("failed to finalize header: %v", err)
+	}
+	// The seed is replaced by the signer, the fork differs by its timestamps
+	fork := make([]*block.Header, 5)
+	for i := range fork {
+		header := &block.Header{
+			Number:          types.NewBigInt(*big.NewInt(int64(i + 1))),
+			TxRootHash:      block.EmptyRootHash,
+			ReceiptRootHash: block.EmptyRootHash,
+			Time:            types.NewBigInt(*big.NewInt(int64(i) + 100)),
+			ParentHash:      bc.GetGenesis().Hash(),
+		}
+		if i > 0 {
+			header.ParentHash = fork[i-1].Hash()
+		}
+		fork[i], _ = block.SignHeader(header, block.NewBlockSigner(defaultChainConfig.ChainId), testBankKey)
+	}
+	if _, err := bc.InsertHeaderChain(fork, 1); err != nil {
+		t.Fatalf("failed to insert forked headers: %v", err)
+	}
+	if head := bc.CurrentHeader().Hash(); head != canonical[2].Hash() {
+		t.Fatalf("head header mismatch: have %x, want %x", head, canonical[2].Hash())
+	}
+	for i, header := range canonical {
+		if hash := bc.GetHeaderByNumber(uint64(i + 1)).Hash(); hash != header.Hash() {
+			t.Fatalf("canonical header %d mismatch: have %x, want %x", i+1, hash, header.Hash())
+		}
+	}
+	// A longer chain keeping the finalized header is adopted
+	extended := makeHeaderChainWithDiff(bc.GetGenesis(), 5, 11)
+	if _, err := bc.InsertHeaderChain(extended, 1); err != nil {
+		t.Fatalf("failed to insert extending headers: %v", err)
+	}
+	if head := bc.CurrentHeader().Hash(); head != extended[4].Hash() {
+		t.Fatalf("head header mismatch: have %x, want %x", head, extended[4].Hash())
+	}
+}
+
 /*
 // Tests chain insertions in the face of one entity containing an invalid nonce.
 func TestHeadersInsertNonceError(t *testing.T) { testInsertNonceError(t, false) }
@@ -512,10 +562,9 @@ func TestFastVsFullChains(t *testing.T) {
 		gendb, _ = database.OpenMemDB()
 		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		address  = crypto.PubkeyToAddress(key.PublicKey)
-		funds    = big.NewInt(1000000000)
 		gspec    = &genesis.Genesis{
 			Config: defaultChainConfig,
-			Alloc:  genesis.GenesisAlloc{address: {Balance: funds}},
+			Alloc:  genesis.GenesisAlloc{address: {}},
 		}
 		genesis = gspec.MustCommit(gendb)
 		signer  = transaction.NewMSigner(gspec.Config.ChainId)
@@ -528,7 +577,7 @@ func TestFastVsFullChains(t *testing.T) {
 		// If the block number is multiple of 3, send a few bonus transactions to the blockproducer
 		if i%3 == 2 {
 			for j := 0; j < i%4+1; j++ {
-				tx, err := transaction.SignTx(transaction.NewTransaction(blockGen.TxNonce(address), types.Address{0x00},actions), signer, key)
+				tx, err := transaction.SignTx(transaction.NewTransaction(blockGen.TxNonce(address), actions), signer, key)
 				if err != nil {
 					panic(err)
 				}
@@ -539,7 +588,7 @@ func TestFastVsFullChains(t *testing.T) {
 	// Import the chain as an archive node for the comparison baseline
 	archiveDb, _ := database.OpenMemDB()
 	gspec.MustCommit(archiveDb)
-	archive, _ := blockchain.NewBlockChain(archiveDb, gspec.Config, &consensus.Engine_empty{})
+	archive, _ := blockchain.NewBlockChain(archiveDb, nil, gspec.Config, &consensus.Engine_empty{})
 	defer archive.Stop()
 
 	if n, err := archive.InsertChain(blocks); err != nil {
@@ -548,7 +597,7 @@ func TestFastVsFullChains(t *testing.T) {
 	// Fast import the chain as a non-archive node to test
 	fastDb, _ := database.OpenMemDB()
 	gspec.MustCommit(fastDb)
-	fast, _ := blockchain.NewBlockChain(fastDb, gspec.Config, &consensus.Engine_empty{})
+	fast, _ := blockchain.NewBlockChain(fastDb, nil, gspec.Config, &consensus.Engine_empty{})
 	defer fast.Stop()
 
 	headers := make([]*block.Header, len(blocks))
@@ -593,8 +642,7 @@ func TestLightVsFastVsFullChainHeads(t *testing.T) {
 		gendb, _ = database.OpenMemDB()
 		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		address  = crypto.PubkeyToAddress(key.PublicKey)
-		funds    = big.NewInt(1000000000)
-		gspec    = &genesis.Genesis{Config: defaultChainConfig, Alloc: genesis.GenesisAlloc{address: {Balance: funds}}}
+		gspec    = &genesis.Genesis{Config: defaultChainConfig, Alloc: genesis.GenesisAlloc{address: {}}}
 		genesis  = gspec.MustCommit(gendb)
 	)
 	height := uint64(1024)
@@ -621,7 +669,7 @@ func TestLightVsFastVsFullChainHeads(t *testing.T) {
 	archiveDb, _ := database.OpenMemDB()
 	gspec.MustCommit(archiveDb)
 
-	archive, _ := blockchain.NewBlockChain(archiveDb, gspec.Config, &consensus.Engine_empty{})
+	archive, _ := blockchain.NewBlockChain(archiveDb, nil, gspec.Config, &consensus.Engine_empty{})
 	if n, err := archive.InsertChain(blocks); err != nil {
 		t.Fatalf("failed to process block %d: %v", n, err)
 	}
@@ -634,7 +682,7 @@ func TestLightVsFastVsFullChainHeads(t *testing.T) {
 	// Import the chain as a non-archive node and ensure all pointers are updated
 	fastDb, _ := database.OpenMemDB()
 	gspec.MustCommit(fastDb)
-	fast, _ := blockchain.NewBlockChain(fastDb, gspec.Config, &consensus.Engine_empty{})
+	fast, _ := blockchain.NewBlockChain(fastDb, nil, gspec.Config, &consensus.Engine_empty{})
 	defer fast.Stop()
 
 	headers := make([]*block.Header, len(blocks))
@@ -655,7 +703,7 @@ func TestLightVsFastVsFullChainHeads(t *testing.T) {
 	lightDb, _ := database.OpenMemDB()
 	gspec.MustCommit(lightDb)
 
-	light, _ := blockchain.NewBlockChain(lightDb, gspec.Config, &consensus.Engine_empty{})
+	light, _ := blockchain.NewBlockChain(lightDb, nil, gspec.Config, &consensus.Engine_empty{})
 	if n, err := light.InsertHeaderChain(headers, 1); err != nil {
 		t.Fatalf("failed to insert header %d: %v", n, err)
 	}
@@ -679,9 +727,9 @@ func TestChainTxReorgs(t *testing.T) {
 		gspec   = &genesis.Genesis{
 			Config:   defaultChainConfig,
 			Alloc: genesis.GenesisAlloc{
-				addr1: {Balance: big.NewInt(1000000)},
-				addr2: {Balance: big.NewInt(1000000)},
-				addr3: {Balance: big.NewInt(1000000)},
+				addr1: {},
+				addr2: {},
+				addr3: {},
 			},
 		}
 		genesis = gspec.MustCommit(db)
@@ -693,9 +741,8 @@ func TestChainTxReorgs(t *testing.T) {
 	// Create two transactions shared between the chains:
 	//  - postponed: transaction included at a later block in the forked chain
 	//  - swapped: transaction included at the same block number in the forked chain
-	postponed, _ := transaction.SignTx(transaction.NewTransaction(0, addr1, actions), signer, key1)
-	//todo set nonce 0 for fake test. future need change to 1
-	swapped, _ := transaction.SignTx(transaction.NewTransaction(0, addr1, actions), signer, key1)
+	postponed, _ := transaction.SignTx(transaction.NewTransaction(0, actions), signer, key1)
+	swapped, _ := transaction.SignTx(transaction.NewTransaction(1, actions), signer, key1)
 
 	// Create two transactions that will be dropped by the forked chain:
 	//  - pastDrop: transaction dropped retroactively from a past block
@@ -711,13 +758,13 @@ func TestChainTxReorgs(t *testing.T) {
 	chain, _ := GenerateChain(gspec.Config, genesis, &consensus.Engine_empty{}, db, 3, func(i int, gen *BlockGen) {
 		switch i {
 		case 0:
-			pastDrop, _ = transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr2), addr2, actions), signer, key2)
+			pastDrop, _ = transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr2), actions), signer, key2)
 
 			gen.AddTx(pastDrop)  // This transaction will be dropped in the fork from below the split point
 			gen.AddTx(postponed) // This transaction will be postponed till block #3 in the fork
 
 		case 2:
-			freshDrop, _ = transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr2), addr2, actions), signer, key2)
+			freshDrop, _ = transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr2), actions), signer, key2)
 
 			gen.AddTx(freshDrop) // This transaction will be dropped in the fork from exactly at the split point
 			gen.AddTx(swapped)   // This transaction will be swapped out at the exact height
@@ -726,7 +773,7 @@ func TestChainTxReorgs(t *testing.T) {
 		}
 	})
 	// Import the chain. This runs all block validation rules.
-	bc, _ := blockchain.NewBlockChain(db, gspec.Config, &consensus.Engine_empty{})
+	bc, _ := blockchain.NewBlockChain(db, nil, gspec.Config, &consensus.Engine_empty{})
 	if i, err := bc.InsertChain(chain); err != nil {
 		t.Fatalf("failed to insert original chain[%d]: %v", i, err)
 	}
@@ -736,18 +783,18 @@ func TestChainTxReorgs(t *testing.T) {
 	chain, _ = GenerateChain(gspec.Config, genesis, &consensus.Engine_empty{}, db, 5, func(i int, gen *BlockGen) {
 		switch i {
 		case 0:
-			pastAdd, _ = transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr3), addr3, actions), signer, key3)
+			pastAdd, _ = transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr3), actions), signer, key3)
 			gen.AddTx(pastAdd) // This transaction needs to be injected during reorg
 
 		case 2:
 			gen.AddTx(postponed) // This transaction was postponed from block #1 in the original chain
 			gen.AddTx(swapped)   // This transaction was swapped from the exact current spot in the original chain
 
-			freshAdd, _ = transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr3), addr3, actions), signer, key3)
+			freshAdd, _ = transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr3), actions), signer, key3)
 			gen.AddTx(freshAdd) // This transaction will be added exactly at reorg time
 
 		case 3:
-			futureAdd, _ = transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr3), addr3, actions), signer, key3)
+			futureAdd, _ = transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr3), actions), signer, key3)
 			gen.AddTx(futureAdd) // This transaction will be added after a full reorg
 		}
 	})
@@ -792,20 +839,20 @@ func TestLogReorgs(t *testing.T) {
 		db, _   = database.OpenMemDB()
 		// this code generates a log
 		// todo code    = util.Hex2Bytes("60606040525b7f24ec1d3ff24c2f6ff210738839dbc339cd45a5294d85c79361016243157aae7b60405180905060405180910390a15b600a8060416000396000f360606040526008565b00")
-		gspec   = &genesis.Genesis{Config: defaultChainConfig, Alloc: genesis.GenesisAlloc{addr1: {Balance: big.NewInt(10000000000000)}}}
+		gspec   = &genesis.Genesis{Config: defaultChainConfig, Alloc: genesis.GenesisAlloc{addr1: {}}}
 		genesis = gspec.MustCommit(db)
 		signer  = transaction.NewMSigner(gspec.Config.ChainId)
 		actions = transaction.ActionSlice{}
 	)
 	actions = append(actions,transaction.Action{&types.Address{0x00},[]byte{1,2,3,4,5}})
-	blockchain, _ := blockchain.NewBlockChain(db, gspec.Config, &consensus.Engine_empty{})
+	blockchain, _ := blockchain.NewBlockChain(db, nil, gspec.Config, &consensus.Engine_empty{})
 	defer blockchain.Stop()
 
 	rmLogsCh := make(chan core.RemovedLogsEvent)
 	blockchain.SubscribeRemovedLogsEvent(rmLogsCh)
 	chain, _ := GenerateChain(defaultChainConfig, genesis, &consensus.Engine_empty{}, db, 2, func(i int, gen *BlockGen) {
 		if i == 1 {
-			tx, err := transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr1), addr1, actions), signer, key1)
+			tx, err := transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr1), actions), signer, key1)
 			// todo : tx, err := transaction.SignTx(transaction.NewContractCreation(gen.TxNonce(addr1), big.NewInt(100), 1000000, big.NewInt(100), code), signer, key1)
 			if err != nil {
 				t.Fatalf("failed to create tx: %v", err)
@@ -840,14 +887,14 @@ func TestReorgSideEvent(t *testing.T) {
 		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
 		gspec   = &genesis.Genesis{
 			Config: defaultChainConfig,
-			Alloc:  genesis.GenesisAlloc{addr1: {Balance: big.NewInt(10000000000000)}},
+			Alloc:  genesis.GenesisAlloc{addr1: {}},
 		}
 		genesis = gspec.MustCommit(db)
 		signer  = transaction.NewMSigner(gspec.Config.ChainId)
 		actions = transaction.ActionSlice{}
 	)
 	actions = append(actions,transaction.Action{&types.Address{0x00},[]byte{1,2,3,4,5}})
-	blockchain, _ := blockchain.NewBlockChain(db, gspec.Config, &consensus.Engine_empty{})
+	blockchain, _ := blockchain.NewBlockChain(db, nil, gspec.Config, &consensus.Engine_empty{})
 	defer blockchain.Stop()
 
 	chain, _ := GenerateChain(gspec.Config, genesis, &consensus.Engine_empty{}, db, 3, func(i int, gen *BlockGen) {})
@@ -856,7 +903,7 @@ func TestReorgSideEvent(t *testing.T) {
 	}
 
 	replacementBlocks, _ := GenerateChain(gspec.Config, genesis, &consensus.Engine_empty{}, db, 4, func(i int, gen *BlockGen) {
-		tx, err := transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr1), addr1, actions), signer, key1)
+		tx, err := transaction.SignTx(transaction.NewTransaction(gen.TxNonce(addr1), actions), signer, key1)
 		//tx, err := transaction.SignTx(transaction.NewContractCreation(gen.TxNonce(addr1), big.NewInt(100), 1000000, big.NewInt(100), nil), signer, key1)
 		if i == 2 {
 			gen.OffsetTime(-9)
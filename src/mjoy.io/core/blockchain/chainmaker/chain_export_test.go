@@ -0,0 +1,162 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: chain_export_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package chainmaker
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"mjoy.io/consensus"
+	"mjoy.io/core/blockchain"
+	"mjoy.io/core/genesis"
+	"mjoy.io/utils/database"
+)
+
+// exportHeaderSize is the size of the magic and header of a chain export file.
+const exportHeaderSize = 8 + 4 + 8 + 32 + 8 + 8
+
+// newImportChain creates an empty chain of the genesis to import blocks into.
+func newImportChain(t *testing.T, gspec *genesis.Genesis) *blockchain.BlockChain {
+	db, _ := database.OpenMemDB()
+	gspec.MustCommit(db)
+	bc, err := blockchain.NewBlockChain(db, nil, gspec.Config, &consensus.Engine_empty{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	return bc
+}
+
+// exportChain exports the blocks first to last of the chain.
+func exportChain(t *testing.T, bc *blockchain.BlockChain, first, last uint64) []byte {
+	var buf bytes.Buffer
+	if err := bc.ExportN(&buf, first, last); err != nil {
+		t.Fatalf("failed to export blocks: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// Tests that an exported chain is imported into an empty database, its state
+// included.
+func TestExportImport(t *testing.T) {
+	const n = 8
+
+	db, src, _ := newTransferBlockChain(t, n, nil)
+	defer src.Stop()
+	data := exportChain(t, src, 0, n)
+
+	bc := newImportChain(t, transferGenesis())
+	defer bc.Stop()
+
+	imported, skipped, err := bc.Import(bytes.NewReader(data), false, nil)
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	if imported != n || skipped != 1 {
+		t.Fatalf("import mismatch: have %d imported %d skipped, want %d imported 1 skipped", imported, skipped, n)
+	}
+	head := bc.CurrentBlock()
+	if head.Hash() != src.CurrentBlock().Hash() {
+		t.Fatalf("head mismatch: have #%d %x, want #%d %x", head.NumberU64(), head.Hash(), src.CurrentBlock().NumberU64(), src.CurrentBlock().Hash())
+	}
+	_, want, _ := balanceValue(db, head.Root())
+	if _, have, err := balanceValue(bc.GetDb(), head.Root()); err != nil || !bytes.Equal(have, want) {
+		t.Fatalf("imported balance mismatch: have %s (%v), want %s", have, err, want)
+	}
+}
+
+// Tests that damaged export files and those of another chain are refused.
+func TestImportRefused(t *testing.T) {
+	const n = 4
+
+	_, src, _ := newTransferBlockChain(t, n, nil)
+	defer src.Stop()
+	data := exportChain(t, src, 0, n)
+
+	corrupted := append([]byte{}, data...)
+	corrupted[exportHeaderSize+8+4] ^= 0xff
+
+	otherGenesis := transferGenesis()
+	otherGenesis.Balances[testBank]++
+
+	otherConfig := *defaultChainConfig
+	otherConfig.ChainId = new(big.Int).Add(defaultChainConfig.ChainId, big.NewInt(1))
+	otherChain := transferGenesis()
+	otherChain.Config = &otherConfig
+
+	tests := []struct {
+		name  string
+		gspec *genesis.Genesis
+		data  []byte
+		err   string
+	}{
+		{"checksum", transferGenesis(), corrupted, blockchain.ErrExportChecksum.Error()},
+		{"truncated", transferGenesis(), data[:len(data)-5], blockchain.ErrExportTruncated.Error()},
+		{"format", transferGenesis(), data[:exportHeaderSize-1], blockchain.ErrExportFormat.Error()},
+		{"genesis", otherGenesis, data, "blocks of genesis"},
+		{"chain id", otherChain, data, "blocks of chain"},
+	}
+	for _, tt := range tests {
+		bc := newImportChain(t, tt.gspec)
+		imported, _, err := bc.Import(bytes.NewReader(tt.data), false, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: import error mismatch: have %v, want %q", tt.name, err, tt.err)
+		}
+		if imported != 0 || bc.CurrentBlock().NumberU64() != 0 {
+			t.Errorf("%s: blocks imported from a refused file: %d", tt.name, imported)
+		}
+		bc.Stop()
+	}
+}
+
+// Tests that an import passes over the blocks known from an earlier one when
+// asked to, and stops when aborted.
+func TestImportResume(t *testing.T) {
+	const n = 8
+
+	_, src, _ := newTransferBlockChain(t, n, nil)
+	defer src.Stop()
+
+	bc := newImportChain(t, transferGenesis())
+	defer bc.Stop()
+
+	if _, _, err := bc.Import(bytes.NewReader(exportChain(t, src, 0, n/2)), false, nil); err != nil {
+		t.Fatalf("failed to import the first half: %v", err)
+	}
+	data := exportChain(t, src, 0, n)
+
+	abort := make(chan struct{})
+	close(abort)
+	if imported, _, err := bc.Import(bytes.NewReader(data), true, abort); err != blockchain.ErrImportAborted || imported != 0 {
+		t.Fatalf("aborted import mismatch: have %d imported (%v), want 0 (%v)", imported, err, blockchain.ErrImportAborted)
+	}
+	imported, skipped, err := bc.Import(bytes.NewReader(data), true, nil)
+	if err != nil {
+		t.Fatalf("failed to resume import: %v", err)
+	}
+	if imported != n/2 || skipped != n/2+1 {
+		t.Fatalf("resumed import mismatch: have %d imported %d skipped, want %d imported %d skipped", imported, skipped, n/2, n/2+1)
+	}
+	if head := bc.CurrentBlock(); head.Hash() != src.CurrentBlock().Hash() {
+		t.Fatalf("head mismatch: have #%d, want #%d", head.NumberU64(), src.CurrentBlock().NumberU64())
+	}
+}
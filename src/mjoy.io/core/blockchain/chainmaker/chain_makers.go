@@ -34,6 +34,10 @@ import (
 	"mjoy.io/utils/database"
 	"mjoy.io/core/blockchain"
 	"mjoy.io/core/genesis"
+	"mjoy.io/core/sdk"
+	"mjoy.io/core/interpreter"
+	"mjoy.io/core/interpreter/intertypes"
+	"mjoy.io/utils/crypto"
 )
 
 // So we can deterministically seed different blockchains
@@ -42,6 +46,11 @@ var (
 	forkSeed      = 2
 )
 var defaultChainConfig = params.TestChainConfig
+
+// producerKey signs the generated blocks, the state processor pays the fees to
+// the signer of a block.
+var producerKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
 // BlockGen creates blocks for testing.
 // See GenerateChain for a detailed explanation.
 type BlockGen struct {
@@ -51,6 +60,9 @@ type BlockGen struct {
 	chainReader consensus.ChainReader
 	header      *block.Header
 	statedb     *state.StateDB
+	db          database.IDatabase
+	cache       *stateprocessor.DbCache
+	sysparam    *intertypes.SystemParams
 
 	txs      []*transaction.Transaction
 	receipts []*transaction.Receipt
@@ -80,8 +92,15 @@ func (b *BlockGen) SetConsensusData(data []byte) {
 // will panic during execution.
 func (b *BlockGen) AddTx(tx *transaction.Transaction) {
 
+	if b.sysparam == nil {
+		sdkHandler := sdk.NewTmpStatusManager(b.db, b.statedb, crypto.PubkeyToAddress(producerKey.PublicKey))
+		b.sysparam = intertypes.MakeSystemParams(sdkHandler, interpreter.NewVm())
+		b.sysparam.BlockNumber = b.header.Number.IntVal.Uint64()
+		b.sysparam.ChainId = b.config.ChainId
+		b.sysparam.Staking = b.config.Dpos != nil
+	}
 	b.statedb.Prepare(tx.Hash(), types.Hash{}, len(b.txs))
-	receipt, err := stateprocessor.ApplyTransaction(b.config,  &b.header.BlockProducer,  b.statedb, b.header, tx)
+	receipt, err := stateprocessor.ApplyTransaction(b.config, nil, b.statedb, b.header, tx, b.cache, b.sysparam)
 	if err != nil {
 		panic(err)
 	}
@@ -104,11 +123,9 @@ func (b *BlockGen) AddUncheckedReceipt(receipt *transaction.Receipt) {
 }
 
 // TxNonce returns the next valid transaction nonce for the
-// account at addr. It panics if the account does not exist.
+// account at addr. Balances are kept by the balance transfer contract, so
+// an account only exists once it sent a transaction.
 func (b *BlockGen) TxNonce(addr types.Address) uint64 {
-	if !b.statedb.Exist(addr) {
-		panic("account does not exist")
-	}
 	return b.statedb.GetNonce(addr)
 }
 
@@ -156,10 +173,11 @@ func GenerateChain(config *params.ChainConfig, parent *block.Block, engine conse
 	genblock := func(i int, parent *block.Block, statedb *state.StateDB) (*block.Block, transaction.Receipts) {
 		// TODO(karalabe): This is needed for clique, which depends on multiple blocks.
 		// It's nonetheless ugly to spin up a blockchain here. Get rid of this somehow.
-		blockchain, _ := blockchain.NewBlockChain(db, config, engine)
+		blockchain, _ := blockchain.NewBlockChain(db, nil, config, engine)
 		defer blockchain.Stop()
 
-		b := &BlockGen{i: i, parent: parent, chain: blocks, chainReader: blockchain, statedb: statedb, config: config, engine: engine}
+		b := &BlockGen{i: i, parent: parent, chain: blocks, chainReader: blockchain, statedb: statedb, db: db, config: config, engine: engine}
+		b.cache = &stateprocessor.DbCache{Cache: make(map[string]interpreter.MemDatabase)}
 		b.header = makeHeader(b.chainReader, parent, statedb, b.engine)
 
 		// Mutate the state and block according to any hard-fork specs
@@ -171,7 +189,10 @@ func GenerateChain(config *params.ChainConfig, parent *block.Block, engine conse
 
 		if b.engine != nil {
 			//b.header.StateHash = statedb.IntermediateRoot()
-			block, _ := b.engine.Finalize(b.chainReader, b.header, statedb, b.txs, b.receipts, true)
+			blk, _ := b.engine.Finalize(b.chainReader, b.header, statedb, b.txs, b.receipts, true)
+			if err := block.SignHeaderInner(blk.B_header, block.NewBlockSigner(config.ChainId), producerKey); err != nil {
+				panic(fmt.Sprintf("block signing error: %v", err))
+			}
 			//b.header.StateHash = statedb.IntermediateRoot()
 			//block.B_header.StateHash = statedb.IntermediateRoot(true)
 			// Write state changes to db
@@ -179,7 +200,12 @@ func GenerateChain(config *params.ChainConfig, parent *block.Block, engine conse
 			if err != nil {
 				panic(fmt.Sprintf("state write error: %v", err))
 			}
-			return block, b.receipts
+			for _, result := range b.cache.Cache {
+				if err := db.Put(result.Key, result.Val); err != nil {
+					panic(fmt.Sprintf("contract value write error: %v", err))
+				}
+			}
+			return blk, b.receipts
 		}
 		return nil, nil
 	}
@@ -222,7 +248,7 @@ func newCanonical(engine consensus.Engine, n int, full bool) (database.IDatabase
 	db, _ := database.OpenMemDB()
 	genesis := gspec.MustCommit(db)
 
-	blockchain, _ := blockchain.NewBlockChain(db, defaultChainConfig, engine)
+	blockchain, _ := blockchain.NewBlockChain(db, nil, defaultChainConfig, engine)
 	// Create and inject the requested chain
 	if n == 0 {
 		return db, blockchain, nil
@@ -0,0 +1,204 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: prune_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package chainmaker
+
+import (
+	"strings"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/consensus"
+	"mjoy.io/core/blockchain"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/genesis"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/state"
+	"mjoy.io/core/transaction"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/database"
+)
+
+var (
+	testBankKey, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	testBank       = crypto.PubkeyToAddress(testBankKey.PublicKey)
+)
+
+// transferGenesis returns the genesis of the transfer chains, funding the test
+// bank.
+func transferGenesis() *genesis.Genesis {
+	return &genesis.Genesis{
+		Config: defaultChainConfig,
+		// The balance transfer contract needs code not to be deleted as empty
+		Alloc:    genesis.GenesisAlloc{balancetransfer.BalanceTransferAddress: {Code: []byte{1, 2, 3, 4, 5}}},
+		Balances: genesis.GenesisBalances{testBank: 1000000000},
+	}
+}
+
+// transferChain generates n blocks on a fresh database, each paying a new
+// account from the test bank, so every block writes new contract values.
+func transferChain(t *testing.T, n int) (database.IDatabase, *genesis.Genesis, []*block.Block) {
+	db, _ := database.OpenMemDB()
+	gspec := transferGenesis()
+	genesisBlock := gspec.MustCommit(db)
+	signer := transaction.NewMSigner(gspec.Config.ChainId)
+
+	blocks, _ := GenerateChain(gspec.Config, genesisBlock, &consensus.Engine_empty{}, db, n, func(i int, gen *BlockGen) {
+		to := types.Address{byte(i + 1)}
+		action := transaction.MakeAction(balancetransfer.BalanceTransferAddress, balancetransfer.MakaBalanceTransferParam(testBank, to, 1000+i))
+		tx, err := transaction.SignTx(transaction.NewTransaction(gen.TxNonce(testBank), transaction.ActionSlice{action}), signer, testBankKey)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		gen.AddTx(tx)
+	})
+	return db, gspec, blocks
+}
+
+// newTransferBlockChain imports a chain of n transfer blocks into a fresh
+// database.
+func newTransferBlockChain(t *testing.T, n int, cacheConfig *blockchain.CacheConfig) (database.IDatabase, *blockchain.BlockChain, []*block.Block) {
+	_, gspec, blocks := transferChain(t, n)
+
+	db, _ := database.OpenMemDB()
+	gspec.MustCommit(db)
+	bc, err := blockchain.NewBlockChain(db, cacheConfig, gspec.Config, &consensus.Engine_empty{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	if i, err := bc.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", i, err)
+	}
+	return db, bc, blocks
+}
+
+// balanceValue returns the hash of the test bank balance in the state with the
+// given root, and the contract value stored under it.
+func balanceValue(db database.IDatabase, root types.Hash) (types.Hash, []byte, error) {
+	statedb, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		return types.Hash{}, nil, err
+	}
+	contract := balancetransfer.BalanceTransferAddress
+	hash := statedb.GetState(contract, crypto.Keccak256Hash(append(contract.Bytes(), testBank[:]...)))
+	val, err := db.Get(hash[:])
+	return hash, val, err
+}
+
+// Tests that pruning keeps the states of the last blocks and the contract values
+// they store, and deletes the older ones.
+func TestPruneState(t *testing.T) {
+	const n, keep = 12, 4
+
+	db, bc, blocks := newTransferBlockChain(t, n, nil)
+	defer bc.Stop()
+
+	values := make([][]byte, n)
+	hashes := make([]types.Hash, n)
+	for i, blk := range blocks {
+		hash, val, err := balanceValue(db, blk.Root())
+		if err != nil {
+			t.Fatalf("block %d: balance missing before pruning: %v", i+1, err)
+		}
+		hashes[i], values[i] = hash, val
+	}
+	kept, deleted, err := blockchain.PruneState(db.(database.IIterableDatabase), keep)
+	if err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	if kept == 0 || deleted == 0 {
+		t.Fatalf("pruning mismatch: kept %d, deleted %d", kept, deleted)
+	}
+	if number, pruned := blockchain.GetLastPruned(db); !pruned || number != n {
+		t.Fatalf("last pruned mismatch: have #%d (%v), want #%d", number, pruned, n)
+	}
+	for i, blk := range blocks {
+		hash, val, err := balanceValue(db, blk.Root())
+		if i >= n-keep {
+			if err != nil || hash != hashes[i] || string(val) != string(values[i]) {
+				t.Errorf("block %d: kept balance mismatch: have %s (%v), want %s", i+1, val, err, values[i])
+			}
+			continue
+		}
+		if _, err := state.New(blk.Root(), state.NewDatabase(db)); err == nil {
+			t.Errorf("block %d: pruned state still readable", i+1)
+		}
+		if val, _ := db.Get(hashes[i][:]); val != nil {
+			t.Errorf("block %d: pruned balance value still stored: %s", i+1, val)
+		}
+	}
+	// The genesis state is always kept
+	if _, err := state.New(bc.Genesis().Root(), state.NewDatabase(db)); err != nil {
+		t.Errorf("genesis state pruned: %v", err)
+	}
+}
+
+// openArchive reopens the chain database as an archive node.
+func openArchive(db database.IDatabase) (*blockchain.BlockChain, error) {
+	return blockchain.NewBlockChain(db, &blockchain.CacheConfig{Disabled: true}, defaultChainConfig, &consensus.Engine_empty{})
+}
+
+// Tests that a database missing old states can't be switched to an archive node,
+// and that the database of an archive node is not pruned.
+func TestNodeModeSwitch(t *testing.T) {
+	// A pruned database misses old states
+	db, bc, _ := newTransferBlockChain(t, 6, nil)
+	bc.Stop()
+	if mode := blockchain.GetNodeMode(db); mode != blockchain.FullMode {
+		t.Fatalf("node mode mismatch: have %v, want %v", mode, blockchain.FullMode)
+	}
+	if _, _, err := blockchain.PruneState(db.(database.IIterableDatabase), 2); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	if _, err := openArchive(db); err == nil || !strings.HasPrefix(err.Error(), blockchain.ErrArchivePruned.Error()) {
+		t.Fatalf("archive node on a pruned database: have %v, want %v", err, blockchain.ErrArchivePruned)
+	}
+	// So does one fast synced, without having been pruned
+	db, bc, blocks := newTransferBlockChain(t, 6, nil)
+	bc.Stop()
+	if err := db.Delete(blocks[2].Root().Bytes()); err != nil {
+		t.Fatalf("failed to delete state root: %v", err)
+	}
+	if _, err := openArchive(db); err == nil || !strings.HasPrefix(err.Error(), blockchain.ErrArchivePruned.Error()) {
+		t.Fatalf("archive node on a database missing a state: have %v, want %v", err, blockchain.ErrArchivePruned)
+	}
+	// A complete database becomes an archive one, and is no longer pruned
+	db, bc, _ = newTransferBlockChain(t, 6, nil)
+	bc.Stop()
+	bc, err := openArchive(db)
+	if err != nil {
+		t.Fatalf("failed to open archive node: %v", err)
+	}
+	bc.Stop()
+	if mode := blockchain.GetNodeMode(db); mode != blockchain.ArchiveMode {
+		t.Fatalf("node mode mismatch: have %v, want %v", mode, blockchain.ArchiveMode)
+	}
+	if _, _, err := blockchain.PruneState(db.(database.IIterableDatabase), 2); err != blockchain.ErrPruneArchive {
+		t.Fatalf("archive database pruning: have %v, want %v", err, blockchain.ErrPruneArchive)
+	}
+	// Until it is run as a full node again
+	if bc, err = blockchain.NewBlockChain(db, nil, defaultChainConfig, &consensus.Engine_empty{}); err != nil {
+		t.Fatalf("failed to open full node: %v", err)
+	}
+	bc.Stop()
+	if _, _, err := blockchain.PruneState(db.(database.IIterableDatabase), 2); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: snapshot_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package chainmaker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/consensus"
+	"mjoy.io/core/blockchain"
+	"mjoy.io/params"
+	"mjoy.io/utils/database"
+)
+
+// exportSnapshot exports the state snapshot of the block number of the chain.
+func exportSnapshot(t *testing.T, bc *blockchain.BlockChain, number uint64) []byte {
+	var buf bytes.Buffer
+	if _, err := bc.ExportSnapshot(&buf, number); err != nil {
+		t.Fatalf("failed to export snapshot: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// Tests that a node bootstrapped from a state snapshot holds the state of the
+// snapshot block and carries the chain on from it.
+func TestSnapshotImport(t *testing.T) {
+	const n, number = 8, 5
+
+	srcDb, src, blocks := newTransferBlockChain(t, n, nil)
+	defer src.Stop()
+	data := exportSnapshot(t, src, number)
+
+	db, _ := database.OpenMemDB()
+	gspec := transferGenesis()
+	gspec.MustCommit(db)
+
+	head, entries, err := blockchain.ImportSnapshot(db, bytes.NewReader(data), blocks[number-1].Hash())
+	if err != nil {
+		t.Fatalf("failed to import snapshot: %v", err)
+	}
+	if head.Hash() != blocks[number-1].Hash() || entries == 0 {
+		t.Fatalf("imported snapshot mismatch: have #%d with %d entries, want #%d", head.NumberU64(), entries, number)
+	}
+	_, want, _ := balanceValue(srcDb, head.Root())
+	if _, have, err := balanceValue(db, head.Root()); err != nil || !bytes.Equal(have, want) {
+		t.Fatalf("imported balance mismatch: have %s (%v), want %s", have, err, want)
+	}
+
+	bc, err := blockchain.NewBlockChain(db, nil, gspec.Config, &consensus.Engine_empty{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer bc.Stop()
+	if bc.CurrentBlock().Hash() != head.Hash() {
+		t.Fatalf("head mismatch: have #%d, want #%d", bc.CurrentBlock().NumberU64(), number)
+	}
+	if i, err := bc.InsertChain(blocks[number:]); err != nil {
+		t.Fatalf("failed to insert block %d after the snapshot: %v", i, err)
+	}
+	if bc.CurrentBlock().Root() != src.CurrentBlock().Root() {
+		t.Fatalf("state root mismatch: have %x, want %x", bc.CurrentBlock().Root(), src.CurrentBlock().Root())
+	}
+}
+
+// Tests that snapshots are refused when corrupted, not of the trusted block or
+// not of an epoch block, leaving the database to import again.
+func TestSnapshotRefused(t *testing.T) {
+	const n, number = 8, 6
+
+	_, src, blocks := newTransferBlockChain(t, n, nil)
+	defer src.Stop()
+	data := exportSnapshot(t, src, number)
+	trusted := blocks[number-1].Hash()
+
+	// Corrupt the value of the first state entry, fixing up its checksum
+	entry := exportHeaderSize + 8 + int(binary.BigEndian.Uint32(data[exportHeaderSize:]))
+	size := int(binary.BigEndian.Uint32(data[entry:]))
+	corrupted := append([]byte{}, data...)
+	corrupted[entry+8+types.HashLength] ^= 0xff
+	binary.BigEndian.PutUint32(corrupted[entry+4:], crc32.Checksum(corrupted[entry+8:entry+8+size], crc32.MakeTable(crc32.Castagnoli)))
+
+	epochConfig := *defaultChainConfig
+	epochConfig.Poa = &params.PoaConfig{Epoch: 4}
+
+	tests := []struct {
+		name    string
+		config  *params.ChainConfig
+		data    []byte
+		trusted types.Hash
+		err     string
+	}{
+		{"corrupted", nil, corrupted, trusted, "hash mismatch"},
+		{"truncated", nil, data[:len(data)-16], trusted, blockchain.ErrExportTruncated.Error()},
+		{"untrusted", nil, data, blocks[number-2].Hash(), blockchain.ErrSnapshotUntrusted.Error()},
+		{"no trusted", nil, data, types.Hash{}, blockchain.ErrSnapshotUntrusted.Error()},
+		{"epoch", &epochConfig, data, trusted, "not an epoch block"},
+	}
+	for _, tt := range tests {
+		db, _ := database.OpenMemDB()
+		genesis := transferGenesis().MustCommit(db)
+		if tt.config != nil {
+			blockchain.WriteChainConfig(db, genesis.Hash(), tt.config)
+		}
+
+		_, _, err := blockchain.ImportSnapshot(db, bytes.NewReader(tt.data), tt.trusted)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: import error mismatch: have %v, want %q", tt.name, err, tt.err)
+		}
+		if head := blockchain.GetHeadBlockHash(db); head != genesis.Hash() {
+			t.Errorf("%s: head moved by a refused snapshot", tt.name)
+		}
+	}
+	// The same database imports the snapshot once intact
+	db, _ := database.OpenMemDB()
+	transferGenesis().MustCommit(db)
+	if _, _, err := blockchain.ImportSnapshot(db, bytes.NewReader(corrupted), trusted); err == nil {
+		t.Fatalf("imported a corrupted snapshot")
+	}
+	if _, _, err := blockchain.ImportSnapshot(db, bytes.NewReader(data), trusted); err != nil {
+		t.Fatalf("failed to import snapshot after a refused one: %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: verify_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package chainmaker
+
+import (
+	"strings"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/consensus"
+	"mjoy.io/core/blockchain"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/transaction"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/database"
+)
+
+// balanceSlot returns the storage slot of the balance of the account in the
+// balance transfer contract.
+func balanceSlot(addr types.Address) types.Hash {
+	contract := balancetransfer.BalanceTransferAddress
+	return crypto.Keccak256Hash(append(contract.Bytes(), addr[:]...))
+}
+
+// newVerifyBlockChain imports a chain whose second block pays two accounts
+// around a transfer failing for lack of balance.
+func newVerifyBlockChain(t *testing.T) (*blockchain.BlockChain, []*block.Block, []transaction.Receipts) {
+	db, _ := database.OpenMemDB()
+	gspec := transferGenesis()
+	genesisBlock := gspec.MustCommit(db)
+	signer := transaction.NewMSigner(gspec.Config.ChainId)
+
+	blocks, receipts := GenerateChain(gspec.Config, genesisBlock, &consensus.Engine_empty{}, db, 2, func(i int, gen *BlockGen) {
+		amounts := []int{1000}
+		if i == 1 {
+			amounts = []int{2000, 2000000000, 3000}
+		}
+		for j, amount := range amounts {
+			to := types.Address{byte(i + 1), byte(j + 1)}
+			action := transaction.MakeAction(balancetransfer.BalanceTransferAddress, balancetransfer.MakaBalanceTransferParam(testBank, to, amount))
+			tx, err := transaction.SignTx(transaction.NewTransaction(gen.TxNonce(testBank), transaction.ActionSlice{action}), signer, testBankKey)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			gen.AddTx(tx)
+		}
+	})
+	chainDb, _ := database.OpenMemDB()
+	gspec.MustCommit(chainDb)
+	bc, err := blockchain.NewBlockChain(chainDb, nil, gspec.Config, &consensus.Engine_empty{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	if i, err := bc.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", i, err)
+	}
+	return bc, blocks, receipts
+}
+
+// Tests that the storage writes of a re-executed block holding a failed
+// transaction are set against their transaction and match the recorded state.
+func TestReexecuteFailedTransaction(t *testing.T) {
+	bc, blocks, receipts := newVerifyBlockChain(t)
+	defer bc.Stop()
+
+	if status := receipts[1][1].Status; status != transaction.ReceiptStatusFailed {
+		t.Fatalf("overdrawing transfer status mismatch: have %d, want failed", status)
+	}
+	r, err := bc.ReexecuteBlock(2)
+	if err != nil {
+		t.Fatalf("failed to re-execute block: %v", err)
+	}
+	if mismatches := r.Mismatches(); len(mismatches) != 0 {
+		t.Fatalf("re-executed block departs from its header: %v", mismatches)
+	}
+	if len(r.Receipts) != 3 || r.Receipts[1].Status != transaction.ReceiptStatusFailed {
+		t.Fatalf("re-executed receipts mismatch: %v", r.Receipts)
+	}
+	diffs, ok := bc.DiffWrites(r)
+	if !ok {
+		t.Fatalf("recorded state of block #2 missing")
+	}
+	if len(diffs) != len(blocks[1].Transactions())+1 {
+		t.Fatalf("write sets mismatch: have %d, want %d", len(diffs), len(blocks[1].Transactions())+1)
+	}
+	// The paid accounts are written by their transaction, the failed one pays none
+	written := func(diffs []blockchain.SlotDiff, slot types.Hash) bool {
+		for _, diff := range diffs {
+			if diff.Slot == slot {
+				return true
+			}
+		}
+		return false
+	}
+	for i, paid := range []bool{true, false, true} {
+		to := types.Address{2, byte(i + 1)}
+		if have := written(diffs[i], balanceSlot(to)); have != paid {
+			t.Errorf("transaction %d: payee write mismatch: have %v, want %v", i, have, paid)
+		}
+	}
+	for i, writes := range diffs {
+		for _, diff := range writes {
+			if diff.Final && !diff.Match {
+				t.Errorf("write set %d: final write of slot %x does not match the recorded state", i, diff.Slot)
+			}
+		}
+	}
+}
+
+// Tests that a block whose header records another state root is reported, its
+// writes set against the recorded state if available.
+func TestReexecuteCorruptedRoot(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     func(blocks []*block.Block) types.Hash
+		recorded bool
+	}{
+		{"parent root", func(blocks []*block.Block) types.Hash { return blocks[0].Root() }, true},
+		{"missing root", func([]*block.Block) types.Hash { return types.Hash{0xff} }, false},
+	}
+	for _, tt := range tests {
+		bc, blocks, _ := newVerifyBlockChain(t)
+
+		// Replace the head block by one recording another state root
+		header := blocks[1].Header()
+		header.StateRootHash = tt.root(blocks)
+		if err := block.SignHeaderInner(header, block.NewBlockSigner(bc.Config().ChainId), producerKey); err != nil {
+			t.Fatalf("%s: failed to sign header: %v", tt.name, err)
+		}
+		corrupted := block.NewBlockWithHeader(header).WithBody(blocks[1].Body())
+		if err := blockchain.WriteBlock(bc.GetDb(), corrupted); err != nil {
+			t.Fatalf("%s: failed to write block: %v", tt.name, err)
+		}
+		if err := blockchain.WriteCanonicalHash(bc.GetDb(), corrupted.Hash(), 2); err != nil {
+			t.Fatalf("%s: failed to write block: %v", tt.name, err)
+		}
+		bc.Stop()
+		bc, err := blockchain.NewBlockChain(bc.GetDb(), nil, bc.Config(), &consensus.Engine_empty{})
+		if err != nil {
+			t.Fatalf("%s: failed to reopen blockchain: %v", tt.name, err)
+		}
+
+		r, err := bc.ReexecuteBlock(2)
+		if err != nil {
+			t.Fatalf("%s: failed to re-execute block: %v", tt.name, err)
+		}
+		if r.Root != blocks[1].Root() {
+			t.Errorf("%s: re-executed root mismatch: have %x, want %x", tt.name, r.Root, blocks[1].Root())
+		}
+		mismatches := r.Mismatches()
+		if len(mismatches) != 1 || !strings.HasPrefix(mismatches[0], "state root") {
+			t.Errorf("%s: mismatches: have %v, want the state root", tt.name, mismatches)
+		}
+		diffs, ok := bc.DiffWrites(r)
+		if ok != tt.recorded {
+			t.Errorf("%s: recorded state availability mismatch: have %v, want %v", tt.name, ok, tt.recorded)
+		}
+		// The payees of block #2 are missing from the recorded state
+		for i, writes := range diffs {
+			for _, diff := range writes {
+				if diff.Match {
+					t.Errorf("%s: write set %d: slot %x matches a state lacking it", tt.name, i, diff.Slot)
+				}
+			}
+		}
+		bc.Stop()
+	}
+}
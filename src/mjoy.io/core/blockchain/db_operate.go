@@ -48,6 +48,10 @@ var (
 	headHeaderKey = []byte("LastHeader")
 	headBlockKey  = []byte("LastBlock")
 	headFastKey   = []byte("LastFast")
+	finalizedKey  = []byte("LastFinalized")
+	nodeModeKey   = []byte("NodeMode")
+	lastPrunedKey = []byte("LastPruned")
+	snapshotKey   = []byte("SnapshotBase")
 
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`).
 	headerPrefix        = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
@@ -55,6 +59,7 @@ var (
 	blockHashPrefix     = []byte("H") // blockHashPrefix + hash -> num (uint64 big endian)
 	bodyPrefix          = []byte("b") // bodyPrefix + num (uint64 big endian) + hash -> block body
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
+	certificatePrefix   = []byte("c") // certificatePrefix + num (uint64 big endian) + hash -> block commit certificate
 	lookupPrefix        = []byte("l") // lookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix     = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 
@@ -89,8 +94,36 @@ func encodeBlockNumber(number uint64) []byte {
 	return enc
 }
 
+// readAncient retrieves the data of the kind of a block from the ancient store
+// of db, nil if the block is not in one.
+func readAncient(db DatabaseReader, kind string, number uint64) []byte {
+	ancients, ok := db.(database.IAncientReader)
+	if !ok || number >= ancients.Ancients() {
+		return nil
+	}
+	data, _ := ancients.Ancient(kind, number)
+	return data
+}
+
+// hasAncientBlock checks if a block is in the ancient store of db.
+func hasAncientBlock(db DatabaseReader, hash types.Hash, number uint64) bool {
+	return bytes.Equal(readAncient(db, database.AncientHashes, number), hash[:])
+}
+
+// readAncientBlock retrieves the data of the kind of a block from the ancient
+// store of db, nil if the block is not in one.
+func readAncientBlock(db DatabaseReader, kind string, hash types.Hash, number uint64) []byte {
+	if !hasAncientBlock(db, hash, number) {
+		return nil
+	}
+	return readAncient(db, kind, number)
+}
+
 // GetCanonicalHash retrieves a hash assigned to a canonical block number.
 func GetCanonicalHash(db DatabaseReader, number uint64) types.Hash {
+	if data := readAncient(db, database.AncientHashes, number); len(data) != 0 {
+		return types.BytesToHash(data)
+	}
 	data, _ := db.Get(append(append(headerPrefix, encodeBlockNumber(number)...), numSuffix...))
 	if len(data) == 0 {
 		return types.Hash{}
@@ -132,6 +165,16 @@ func GetHeadBlockHash(db DatabaseReader) types.Hash {
 	return types.BytesToHash(data)
 }
 
+// GetFinalizedBlockHash retrieves the hash of the last block proven final by a
+// commit certificate.
+func GetFinalizedBlockHash(db DatabaseReader) types.Hash {
+	data, _ := db.Get(finalizedKey)
+	if len(data) == 0 {
+		return types.Hash{}
+	}
+	return types.BytesToHash(data)
+}
+
 // GetHeadFastBlockHash retrieves the hash of the current canonical head block during
 // fast synchronization. The difference between this and GetHeadBlockHash is that
 // whereas the last block hash is only updated upon a full block import, the last
@@ -147,6 +190,9 @@ func GetHeadFastBlockHash(db DatabaseReader) types.Hash {
 // GetHeaderMsgp retrieves a block header in its raw MSGP database encoding, or nil
 // if the header's not found.
 func GetHeaderMsgp(db DatabaseReader, hash types.Hash, number uint64) []byte {
+	if data := readAncientBlock(db, database.AncientHeaders, hash, number); data != nil {
+		return data
+	}
 	data, _ := db.Get(headerKey(hash, number))
 	return data
 }
@@ -170,6 +216,9 @@ func GetHeader(db DatabaseReader, hash types.Hash, number uint64) *block.Header
 
 // GetBodyMsgp retrieves the block body in Msgp encoding.
 func GetBodyMsgp(db DatabaseReader, hash types.Hash, number uint64) []byte {
+	if data := readAncientBlock(db, database.AncientBodies, hash, number); data != nil {
+		return data
+	}
 	data, _ := db.Get(blockBodyKey(hash, number))
 	return data
 }
@@ -220,11 +269,34 @@ func GetBlock(db DatabaseReader, hash types.Hash, number uint64) *block.Block {
 	return block.NewBlockWithHeader(header).WithBody(body)
 }
 
+// GetCertificate retrieves the commit certificate finalizing a block, or nil if
+// the block was not proven final.
+func GetCertificate(db DatabaseReader, hash types.Hash, number uint64) *block.Certificate {
+	data, _ := db.Get(append(append(certificatePrefix, encodeBlockNumber(number)...), hash[:]...))
+	if len(data) == 0 {
+		return nil
+	}
+	cert := new(block.Certificate)
+	if _, err := cert.UnmarshalMsg(data); err != nil {
+		logger.Error("Invalid certificate MSGP", "hash", hash, "err", err)
+		return nil
+	}
+	return cert
+}
+
+// GetBlockReceiptsMsgp retrieves the receipts of a block in their MSGP encoding.
+func GetBlockReceiptsMsgp(db DatabaseReader, hash types.Hash, number uint64) []byte {
+	if data := readAncientBlock(db, database.AncientReceipts, hash, number); data != nil {
+		return data
+	}
+	data, _ := db.Get(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash[:]...))
+	return data
+}
 
 // GetBlockReceipts retrieves the receipts generated by the transactions included
 // in a block given by its hash.
 func GetBlockReceipts(db DatabaseReader, hash types.Hash, number uint64) transaction.Receipts {
-	data, _ := db.Get(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash[:]...))
+	data := GetBlockReceiptsMsgp(db, hash, number)
 	if len(data) == 0 {
 		return nil
 	}
@@ -373,6 +445,15 @@ func WriteHeadBlockHash(db database.IDatabasePutter, hash types.Hash) error {
 	return nil
 }
 
+// WriteFinalizedBlockHash stores the hash of the last finalized block.
+func WriteFinalizedBlockHash(db database.IDatabasePutter, hash types.Hash) error {
+	if err := db.Put(finalizedKey, hash.Bytes()); err != nil {
+		logger.Critical("Failed to store last finalized block's hash", "err", err)
+		return err
+	}
+	return nil
+}
+
 // WriteHeadFastBlockHash stores the fast head block's hash.
 func WriteHeadFastBlockHash(db database.IDatabasePutter, hash types.Hash) error {
 	if err := db.Put(headFastKey, hash.Bytes()); err != nil {
@@ -455,6 +536,21 @@ func WriteBlockReceipts(db database.IDatabasePutter, hash types.Hash, number uin
 	return nil
 }
 
+// WriteCertificate stores the commit certificate finalizing a block.
+func WriteCertificate(db database.IDatabasePutter, cert *block.Certificate) error {
+	var buf bytes.Buffer
+	if err := msgp.Encode(&buf, cert); err != nil {
+		logger.Critical("Failed to Encode certificate", "err", err)
+		return err
+	}
+	key := append(append(certificatePrefix, encodeBlockNumber(cert.Number)...), cert.Hash.Bytes()...)
+	if err := db.Put(key, buf.Bytes()); err != nil {
+		logger.Critical("Failed to store block certificate", "err", err)
+		return err
+	}
+	return nil
+}
+
 // WriteTxLookupEntries stores a positional metadata for every transaction from
 // a block, enabling hash based transaction and receipt lookups.
 func WriteTxLookupEntries(db database.IDatabasePutter, block *block.Block) error {
@@ -510,6 +606,7 @@ func DeleteBody(db DatabaseDeleter, hash types.Hash, number uint64) {
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db DatabaseDeleter, hash types.Hash, number uint64) {
 	DeleteBlockReceipts(db, hash, number)
+	DeleteCertificate(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 }
@@ -519,6 +616,11 @@ func DeleteBlockReceipts(db DatabaseDeleter, hash types.Hash, number uint64) {
 	db.Delete(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
 }
 
+// DeleteCertificate removes the commit certificate of a block.
+func DeleteCertificate(db DatabaseDeleter, hash types.Hash, number uint64) {
+	db.Delete(append(append(certificatePrefix, encodeBlockNumber(number)...), hash.Bytes()...))
+}
+
 // DeleteTxLookupEntry removes all transaction data associated with a hash.
 func DeleteTxLookupEntry(db DatabaseDeleter, hash types.Hash) {
 	db.Delete(append(lookupPrefix, hash.Bytes()...))
@@ -563,10 +665,60 @@ func GetBlockChainVersion(db DatabaseReader) int {
 }
 
 // WriteBlockChainVersion writes vsn as the version number to db.
-func WriteBlockChainVersion(db database.IDatabasePutter, vsn int) {
+func WriteBlockChainVersion(db database.IDatabasePutter, vsn int) error {
 	enc := make([]byte, 8)
 	binary.BigEndian.PutUint32(enc, uint32(vsn))
-	db.Put([]byte("BlockchainVersion"), enc)
+	return db.Put([]byte("BlockchainVersion"), enc)
+}
+
+// GetNodeMode reads the mode of the node owning the database, UnknownMode if
+// none was recorded.
+func GetNodeMode(db DatabaseReader) NodeMode {
+	enc, _ := db.Get(nodeModeKey)
+	if len(enc) != 1 {
+		return UnknownMode
+	}
+	return NodeMode(enc[0])
+}
+
+// WriteNodeMode records the mode of the node owning the database.
+func WriteNodeMode(db database.IDatabasePutter, mode NodeMode) error {
+	return db.Put(nodeModeKey, []byte{byte(mode)})
+}
+
+// GetLastPruned reads the number of the head block when the state was last
+// pruned, and whether it ever was.
+func GetLastPruned(db DatabaseReader) (uint64, bool) {
+	enc, _ := db.Get(lastPrunedKey)
+	if len(enc) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(enc), true
+}
+
+// WriteLastPruned records the number of the head block the state was pruned at.
+func WriteLastPruned(db database.IDatabasePutter, number uint64) error {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return db.Put(lastPrunedKey, enc)
+}
+
+// GetSnapshotBase reads the number of the block the database was bootstrapped
+// from by a state snapshot, and whether it was. The blocks below it are missing.
+func GetSnapshotBase(db DatabaseReader) (uint64, bool) {
+	enc, _ := db.Get(snapshotKey)
+	if len(enc) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(enc), true
+}
+
+// WriteSnapshotBase records the number of the block the database was
+// bootstrapped from by a state snapshot.
+func WriteSnapshotBase(db database.IDatabasePutter, number uint64) error {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return db.Put(snapshotKey, enc)
 }
 
 // WriteChainConfig writes the chain config settings to the database.
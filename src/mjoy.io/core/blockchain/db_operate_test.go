@@ -80,7 +80,7 @@ func TestBodyStorage(t *testing.T) {
 
 	encData, err := body.MarshalMsg(nil)
 	if err != nil{
-		t.Fatalf("body enc err: %v")
+		t.Fatalf("body enc err: %v", err)
 	}
 	hash := sh3Hash(encData)
 
@@ -274,9 +274,9 @@ func TestHeadStorage(t *testing.T) {
 func TestLookupStorage(t *testing.T) {
 	db, _ := database.OpenMemDB()
 
-	tx1 := transaction.NewTransaction(1, types.BytesToAddress([]byte{0x11}), big.NewInt(111), 1111, big.NewInt(11111), []byte{0x11, 0x11, 0x11})
-	tx2 := transaction.NewTransaction(2, types.BytesToAddress([]byte{0x22}), big.NewInt(222), 2222, big.NewInt(22222), []byte{0x22, 0x22, 0x22})
-	tx3 := transaction.NewTransaction(3, types.BytesToAddress([]byte{0x33}), big.NewInt(333), 3333, big.NewInt(33333), []byte{0x33, 0x33, 0x33})
+	tx1 := transaction.NewTransaction(1, transaction.ActionSlice{transaction.MakeAction(types.BytesToAddress([]byte{0x11}), []byte{0x11, 0x11, 0x11})})
+	tx2 := transaction.NewTransaction(2, transaction.ActionSlice{transaction.MakeAction(types.BytesToAddress([]byte{0x22}), []byte{0x22, 0x22, 0x22})})
+	tx3 := transaction.NewTransaction(3, transaction.ActionSlice{transaction.MakeAction(types.BytesToAddress([]byte{0x33}), []byte{0x33, 0x33, 0x33})})
 	txs := []*transaction.Transaction{tx1, tx2, tx3}
 
 	block := block.NewBlock(&block.Header{Number: types.NewBigInt(*big.NewInt(314))}, txs, nil)
@@ -329,7 +329,7 @@ func TestBlockReceiptStorage(t *testing.T) {
 		ContractAddress: types.BytesToAddress([]byte{0x01, 0x11, 0x11}),
 	}
 	receipt2 := &transaction.Receipt{
-		PostState:         types.Hash{2}.Bytes(),
+		Status:            transaction.ReceiptStatusSuccessful,
 		Logs: []*transaction.Log{
 			{Address: types.BytesToAddress([]byte{0x22})},
 			{Address: types.BytesToAddress([]byte{0x02, 0x22})},
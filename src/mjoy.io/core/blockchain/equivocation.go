@@ -0,0 +1,116 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: equivocation.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/tinylib/msgp/msgp"
+	"mjoy.io/common/types"
+	"mjoy.io/core"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/utils/database"
+	"mjoy.io/utils/event"
+)
+
+var evidencePrefix = []byte("e") // evidencePrefix + producer + num (uint64 big endian) -> equivocation evidence
+
+// signedHeaderKey identifies the header signed by a producer at a height.
+type signedHeaderKey struct {
+	producer types.Address
+	number   uint64
+}
+
+func evidenceKey(producer types.Address, number uint64) []byte {
+	return append(append(append([]byte{}, evidencePrefix...), producer.Bytes()...), encodeBlockNumber(number)...)
+}
+
+// GetEvidence retrieves the evidence of a producer signing two headers at the
+// given height, or nil if it was not caught.
+func GetEvidence(db DatabaseReader, producer types.Address, number uint64) *block.Evidence {
+	data, _ := db.Get(evidenceKey(producer, number))
+	if len(data) == 0 {
+		return nil
+	}
+	evidence := new(block.Evidence)
+	if _, err := evidence.UnmarshalMsg(data); err != nil {
+		logger.Error("Invalid evidence MSGP", "producer", producer, "number", number, "err", err)
+		return nil
+	}
+	return evidence
+}
+
+// WriteEvidence stores the evidence of a producer equivocation.
+func WriteEvidence(db database.IDatabasePutter, producer types.Address, evidence *block.Evidence) error {
+	var buf bytes.Buffer
+	if err := msgp.Encode(&buf, evidence); err != nil {
+		logger.Critical("Failed to Encode evidence", "err", err)
+		return err
+	}
+	if err := db.Put(evidenceKey(producer, evidence.Number()), buf.Bytes()); err != nil {
+		logger.Critical("Failed to store evidence", "err", err)
+		return err
+	}
+	return nil
+}
+
+// CheckEquivocation compares a verified header with the other headers its
+// producer signed at the same height, among the recently seen ones and the
+// canonical one. If they differ the evidence is stored and an
+// EquivocationEvent posted. The evidence is returned, or nil if the header is
+// the only one.
+func (bc *BlockChain) CheckEquivocation(header *block.Header) *block.Evidence {
+	signer := block.NewBlockSigner(bc.config.ChainId)
+	producer, err := signer.Sender(header)
+	if err != nil {
+		return nil
+	}
+	number := header.Number.IntVal.Uint64()
+	key := signedHeaderKey{producer, number}
+
+	var other *block.Header
+	if cached, ok := bc.signedHeaders.Get(key); ok {
+		other = cached.(*block.Header)
+	} else if canon := bc.GetHeaderByNumber(number); canon != nil {
+		if canonProducer, err := signer.Sender(canon); err == nil && canonProducer == producer {
+			other = canon
+		}
+	}
+	if other == nil || signer.Hash(other) == signer.Hash(header) {
+		bc.signedHeaders.Add(key, header)
+		return nil
+	}
+	if evidence := GetEvidence(bc.chainDb, producer, number); evidence != nil {
+		return evidence
+	}
+	evidence := &block.Evidence{First: other, Second: header}
+	if err := WriteEvidence(bc.chainDb, producer, evidence); err != nil {
+		return nil
+	}
+	logger.Warn("Producer equivocation detected", "producer", producer, "number", number, "first", other.Hash(), "second", header.Hash())
+	go bc.equivocationFeed.Send(core.EquivocationEvent{Producer: producer, Evidence: evidence})
+	return evidence
+}
+
+// SubscribeEquivocationEvent registers a subscription of EquivocationEvent.
+func (bc *BlockChain) SubscribeEquivocationEvent(ch chan<- core.EquivocationEvent) event.Subscription {
+	return bc.scope.Track(bc.equivocationFeed.Subscribe(ch))
+}
@@ -0,0 +1,138 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: freezer.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"mjoy.io/common"
+	"mjoy.io/common/types"
+	"mjoy.io/utils/database"
+)
+
+// freezeBatch is the maximum number of blocks moved to the ancient store at once.
+const freezeBatch = 2048
+
+// freeze moves the canonical blocks deeper than FreezeDepth below the head, and
+// final if the chain finalizes blocks, out of the key-value store into its
+// ancient store, deleting the side chain blocks of their heights. The genesis
+// block is kept in both. The ancient store of a database bootstrapped from a
+// state snapshot starts at the snapshot block.
+func (bc *BlockChain) freeze() {
+	db, ok := bc.chainDb.(database.IAncientStore)
+	if !ok {
+		return
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	head := bc.currentBlock.NumberU64()
+	if head < bc.cacheConfig.FreezeDepth || bc.currentFastBlock.NumberU64() > head {
+		return
+	}
+	limit := head - bc.cacheConfig.FreezeDepth
+	if bc.currentFinalized != nil && bc.currentFinalized.Number.IntVal.Uint64() < limit {
+		limit = bc.currentFinalized.Number.IntVal.Uint64()
+	}
+	first := db.Ancients()
+	if base, ok := GetSnapshotBase(db); ok && first < base {
+		// The blocks below the snapshot block are missing, start after the gap
+		if err := db.SetAncientTail(base); err != nil {
+			logger.Error("Failed to move ancient store to the snapshot block", "number", base, "err", err)
+			return
+		}
+		first = base
+	}
+	if first > limit {
+		return
+	}
+	if limit-first >= freezeBatch {
+		limit = first + freezeBatch - 1
+	}
+	start := time.Now()
+
+	// Copy the blocks first, so each is always in one store at least
+	var hashes []types.Hash
+	for number := first; number <= limit; number++ {
+		hash := GetCanonicalHash(db, number)
+		header := GetHeaderMsgp(db, hash, number)
+		body := GetBodyMsgp(db, hash, number)
+		if len(header) == 0 || len(body) == 0 {
+			logger.Error("Failed to freeze block", "number", number, "err", fmt.Sprintf("block %x missing", hash))
+			break
+		}
+		if err := db.AppendAncient(number, hash[:], header, body, GetBlockReceiptsMsgp(db, hash, number)); err != nil {
+			logger.Error("Failed to freeze block", "number", number, "err", err)
+			break
+		}
+		hashes = append(hashes, hash)
+	}
+	if err := db.SyncAncients(); err != nil {
+		logger.Critical("Failed to sync ancient store", "err", err)
+		return
+	}
+	for i, hash := range hashes {
+		if number := first + uint64(i); number > 0 {
+			deleteFrozen(db, hash, number)
+		}
+	}
+	if len(hashes) > 0 {
+		logger.Info("Moved blocks to the ancient store", "from", first, "to", first+uint64(len(hashes))-1, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// deleteFrozen deletes from the key-value store a canonical block moved to the
+// ancient store, except for its hash to number mapping and commit certificate,
+// and the side chain blocks of its height.
+func deleteFrozen(db database.IAncientStore, hash types.Hash, number uint64) {
+	prefix := append(append([]byte{}, headerPrefix...), encodeBlockNumber(number)...)
+	var side []types.Hash
+	db.Walk(prefix, func(key, value []byte) bool {
+		if len(key) == len(prefix)+types.HashLength && !bytes.Equal(key[len(prefix):], hash[:]) {
+			side = append(side, types.BytesToHash(key[len(prefix):]))
+		}
+		return true
+	})
+	for _, sideHash := range side {
+		DeleteBlock(db, sideHash, number)
+	}
+	db.Delete(headerKey(hash, number))
+	DeleteBody(db, hash, number)
+	DeleteBlockReceipts(db, hash, number)
+	DeleteCanonicalHash(db, number)
+}
+
+// repairAncients drops the ancient blocks above the head header, left by a
+// crash while rewinding the chain.
+func (bc *BlockChain) repairAncients() error {
+	db, ok := bc.chainDb.(database.IAncientStore)
+	if !ok {
+		return nil
+	}
+	head := bc.hc.CurrentHeader().Number.IntVal.Uint64()
+	if db.Ancients() <= head+1 {
+		return nil
+	}
+	logger.Warn("Truncating ancient store to the head", "ancients", db.Ancients(), "head", head)
+	return db.TruncateAncients(head + 1)
+}
@@ -0,0 +1,109 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: freezer_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/consensus"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/params"
+	"mjoy.io/utils/database"
+)
+
+// newFreezeBlock creates an empty block of the number on top of the parent.
+func newFreezeBlock(number uint64, parent types.Hash) *block.Block {
+	return block.NewBlockWithHeader(&block.Header{
+		ParentHash: parent,
+		Number:     types.NewBigInt(*new(big.Int).SetUint64(number)),
+		Time:       types.NewBigInt(*new(big.Int).SetUint64(number * 10)),
+	})
+}
+
+// Tests that the ancient store of a database bootstrapped from a state snapshot
+// starts at the snapshot block, the blocks below it missing.
+func TestFreezeSnapshotBase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mjoyfreeze_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := database.OpenFreezerDB(filepath.Join(dir, "chaindata"), filepath.Join(dir, "ancient"), 16, 16)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// Write the genesis and the blocks from the snapshot block on
+	const base, head, depth = 5, 12, 2
+
+	genesis := newFreezeBlock(0, types.Hash{})
+	blocks := []*block.Block{genesis, newFreezeBlock(base, types.Hash{1})}
+	for number := uint64(base + 1); number <= head; number++ {
+		blocks = append(blocks, newFreezeBlock(number, blocks[len(blocks)-1].Hash()))
+	}
+	for _, blk := range blocks {
+		if err := WriteBlock(db, blk); err != nil {
+			t.Fatalf("failed to write block #%d: %v", blk.NumberU64(), err)
+		}
+		if err := WriteCanonicalHash(db, blk.Hash(), blk.NumberU64()); err != nil {
+			t.Fatalf("failed to write block #%d: %v", blk.NumberU64(), err)
+		}
+	}
+	config := &params.ChainConfig{ChainId: big.NewInt(1)}
+	last := blocks[len(blocks)-1].Hash()
+	for _, err := range []error{
+		WriteChainConfig(db, genesis.Hash(), config),
+		WriteSnapshotBase(db, base),
+		WriteHeadHeaderHash(db, last),
+		WriteHeadFastBlockHash(db, last),
+		WriteHeadBlockHash(db, last),
+	} {
+		if err != nil {
+			t.Fatalf("failed to write chain: %v", err)
+		}
+	}
+	bc, err := NewBlockChain(db, &CacheConfig{FreezeDepth: depth}, config, &consensus.Engine_empty{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer bc.Stop()
+
+	bc.freeze()
+	if db.AncientTail() != base || db.Ancients() != head-depth+1 {
+		t.Fatalf("ancient range mismatch: have %d-%d, want %d-%d", db.AncientTail(), db.Ancients(), base, head-depth+1)
+	}
+	for _, blk := range blocks {
+		frozen := blk.NumberU64() >= base && blk.NumberU64() <= head-depth
+		if hasAncientBlock(db, blk.Hash(), blk.NumberU64()) != frozen {
+			t.Errorf("block #%d: frozen mismatch: want %v", blk.NumberU64(), frozen)
+		}
+		if have := bc.GetBlockByNumber(blk.NumberU64()); have == nil || have.Hash() != blk.Hash() {
+			t.Errorf("block #%d: missing after freezing", blk.NumberU64())
+		}
+	}
+}
@@ -133,7 +133,14 @@ func (hc *HeaderChain) WriteHeader(header *block.Header) (status WriteStatus, er
 		logger.Critical("Failed to write header content", "err", err)
 	}
 	// if header number is bigger,
-	if number > currentCanonicaNumber{
+	reorg := number > currentCanonicaNumber
+
+	// Never reorganise away from a finalized block, keep the fork as a side chain
+	if reorg && header.ParentHash != hc.currentHeaderHash && !hc.extendsFinalized(header) {
+		logger.Warn("Refused header reorg below the finalized block", "number", number, "hash", hash)
+		reorg = false
+	}
+	if reorg {
 		// Overwrite any stale canonical number assignments
 		var (
 			headHash   = header.ParentHash
@@ -167,6 +174,28 @@ func (hc *HeaderChain) WriteHeader(header *block.Header) (status WriteStatus, er
 	return
 }
 
+// extendsFinalized reports whether the header descends from the last finalized
+// block, as stored in the database.
+func (hc *HeaderChain) extendsFinalized(header *block.Header) bool {
+	finalHash := GetFinalizedBlockHash(hc.chainDb)
+	if finalHash == (types.Hash{}) {
+		return true
+	}
+	final := hc.GetBlockNumber(finalHash)
+	if final == missingNumber {
+		return true
+	}
+	number, hash := header.Number.IntVal.Uint64(), header.Hash()
+	for number > final {
+		parent := hc.GetHeader(hash, number)
+		if parent == nil {
+			return false
+		}
+		number, hash = number-1, parent.ParentHash
+	}
+	return number == final && hash == finalHash
+}
+
 // WhCallback is a callback function for inserting individual headers.
 // A callback is used for two reasons: first, in a LightChain, status should be
 // processed and light chain events sent, while in a BlockChain this is not
@@ -182,8 +211,8 @@ func (hc *HeaderChain) ValidateHeaderChain(chain []*block.Header, checkFreq int)
 			logger.Error("Non contiguous header insert", "number", chain[i].Number.IntVal.String(), "hash", chain[i].Hash().String(),
 				"parent", chain[i].ParentHash.String(), "prevnumber", chain[i-1].Number.IntVal.String(), "prevhash", chain[i-1].Hash().String())
 
-			return 0, fmt.Errorf("non contiguous insert: item %d is #%d [%x…], item %d is #%d [%x…] (parent [%x…])", i-1, chain[i-1].Number,
-				chain[i-1].Hash().Bytes()[:4], i, chain[i].Number, chain[i].Hash().Bytes()[:4], chain[i].ParentHash[:4])
+			return 0, fmt.Errorf("non contiguous insert: item %d is #%d [%x…], item %d is #%d [%x…] (parent [%x…])", i-1, &chain[i-1].Number.IntVal,
+				chain[i-1].Hash().Bytes()[:4], i, &chain[i].Number.IntVal, chain[i].Hash().Bytes()[:4], chain[i].ParentHash[:4])
 		}
 	}
 
@@ -302,7 +331,7 @@ func (hc *HeaderChain) GetHeaderByHash(hash types.Hash) *block.Header {
 
 // HasHeader checks if a block header is present in the database or not.
 func (hc *HeaderChain) HasHeader(hash types.Hash, number uint64) bool {
-	if hc.numberCache.Contains(hash) || hc.headerCache.Contains(hash) {
+	if hc.numberCache.Contains(hash) || hc.headerCache.Contains(hash) || hasAncientBlock(hc.chainDb, hash, number) {
 		return true
 	}
 	ok, _ := hc.chainDb.Has(headerKey(hash, number))
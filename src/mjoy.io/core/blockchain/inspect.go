@@ -0,0 +1,151 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: inspect.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"bytes"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core/state"
+	"mjoy.io/utils/database"
+)
+
+// DatabaseStat is the number of entries of a kind in the database and their
+// size, keys included.
+type DatabaseStat struct {
+	Name  string
+	Count uint64
+	Size  uint64
+}
+
+// The kinds of entries reported by InspectDatabase, in report order.
+var inspectKinds = []string{
+	"Headers", "Canonical hashes", "Hash to number", "Bodies", "Receipts",
+	"Certificates", "Tx lookups", "Bloom bits", "Bloom bits index", "Evidence",
+	"Trie nodes", "Contract code", "Contract values", "Old state",
+	"Preimages", "Chain config", "POA snapshots", "Metadata", "Other",
+}
+
+var metadataKeys = [][]byte{
+	headHeaderKey, headBlockKey, headFastKey, finalizedKey, nodeModeKey, lastPrunedKey, snapshotKey,
+	[]byte("BlockchainVersion"),
+}
+
+// upgradePrefix starts the keys marking the database upgrades done.
+var upgradePrefix = []byte("dbUpgrade_")
+
+// InspectDatabase walks db and returns the number and size of its entries by
+// kind, followed by the sizes of the ancient store if db has one. The state
+// entries not reachable from the head state are reported as old state.
+func InspectDatabase(db database.IIterableDatabase) ([]DatabaseStat, error) {
+	headState := make(map[types.Hash]state.StateEntryKind)
+	headHash := GetHeadBlockHash(db)
+	if header := GetHeader(db, headHash, GetBlockNumber(db, headHash)); header != nil {
+		err := state.WalkState(db, header.StateRootHash, func(kind state.StateEntryKind, hash types.Hash, blob []byte) error {
+			headState[hash] = kind
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	stats := make(map[string]*DatabaseStat)
+	for _, name := range inspectKinds {
+		stats[name] = &DatabaseStat{Name: name}
+	}
+	err := db.Walk(nil, func(key, value []byte) bool {
+		stat := stats[inspectKind(key, headState)]
+		stat.Count++
+		stat.Size += uint64(len(key) + len(value))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]DatabaseStat, 0, len(inspectKinds)+4)
+	for _, name := range inspectKinds {
+		result = append(result, *stats[name])
+	}
+	if ancients, ok := db.(database.IAncientReader); ok {
+		for _, kind := range []string{database.AncientHashes, database.AncientHeaders, database.AncientBodies, database.AncientReceipts} {
+			size, err := ancients.AncientSize(kind)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, DatabaseStat{Name: "Ancient " + kind, Count: ancients.Ancients() - ancients.AncientTail(), Size: size})
+		}
+	}
+	return result, nil
+}
+
+// inspectKind returns the kind of the entry of the key.
+func inspectKind(key []byte, headState map[types.Hash]state.StateEntryKind) string {
+	// State entries are the only ones keyed by a bare hash
+	if len(key) == types.HashLength {
+		kind, ok := headState[types.BytesToHash(key)]
+		switch {
+		case !ok:
+			return "Old state"
+		case kind == state.CodeEntry:
+			return "Contract code"
+		case kind == state.ValueEntry:
+			return "Contract values"
+		}
+		return "Trie nodes"
+	}
+	for _, meta := range metadataKeys {
+		if bytes.Equal(key, meta) {
+			return "Metadata"
+		}
+	}
+	if bytes.HasPrefix(key, upgradePrefix) || bytes.HasPrefix(key, migrationPrefix) {
+		return "Metadata"
+	}
+	switch {
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == 1+8+types.HashLength:
+		return "Headers"
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == 1+8+len(numSuffix) && bytes.HasSuffix(key, numSuffix):
+		return "Canonical hashes"
+	case bytes.HasPrefix(key, blockHashPrefix) && len(key) == 1+types.HashLength:
+		return "Hash to number"
+	case bytes.HasPrefix(key, bodyPrefix) && len(key) == 1+8+types.HashLength:
+		return "Bodies"
+	case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == 1+8+types.HashLength:
+		return "Receipts"
+	case bytes.HasPrefix(key, certificatePrefix) && len(key) == 1+8+types.HashLength:
+		return "Certificates"
+	case bytes.HasPrefix(key, lookupPrefix) && len(key) == 1+types.HashLength:
+		return "Tx lookups"
+	case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == 1+2+8+types.HashLength:
+		return "Bloom bits"
+	case bytes.HasPrefix(key, BloomBitsIndexPrefix):
+		return "Bloom bits index"
+	case bytes.HasPrefix(key, evidencePrefix) && len(key) == 1+types.AddressLength+8:
+		return "Evidence"
+	case bytes.HasPrefix(key, []byte(preimagePrefix)):
+		return "Preimages"
+	case bytes.HasPrefix(key, configPrefix):
+		return "Chain config"
+	case bytes.HasPrefix(key, []byte("poa-")):
+		return "POA snapshots"
+	}
+	return "Other"
+}
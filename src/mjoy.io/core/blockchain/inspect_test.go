@@ -0,0 +1,175 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: inspect_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/state"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/database"
+)
+
+// inspectKey concatenates the parts of a database key.
+func inspectKey(parts ...[]byte) []byte {
+	var key []byte
+	for _, part := range parts {
+		key = append(key, part...)
+	}
+	return key
+}
+
+// encodeNumber returns the big endian encoding of a number, as used in keys.
+func encodeNumber(number uint64, size int) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return enc[8-size:]
+}
+
+// Tests that keys sharing a prefix are told apart by their layout, and that
+// bare hashes are classified by the head state.
+func TestInspectKind(t *testing.T) {
+	var (
+		hash     = types.Hash{0x6e} // ends the header number like the canonical suffix would
+		num      = encodeNumber(7, 8)
+		trieHash = types.Hash{1}
+		codeHash = types.Hash{2}
+		valHash  = types.Hash{3}
+		oldHash  = types.Hash{4}
+	)
+	headState := map[types.Hash]state.StateEntryKind{
+		trieHash: state.TrieNodeEntry,
+		codeHash: state.CodeEntry,
+		valHash:  state.ValueEntry,
+	}
+	tests := []struct {
+		key  []byte
+		want string
+	}{
+		{inspectKey(headerPrefix, num, hash[:]), "Headers"},
+		{inspectKey(headerPrefix, num, numSuffix), "Canonical hashes"},
+		{inspectKey(headerPrefix, num), "Other"},
+		{inspectKey(blockHashPrefix, hash[:]), "Hash to number"},
+		{inspectKey(bodyPrefix, num, hash[:]), "Bodies"},
+		{inspectKey(blockReceiptsPrefix, num, hash[:]), "Receipts"},
+		{inspectKey(certificatePrefix, num, hash[:]), "Certificates"},
+		{inspectKey(lookupPrefix, hash[:]), "Tx lookups"},
+		{inspectKey(bloomBitsPrefix, encodeNumber(3, 2), num, hash[:]), "Bloom bits"},
+		{[]byte("BlockchainVersion"), "Metadata"},
+		{inspectKey(bloomBitsPrefix, num), "Other"},
+		{inspectKey(BloomBitsIndexPrefix, []byte("count")), "Bloom bits index"},
+		{inspectKey(evidencePrefix, types.Address{1}.Bytes(), num), "Evidence"},
+		{inspectKey([]byte(preimagePrefix), hash[:]), "Preimages"},
+		{inspectKey(configPrefix, hash[:]), "Chain config"},
+		{inspectKey([]byte("poa-"), hash[:]), "POA snapshots"},
+		{headBlockKey, "Metadata"},
+		{finalizedKey, "Metadata"},
+		{inspectKey(upgradePrefix, []byte("receipts")), "Metadata"},
+		{migrationKey(1), "Metadata"},
+		{trieHash[:], "Trie nodes"},
+		{codeHash[:], "Contract code"},
+		{valHash[:], "Contract values"},
+		{oldHash[:], "Old state"},
+		{[]byte("unknown"), "Other"},
+	}
+	for _, test := range tests {
+		if kind := inspectKind(test.key, headState); kind != test.want {
+			t.Errorf("key %x: kind mismatch: have %s, want %s", test.key, kind, test.want)
+		}
+	}
+}
+
+// Tests that InspectDatabase counts every entry under its kind, telling the
+// head state from older state entries.
+func TestInspectDatabase(t *testing.T) {
+	db, _ := database.OpenMemDB()
+
+	// A head state with a contract, its code and one value
+	statedb, _ := state.New(types.Hash{}, state.NewDatabase(db))
+	contract := types.Address{1}
+	val := []byte("value")
+	valHash := crypto.Keccak256Hash(val)
+	statedb.SetCode(contract, []byte{1, 2, 3})
+	statedb.SetState(contract, types.Hash{1}, valHash)
+	root, err := statedb.CommitTo(db, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	db.Put(valHash[:], val)
+	db.Put(crypto.Keccak256([]byte("old")), []byte("old"))
+
+	header := &block.Header{Number: types.NewBigInt(*big.NewInt(1)), StateRootHash: root}
+	hash, num := header.Hash(), encodeNumber(1, 8)
+	WriteHeader(db, header)
+	WriteCanonicalHash(db, hash, 1)
+	WriteHeadBlockHash(db, hash)
+
+	for _, key := range [][]byte{
+		inspectKey(bodyPrefix, num, hash[:]),
+		inspectKey(blockReceiptsPrefix, num, hash[:]),
+		inspectKey(certificatePrefix, num, hash[:]),
+		inspectKey(lookupPrefix, hash[:]),
+		inspectKey(bloomBitsPrefix, encodeNumber(0, 2), encodeNumber(0, 8), hash[:]),
+		inspectKey(BloomBitsIndexPrefix, []byte("count")),
+		inspectKey(evidencePrefix, types.Address{1}.Bytes(), num),
+		inspectKey([]byte(preimagePrefix), hash[:]),
+		inspectKey(configPrefix, hash[:]),
+		inspectKey([]byte("poa-"), hash[:]),
+		[]byte("BlockchainVersion"),
+		[]byte("unknown"),
+	} {
+		db.Put(key, []byte{1})
+	}
+	stats, err := InspectDatabase(db)
+	if err != nil {
+		t.Fatalf("failed to inspect database: %v", err)
+	}
+	want := map[string]uint64{
+		"Headers": 1, "Canonical hashes": 1, "Hash to number": 1, "Bodies": 1, "Receipts": 1,
+		"Certificates": 1, "Tx lookups": 1, "Bloom bits": 1, "Bloom bits index": 1, "Evidence": 1,
+		"Contract code": 1, "Contract values": 1, "Old state": 1,
+		"Chain config": 1, "POA snapshots": 1, "Metadata": 2, "Other": 1,
+		// The state commit adds the preimages of the contract address and of its slot
+		"Preimages": 3,
+	}
+	if len(stats) != len(inspectKinds) {
+		t.Fatalf("stat count mismatch: have %d, want %d", len(stats), len(inspectKinds))
+	}
+	for i, stat := range stats {
+		if stat.Name != inspectKinds[i] {
+			t.Errorf("stat %d name mismatch: have %s, want %s", i, stat.Name, inspectKinds[i])
+		}
+		// The trie layout is not fixed, the account and storage tries have one node at least
+		if stat.Name == "Trie nodes" {
+			if stat.Count < 2 {
+				t.Errorf("trie node count mismatch: have %d, want 2 at least", stat.Count)
+			}
+			continue
+		}
+		if stat.Count != want[stat.Name] {
+			t.Errorf("%s count mismatch: have %d, want %d", stat.Name, stat.Count, want[stat.Name])
+		}
+	}
+}
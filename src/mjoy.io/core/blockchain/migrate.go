@@ -0,0 +1,369 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: migrate.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"mjoy.io/common"
+	"mjoy.io/common/types"
+	"mjoy.io/utils/database"
+)
+
+var (
+	// ErrSchemaTooOld is returned when the database has a schema version no
+	// migration starts from.
+	ErrSchemaTooOld = errors.New("database schema too old to migrate, resync it from scratch")
+
+	// ErrSchemaTooNew is returned when the database was written by a newer
+	// release.
+	ErrSchemaTooNew = errors.New("database schema newer than supported, upgrade mjoyd")
+)
+
+var migrationPrefix = []byte("migration-") // migrationPrefix + version (uint64 big endian) -> key an interrupted migration resumes from
+
+// migrationCheckpoint is the number of entries walked by a migration between
+// two records of its progress.
+const migrationCheckpoint = 10000
+
+// MigrateFunc migrates a database from the schema version before the one it
+// is registered under. It walks the database through m.Walk, so an interrupted
+// run resumes where it stopped, and writes through m.DB(), so a dry run writes
+// nothing. Entries walked again on resuming must be migrated again harmlessly.
+type MigrateFunc func(m *Migrator) error
+
+type migrationEntry struct {
+	version int
+	name    string
+	migrate MigrateFunc
+}
+
+var (
+	migrationsMu sync.RWMutex
+	migrations   = make(map[int]*migrationEntry)
+)
+
+func init() {
+	RegisterMigration(4, "deduplicate transaction data", migrateDeduplicateData)
+}
+
+// RegisterMigration makes a migration to the given schema version available
+// to MigrateDatabase. The version must follow BlockChainVersion, the oldest
+// schema migrated from. It panics if the version is already taken.
+func RegisterMigration(version int, name string, migrate MigrateFunc) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+
+	if version <= BlockChainVersion {
+		panic(fmt.Sprintf("migration %d (%s) not above schema version %d", version, name, BlockChainVersion))
+	}
+	if entry, exist := migrations[version]; exist {
+		panic(fmt.Sprintf("migration %d already registered as %s", version, entry.name))
+	}
+	migrations[version] = &migrationEntry{version: version, name: name, migrate: migrate}
+}
+
+// SchemaVersion returns the schema version of the databases written by this
+// release, the one of the last migration registered.
+func SchemaVersion() int {
+	migrationsMu.RLock()
+	defer migrationsMu.RUnlock()
+
+	version := BlockChainVersion
+	for v := range migrations {
+		if v > version {
+			version = v
+		}
+	}
+	return version
+}
+
+// MigrationResult reports a migration run on a database.
+type MigrationResult struct {
+	Version  int
+	Name     string
+	Resumed  bool          // Whether the migration resumed an interrupted run
+	Walked   uint64        // Number of entries walked
+	Migrated uint64        // Number of entries migrated
+	Writes   uint64        // Number of entries written, or to be written by a dry run
+	Deletes  uint64        // Number of entries deleted, or to be deleted by a dry run
+	Elapsed  time.Duration // Time spent migrating
+}
+
+// Migrator runs a migration on a database, recording its progress.
+type Migrator struct {
+	Version int
+	Name    string
+	DryRun  bool
+
+	db       database.IIterableDatabase
+	counter  *migrationDB
+	resume   []byte // Key the interrupted run stopped at
+	walked   uint64
+	migrated uint64
+	reported time.Time
+}
+
+// DB returns the database to migrate, dropping the writes of a dry run.
+func (m *Migrator) DB() database.IIterableDatabase {
+	return m.counter
+}
+
+// Walk calls fn on the entries whose key starts with prefix, in key order,
+// starting from the key an interrupted run stopped at. fn tells whether it
+// migrated the entry. The progress is recorded every migrationCheckpoint
+// entries, and the walk stops at the first error of fn.
+func (m *Migrator) Walk(prefix []byte, fn func(key, value []byte) (bool, error)) error {
+	var failed error
+	err := m.db.WalkFrom(prefix, m.resume, func(key, value []byte) bool {
+		migrated, err := fn(key, value)
+		if err != nil {
+			failed = fmt.Errorf("migrate key %x: %v", key, err)
+			return false
+		}
+		m.walked++
+		if migrated {
+			m.migrated++
+		}
+		if m.walked%migrationCheckpoint == 0 {
+			if failed = m.checkpoint(key); failed != nil {
+				return false
+			}
+		}
+		if time.Since(m.reported) > statsReportLimit {
+			logger.Info("Migrating database", "version", m.Version, "walked", m.walked, "migrated", m.migrated, "key", fmt.Sprintf("%x", key))
+			m.reported = time.Now()
+		}
+		return true
+	})
+	if failed != nil {
+		return failed
+	}
+	return err
+}
+
+// checkpoint records the key a later run resumes from.
+func (m *Migrator) checkpoint(key []byte) error {
+	if m.DryRun {
+		return nil
+	}
+	return m.db.Put(migrationKey(m.Version), key)
+}
+
+func migrationKey(version int) []byte {
+	key := make([]byte, len(migrationPrefix)+8)
+	copy(key, migrationPrefix)
+	binary.BigEndian.PutUint64(key[len(migrationPrefix):], uint64(version))
+	return key
+}
+
+// PendingMigrations returns the versions and names of the migrations the
+// database needs, in the order they run.
+func PendingMigrations(db database.IIterableDatabase) ([]int, []string, error) {
+	from, fresh, err := databaseSchema(db)
+	if err != nil || fresh {
+		return nil, nil, err
+	}
+	entries, err := migrationsFrom(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		versions []int
+		names    []string
+	)
+	for _, entry := range entries {
+		versions = append(versions, entry.version)
+		names = append(names, entry.name)
+	}
+	return versions, names, nil
+}
+
+// databaseSchema returns the schema version of the database and whether it
+// holds no chain yet.
+func databaseSchema(db database.IIterableDatabase) (int, bool, error) {
+	version := GetBlockChainVersion(db)
+	if version == 0 {
+		if GetHeadHeaderHash(db) == (types.Hash{}) {
+			return 0, true, nil
+		}
+		// Databases were not versioned before BlockChainVersion
+		version = BlockChainVersion
+	}
+	switch {
+	case version < BlockChainVersion:
+		return version, false, fmt.Errorf("%v: version %d, oldest supported %d", ErrSchemaTooOld, version, BlockChainVersion)
+	case version > SchemaVersion():
+		return version, false, fmt.Errorf("%v: version %d, newest supported %d", ErrSchemaTooNew, version, SchemaVersion())
+	}
+	return version, false, nil
+}
+
+// migrationsFrom returns the migrations from the schema version on, in order.
+func migrationsFrom(version int) ([]*migrationEntry, error) {
+	migrationsMu.RLock()
+	defer migrationsMu.RUnlock()
+
+	var entries []*migrationEntry
+	for _, entry := range migrations {
+		if entry.version > version {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].version < entries[j].version })
+	for i, entry := range entries {
+		if entry.version != version+i+1 {
+			return nil, fmt.Errorf("migration to schema version %d not registered", version+i+1)
+		}
+	}
+	return entries, nil
+}
+
+// MigrateDatabase runs the migrations the database needs in order, recording
+// the schema version reached after each one. An interrupted migration resumes
+// from its last recorded progress. A database holding no chain yet gets the
+// latest schema version. With dryRun set, the migrations run without writing
+// and report the changes they would make.
+//
+// The database must not be written to while migrating.
+func MigrateDatabase(db database.IIterableDatabase, dryRun bool) ([]MigrationResult, error) {
+	from, fresh, err := databaseSchema(db)
+	if err != nil {
+		return nil, err
+	}
+	if fresh {
+		if dryRun {
+			return nil, nil
+		}
+		return nil, WriteBlockChainVersion(db, SchemaVersion())
+	}
+	entries, err := migrationsFrom(from)
+	if err != nil {
+		return nil, err
+	}
+	var results []MigrationResult
+	for _, entry := range entries {
+		m := &Migrator{
+			Version:  entry.version,
+			Name:     entry.name,
+			DryRun:   dryRun,
+			db:       db,
+			counter:  &migrationDB{IIterableDatabase: db, dryRun: dryRun},
+			reported: time.Now(),
+		}
+		if resume, _ := db.Get(migrationKey(entry.version)); len(resume) > 0 {
+			m.resume = resume
+		}
+		logger.Info("Migrating database", "version", entry.version, "name", entry.name, "resume", fmt.Sprintf("%x", m.resume), "dryrun", dryRun)
+
+		start := time.Now()
+		if err := entry.migrate(m); err != nil {
+			return results, fmt.Errorf("migration %d (%s): %v", entry.version, entry.name, err)
+		}
+		result := MigrationResult{
+			Version:  entry.version,
+			Name:     entry.name,
+			Resumed:  m.resume != nil,
+			Walked:   m.walked,
+			Migrated: m.migrated,
+			Writes:   m.counter.writes,
+			Deletes:  m.counter.deletes,
+			Elapsed:  time.Since(start),
+		}
+		results = append(results, result)
+		if dryRun {
+			logger.Info("Dry run of database migration", "version", entry.version, "migrated", result.Migrated, "writes", result.Writes, "deletes", result.Deletes)
+			continue
+		}
+		// Record the version first, a progress left behind is ignored
+		if err := WriteBlockChainVersion(db, entry.version); err != nil {
+			return results, err
+		}
+		if err := db.Delete(migrationKey(entry.version)); err != nil {
+			return results, err
+		}
+		logger.Info("Migrated database", "version", entry.version, "migrated", result.Migrated, "elapsed", common.PrettyDuration(result.Elapsed))
+	}
+	return results, nil
+}
+
+// migrationDB counts the writes of a migration, dropping them on a dry run.
+type migrationDB struct {
+	database.IIterableDatabase
+	dryRun  bool
+	writes  uint64
+	deletes uint64
+}
+
+func (db *migrationDB) Put(key []byte, value []byte) error {
+	db.writes++
+	if db.dryRun {
+		return nil
+	}
+	return db.IIterableDatabase.Put(key, value)
+}
+
+func (db *migrationDB) Delete(key []byte) error {
+	db.deletes++
+	if db.dryRun {
+		return nil
+	}
+	return db.IIterableDatabase.Delete(key)
+}
+
+func (db *migrationDB) NewBatch() database.IBatch {
+	return &migrationBatch{IBatch: db.IIterableDatabase.NewBatch(), db: db}
+}
+
+// migrationBatch counts the writes of a migration batch, dropping them on a
+// dry run.
+type migrationBatch struct {
+	database.IBatch
+	db     *migrationDB
+	writes uint64
+}
+
+func (b *migrationBatch) Put(key []byte, value []byte) error {
+	b.writes++
+	if b.db.dryRun {
+		return nil
+	}
+	return b.IBatch.Put(key, value)
+}
+
+func (b *migrationBatch) Write() error {
+	b.db.writes += b.writes
+	b.writes = 0
+	if b.db.dryRun {
+		return nil
+	}
+	return b.IBatch.Write()
+}
+
+func (b *migrationBatch) Reset() {
+	b.writes = 0
+	b.IBatch.Reset()
+}
@@ -0,0 +1,275 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: migrate_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/utils/database"
+)
+
+// withMigrations runs fn with the registered migrations replaced by none, for
+// the test to register its own.
+func withMigrations(fn func()) {
+	migrationsMu.Lock()
+	saved := migrations
+	migrations = make(map[int]*migrationEntry)
+	migrationsMu.Unlock()
+
+	defer func() {
+		migrationsMu.Lock()
+		migrations = saved
+		migrationsMu.Unlock()
+	}()
+	fn()
+}
+
+// newMigrationDB creates a database holding a chain of the schema version, with
+// the given number of entries for the test migrations to walk.
+func newMigrationDB(t *testing.T, version int, entries int) *database.MemDatabase {
+	db, _ := database.OpenMemDB()
+	if err := WriteHeadHeaderHash(db, types.Hash{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteBlockChainVersion(db, version); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < entries; i++ {
+		if err := db.Put([]byte(fmt.Sprintf("old-%08d", i)), []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Put([]byte("stale"), []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// copyMigration copies the entries of the old prefix to the new one, through a
+// batch every other entry, then deletes the stale marker. It fails on the entry
+// number fail if not negative.
+func copyMigration(fail int) MigrateFunc {
+	return func(m *Migrator) error {
+		db := m.DB()
+		batch := db.NewBatch()
+		err := m.Walk([]byte("old-"), func(key, value []byte) (bool, error) {
+			var i int
+			fmt.Sscanf(string(key), "old-%08d", &i)
+			if i == fail {
+				return false, errors.New("interrupted")
+			}
+			newKey := append([]byte("new-"), key[4:]...)
+			if i%2 == 1 {
+				return true, db.Put(newKey, value)
+			}
+			if err := batch.Put(newKey, value); err != nil {
+				return false, err
+			}
+			if err := batch.Write(); err != nil {
+				return false, err
+			}
+			batch.Reset()
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		return db.Delete([]byte("stale"))
+	}
+}
+
+// dumpDB returns a copy of the entries of the database.
+func dumpDB(db *database.MemDatabase) map[string]string {
+	dump := make(map[string]string)
+	db.Walk(nil, func(key, value []byte) bool {
+		dump[string(key)] = string(value)
+		return true
+	})
+	return dump
+}
+
+// Tests that migrations run in version order whatever their registration order,
+// each recording the version it reached.
+func TestMigrationOrder(t *testing.T) {
+	withMigrations(func() {
+		var ran []int
+		migration := func(version int) MigrateFunc {
+			return func(m *Migrator) error {
+				if have := GetBlockChainVersion(m.DB()); have != version-1 {
+					return fmt.Errorf("schema version %d before migration %d", have, version)
+				}
+				ran = append(ran, version)
+				return nil
+			}
+		}
+		RegisterMigration(BlockChainVersion+2, "second", migration(BlockChainVersion+2))
+		RegisterMigration(BlockChainVersion+1, "first", migration(BlockChainVersion+1))
+
+		if SchemaVersion() != BlockChainVersion+2 {
+			t.Fatalf("schema version mismatch: have %d, want %d", SchemaVersion(), BlockChainVersion+2)
+		}
+		db := newMigrationDB(t, BlockChainVersion, 0)
+		versions, names, err := PendingMigrations(db)
+		if err != nil || !reflect.DeepEqual(versions, []int{BlockChainVersion + 1, BlockChainVersion + 2}) || !reflect.DeepEqual(names, []string{"first", "second"}) {
+			t.Fatalf("pending migrations mismatch: have %v %v (%v)", versions, names, err)
+		}
+		results, err := MigrateDatabase(db, false)
+		if err != nil {
+			t.Fatalf("failed to migrate: %v", err)
+		}
+		if want := []int{BlockChainVersion + 1, BlockChainVersion + 2}; !reflect.DeepEqual(ran, want) || len(results) != 2 {
+			t.Fatalf("migration order mismatch: have %v, want %v", ran, want)
+		}
+		if version := GetBlockChainVersion(db); version != BlockChainVersion+2 {
+			t.Fatalf("schema version after migrating: have %d, want %d", version, BlockChainVersion+2)
+		}
+		if versions, _, err := PendingMigrations(db); err != nil || len(versions) != 0 {
+			t.Fatalf("migrations pending after migrating: %v (%v)", versions, err)
+		}
+
+		// A gap in the migrations refuses to migrate
+		RegisterMigration(BlockChainVersion+4, "gap", migration(BlockChainVersion+4))
+		if _, err := MigrateDatabase(db, false); err == nil {
+			t.Fatalf("migrated across a missing migration")
+		}
+		if version := GetBlockChainVersion(db); version != BlockChainVersion+2 {
+			t.Fatalf("schema version moved by a refused migration: %d", version)
+		}
+	})
+}
+
+// Tests that an interrupted migration resumes from its last checkpoint.
+func TestMigrationResume(t *testing.T) {
+	const entries, fail = 2*migrationCheckpoint + 500, migrationCheckpoint + 500
+
+	withMigrations(func() {
+		db := newMigrationDB(t, BlockChainVersion, entries)
+
+		RegisterMigration(BlockChainVersion+1, "copy", copyMigration(fail))
+		if _, err := MigrateDatabase(db, false); err == nil || !strings.Contains(err.Error(), "interrupted") {
+			t.Fatalf("interrupted migration error mismatch: have %v", err)
+		}
+		if version := GetBlockChainVersion(db); version != BlockChainVersion {
+			t.Fatalf("schema version moved by an interrupted migration: %d", version)
+		}
+		checkpoint, _ := db.Get(migrationKey(BlockChainVersion + 1))
+		if want := fmt.Sprintf("old-%08d", migrationCheckpoint-1); string(checkpoint) != want {
+			t.Fatalf("checkpoint mismatch: have %q, want %q", checkpoint, want)
+		}
+
+		migrations[BlockChainVersion+1].migrate = copyMigration(-1)
+		results, err := MigrateDatabase(db, false)
+		if err != nil {
+			t.Fatalf("failed to resume migration: %v", err)
+		}
+		// The walk resumes at the checkpoint key, skipping the entries before it
+		if len(results) != 1 || !results[0].Resumed || results[0].Walked != entries-migrationCheckpoint+1 {
+			t.Fatalf("resumed migration mismatch: %+v", results)
+		}
+		if version := GetBlockChainVersion(db); version != BlockChainVersion+1 {
+			t.Fatalf("schema version after migrating: have %d, want %d", version, BlockChainVersion+1)
+		}
+		if checkpoint, _ := db.Get(migrationKey(BlockChainVersion + 1)); len(checkpoint) != 0 {
+			t.Fatalf("checkpoint left after migrating: %q", checkpoint)
+		}
+		if has, _ := db.Has([]byte("stale")); has {
+			t.Fatalf("stale marker not deleted")
+		}
+		for i := 0; i < entries; i++ {
+			if value, _ := db.Get([]byte(fmt.Sprintf("new-%08d", i))); !bytes.Equal(value, []byte{byte(i)}) {
+				t.Fatalf("entry %d migrated to %x, want %x", i, value, []byte{byte(i)})
+			}
+		}
+	})
+}
+
+// Tests that a dry run reports the changes of the migrations without writing
+// anything, the schema version and the checkpoints included.
+func TestMigrationDryRun(t *testing.T) {
+	const entries = migrationCheckpoint + 10
+
+	withMigrations(func() {
+		RegisterMigration(BlockChainVersion+1, "copy", copyMigration(-1))
+
+		db := newMigrationDB(t, BlockChainVersion, entries)
+		before := dumpDB(db)
+		results, err := MigrateDatabase(db, true)
+		if err != nil {
+			t.Fatalf("failed to dry run: %v", err)
+		}
+		if len(results) != 1 || results[0].Migrated != entries || results[0].Writes != entries || results[0].Deletes != 1 {
+			t.Fatalf("dry run results mismatch: %+v", results)
+		}
+		if after := dumpDB(db); !reflect.DeepEqual(after, before) {
+			t.Fatalf("dry run wrote %d entries, want none", len(after)-len(before))
+		}
+
+		// A fresh database gets no version either
+		fresh, _ := database.OpenMemDB()
+		if _, err := MigrateDatabase(fresh, true); err != nil || fresh.Len() != 0 {
+			t.Fatalf("dry run on a fresh database wrote %d entries (%v)", fresh.Len(), err)
+		}
+		if _, err := MigrateDatabase(fresh, false); err != nil || GetBlockChainVersion(fresh) != SchemaVersion() {
+			t.Fatalf("fresh database version mismatch: have %d (%v), want %d", GetBlockChainVersion(fresh), err, SchemaVersion())
+		}
+	})
+}
+
+// Tests that databases of schemas no migration starts from or newer than the
+// supported one are refused.
+func TestMigrationSchemaRange(t *testing.T) {
+	withMigrations(func() {
+		RegisterMigration(BlockChainVersion+1, "noop", func(*Migrator) error { return nil })
+
+		tests := []struct {
+			version int
+			err     error
+		}{
+			{BlockChainVersion - 1, ErrSchemaTooOld},
+			{SchemaVersion() + 1, ErrSchemaTooNew},
+		}
+		for _, tt := range tests {
+			db := newMigrationDB(t, tt.version, 0)
+			if _, _, err := PendingMigrations(db); err == nil || !strings.HasPrefix(err.Error(), tt.err.Error()) {
+				t.Errorf("version %d: pending migrations error mismatch: have %v, want %v", tt.version, err, tt.err)
+			}
+			if _, err := MigrateDatabase(db, false); err == nil || !strings.HasPrefix(err.Error(), tt.err.Error()) {
+				t.Errorf("version %d: migration error mismatch: have %v, want %v", tt.version, err, tt.err)
+			}
+			if version := GetBlockChainVersion(db); version != tt.version {
+				t.Errorf("version %d: schema version moved to %d", tt.version, version)
+			}
+		}
+		// Registering a migration from below the oldest schema panics
+		defer func() {
+			if recover() == nil {
+				t.Errorf("registered a migration to schema version %d", BlockChainVersion)
+			}
+		}()
+		RegisterMigration(BlockChainVersion, "too old", func(*Migrator) error { return nil })
+	})
+}
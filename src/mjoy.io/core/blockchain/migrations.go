@@ -0,0 +1,74 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: migrations.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/tinylib/msgp/msgp"
+	"mjoy.io/common/types"
+)
+
+// deduplicateDataKey marks the databases deduplicated before the migrations.
+var deduplicateDataKey = []byte("dbUpgrade_20170714deduplicateData")
+
+// migrateDeduplicateData converts the old transaction metadata entries into
+// lookup entries, deleting the transactions and receipts stored apart from
+// their blocks.
+func migrateDeduplicateData(m *Migrator) error {
+	db := m.DB()
+	if data, _ := db.Get(deduplicateDataKey); len(data) > 0 && data[0] == 42 {
+		return db.Delete(deduplicateDataKey)
+	}
+	err := m.Walk(nil, func(key, value []byte) (bool, error) {
+		// Skip any entries that don't look like old transaction meta entries (<hash>0x01)
+		if len(key) != types.HashLength+1 || !bytes.HasSuffix(key, oldTxMetaSuffix) {
+			return false, nil
+		}
+		// Skip any entries that don't contain metadata (name clash between <hash>0x01 and <some-prefix><hash>)
+		var entry TxLookupEntry
+		if err := msgp.Decode(bytes.NewReader(value), &entry); err != nil {
+			return false, nil
+		}
+		hash := types.BytesToHash(key[:types.HashLength])
+		if hash[0] == lookupPrefix[0] {
+			// Potential clash, the old hash must point to a live transaction
+			if tx, _, _, _ := GetTransaction(db, hash); tx == nil || tx.Hash() != hash {
+				return false, nil
+			}
+		}
+		// Convert the old metadata to a new lookup entry, delete duplicate data
+		if err := db.Put(append(lookupPrefix, hash[:]...), value); err != nil {
+			return false, err
+		}
+		if err := db.Delete(hash[:]); err != nil {
+			return false, err
+		}
+		if err := db.Delete(append(oldReceiptsPrefix, hash[:]...)); err != nil {
+			return false, err
+		}
+		return true, db.Delete(append([]byte(nil), key...))
+	})
+	if err != nil {
+		return err
+	}
+	return db.Delete(deduplicateDataKey)
+}
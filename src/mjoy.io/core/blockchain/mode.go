@@ -0,0 +1,68 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: mode.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import "fmt"
+
+// NodeMode tells which states a node keeps on disk. It is recorded in the
+// database, as a pruned database can not serve an archive node.
+type NodeMode byte
+
+const (
+	UnknownMode NodeMode = iota // Database written before the mode was recorded
+	FullMode                    // Keep the recent states only, pruning the old ones
+	ArchiveMode                 // Keep the state of every block
+)
+
+// String implements the stringer interface.
+func (mode NodeMode) String() string {
+	switch mode {
+	case FullMode:
+		return "full"
+	case ArchiveMode:
+		return "archive"
+	default:
+		return "unknown"
+	}
+}
+
+func (mode NodeMode) MarshalText() ([]byte, error) {
+	switch mode {
+	case FullMode:
+		return []byte("full"), nil
+	case ArchiveMode:
+		return []byte("archive"), nil
+	default:
+		return nil, fmt.Errorf("unknown node mode %d", mode)
+	}
+}
+
+func (mode *NodeMode) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "full":
+		*mode = FullMode
+	case "archive":
+		*mode = ArchiveMode
+	default:
+		return fmt.Errorf(`unknown node mode %q, want "full" or "archive"`, text)
+	}
+	return nil
+}
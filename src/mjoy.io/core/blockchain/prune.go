@@ -0,0 +1,146 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: prune.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mjoy.io/common"
+	"mjoy.io/core/state"
+	"mjoy.io/utils/database"
+)
+
+var (
+	// ErrArchivePruned is returned when an archive node is started on a
+	// database missing old states.
+	ErrArchivePruned = errors.New("database misses old states, resync it from scratch to run an archive node")
+
+	// ErrPruneArchive is returned when pruning the database of an archive node.
+	ErrPruneArchive = errors.New("database belongs to an archive node, run the node in full mode once to prune it")
+)
+
+// PruneState deletes from db the state not reachable from the genesis state or
+// the states of the last keep canonical blocks, the head one included. It
+// returns the number of state entries kept and deleted.
+//
+// The database must not be written to while pruning.
+func PruneState(db database.IIterableDatabase, keep uint64) (int, int, error) {
+	if GetNodeMode(db) == ArchiveMode {
+		return 0, 0, ErrPruneArchive
+	}
+	headHash := GetHeadBlockHash(db)
+	head := GetBlockNumber(db, headHash)
+	if head == missingNumber {
+		return 0, 0, ErrNoGenesis
+	}
+	pruner := state.NewPruner(db)
+
+	numbers := []uint64{0}
+	for number := head; number > 0 && head-number < keep; number-- {
+		numbers = append(numbers, number)
+	}
+	for _, number := range numbers {
+		header := GetHeader(db, GetCanonicalHash(db, number), number)
+		if header == nil {
+			if number == 0 {
+				return 0, 0, ErrNoGenesis
+			}
+			continue
+		}
+		// States below the head may be gone already, keep what is left
+		if err := pruner.Mark(header.StateRootHash); err != nil && number == head {
+			return 0, 0, err
+		}
+	}
+	// Record the pruning first, an interrupted sweep leaves states missing too
+	if err := WriteLastPruned(db, head); err != nil {
+		return 0, 0, err
+	}
+	deleted, err := pruner.Sweep()
+	return pruner.Marked(), deleted, err
+}
+
+// checkNodeMode records the mode of the node in the database, refusing to run
+// an archive node on a database missing the state of a canonical block.
+func (bc *BlockChain) checkNodeMode() error {
+	mode := FullMode
+	if bc.cacheConfig.Disabled {
+		mode = ArchiveMode
+	}
+	recorded := GetNodeMode(bc.chainDb)
+	if mode == ArchiveMode && recorded != ArchiveMode {
+		if number, pruned := GetLastPruned(bc.chainDb); pruned {
+			return fmt.Errorf("%v: pruned at block #%d", ErrArchivePruned, number)
+		}
+		// Fast synced databases miss the states below the pivot block
+		for number := uint64(0); number <= bc.currentBlock.NumberU64(); number++ {
+			header := bc.GetHeaderByNumber(number)
+			if header == nil || !bc.HasState(header.StateRootHash) {
+				return fmt.Errorf("%v: state of block #%d missing", ErrArchivePruned, number)
+			}
+		}
+	}
+	if mode != recorded {
+		logger.Info("Recording node mode", "mode", mode, "recorded", recorded)
+		return WriteNodeMode(bc.chainDb, mode)
+	}
+	return nil
+}
+
+// pruneState prunes the chain database once PruneInterval blocks were written
+// since the last pruning, keeping the last TriesKept states.
+//
+// The sweep must not race a state write, which may store again a node about to
+// be deleted, so both chain locks are held for the whole pruning: block import
+// and head changes stall for as long as walking the kept states and iterating
+// the database take. The pruning interval is kept large to make this rare.
+func (bc *BlockChain) pruneState() {
+	if bc.cacheConfig.Disabled {
+		return
+	}
+	db, ok := bc.chainDb.(database.IIterableDatabase)
+	if !ok {
+		return
+	}
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	head := bc.currentBlock.NumberU64()
+	if head < bc.lastPruned+bc.cacheConfig.PruneInterval {
+		return
+	}
+	// The state being fast synced is not reachable from the head yet
+	if bc.currentFastBlock.NumberU64() > head {
+		return
+	}
+	start := time.Now()
+	kept, deleted, err := PruneState(db, bc.cacheConfig.TriesKept)
+	if err != nil {
+		logger.Error("Failed to prune state", "err", err)
+		return
+	}
+	bc.lastPruned = head
+	logger.Info("Pruned state", "number", head, "kept", kept, "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+}
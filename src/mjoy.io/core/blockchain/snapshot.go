@@ -0,0 +1,239 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: snapshot.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"mjoy.io/common"
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/state"
+	"mjoy.io/params"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/database"
+)
+
+// snapshotMagic starts every state snapshot file.
+const snapshotMagic = "MJOYSNP\x00"
+
+// ErrSnapshotUntrusted is returned by ImportSnapshot if the snapshot block is
+// not the trusted one.
+var ErrSnapshotUntrusted = errors.New("snapshot block not trusted")
+
+// ExportSnapshot writes the state of the canonical block number to w, as a
+// state snapshot file ImportSnapshot bootstraps a database from. It returns the
+// number of state entries written. The consensus engines with epochs verify the
+// blocks from the last epoch block on, so only those can be exported.
+//
+// The file starts with an ExportHeader of range number to number, followed by
+// the block and the state entries, each one an item holding the hash the entry
+// is stored under and its blob, and ends with an empty item. The items are
+// written like the blocks of a chain export file.
+func (bc *BlockChain) ExportSnapshot(w io.Writer, number uint64) (int, error) {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	if err := checkSnapshotEpoch(bc.config, number); err != nil {
+		return 0, err
+	}
+	blk := bc.GetBlockByNumber(number)
+	if blk == nil {
+		return 0, fmt.Errorf("block #%d not found", number)
+	}
+	if !bc.HasState(blk.Root()) {
+		return 0, fmt.Errorf("state of block #%d missing", number)
+	}
+	logger.Info("Exporting state snapshot", "number", number, "hash", blk.Hash().String(), "root", blk.Root().String())
+
+	header := &ExportHeader{
+		Version: ExportVersion,
+		ChainId: chainId(bc.config),
+		Genesis: bc.genesisBlock.Hash(),
+		First:   number,
+		Last:    number,
+	}
+	if err := header.write(w, snapshotMagic); err != nil {
+		return 0, err
+	}
+	blob, err := blk.MarshalMsg(nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeExportItem(w, blob); err != nil {
+		return 0, err
+	}
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		entries  int
+	)
+	err = state.WalkState(bc.chainDb, blk.Root(), func(kind state.StateEntryKind, hash types.Hash, value []byte) error {
+		blob = append(append(blob[:0], hash[:]...), value...)
+		if err := writeExportItem(w, blob); err != nil {
+			return err
+		}
+		entries++
+		if time.Since(reported) >= statsReportLimit {
+			logger.Info("Exporting state snapshot", "entries", entries, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+		return nil
+	})
+	if err != nil {
+		return entries, err
+	}
+	return entries, writeExportItem(w, nil)
+}
+
+// ImportSnapshot bootstraps db, holding the genesis block alone, from the state
+// snapshot file read from r. The block must be the trusted one, whose hash is
+// taken from a source other than the file, as the blocks before it are not
+// there to verify it. The state entries are checked against the hash they are
+// stored under and the state against the root of the block, which then becomes
+// the head. The blocks below it stay missing, the chain carries on from its
+// height.
+//
+// ImportSnapshot returns the head block and the number of state entries
+// written. An import failing midway leaves the head at the genesis block and
+// may be run again.
+func ImportSnapshot(db database.IDatabase, r io.Reader, trusted types.Hash) (*block.Block, int, error) {
+	if trusted == (types.Hash{}) {
+		return nil, 0, ErrSnapshotUntrusted
+	}
+	var header ExportHeader
+	if err := header.read(r, snapshotMagic); err != nil {
+		return nil, 0, err
+	}
+	genesis := GetCanonicalHash(db, 0)
+	if genesis == (types.Hash{}) {
+		return nil, 0, ErrNoGenesis
+	}
+	if header.Genesis != genesis {
+		return nil, 0, fmt.Errorf("snapshot of genesis %x, want genesis %x", header.Genesis[:8], genesis[:8])
+	}
+	config, err := GetChainConfig(db, genesis)
+	if err != nil {
+		return nil, 0, err
+	}
+	if header.ChainId != chainId(config) {
+		return nil, 0, fmt.Errorf("snapshot of chain %d, want chain %d", header.ChainId, chainId(config))
+	}
+	if err := checkSnapshotEpoch(config, header.First); err != nil {
+		return nil, 0, err
+	}
+	if head := GetHeadBlockHash(db); head != genesis {
+		return nil, 0, fmt.Errorf("database holds block #%d already", GetBlockNumber(db, head))
+	}
+
+	blob, err := readExportItem(r, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("block #%d: %v", header.First, err)
+	}
+	blk := new(block.Block)
+	if _, err := blk.UnmarshalMsg(blob); err != nil {
+		return nil, 0, fmt.Errorf("block #%d: failed to parse: %v", header.First, err)
+	}
+	if blk.NumberU64() != header.First || blk.NumberU64() == 0 {
+		return nil, 0, fmt.Errorf("block #%d: found block #%d instead", header.First, blk.NumberU64())
+	}
+	if blk.Hash() != trusted {
+		return nil, 0, fmt.Errorf("%v: block #%d %x, want %x", ErrSnapshotUntrusted, blk.NumberU64(), blk.Hash(), trusted)
+	}
+	logger.Info("Importing state snapshot", "number", blk.NumberU64(), "hash", blk.Hash().String(), "root", blk.Root().String())
+
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		entries  int
+		batch    = db.NewBatch()
+	)
+	for {
+		if blob, err = readExportItem(r, blob); err != nil {
+			return nil, entries, fmt.Errorf("state entry %d: %v", entries, err)
+		}
+		if len(blob) == 0 {
+			break
+		}
+		if len(blob) <= types.HashLength {
+			return nil, entries, fmt.Errorf("state entry %d: short item", entries)
+		}
+		hash, value := blob[:types.HashLength], blob[types.HashLength:]
+		if crypto.Keccak256Hash(value) != types.BytesToHash(hash) {
+			return nil, entries, fmt.Errorf("state entry %x: hash mismatch", hash)
+		}
+		if err := batch.Put(hash, value); err != nil {
+			return nil, entries, err
+		}
+		entries++
+		if batch.ValueSize() >= IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return nil, entries, err
+			}
+			batch.Reset()
+		}
+		if time.Since(reported) >= statsReportLimit {
+			logger.Info("Importing state snapshot", "entries", entries, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return nil, entries, err
+	}
+	if err := state.WalkState(db, blk.Root(), func(state.StateEntryKind, types.Hash, []byte) error { return nil }); err != nil {
+		return nil, entries, fmt.Errorf("incomplete state of block #%d: %v", blk.NumberU64(), err)
+	}
+
+	// Move the head last, so that a failed import can be run again
+	if err := WriteSnapshotBase(db, blk.NumberU64()); err != nil {
+		return nil, entries, err
+	}
+	if err := WriteBlock(db, blk); err != nil {
+		return nil, entries, err
+	}
+	if err := WriteCanonicalHash(db, blk.Hash(), blk.NumberU64()); err != nil {
+		return nil, entries, err
+	}
+	if err := WriteHeadHeaderHash(db, blk.Hash()); err != nil {
+		return nil, entries, err
+	}
+	if err := WriteHeadFastBlockHash(db, blk.Hash()); err != nil {
+		return nil, entries, err
+	}
+	if err := WriteHeadBlockHash(db, blk.Hash()); err != nil {
+		return nil, entries, err
+	}
+	logger.Info("Imported state snapshot", "number", blk.NumberU64(), "entries", entries, "elapsed", common.PrettyDuration(time.Since(start)))
+	return blk, entries, nil
+}
+
+// checkSnapshotEpoch refuses snapshots of blocks other than the epoch ones of the
+// consensus engines with epochs, which verify the blocks after the snapshot
+// block from the producers it lists.
+func checkSnapshotEpoch(config *params.ChainConfig, number uint64) error {
+	if epoch := config.Epoch(); epoch != 0 && number%epoch != 0 {
+		return fmt.Errorf("block #%d is not an epoch block, the last one is #%d", number, number-number%epoch)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: verify.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package blockchain
+
+import (
+	"fmt"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/state"
+	"mjoy.io/core/stateprocessor"
+	"mjoy.io/core/transaction"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/database"
+)
+
+// Reexecution is the outcome of executing a block again from the state of its
+// parent.
+type Reexecution struct {
+	Block    *block.Block
+	Receipts transaction.Receipts
+	Root     types.Hash              // State root after the block
+	Writes   [][]state.StorageWrite  // Storage writes of every transaction, then of the block finalisation
+	Cache    *stateprocessor.DbCache // Contract values written
+}
+
+// SlotValue is a contract value held by a storage slot.
+type SlotValue struct {
+	Hash  types.Hash // Hash the value is stored under, zero if none
+	Value []byte     // Value, nil if not found
+}
+
+// SlotDiff sets a storage write of a re-executed block against the state its
+// header records.
+type SlotDiff struct {
+	Account  types.Address
+	Slot     types.Hash // Storage slot, the hash of the contract address and key
+	Key      []byte     // Contract key of the slot, nil if unknown
+	Prev     SlotValue  // Value before the write
+	Value    SlotValue  // Value written by the re-execution
+	Recorded SlotValue  // Value in the recorded state
+	Final    bool       // Whether no later write of the block overwrites it
+	Match    bool       // Whether the recorded value is the one written, for final writes
+}
+
+// ReexecuteBlock executes the canonical block number again from the state of
+// its parent, which must be available.
+func (bc *BlockChain) ReexecuteBlock(number uint64) (*Reexecution, error) {
+	if number == 0 {
+		return nil, fmt.Errorf("genesis block can't be executed")
+	}
+	blk := bc.GetBlockByNumber(number)
+	if blk == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	parent := bc.GetBlock(blk.ParentHash(), number-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent of block #%d not found", number)
+	}
+	statedb, err := state.New(parent.Root(), bc.stateCache)
+	if err != nil {
+		return nil, fmt.Errorf("state of block #%d missing: %v", number-1, err)
+	}
+	statedb.RecordStorageWrites()
+
+	processor := stateprocessor.NewStateProcessor(bc.config, bc, bc.engine)
+	cache, receipts, _, err := processor.Process(blk, statedb, bc.chainDb, bc.config)
+	if err != nil {
+		return nil, fmt.Errorf("block #%d: %v", number, err)
+	}
+	root := statedb.IntermediateRoot()
+
+	// The state is finalised once per transaction, failed ones included, then
+	// at least once more by the engine and the root computation
+	writes := statedb.StorageWrites()
+	txs := len(blk.Transactions())
+	if len(writes) < txs+1 {
+		return nil, fmt.Errorf("block #%d: state finalised %d times for %d transactions", number, len(writes), txs)
+	}
+	for len(writes) > txs+1 {
+		writes[txs] = append(writes[txs], writes[txs+1]...)
+		writes = append(writes[:txs+1], writes[txs+2:]...)
+	}
+	return &Reexecution{
+		Block:    blk,
+		Receipts: receipts,
+		Root:     root,
+		Writes:   writes,
+		Cache:    cache,
+	}, nil
+}
+
+// Mismatches lists the fields of the block header the re-execution departs
+// from, in the order block validation checks them.
+func (r *Reexecution) Mismatches() []string {
+	var (
+		header     = r.Block.Header()
+		mismatches []string
+	)
+	if bloom := transaction.CreateBloom(r.Receipts); bloom != header.Bloom {
+		mismatches = append(mismatches, "bloom: re-executed one differs from the header one")
+	}
+	if root := block.DeriveSha(r.Receipts); root != header.ReceiptRootHash {
+		mismatches = append(mismatches, fmt.Sprintf("receipt root: re-executed %x, header %x", root, header.ReceiptRootHash))
+	}
+	if r.Root != header.StateRootHash {
+		mismatches = append(mismatches, fmt.Sprintf("state root: re-executed %x, header %x", r.Root, header.StateRootHash))
+	}
+	return mismatches
+}
+
+// DiffWrites sets the storage writes of the re-execution against the state the
+// block header records, one slice per transaction then one for the block
+// finalisation. Only the final write of a slot is expected to match. It reports
+// whether the recorded state is available, the writes matching none if not.
+func (bc *BlockChain) DiffWrites(r *Reexecution) ([][]SlotDiff, bool) {
+	// The contract values written are in the cache, keyed by address and key
+	var (
+		keys   = make(map[types.Hash][]byte)
+		values = make(map[types.Hash][]byte)
+	)
+	for storageKey, value := range r.Cache.Cache {
+		keys[crypto.Keccak256Hash([]byte(storageKey))] = []byte(storageKey)[types.AddressLength:]
+		values[types.BytesToHash(value.Key)] = value.Val
+	}
+	value := func(hash types.Hash) SlotValue {
+		if hash == (types.Hash{}) {
+			return SlotValue{}
+		}
+		if val, ok := values[hash]; ok {
+			return SlotValue{hash, val}
+		}
+		val, _ := bc.chainDb.Get(hash[:])
+		return SlotValue{hash, val}
+	}
+	recorded, err := state.New(r.Block.Root(), bc.stateCache)
+
+	type slot struct {
+		account types.Address
+		key     types.Hash
+	}
+	final := make(map[slot]*SlotDiff)
+	diffs := make([][]SlotDiff, len(r.Writes))
+	for i, writes := range r.Writes {
+		diffs[i] = make([]SlotDiff, 0, len(writes))
+		for _, write := range writes {
+			diff := SlotDiff{
+				Account: write.Account,
+				Slot:    write.Key,
+				Key:     keys[write.Key],
+				Prev:    value(write.Prev),
+				Value:   value(write.Value),
+			}
+			if recorded != nil {
+				hash := recorded.GetState(write.Account, write.Key)
+				diff.Recorded, diff.Match = value(hash), hash == write.Value
+			}
+			diffs[i] = append(diffs[i], diff)
+			final[slot{write.Account, write.Key}] = &diffs[i][len(diffs[i])-1]
+		}
+	}
+	for _, diff := range final {
+		diff.Final = true
+	}
+	return diffs, err == nil
+}
+
+// WriteReexecution writes the receipts and transaction lookup entries of a
+// re-executed block, rebuilding them. The receipts of the blocks in the ancient
+// store are kept there.
+func (bc *BlockChain) WriteReexecution(r *Reexecution) error {
+	blk := r.Block
+	batch := bc.chainDb.NewBatch()
+	if db, ok := bc.chainDb.(database.IAncientReader); !ok || blk.NumberU64() >= db.Ancients() {
+		if err := WriteBlockReceipts(batch, blk.Hash(), blk.NumberU64(), r.Receipts); err != nil {
+			return err
+		}
+	}
+	if err := WriteTxLookupEntries(batch, blk); err != nil {
+		return err
+	}
+	return batch.Write()
+}
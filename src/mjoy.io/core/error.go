@@ -36,4 +36,8 @@ var (
 	// ErrNonceTooLow is returned if the nonce of a transaction is lower than the
 	// one present in the local chain.
 	ErrNonceTooLow = errors.New("nonce too low")
+
+	// ErrInsufficientFee is returned if the sender of a transaction can't pay
+	// the fee it declares.
+	ErrInsufficientFee = errors.New("insufficient funds for fee")
 )
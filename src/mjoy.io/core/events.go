@@ -29,6 +29,14 @@ import (
 // TxPreEvent is posted when a transaction enters the transaction pool.
 type TxPreEvent struct{ Tx *transaction.Transaction}
 
+// TxLifecycleEvent is posted when a transaction changes stage in the pool,
+// with the reason of the change if there is one.
+type TxLifecycleEvent struct {
+	Hash   types.Hash
+	Status string
+	Reason string
+}
+
 // PendingLogsEvent is posted pre producing and notifies of pending logs.
 type PendingLogsEvent struct {
 	Logs []*transaction.Log
@@ -57,3 +65,10 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *block.Block }
+
+// EquivocationEvent is posted when a producer is caught signing two headers at
+// the same height.
+type EquivocationEvent struct {
+	Producer types.Address
+	Evidence *block.Evidence
+}
@@ -29,7 +29,12 @@ import (
 	"mjoy.io/utils/database"
 	"mjoy.io/core/blockchain"
 	"mjoy.io/core/blockchain/block"
+	"mjoy.io/consensus/dpos"
+	"mjoy.io/consensus/poa"
 	"mjoy.io/core/state"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/utils/crypto"
+	"encoding/json"
 )
 
 var errGenesisNoConfig = errors.New("genesis has no chain configuration")
@@ -40,6 +45,7 @@ type Genesis struct {
 	Config     *params.ChainConfig `json:"config"`
 	Timestamp  uint64              `json:"timestamp"`
 	Alloc      GenesisAlloc        `json:"alloc"`
+	Balances   GenesisBalances     `json:"balances,omitempty"`
 
 	// These fields are used for consensus tests. Please don't use them
 	// in actual genesis blocks.
@@ -49,6 +55,10 @@ type Genesis struct {
 
 type GenesisAlloc map[types.Address]GenesisAccount
 
+// GenesisBalances are the balances of the accounts in the balance transfer
+// contract at the genesis block.
+type GenesisBalances map[types.Address]int
+
 // GenesisAccount is an account in the state of the genesis block.
 type GenesisAccount struct {
 	Code       []byte                      `json:"code,omitempty"`
@@ -144,6 +154,26 @@ func DefaultGenesisBlock() *Genesis {
 	}
 }
 
+// DevGenesisBlock returns the genesis block of a developer chain, funding the
+// given account.
+func DevGenesisBlock(faucet types.Address) *Genesis {
+	return &Genesis{
+		Config:     params.DevChainConfig,
+		Alloc: map[types.Address]GenesisAccount{
+			types.Address{}: {Code: []byte{1,2,3,4,5}},
+		},
+		Balances: GenesisBalances{faucet: params.DevBalance},
+	}
+}
+
+// balanceValue returns the storage slot of the balance of an account in the
+// balance transfer contract, with the hash of its value and the value itself,
+// laid out the way the state processor stores the contract results.
+func balanceValue(addr types.Address, amount int) (slot, hash types.Hash, val []byte) {
+	val, _ = json.Marshal(&balancetransfer.BalanceValue{Amount:amount})
+	slot = crypto.Keccak256Hash(append(balancetransfer.BalanceTransferAddress.Bytes(), addr[:]...))
+	return slot, crypto.Keccak256Hash(val), val
+}
 
 // ToBlock creates the block and state of a genesis specification.
 func (g *Genesis) ToBlock() (*block.Block, *state.StateDB) {
@@ -156,6 +186,10 @@ func (g *Genesis) ToBlock() (*block.Block, *state.StateDB) {
 			statedb.SetState(addr, key, value)
 		}
 	}
+	for addr, amount := range g.Balances {
+		slot, hash, _ := balanceValue(addr, amount)
+		statedb.SetState(balancetransfer.BalanceTransferAddress, slot, hash)
+	}
 	root := statedb.IntermediateRoot()
 	head := &block.Header{
 		Number:     		types.NewBigInt(*new(big.Int).SetUint64(g.Number)),
@@ -163,6 +197,12 @@ func (g *Genesis) ToBlock() (*block.Block, *state.StateDB) {
 		ParentHash: 		g.ParentHash,
 		StateRootHash: 		root,
 	}
+	if g.Config != nil && g.Config.Poa != nil {
+		head.ConsensusData, _ = poa.GenesisConsensusData(g.Config.Poa)
+	}
+	if g.Config != nil && g.Config.Dpos != nil {
+		head.ConsensusData, _ = dpos.GenesisConsensusData(g.Config.Dpos)
+	}
 
 	return block.NewBlock(head, nil, nil), statedb
 }
@@ -177,6 +217,12 @@ func (g *Genesis) Commit(db database.IDatabase) (*block.Block, error) {
 	if _, err := statedb.CommitTo(db, false); err != nil {
 		return nil, fmt.Errorf("cannot write state: %v", err)
 	}
+	for addr, amount := range g.Balances {
+		_, hash, val := balanceValue(addr, amount)
+		if err := db.Put(hash[:], val); err != nil {
+			return nil, fmt.Errorf("cannot write balance: %v", err)
+		}
+	}
 	if err := blockchain.WriteBlock(db, block); err != nil {
 		return nil, err
 	}
@@ -30,9 +30,12 @@ import (
 	"mjoy.io/common/types"
 	"mjoy.io/utils/database"
 	"mjoy.io/core/blockchain"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/sdk"
+	"mjoy.io/core/state"
 )
 
-var defaultGenesisHexHash = "a0623059b648f7b592166b5c165e5c0c79a018d11ef739c049d451884f8c23eb"
+var defaultGenesisHexHash = "1940df486667d80fab247a971e42a054bad501bef420735f868d8623c71bf44e"
 
 func TestDefaultGenesisBlock(t *testing.T) {
 	block, _ := DefaultGenesisBlock().ToBlock()
@@ -42,23 +45,56 @@ func TestDefaultGenesisBlock(t *testing.T) {
 	}
 }
 
+// Tests that a genesis without balances hashes as before balances existed.
+func TestGenesisWithoutBalances(t *testing.T) {
+	g := DefaultGenesisBlock()
+	g.Balances = GenesisBalances{}
+	block, _ := g.ToBlock()
+
+	if hexHash := util.Bytes2Hex(block.Hash().Bytes()); hexHash != defaultGenesisHexHash {
+		t.Errorf("genesis hash changed by empty balances, got %v", hexHash)
+	}
+}
+
+// Tests that the balances of a committed genesis are read by the balance
+// transfer contract.
+func TestGenesisBalances(t *testing.T) {
+	faucet, other := types.Address{1}, types.Address{2}
+	db, _ := database.OpenMemDB()
+	block := DevGenesisBlock(faucet).MustCommit(db)
+
+	statedb, err := state.New(block.Root(), state.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to open genesis state: %v", err)
+	}
+	sdkHandler := sdk.NewTmpStatusManager(db, statedb, types.Address{})
+	if balance := balancetransfer.BalanceOf(sdkHandler, faucet); balance != params.DevBalance {
+		t.Errorf("faucet balance mismatch: have %d, want %d", balance, params.DevBalance)
+	}
+	if balance := balancetransfer.BalanceOf(sdkHandler, other); balance != 0 {
+		t.Errorf("unfunded balance mismatch: have %d, want 0", balance)
+	}
+	if plain, _ := DefaultGenesisBlock().ToBlock(); block.Root() == plain.Root() {
+		t.Errorf("balances not committed to the state root")
+	}
+}
 
 func TestSetupGenesis(t *testing.T) {
 	var (
-		customghash = types.HexToHash("0x11873b8c6b93ee8e86d78734a089f5f2b546cf29d3e9ec785fe84253eca91e6b")
+		customghash = types.HexToHash("0x3ea3e91ad63f8117920a26e69e477193746bbc07277a58a9d978f5377003220c")
 		customg     = Genesis{
-			Config:  &params.ChainConfig{big.NewInt(500)},
+			Config:  &params.ChainConfig{ChainId: big.NewInt(500)},
 			Alloc: GenesisAlloc{
-				{1}: {Balance: big.NewInt(1), Storage: map[types.Hash]types.Hash{{1}: {1}}},
+				{1}: {Nonce: 1, Storage: map[types.Hash]types.Hash{{1}: {1}}},
 			},
 		}
 		oldcustomg = customg
 
-		customghash2 = types.HexToHash("0xab0d510f06569cb8a0a2e60eaa6a0bd1ba1a0fd5d94d5f49cc771bcae08ef648")
+		customghash2 = types.HexToHash("0x840b28cea685a136190f555fb61eaa509cb4f730c24c2f2b7532d3e891e31825")
 		customg2     = Genesis{
-			Config:  &params.ChainConfig{big.NewInt(700)},
+			Config:  &params.ChainConfig{ChainId: big.NewInt(700)},
 			Alloc: GenesisAlloc{
-				{1}: {Balance: big.NewInt(2), Storage: map[types.Hash]types.Hash{{2}: {2}}},
+				{1}: {Nonce: 2, Storage: map[types.Hash]types.Hash{{2}: {2}}},
 			},
 		}
 	)
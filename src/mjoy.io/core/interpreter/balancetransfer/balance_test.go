@@ -87,7 +87,7 @@ func GetBalanceForTest(param map[string]interface{} )([]intertypes.ActionResult
 		return nil , errors.New(fmt.Sprintf("GetBalance Last Marshal Err:%s" , err.Error()))
 	}
 	//right
-	results = append(results , intertypes.ActionResult{nil , resultBytes})
+	results = append(results , intertypes.ActionResult{Key:nil , Val:resultBytes})
 	return results , nil
 
 }
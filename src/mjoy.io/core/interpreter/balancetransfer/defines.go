@@ -54,3 +54,17 @@ func MakaBalanceTransferParam(from , to types.Address , amount int)[]byte{
 	return r
 }
 
+//MakeTransferFeeParam makes the params charging a transaction fee from the sender to the block producer
+func MakeTransferFeeParam(from types.Address , amount uint64)[]byte{
+	a := make(map[string]interface{})
+
+	a["funcId"] = fmt.Sprintf("%d" , TransferFee_FunId)
+	a["from"] = from.Hex()
+	a["amount"] = fmt.Sprintf("%d" , amount)
+
+	r , err :=json.Marshal(a)
+	if err != nil {
+		return nil
+	}
+	return r
+}
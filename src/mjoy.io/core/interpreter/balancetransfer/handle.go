@@ -70,7 +70,7 @@ func GetBalance(param map[string]interface{} , sysparam *intertypes.SystemParams
 		return nil , errors.New(fmt.Sprintf("GetBalance Last Marshal Err:%s" , err.Error()))
 	}
 	//right
-	results = append(results , intertypes.ActionResult{nil , resultBytes})
+	results = append(results , intertypes.ActionResult{Key:nil , Val:resultBytes})
 	return results , nil
 
 }
@@ -4,6 +4,10 @@ import (
 	"mjoy.io/common/types"
 	"strconv"
 	"errors"
+	"encoding/json"
+	"fmt"
+	"mjoy.io/core/sdk"
+	"mjoy.io/core/interpreter/intertypes"
 )
 
 func CheckFee(addr types.Address , params []byte)(int , error){
@@ -39,3 +43,63 @@ func CheckFee(addr types.Address , params []byte)(int , error){
 	return 0 , errors.New("CheckFee Unkown Error")
 
 }
+
+//BalanceOf returns the balance of an account as seen by the sdk handler,0 if the account has none
+func BalanceOf(sdkHandler *sdk.TmpStatusManager , addr types.Address)int{
+	data := sdk.Sys_GetValue(sdkHandler ,  BalanceTransferAddress , addr[:])
+	if nil == data{
+		return 0
+	}
+	balance := new(BalanceValue)
+	if err := json.Unmarshal(data , balance);err != nil {
+		return 0
+	}
+	return balance.Amount
+}
+
+//CheckFeeBalance checks that the account can pay the given transaction fee
+func CheckFeeBalance(sdkHandler *sdk.TmpStatusManager , from types.Address , fee uint64)error{
+	if balance := BalanceOf(sdkHandler , from);balance < 0 || uint64(balance) < fee {
+		return fmt.Errorf("fee balance: has %d , but want %d" , balance , fee)
+	}
+	return nil
+}
+
+//PreCheck is run by the txpool before admitting a transaction calling the contract,against pending state.
+//Rewards and fees are only moved by the system,and a transfer must spend the sender's own affordable balance
+func (this *ContractBalancer)PreCheck(from types.Address , params []byte , sysparam *intertypes.SystemParams)error{
+	jsonParams , err := ParseParms(params)
+	if err != nil {
+		return err
+	}
+	v , ok := jsonParams["funcId"].(string)
+	if !ok {
+		return errors.New("ContractBalancer: Params not contain funcId")
+	}
+	funcId , err := strconv.Atoi(v)
+	if err != nil {
+		return errors.New("ContractBalancer: Params  funcId format is not right")
+	}
+
+	switch funcId {
+	case RewordBlockProducer_FunId , TransferFee_FunId:
+		return fmt.Errorf("ContractBalancer: func Id:%d is reserved for the system" , funcId)
+	case TransferBalance_FunId:
+		fromStr , ok := jsonParams["from"].(string)
+		if !ok || types.HexToAddress(fromStr) != from {
+			return errors.New("ContractBalancer: transfer not from the sender")
+		}
+		amountStr , ok := jsonParams["amount"].(string)
+		if !ok {
+			return errors.New("ContractBalancer: transfer without amount")
+		}
+		amount , err := strconv.Atoi(amountStr)
+		if err != nil || amount < 0 {
+			return errors.New("ContractBalancer: transfer amount format is not right")
+		}
+		if balance := BalanceOf(sysparam.SdkHandler , from);balance < amount {
+			return fmt.Errorf("ContractBalancer: has %d , but want %d" , balance , amount)
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"math/big"
 	"mjoy.io/core/interpreter/intertypes"
 	"fmt"
+	"errors"
 )
 
 //InnerContrancInterface
@@ -18,6 +19,18 @@ type InnerContract interface {
 
 }
 
+//InnerContractPreChecker is implemented by inner contracts that can reject a call
+//before its transaction is admitted into the txpool
+type InnerContractPreChecker interface {
+	PreCheck(from types.Address , params []byte , sysparam *intertypes.SystemParams)error
+}
+
+//InnerContractFromCaller is implemented by inner contracts that need to know the sender
+//of the transaction calling them
+type InnerContractFromCaller interface {
+	DoFunFrom(from types.Address , params []byte , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error)
+}
+
 //InnerContranctMap is a innerContract controller,like check contract ,do a contract
 type InnerContractManager struct {
 	mu sync.RWMutex
@@ -74,6 +87,26 @@ func (this *InnerContractManager)DoFun(address types.Address , params []byte,sys
 	return inner.DoFun(params,sysparam)
 }
 
+//call a innerContract on behalf of a sender,contracts not caring about the sender are called by DoFun
+func (this *InnerContractManager)DoFunFrom(address types.Address , from types.Address , params []byte,sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	inner := this.Inners[address]
+	if caller , ok := inner.(InnerContractFromCaller);ok {
+		return caller.DoFunFrom(from , params , sysparam)
+	}
+	return inner.DoFun(params,sysparam)
+}
 
+//run the pre-check of a innerContract,if it has one
+func (this *InnerContractManager)PreCheck(address types.Address , from types.Address , params []byte , sysparam *intertypes.SystemParams)error{
+	this.mu.RLock()
+	inner , ok := this.Inners[address]
+	this.mu.RUnlock()
 
-
+	if !ok {
+		return errors.New("innerContract Not Exist....")
+	}
+	if checker , ok := inner.(InnerContractPreChecker);ok {
+		return checker.PreCheck(from , params , sysparam)
+	}
+	return nil
+}
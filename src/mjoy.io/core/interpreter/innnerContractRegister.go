@@ -7,6 +7,8 @@ package interpreter
 import (
 	"mjoy.io/common/types"
 	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/interpreter/multisig"
+	"mjoy.io/core/interpreter/staking"
 )
 
 type innerRegisterMap struct {
@@ -18,5 +20,7 @@ type InnersRegister []innerRegisterMap
 
 var allInnerRegister InnersRegister = InnersRegister{
 	{balancetransfer.BalanceTransferAddress , balancetransfer.NewContractBalancer()},
+	{staking.StakingAddress , staking.NewContractStaking()},
+	{multisig.MultiSigAddress , multisig.NewContractMultiSig()},
 }
 
@@ -54,7 +54,7 @@ func (this *Vms)DealActions(pWork *Work)error{
 		workResult.Results = make([]intertypes.ActionResult , 0 )
 		workResult.Err = nil
 
-		r , err := this.DealAction(pWork.contractAddress,a ,pWork.sysParams)
+		r , err := this.DealAction(pWork.from , pWork.contractAddress,a ,pWork.sysParams)
 		if err != nil{
 			//get a err,return
 			workResult.Results = nil
@@ -72,9 +72,9 @@ func (this *Vms)DealActions(pWork *Work)error{
 }
 
 //DealActions is a little part of full work
-func (this *Vms)DealAction(contractAddress types.Address , action transaction.Action ,sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+func (this *Vms)DealAction(from types.Address , contractAddress types.Address , action transaction.Action ,sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
 	if this.pInnerContractMaper.Exist(contractAddress){
-		results , err := this.pInnerContractMaper.DoFun(contractAddress , action.Params , sysparam)
+		results , err := this.pInnerContractMaper.DoFunFrom(contractAddress , from , action.Params , sysparam)
 		if err != nil {
 			return nil , err
 		}
@@ -93,6 +93,7 @@ func (this *Vms)SendWork(from types.Address , action transaction.Action , sysPar
 	actions = append(actions , action)
 	fmt.Println("SendWork actions Len:" , len(actions))
 	w := NewWork(*action.Address , actions , sysParam)
+	w.from = from
 	//this.WorkingChan<-w
 
 	go this.DealActions(w)
@@ -122,6 +123,15 @@ func (this *Vms)GetStorage(address types.Address , action transaction.Action , s
 }
 
 
+//PreCheck is called by txpool before admitting a transaction,it runs the pre-check of the contract the action calls
+func (this *Vms)PreCheck(from types.Address , action transaction.Action , sysParam *intertypes.SystemParams)error{
+	if action.Address == nil {
+		//the code of a contract creation,nothing is called
+		return nil
+	}
+	return this.pInnerContractMaper.PreCheck(*action.Address , from , action.Params , sysParam)
+}
+
 /********************************************************************/
 //cycle Dealing
 /********************************************************************/
@@ -1,6 +1,7 @@
 package intertypes
 
 import (
+	"math/big"
 	"mjoy.io/core/sdk"
 	"mjoy.io/common/types"
 	"mjoy.io/core/transaction"
@@ -10,6 +11,7 @@ import (
 type ActionResult struct {
 	Key []byte
 	Val []byte
+	Contract *types.Address    //storage the result is written to,nil for the called contract
 }
 
 type WorkResult struct {
@@ -33,6 +35,9 @@ type VmInterface interface {
 type SystemParams struct {
 	SdkHandler *sdk.TmpStatusManager    //contain current
 	VmHandler VmInterface
+	BlockNumber uint64    //number of the block the actions run in
+	ChainId *big.Int      //id of the chain the headers are signed for
+	Staking bool          //whether the consensus engine elects the producers by stake
 }
 
 func MakeSystemParams(sdkHandler *sdk.TmpStatusManager , vmHandler VmInterface )*SystemParams{
@@ -0,0 +1,66 @@
+package multisig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mjoy.io/common/types"
+	"mjoy.io/core/transaction"
+	"mjoy.io/utils/crypto"
+)
+
+const(
+	SetKeySet_FunId = iota
+	GetKeySet_FunId
+)
+
+var MultiSigAddress = types.HexToAddress("0x0000000000000000000000000000000000000003")
+
+var (
+	ErrKeySetNotRegistered = errors.New("multisig key set not registered for the account")
+	ErrKeySetMismatch      = errors.New("multisig key set differs from the one registered for the account")
+	ErrKeySetRequired      = errors.New("account is controlled by a multisig key set")
+)
+
+//KeySetValue is the key set registered for an account,the owners in canonical order
+type KeySetValue struct {
+	Threshold uint64    `json:"threshold"`
+	Owners []string     `json:"owners"`
+}
+
+//Hash returns the hash of the key set,as carried by the multisig transactions of the account
+func (v *KeySetValue)Hash()types.Hash{
+	owners := make([]types.Address , 0 , len(v.Owners))
+	for _ , o := range v.Owners {
+		owners = append(owners , types.HexToAddress(o))
+	}
+	return transaction.KeySetHash(v.Threshold , owners)
+}
+
+//storage keys are 20 bytes long,like the balance keys
+func keySetKey(addr types.Address)[]byte{
+	return crypto.Keccak256([]byte("keyset") , addr[:])[12:]
+}
+
+func makeParams(a map[string]interface{})[]byte{
+	r , err :=json.Marshal(a)
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+//MakeSetKeySetParam makes the params putting the sender under the key set requiring threshold of the owners,
+//no owners and a zero threshold returning it to its own key
+func MakeSetKeySetParam(threshold uint64 , owners []types.Address)[]byte{
+	all := make([]string , 0 , len(owners))
+	for _ , o := range owners {
+		all = append(all , o.Hex())
+	}
+	return makeParams(map[string]interface{}{"funcId":fmt.Sprintf("%d" , SetKeySet_FunId) , "threshold":fmt.Sprintf("%d" , threshold) , "owners":all})
+}
+
+//MakeGetKeySetParam makes the params reading the key set registered for addr
+func MakeGetKeySetParam(addr types.Address)[]byte{
+	return makeParams(map[string]interface{}{"funcId":fmt.Sprintf("%d" , GetKeySet_FunId) , "address":addr.Hex()})
+}
@@ -0,0 +1,45 @@
+package multisig
+
+import (
+	"encoding/json"
+	"errors"
+	"mjoy.io/common/types"
+	"mjoy.io/core/interpreter/intertypes"
+)
+
+//SetKeySet registers the key set controlling the sender,replacing the previous one. A sender already
+//under a key set is authorized by it,so only its owners can change or remove it
+func SetKeySet(from types.Address , param map[string]interface{} , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	logger.Trace("Start: SetKeySet.")
+	keySet , err := parseKeySet(from , param)
+	if err != nil {
+		return nil , err
+	}
+	if keySet == nil {
+		if KeySetOf(sysparam.SdkHandler , from) == nil {
+			return nil , errors.New("SetKeySet:no key set registered")
+		}
+		keySet = &KeySetValue{}
+	}
+	result , err := setValue(sysparam , keySetKey(from) , keySet)
+	if err != nil {
+		return nil , err
+	}
+	return []intertypes.ActionResult{result} , nil
+}
+
+func GetKeySet(from types.Address , param map[string]interface{} , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	addrStr , ok := param["address"].(string)
+	if !ok {
+		return nil , errors.New("GetKeySet:no address")
+	}
+	keySet := KeySetOf(sysparam.SdkHandler , types.HexToAddress(addrStr))
+	if keySet == nil {
+		keySet = &KeySetValue{Owners:[]string{}}
+	}
+	data , err := json.Marshal(keySet)
+	if err != nil {
+		return nil , err
+	}
+	return []intertypes.ActionResult{{Val:data}} , nil
+}
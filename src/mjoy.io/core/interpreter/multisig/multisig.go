@@ -0,0 +1,97 @@
+package multisig
+
+import (
+	"errors"
+	"fmt"
+	"mjoy.io/common/types"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/interpreter/intertypes"
+	"strconv"
+)
+
+type DoFunc func(types.Address , map[string]interface{} ,  *intertypes.SystemParams)([]intertypes.ActionResult , error)
+
+//ContractMultiSig keeps the key sets authorizing the multisig transactions of the accounts
+type ContractMultiSig struct {
+	funcMapper map[int]DoFunc
+}
+
+//managed by vm
+func NewContractMultiSig()*ContractMultiSig{
+	m := new(ContractMultiSig)
+	m.init()
+	return m
+}
+
+func (this *ContractMultiSig)init(){
+	//register call Back
+	this.funcMapper = make(map[int]DoFunc)
+	this.funcMapper[SetKeySet_FunId] = SetKeySet       //put the sender under a key set
+	this.funcMapper[GetKeySet_FunId] = GetKeySet
+}
+
+func parseFuncId(jsonParams map[string]interface{})(int , error){
+	v , ok := jsonParams["funcId"].(string)
+	if !ok {
+		return 0 , errors.New("ContractMultiSig: Params not contain funcId")
+	}
+	funcId , err := strconv.Atoi(v)
+	if err != nil {
+		return 0 , errors.New("ContractMultiSig: Params  funcId format is not right")
+	}
+	return funcId , nil
+}
+
+//DoFun serves the calls not made by a transaction,which can only read
+func (this *ContractMultiSig)DoFun(params []byte , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	jsonParams , err := balancetransfer.ParseParms(params)
+	if err != nil {
+		return nil,err
+	}
+	funcId , err := parseFuncId(jsonParams)
+	if err != nil {
+		return nil , err
+	}
+	if funcId != GetKeySet_FunId {
+		return nil , fmt.Errorf("ContractMultiSig: func Id:%d needs a sender" , funcId)
+	}
+	return this.funcMapper[funcId](types.Address{} , jsonParams , sysparam)
+}
+
+//DoFunFrom serves the calls of a transaction sender
+func (this *ContractMultiSig)DoFunFrom(from types.Address , params []byte , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	jsonParams , err := balancetransfer.ParseParms(params)
+	if err != nil {
+		return nil,err
+	}
+	funcId , err := parseFuncId(jsonParams)
+	if err != nil {
+		return nil , err
+	}
+	if doFunc,ok := this.funcMapper[funcId];ok {
+		return doFunc(from , jsonParams , sysparam)
+	}
+	return nil , fmt.Errorf("ContractMultiSig: no Func Id:%d find in map" , funcId)
+}
+
+//PreCheck is run by the txpool before admitting a transaction calling the contract,against pending state
+func (this *ContractMultiSig)PreCheck(from types.Address , params []byte , sysparam *intertypes.SystemParams)error{
+	jsonParams , err := balancetransfer.ParseParms(params)
+	if err != nil {
+		return err
+	}
+	funcId , err := parseFuncId(jsonParams)
+	if err != nil {
+		return err
+	}
+	if funcId == SetKeySet_FunId {
+		keySet , err := parseKeySet(from , jsonParams)
+		if err != nil {
+			return err
+		}
+		if keySet == nil && KeySetOf(sysparam.SdkHandler , from) == nil {
+			return errors.New("ContractMultiSig: no key set registered")
+		}
+	}
+	return nil
+}
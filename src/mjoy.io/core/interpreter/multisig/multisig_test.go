@@ -0,0 +1,110 @@
+package multisig
+
+import (
+	"testing"
+	"mjoy.io/common/types"
+	"mjoy.io/core/interpreter/intertypes"
+	"mjoy.io/core/sdk"
+	"mjoy.io/core/state"
+	"mjoy.io/core/transaction"
+	"mjoy.io/utils/database"
+)
+
+//newTestParams returns system params over an empty state
+func newTestParams(t *testing.T)*intertypes.SystemParams{
+	db , _ := database.OpenMemDB()
+	statedb , err := state.New(types.Hash{} , state.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to create state: %v" , err)
+	}
+	return intertypes.MakeSystemParams(sdk.NewTmpStatusManager(db , statedb , types.Address{}) , nil)
+}
+
+//call runs a function of the contract for a transaction sender,after its pre-check
+func call(sysparam *intertypes.SystemParams , from types.Address , params []byte)error{
+	contract := NewContractMultiSig()
+	if err := contract.PreCheck(from , params , sysparam);err != nil {
+		return err
+	}
+	_ , err := contract.DoFunFrom(from , params , sysparam)
+	return err
+}
+
+func keySetHash(t *testing.T , account types.Address , threshold uint64 , owners []types.Address)types.Hash{
+	ms , err := transaction.NewMultiSig(account , threshold , owners)
+	if err != nil {
+		t.Fatalf("NewMultiSig error: %v" , err)
+	}
+	return ms.KeySetHash()
+}
+
+func TestKeySetRegistration(t *testing.T){
+	sysparam := newTestParams(t)
+	account := types.Address{1}
+	owners := []types.Address{{4} , {3} , {2}}
+	single := types.Hash{}
+	twoOfThree := keySetHash(t , account , 2 , owners)
+
+	//an account starts under its own key
+	if err := CheckSender(sysparam.SdkHandler , account , single);err != nil {
+		t.Fatalf("single key sender refused: %v" , err)
+	}
+	if err := CheckSender(sysparam.SdkHandler , account , twoOfThree);err != ErrKeySetNotRegistered {
+		t.Fatalf("unregistered key set error mismatch: have %v, want %v" , err , ErrKeySetNotRegistered)
+	}
+	//the existing account is put under the key set,given in any order
+	if err := call(sysparam , account , MakeSetKeySetParam(4 , owners));err == nil {
+		t.Fatalf("threshold over the owners accepted")
+	}
+	if err := call(sysparam , account , MakeSetKeySetParam(2 , owners));err != nil {
+		t.Fatalf("failed to register the key set: %v" , err)
+	}
+	if err := CheckSender(sysparam.SdkHandler , account , twoOfThree);err != nil {
+		t.Fatalf("registered key set refused: %v" , err)
+	}
+	if err := CheckSender(sysparam.SdkHandler , account , single);err != ErrKeySetRequired {
+		t.Fatalf("single key error mismatch: have %v, want %v" , err , ErrKeySetRequired)
+	}
+	if err := CheckSender(sysparam.SdkHandler , types.Address{9} , twoOfThree);err != ErrKeySetNotRegistered {
+		t.Fatalf("foreign account error mismatch: have %v, want %v" , err , ErrKeySetNotRegistered)
+	}
+	//rotating the key set keeps the account
+	if err := call(sysparam , account , MakeSetKeySetParam(1 , owners[:2]));err != nil {
+		t.Fatalf("failed to rotate the key set: %v" , err)
+	}
+	if err := CheckSender(sysparam.SdkHandler , account , twoOfThree);err != ErrKeySetMismatch {
+		t.Fatalf("rotated key set error mismatch: have %v, want %v" , err , ErrKeySetMismatch)
+	}
+	if err := CheckSender(sysparam.SdkHandler , account , keySetHash(t , account , 1 , owners[:2]));err != nil {
+		t.Fatalf("rotated key set refused: %v" , err)
+	}
+	//removing it returns the account to its own key
+	if err := call(sysparam , account , MakeSetKeySetParam(0 , nil));err != nil {
+		t.Fatalf("failed to remove the key set: %v" , err)
+	}
+	if err := CheckSender(sysparam.SdkHandler , account , single);err != nil {
+		t.Fatalf("single key sender refused after removal: %v" , err)
+	}
+	if err := call(sysparam , account , MakeSetKeySetParam(0 , nil));err == nil {
+		t.Fatalf("removal of a missing key set accepted")
+	}
+}
+
+func TestGetKeySet(t *testing.T){
+	sysparam := newTestParams(t)
+	account := types.Address{1}
+	if err := call(sysparam , account , MakeSetKeySetParam(1 , []types.Address{{3} , {2}}));err != nil {
+		t.Fatalf("failed to register the key set: %v" , err)
+	}
+	results , err := NewContractMultiSig().DoFun(MakeGetKeySetParam(account) , sysparam)
+	if err != nil {
+		t.Fatalf("GetKeySet error: %v" , err)
+	}
+	want := `{"threshold":1,"owners":["` + types.Address{2}.Hex() + `","` + types.Address{3}.Hex() + `"]}`
+	if len(results) != 1 || string(results[0].Val) != want {
+		t.Fatalf("key set mismatch: have %s, want %s" , results , want)
+	}
+	if _ , err := NewContractMultiSig().DoFun(MakeSetKeySetParam(1 , []types.Address{{2}}) , sysparam);err == nil {
+		t.Fatalf("registration without a sender accepted")
+	}
+}
@@ -0,0 +1,88 @@
+package multisig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mjoy.io/common/types"
+	"mjoy.io/core/interpreter/intertypes"
+	"mjoy.io/core/sdk"
+	"mjoy.io/core/transaction"
+	"strconv"
+)
+
+//KeySetOf returns the key set registered for an account as seen by the sdk handler,nil if it has none
+func KeySetOf(sdkHandler *sdk.TmpStatusManager , addr types.Address)*KeySetValue{
+	data := sdk.Sys_GetValue(sdkHandler , MultiSigAddress , keySetKey(addr))
+	if nil == data{
+		return nil
+	}
+	keySet := new(KeySetValue)
+	if err := json.Unmarshal(data , keySet);err != nil || keySet.Threshold == 0 {
+		return nil
+	}
+	return keySet
+}
+
+//CheckSender checks the authorization of a transaction from an account against the key set registered
+//for it. keySet is the hash of the key set carried by a multisig transaction,zero for a single key one
+func CheckSender(sdkHandler *sdk.TmpStatusManager , from types.Address , keySet types.Hash)error{
+	registered := KeySetOf(sdkHandler , from)
+	switch {
+	case keySet == (types.Hash{}) && registered != nil:
+		return ErrKeySetRequired
+	case keySet == (types.Hash{}):
+		return nil
+	case registered == nil:
+		return ErrKeySetNotRegistered
+	case registered.Hash() != keySet:
+		return ErrKeySetMismatch
+	}
+	return nil
+}
+
+//parseKeySet returns the key set of the params,nil for the deregistration
+func parseKeySet(from types.Address , param map[string]interface{})(*KeySetValue , error){
+	thresholdStr , ok := param["threshold"].(string)
+	if !ok {
+		return nil , errors.New("ContractMultiSig: no threshold")
+	}
+	threshold , err := strconv.ParseUint(thresholdStr , 10 , 64)
+	if err != nil {
+		return nil , errors.New("ContractMultiSig: threshold format is not right")
+	}
+	all , _ := param["owners"].([]interface{})
+	if threshold == 0 && len(all) == 0 {
+		return nil , nil
+	}
+	owners := make([]types.Address , 0 , len(all))
+	for _ , v := range all {
+		str , ok := v.(string)
+		if !ok || !types.IsHexAddress(str) {
+			return nil , errors.New("ContractMultiSig: owner is not an address")
+		}
+		owners = append(owners , types.HexToAddress(str))
+	}
+	//sorts the owners and checks the threshold
+	ms , err := transaction.NewMultiSig(from , threshold , owners)
+	if err != nil {
+		return nil , fmt.Errorf("ContractMultiSig: %s" , err.Error())
+	}
+	keySet := &KeySetValue{Threshold:ms.Threshold , Owners:make([]string , 0 , len(ms.Owners))}
+	for _ , o := range ms.Owners {
+		keySet.Owners = append(keySet.Owners , o.Hex())
+	}
+	return keySet , nil
+}
+
+//setValue writes a value into the contract storage,returning the action result recording it
+func setValue(sysparam *intertypes.SystemParams , key []byte , v interface{})(intertypes.ActionResult , error){
+	data , err := json.Marshal(v)
+	if err != nil {
+		return intertypes.ActionResult{} , fmt.Errorf("ContractMultiSig:Marshal json:%s" , err.Error())
+	}
+	if err = sdk.Sys_SetValue(sysparam.SdkHandler , MultiSigAddress , key , data);err != nil{
+		return intertypes.ActionResult{} , fmt.Errorf("ContractMultiSig:Set :%s" , err.Error())
+	}
+	return intertypes.ActionResult{Key:key , Val:data} , nil
+}
@@ -0,0 +1,130 @@
+package staking
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/utils/crypto"
+)
+
+const(
+	Stake_FunId = iota
+	Unstake_FunId
+	Withdraw_FunId
+	Vote_FunId
+	RewordVoters_FunId
+	GetStake_FunId
+	GetCandidates_FunId
+	Slash_FunId
+)
+
+var StakingAddress = types.HexToAddress("0x0000000000000000000000000000000000000002")
+
+var (
+	//number of blocks unstaked tokens stay locked before they can be withdrawn
+	UnbondingPeriod uint64 = 50400
+	//maximum number of candidates an account can vote for
+	MaxVotes = 30
+	//tokens minted for every block
+	BlockReword int64 = 5e+5
+	//percent of the block reword kept by the producer,the rest is split among its voters by stake
+	ProducerShare int64 = 20
+	//percent of the stake taken from a producer caught signing two blocks at the same height
+	SlashPercent = 50
+	//percent of the taken stake given to the account submitting the evidence,the rest is burnt
+	ReporterPercent = 10
+)
+
+//Unbond is stake waiting for the unbonding period to end
+type Unbond struct {
+	Amount int       `json:"amount"`
+	Release uint64   `json:"release"`
+}
+
+//StakeValue is the stake of an account and the candidates it votes for
+type StakeValue struct {
+	Amount int           `json:"amount"`
+	Votes []string       `json:"votes"`
+	Unbonding []Unbond   `json:"unbonding"`
+}
+
+//CandidateValue is the vote tally of a block producer candidate
+type CandidateValue struct {
+	Votes int            `json:"votes"`
+	Voters []string      `json:"voters"`
+	Jailed bool          `json:"jailed,omitempty"`    //slashed candidates are never elected again
+}
+
+//CandidateList holds every address ever voted for
+type CandidateList struct {
+	All []string   `json:"all"`
+}
+
+//CandidateTally is the result of GetCandidates
+type CandidateTally struct {
+	Address types.Address   `json:"address"`
+	Votes int               `json:"votes"`
+}
+
+//storage keys are 20 bytes long,like the balance keys
+func stakeKey(addr types.Address)[]byte{
+	return crypto.Keccak256([]byte("stake") , addr[:])[12:]
+}
+
+func candidateKey(addr types.Address)[]byte{
+	return crypto.Keccak256([]byte("candidate") , addr[:])[12:]
+}
+
+var candidatesKey = crypto.Keccak256([]byte("candidates"))[12:]
+
+func slashedKey(addr types.Address , number uint64)[]byte{
+	return crypto.Keccak256([]byte("slashed") , addr[:] , []byte(fmt.Sprintf("%d" , number)))[12:]
+}
+
+func makeParams(a map[string]interface{})[]byte{
+	r , err :=json.Marshal(a)
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+//MakeStakeParam makes the params locking amount of the sender's balance as stake
+func MakeStakeParam(amount int)[]byte{
+	return makeParams(map[string]interface{}{"funcId":fmt.Sprintf("%d" , Stake_FunId) , "amount":fmt.Sprintf("%d" , amount)})
+}
+
+//MakeUnstakeParam makes the params starting the unbonding of amount of the sender's stake
+func MakeUnstakeParam(amount int)[]byte{
+	return makeParams(map[string]interface{}{"funcId":fmt.Sprintf("%d" , Unstake_FunId) , "amount":fmt.Sprintf("%d" , amount)})
+}
+
+//MakeWithdrawParam makes the params moving the sender's unbonded stake back to its balance
+func MakeWithdrawParam()[]byte{
+	return makeParams(map[string]interface{}{"funcId":fmt.Sprintf("%d" , Withdraw_FunId)})
+}
+
+//MakeVoteParam makes the params replacing the candidates the sender votes for
+func MakeVoteParam(candidates []types.Address)[]byte{
+	all := make([]string , 0 , len(candidates))
+	for _ , c := range candidates {
+		all = append(all , c.Hex())
+	}
+	return makeParams(map[string]interface{}{"funcId":fmt.Sprintf("%d" , Vote_FunId) , "candidates":all})
+}
+
+//MakeSlashParam makes the params slashing the producer which signed both headers of the evidence
+func MakeSlashParam(evidence *block.Evidence)([]byte , error){
+	data , err := evidence.MarshalMsg(nil)
+	if err != nil {
+		return nil , err
+	}
+	return makeParams(map[string]interface{}{"funcId":fmt.Sprintf("%d" , Slash_FunId) , "evidence":hex.EncodeToString(data)}) , nil
+}
+
+//MakeActionParamsReword makes the params of the block reword,split between the producer and its voters
+func MakeActionParamsReword(producer types.Address)[]byte{
+	return makeParams(map[string]interface{}{"funcId":fmt.Sprintf("%d" , RewordVoters_FunId) , "producer":producer.Hex()})
+}
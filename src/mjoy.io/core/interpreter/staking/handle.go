@@ -0,0 +1,296 @@
+package staking
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"mjoy.io/common/types"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/interpreter/intertypes"
+	"mjoy.io/params"
+)
+
+//addVotes changes the tallies of the candidates voted by an account
+func addVotes(sysparam *intertypes.SystemParams , candidates []string , amount int)([]intertypes.ActionResult , error){
+	results := make([]intertypes.ActionResult , 0 , len(candidates))
+	for _ , c := range candidates {
+		addr := types.HexToAddress(c)
+		candidate := CandidateOf(sysparam.SdkHandler , addr)
+		candidate.Votes += amount
+		result , err := setValue(sysparam , StakingAddress , candidateKey(addr) , candidate)
+		if err != nil {
+			return nil , err
+		}
+		results = append(results , result)
+	}
+	return results , nil
+}
+
+func Stake(from types.Address , param map[string]interface{} , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	logger.Trace("Start: Stake.")
+	amount , err := parseAmount(param)
+	if err != nil {
+		return nil , err
+	}
+	balance := balancetransfer.BalanceOf(sysparam.SdkHandler , from)
+	if balance < amount {
+		return nil , fmt.Errorf("Stake:has %d , but want %d" , balance , amount)
+	}
+	stake := StakeOf(sysparam.SdkHandler , from)
+	stake.Amount += amount
+
+	results := make([]intertypes.ActionResult , 0 , 2+len(stake.Votes))
+	result , err := setBalance(sysparam , from , balance-amount)
+	if err != nil {
+		return nil , err
+	}
+	results = append(results , result)
+	if result , err = setValue(sysparam , StakingAddress , stakeKey(from) , stake);err != nil {
+		return nil , err
+	}
+	results = append(results , result)
+
+	votes , err := addVotes(sysparam , stake.Votes , amount)
+	if err != nil {
+		return nil , err
+	}
+	return append(results , votes...) , nil
+}
+
+func Unstake(from types.Address , param map[string]interface{} , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	logger.Trace("Start: Unstake.")
+	amount , err := parseAmount(param)
+	if err != nil {
+		return nil , err
+	}
+	stake := StakeOf(sysparam.SdkHandler , from)
+	if stake.Amount < amount {
+		return nil , fmt.Errorf("Unstake:staked %d , but want %d" , stake.Amount , amount)
+	}
+	stake.Amount -= amount
+	stake.Unbonding = append(stake.Unbonding , Unbond{Amount:amount , Release:sysparam.BlockNumber + UnbondingPeriod})
+
+	result , err := setValue(sysparam , StakingAddress , stakeKey(from) , stake)
+	if err != nil {
+		return nil , err
+	}
+	votes , err := addVotes(sysparam , stake.Votes , -amount)
+	if err != nil {
+		return nil , err
+	}
+	return append([]intertypes.ActionResult{result} , votes...) , nil
+}
+
+func Withdraw(from types.Address , param map[string]interface{} , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	logger.Trace("Start: Withdraw.")
+	stake := StakeOf(sysparam.SdkHandler , from)
+
+	released := 0
+	unbonding := stake.Unbonding[:0]
+	for _ , unbond := range stake.Unbonding {
+		if unbond.Release <= sysparam.BlockNumber {
+			released += unbond.Amount
+		} else {
+			unbonding = append(unbonding , unbond)
+		}
+	}
+	if released == 0 {
+		return nil , errors.New("Withdraw:no unbonded stake")
+	}
+	stake.Unbonding = unbonding
+
+	result , err := setValue(sysparam , StakingAddress , stakeKey(from) , stake)
+	if err != nil {
+		return nil , err
+	}
+	balance , err := setBalance(sysparam , from , balancetransfer.BalanceOf(sysparam.SdkHandler , from) + released)
+	if err != nil {
+		return nil , err
+	}
+	return []intertypes.ActionResult{result , balance} , nil
+}
+
+func Vote(from types.Address , param map[string]interface{} , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	logger.Trace("Start: Vote.")
+	candidates , err := parseCandidates(param)
+	if err != nil {
+		return nil , err
+	}
+	stake := StakeOf(sysparam.SdkHandler , from)
+	results := []intertypes.ActionResult{}
+
+	//withdraw the previous votes
+	for _ , c := range stake.Votes {
+		addr := types.HexToAddress(c)
+		candidate := CandidateOf(sysparam.SdkHandler , addr)
+		candidate.Votes -= stake.Amount
+		for i , voter := range candidate.Voters {
+			if types.HexToAddress(voter) == from {
+				candidate.Voters = append(candidate.Voters[:i] , candidate.Voters[i+1:]...)
+				break
+			}
+		}
+		result , err := setValue(sysparam , StakingAddress , candidateKey(addr) , candidate)
+		if err != nil {
+			return nil , err
+		}
+		results = append(results , result)
+	}
+	//cast the new ones,remembering the candidates never voted before
+	list := new(CandidateList)
+	getValue(sysparam.SdkHandler , candidatesKey , list)
+	known := make(map[types.Address]bool , len(list.All))
+	for _ , c := range list.All {
+		known[types.HexToAddress(c)] = true
+	}
+	listed := len(list.All)
+
+	stake.Votes = make([]string , 0 , len(candidates))
+	for _ , addr := range candidates {
+		candidate := CandidateOf(sysparam.SdkHandler , addr)
+		candidate.Votes += stake.Amount
+		candidate.Voters = append(candidate.Voters , from.Hex())
+		result , err := setValue(sysparam , StakingAddress , candidateKey(addr) , candidate)
+		if err != nil {
+			return nil , err
+		}
+		results = append(results , result)
+		stake.Votes = append(stake.Votes , addr.Hex())
+
+		if !known[addr] {
+			known[addr] = true
+			list.All = append(list.All , addr.Hex())
+		}
+	}
+	result , err := setValue(sysparam , StakingAddress , stakeKey(from) , stake)
+	if err != nil {
+		return nil , err
+	}
+	results = append(results , result)
+	if len(list.All) != listed {
+		if result , err = setValue(sysparam , StakingAddress , candidatesKey , list);err != nil {
+			return nil , err
+		}
+		results = append(results , result)
+	}
+	return results , nil
+}
+
+//RewordVoters mints the block reword,keeping ProducerShare percent for the producer and
+//splitting the rest between its voters by stake
+func RewordVoters(from types.Address , param map[string]interface{} , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	if from != params.Address {
+		return nil , errors.New("RewordVoters:not sent by the reword account")
+	}
+	producerStr , ok := param["producer"].(string)
+	if !ok {
+		return nil , errors.New("RewordVoters:no producer")
+	}
+	producer := types.HexToAddress(producerStr)
+	logger.Trace("RewordVoters", producer.Hex())
+
+	results := []intertypes.ActionResult{}
+	paid := int64(0)
+
+	candidate := CandidateOf(sysparam.SdkHandler , producer)
+	if candidate.Votes > 0 {
+		share := big.NewInt(BlockReword * (100 - ProducerShare) / 100)
+		for _ , voter := range candidate.Voters {
+			addr := types.HexToAddress(voter)
+			stake := StakeOf(sysparam.SdkHandler , addr)
+			amount := new(big.Int).Mul(share , big.NewInt(int64(stake.Amount)))
+			amount.Div(amount , big.NewInt(int64(candidate.Votes)))
+			if amount.Sign() <= 0 {
+				continue
+			}
+			result , err := setBalance(sysparam , addr , balancetransfer.BalanceOf(sysparam.SdkHandler , addr) + int(amount.Int64()))
+			if err != nil {
+				return nil , err
+			}
+			results = append(results , result)
+			paid += amount.Int64()
+		}
+	}
+	result , err := setBalance(sysparam , producer , balancetransfer.BalanceOf(sysparam.SdkHandler , producer) + int(BlockReword - paid))
+	if err != nil {
+		return nil , err
+	}
+	return append(results , result) , nil
+}
+
+//Slash punishes a producer caught signing two blocks at the same height:SlashPercent percent of its
+//stake is taken,ReporterPercent percent of it goes to the sender and the producer is jailed
+func Slash(from types.Address , param map[string]interface{} , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	logger.Trace("Start: Slash.")
+	evidence , err := parseEvidence(param)
+	if err != nil {
+		return nil , err
+	}
+	producer , err := verifyEvidence(from , evidence , sysparam)
+	if err != nil {
+		return nil , err
+	}
+	if isSlashed(sysparam.SdkHandler , producer , evidence.Number()) {
+		return nil , fmt.Errorf("Slash:%s already slashed at %d" , producer.Hex() , evidence.Number())
+	}
+	stake := StakeOf(sysparam.SdkHandler , producer)
+	staked := stake.Amount * SlashPercent / 100
+	stake.Amount -= staked
+	taken := staked
+	for i := range stake.Unbonding {
+		cut := stake.Unbonding[i].Amount * SlashPercent / 100
+		stake.Unbonding[i].Amount -= cut
+		taken += cut
+	}
+	results := []intertypes.ActionResult{}
+	result , err := setValue(sysparam , StakingAddress , stakeKey(producer) , stake)
+	if err != nil {
+		return nil , err
+	}
+	results = append(results , result)
+
+	votes , err := addVotes(sysparam , stake.Votes , -staked)
+	if err != nil {
+		return nil , err
+	}
+	results = append(results , votes...)
+
+	candidate := CandidateOf(sysparam.SdkHandler , producer)
+	candidate.Jailed = true
+	if result , err = setValue(sysparam , StakingAddress , candidateKey(producer) , candidate);err != nil {
+		return nil , err
+	}
+	results = append(results , result)
+
+	if reword := taken * ReporterPercent / 100;reword > 0 {
+		if result , err = setBalance(sysparam , from , balancetransfer.BalanceOf(sysparam.SdkHandler , from) + reword);err != nil {
+			return nil , err
+		}
+		results = append(results , result)
+	}
+	if result , err = setValue(sysparam , StakingAddress , slashedKey(producer , evidence.Number()) , true);err != nil {
+		return nil , err
+	}
+	return append(results , result) , nil
+}
+
+func GetStake(from types.Address , param map[string]interface{} , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	addrStr , ok := param["address"].(string)
+	if !ok {
+		return nil , errors.New("GetStake:no address")
+	}
+	data , err := json.Marshal(StakeOf(sysparam.SdkHandler , types.HexToAddress(addrStr)))
+	if err != nil {
+		return nil , err
+	}
+	return []intertypes.ActionResult{{Val:data}} , nil
+}
+
+func GetCandidates(from types.Address , param map[string]interface{} , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	data , err := json.Marshal(Tallies(sysparam.SdkHandler))
+	if err != nil {
+		return nil , err
+	}
+	return []intertypes.ActionResult{{Val:data}} , nil
+}
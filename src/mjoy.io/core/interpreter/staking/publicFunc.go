@@ -0,0 +1,154 @@
+package staking
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/interpreter/intertypes"
+	"mjoy.io/core/sdk"
+	"sort"
+	"strconv"
+)
+
+//getValue reads a value of the contract storage,reporting whether it exists
+func getValue(sdkHandler *sdk.TmpStatusManager , key []byte , v interface{})bool{
+	data := sdk.Sys_GetValue(sdkHandler , StakingAddress , key)
+	if nil == data{
+		return false
+	}
+	return json.Unmarshal(data , v) == nil
+}
+
+//setValue writes a value into the storage of a contract,returning the action result recording it
+func setValue(sysparam *intertypes.SystemParams , contract types.Address , key []byte , v interface{})(intertypes.ActionResult , error){
+	data , err := json.Marshal(v)
+	if err != nil {
+		return intertypes.ActionResult{} , fmt.Errorf("ContractStaking:Marshal json:%s" , err.Error())
+	}
+	if err = sdk.Sys_SetValue(sysparam.SdkHandler , contract , key , data);err != nil{
+		return intertypes.ActionResult{} , fmt.Errorf("ContractStaking:Set :%s" , err.Error())
+	}
+	result := intertypes.ActionResult{Key:key , Val:data}
+	if contract != StakingAddress {
+		result.Contract = &contract
+	}
+	return result , nil
+}
+
+//setBalance writes the balance of an account into the balance transfer contract
+func setBalance(sysparam *intertypes.SystemParams , addr types.Address , amount int)(intertypes.ActionResult , error){
+	return setValue(sysparam , balancetransfer.BalanceTransferAddress , addr[:] , &balancetransfer.BalanceValue{Amount:amount})
+}
+
+//StakeOf returns the stake of an account as seen by the sdk handler
+func StakeOf(sdkHandler *sdk.TmpStatusManager , addr types.Address)*StakeValue{
+	stake := new(StakeValue)
+	getValue(sdkHandler , stakeKey(addr) , stake)
+	return stake
+}
+
+//CandidateOf returns the vote tally of a candidate as seen by the sdk handler
+func CandidateOf(sdkHandler *sdk.TmpStatusManager , addr types.Address)*CandidateValue{
+	candidate := new(CandidateValue)
+	getValue(sdkHandler , candidateKey(addr) , candidate)
+	return candidate
+}
+
+//Tallies returns the candidates with votes and not jailed,the most voted first and ties broken by address
+func Tallies(sdkHandler *sdk.TmpStatusManager)[]CandidateTally{
+	list := new(CandidateList)
+	getValue(sdkHandler , candidatesKey , list)
+
+	tallies := make([]CandidateTally , 0 , len(list.All))
+	for _ , c := range list.All {
+		addr := types.HexToAddress(c)
+		if candidate := CandidateOf(sdkHandler , addr);candidate.Votes > 0 && !candidate.Jailed {
+			tallies = append(tallies , CandidateTally{addr , candidate.Votes})
+		}
+	}
+	sort.Slice(tallies , func(i , j int)bool{
+		if tallies[i].Votes != tallies[j].Votes {
+			return tallies[i].Votes > tallies[j].Votes
+		}
+		return bytes.Compare(tallies[i].Address[:] , tallies[j].Address[:]) < 0
+	})
+	return tallies
+}
+
+func parseAmount(param map[string]interface{})(int , error){
+	amountStr , ok := param["amount"].(string)
+	if !ok {
+		return 0 , errors.New("ContractStaking: no amount")
+	}
+	amount , err := strconv.Atoi(amountStr)
+	if err != nil || amount <= 0 {
+		return 0 , errors.New("ContractStaking: amount format is not right")
+	}
+	return amount , nil
+}
+
+func parseCandidates(param map[string]interface{})([]types.Address , error){
+	all , ok := param["candidates"].([]interface{})
+	if !ok {
+		return nil , errors.New("ContractStaking: no candidates")
+	}
+	if len(all) > MaxVotes {
+		return nil , fmt.Errorf("ContractStaking: %d candidates , at most %d" , len(all) , MaxVotes)
+	}
+	candidates := make([]types.Address , 0 , len(all))
+	seen := make(map[types.Address]bool)
+	for _ , v := range all {
+		str , ok := v.(string)
+		if !ok || !types.IsHexAddress(str) {
+			return nil , errors.New("ContractStaking: candidate is not an address")
+		}
+		addr := types.HexToAddress(str)
+		if !seen[addr] {
+			seen[addr] = true
+			candidates = append(candidates , addr)
+		}
+	}
+	return candidates , nil
+}
+
+func parseEvidence(param map[string]interface{})(*block.Evidence , error){
+	evidenceStr , ok := param["evidence"].(string)
+	if !ok {
+		return nil , errors.New("ContractStaking: no evidence")
+	}
+	data , err := hex.DecodeString(evidenceStr)
+	if err != nil {
+		return nil , errors.New("ContractStaking: evidence format is not right")
+	}
+	evidence := new(block.Evidence)
+	if _ , err = evidence.UnmarshalMsg(data);err != nil {
+		return nil , errors.New("ContractStaking: evidence format is not right")
+	}
+	return evidence , nil
+}
+
+//verifyEvidence returns the producer which signed both headers of the evidence reported by from.
+//Evidence is refused when the producers are not elected by stake,jailing them would not remove them
+func verifyEvidence(from types.Address , evidence *block.Evidence , sysparam *intertypes.SystemParams)(types.Address , error){
+	if !sysparam.Staking {
+		return types.Address{} , errors.New("ContractStaking: producers are not elected by stake")
+	}
+	producer , err := evidence.Producer(block.NewBlockSigner(sysparam.ChainId))
+	if err != nil {
+		return types.Address{} , fmt.Errorf("ContractStaking: %s" , err.Error())
+	}
+	if producer == from {
+		return types.Address{} , errors.New("ContractStaking: producer can't report itself")
+	}
+	return producer , nil
+}
+
+//isSlashed reports whether a producer was already slashed for the given height
+func isSlashed(sdkHandler *sdk.TmpStatusManager , producer types.Address , number uint64)bool{
+	return sdk.Sys_GetValue(sdkHandler , StakingAddress , slashedKey(producer , number)) != nil
+}
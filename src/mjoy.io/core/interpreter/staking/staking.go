@@ -0,0 +1,130 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"mjoy.io/common/types"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/interpreter/intertypes"
+	"strconv"
+)
+
+type DoFunc func(types.Address , map[string]interface{} ,  *intertypes.SystemParams)([]intertypes.ActionResult , error)
+
+//ContractStaking keeps the stakes and the votes electing the block producers
+type ContractStaking struct {
+	funcMapper map[int]DoFunc
+}
+
+//managed by vm
+func NewContractStaking()*ContractStaking{
+	s := new(ContractStaking)
+	s.init()
+	return s
+}
+
+func (this *ContractStaking)init(){
+	//register call Back
+	this.funcMapper = make(map[int]DoFunc)
+	this.funcMapper[Stake_FunId] = Stake                   //lock balance as stake
+	this.funcMapper[Unstake_FunId] = Unstake               //start unbonding stake
+	this.funcMapper[Withdraw_FunId] = Withdraw             //move unbonded stake back to balance
+	this.funcMapper[Vote_FunId] = Vote                     //vote for producer candidates
+	this.funcMapper[RewordVoters_FunId] = RewordVoters     //block reword for the producer and its voters
+	this.funcMapper[GetStake_FunId] = GetStake
+	this.funcMapper[GetCandidates_FunId] = GetCandidates
+	this.funcMapper[Slash_FunId] = Slash                   //punish a producer signing two blocks at a height
+}
+
+func parseFuncId(jsonParams map[string]interface{})(int , error){
+	v , ok := jsonParams["funcId"].(string)
+	if !ok {
+		return 0 , errors.New("ContractStaking: Params not contain funcId")
+	}
+	funcId , err := strconv.Atoi(v)
+	if err != nil {
+		return 0 , errors.New("ContractStaking: Params  funcId format is not right")
+	}
+	return funcId , nil
+}
+
+//DoFun serves the calls not made by a transaction,which can only read
+func (this *ContractStaking)DoFun(params []byte , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	jsonParams , err := balancetransfer.ParseParms(params)
+	if err != nil {
+		return nil,err
+	}
+	funcId , err := parseFuncId(jsonParams)
+	if err != nil {
+		return nil , err
+	}
+	if funcId != GetStake_FunId && funcId != GetCandidates_FunId {
+		return nil , fmt.Errorf("ContractStaking: func Id:%d needs a sender" , funcId)
+	}
+	return this.funcMapper[funcId](types.Address{} , jsonParams , sysparam)
+}
+
+//DoFunFrom serves the calls of a transaction sender
+func (this *ContractStaking)DoFunFrom(from types.Address , params []byte , sysparam *intertypes.SystemParams)([]intertypes.ActionResult , error){
+	jsonParams , err := balancetransfer.ParseParms(params)
+	if err != nil {
+		return nil,err
+	}
+	funcId , err := parseFuncId(jsonParams)
+	if err != nil {
+		return nil , err
+	}
+	if doFunc,ok := this.funcMapper[funcId];ok {
+		return doFunc(from , jsonParams , sysparam)
+	}
+	return nil , fmt.Errorf("ContractStaking: no Func Id:%d find in map" , funcId)
+}
+
+//PreCheck is run by the txpool before admitting a transaction calling the contract,against pending state
+func (this *ContractStaking)PreCheck(from types.Address , params []byte , sysparam *intertypes.SystemParams)error{
+	jsonParams , err := balancetransfer.ParseParms(params)
+	if err != nil {
+		return err
+	}
+	funcId , err := parseFuncId(jsonParams)
+	if err != nil {
+		return err
+	}
+	switch funcId {
+	case RewordVoters_FunId:
+		return fmt.Errorf("ContractStaking: func Id:%d is reserved for the system" , funcId)
+	case Stake_FunId:
+		amount , err := parseAmount(jsonParams)
+		if err != nil {
+			return err
+		}
+		if balance := balancetransfer.BalanceOf(sysparam.SdkHandler , from);balance < amount {
+			return fmt.Errorf("ContractStaking: has %d , but want %d" , balance , amount)
+		}
+	case Unstake_FunId:
+		amount , err := parseAmount(jsonParams)
+		if err != nil {
+			return err
+		}
+		if stake := StakeOf(sysparam.SdkHandler , from);stake.Amount < amount {
+			return fmt.Errorf("ContractStaking: staked %d , but want %d" , stake.Amount , amount)
+		}
+	case Vote_FunId:
+		if _ , err := parseCandidates(jsonParams);err != nil {
+			return err
+		}
+	case Slash_FunId:
+		evidence , err := parseEvidence(jsonParams)
+		if err != nil {
+			return err
+		}
+		producer , err := verifyEvidence(from , evidence , sysparam)
+		if err != nil {
+			return err
+		}
+		if isSlashed(sysparam.SdkHandler , producer , evidence.Number()) {
+			return fmt.Errorf("ContractStaking: %s already slashed at %d" , producer.Hex() , evidence.Number())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package staking
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/interpreter/intertypes"
+	"mjoy.io/core/sdk"
+	"mjoy.io/core/state"
+	"mjoy.io/params"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/database"
+)
+
+//newTestParams returns system params over an empty state of a chain electing its producers by stake
+func newTestParams(t *testing.T)*intertypes.SystemParams{
+	db , _ := database.OpenMemDB()
+	statedb , err := state.New(types.Hash{} , state.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to create state: %v" , err)
+	}
+	sysparam := intertypes.MakeSystemParams(sdk.NewTmpStatusManager(db , statedb , types.Address{}) , nil)
+	sysparam.BlockNumber = 1
+	sysparam.ChainId = big.NewInt(1)
+	sysparam.Staking = true
+	return sysparam
+}
+
+//call runs a function of the contract for a transaction sender
+func call(sysparam *intertypes.SystemParams , from types.Address , params []byte)error{
+	_ , err := NewContractStaking().DoFunFrom(from , params , sysparam)
+	return err
+}
+
+func fund(t *testing.T , sysparam *intertypes.SystemParams , addr types.Address , amount int){
+	if _ , err := setBalance(sysparam , addr , amount);err != nil {
+		t.Fatalf("failed to set balance: %v" , err)
+	}
+}
+
+func checkBalance(t *testing.T , sysparam *intertypes.SystemParams , addr types.Address , want int){
+	if have := balancetransfer.BalanceOf(sysparam.SdkHandler , addr);have != want {
+		t.Fatalf("balance of %x mismatch: have %d, want %d" , addr , have , want)
+	}
+}
+
+func checkVotes(t *testing.T , sysparam *intertypes.SystemParams , addr types.Address , want int){
+	if have := CandidateOf(sysparam.SdkHandler , addr).Votes;have != want {
+		t.Fatalf("votes of %x mismatch: have %d, want %d" , addr , have , want)
+	}
+}
+
+func TestStakeUnstakeWithdraw(t *testing.T){
+	sysparam := newTestParams(t)
+	from := types.Address{1}
+	fund(t , sysparam , from , 1000)
+
+	if err := call(sysparam , from , MakeStakeParam(1500));err == nil {
+		t.Fatalf("stake over the balance accepted")
+	}
+	if err := call(sysparam , from , MakeStakeParam(600));err != nil {
+		t.Fatalf("failed to stake: %v" , err)
+	}
+	checkBalance(t , sysparam , from , 400)
+
+	if err := call(sysparam , from , MakeUnstakeParam(700));err == nil {
+		t.Fatalf("unstake over the stake accepted")
+	}
+	if err := call(sysparam , from , MakeUnstakeParam(200));err != nil {
+		t.Fatalf("failed to unstake: %v" , err)
+	}
+	stake := StakeOf(sysparam.SdkHandler , from)
+	if stake.Amount != 400 || len(stake.Unbonding) != 1 || stake.Unbonding[0] != (Unbond{200 , 1 + UnbondingPeriod}) {
+		t.Fatalf("stake mismatch: have %+v" , stake)
+	}
+	//the unbonding stake stays locked until its release
+	sysparam.BlockNumber = UnbondingPeriod
+	if err := call(sysparam , from , MakeWithdrawParam());err == nil {
+		t.Fatalf("withdraw before the release accepted")
+	}
+	sysparam.BlockNumber = 1 + UnbondingPeriod
+	if err := call(sysparam , from , MakeWithdrawParam());err != nil {
+		t.Fatalf("failed to withdraw: %v" , err)
+	}
+	checkBalance(t , sysparam , from , 600)
+	if stake := StakeOf(sysparam.SdkHandler , from);stake.Amount != 400 || len(stake.Unbonding) != 0 {
+		t.Fatalf("stake after withdraw mismatch: have %+v" , stake)
+	}
+	if err := call(sysparam , from , MakeWithdrawParam());err == nil {
+		t.Fatalf("withdraw without unbonded stake accepted")
+	}
+}
+
+func TestVote(t *testing.T){
+	sysparam := newTestParams(t)
+	a , b := types.Address{1} , types.Address{2}
+	x , y := types.Address{0x10} , types.Address{0x20}
+	fund(t , sysparam , a , 1000)
+	fund(t , sysparam , b , 1000)
+
+	for _ , step := range []struct{
+		from types.Address
+		params []byte
+	}{
+		{a , MakeStakeParam(300)},
+		{b , MakeStakeParam(100)},
+		{a , MakeVoteParam([]types.Address{x , y})},
+		{b , MakeVoteParam([]types.Address{x})},
+	}{
+		if err := call(sysparam , step.from , step.params);err != nil {
+			t.Fatalf("failed to call the contract: %v" , err)
+		}
+	}
+	checkVotes(t , sysparam , x , 400)
+	checkVotes(t , sysparam , y , 300)
+
+	//votes follow the stake changes and are replaced by a new vote
+	if err := call(sysparam , a , MakeVoteParam([]types.Address{y}));err != nil {
+		t.Fatalf("failed to vote again: %v" , err)
+	}
+	if err := call(sysparam , b , MakeStakeParam(50));err != nil {
+		t.Fatalf("failed to stake: %v" , err)
+	}
+	if err := call(sysparam , a , MakeUnstakeParam(100));err != nil {
+		t.Fatalf("failed to unstake: %v" , err)
+	}
+	checkVotes(t , sysparam , x , 150)
+	checkVotes(t , sysparam , y , 200)
+
+	tallies := Tallies(sysparam.SdkHandler)
+	if len(tallies) != 2 || tallies[0] != (CandidateTally{y , 200}) || tallies[1] != (CandidateTally{x , 150}) {
+		t.Fatalf("tallies mismatch: have %+v" , tallies)
+	}
+	candidates := make([]types.Address , MaxVotes+1)
+	for i := range candidates {
+		candidates[i] = types.Address{byte(i) , 1}
+	}
+	if err := call(sysparam , a , MakeVoteParam(candidates));err == nil {
+		t.Fatalf("vote for too many candidates accepted")
+	}
+}
+
+func TestRewordVoters(t *testing.T){
+	sysparam := newTestParams(t)
+	producer , a , b := types.Address{0x10} , types.Address{1} , types.Address{2}
+
+	//a producer without votes keeps the whole reword
+	if err := call(sysparam , params.Address , MakeActionParamsReword(producer));err != nil {
+		t.Fatalf("failed to reword: %v" , err)
+	}
+	checkBalance(t , sysparam , producer , int(BlockReword))
+
+	fund(t , sysparam , a , 300)
+	fund(t , sysparam , b , 100)
+	for _ , from := range []types.Address{a , b} {
+		if err := call(sysparam , from , MakeStakeParam(balancetransfer.BalanceOf(sysparam.SdkHandler , from)));err != nil {
+			t.Fatalf("failed to stake: %v" , err)
+		}
+		if err := call(sysparam , from , MakeVoteParam([]types.Address{producer}));err != nil {
+			t.Fatalf("failed to vote: %v" , err)
+		}
+	}
+	if err := call(sysparam , a , MakeActionParamsReword(a));err == nil {
+		t.Fatalf("reword sent by an account accepted")
+	}
+	if err := NewContractStaking().PreCheck(a , MakeActionParamsReword(a) , sysparam);err == nil {
+		t.Fatalf("reword admitted by the pool")
+	}
+	if err := call(sysparam , params.Address , MakeActionParamsReword(producer));err != nil {
+		t.Fatalf("failed to reword: %v" , err)
+	}
+	//the voters split their share by stake
+	shared := int(BlockReword * (100 - ProducerShare) / 100)
+	checkBalance(t , sysparam , a , shared*3/4)
+	checkBalance(t , sysparam , b , shared/4)
+	checkBalance(t , sysparam , producer , 2*int(BlockReword) - shared)
+}
+
+//doubleSign returns the evidence of key signing two blocks at the same height
+func doubleSign(t *testing.T , sysparam *intertypes.SystemParams , key *ecdsa.PrivateKey)*block.Evidence{
+	signer := block.NewBlockSigner(sysparam.ChainId)
+	sign := func(time int64)*block.Header{
+		header , err := block.SignHeader(&block.Header{Number:types.NewBigInt(*big.NewInt(10)) , Time:types.NewBigInt(*big.NewInt(time))} , signer , key)
+		if err != nil {
+			t.Fatalf("failed to sign header: %v" , err)
+		}
+		return header
+	}
+	return &block.Evidence{First:sign(1000) , Second:sign(1001)}
+}
+
+func TestSlash(t *testing.T){
+	sysparam := newTestParams(t)
+	key , _ := crypto.GenerateKey()
+	producer , reporter := crypto.PubkeyToAddress(key.PublicKey) , types.Address{1}
+
+	fund(t , sysparam , producer , 1000)
+	for _ , params := range [][]byte{MakeStakeParam(1000) , MakeVoteParam([]types.Address{producer}) , MakeUnstakeParam(200)} {
+		if err := call(sysparam , producer , params);err != nil {
+			t.Fatalf("failed to call the contract: %v" , err)
+		}
+	}
+	slash , err := MakeSlashParam(doubleSign(t , sysparam , key))
+	if err != nil {
+		t.Fatalf("failed to encode evidence: %v" , err)
+	}
+	contract := NewContractStaking()
+
+	//evidence can't jail the producers of an engine not electing them by stake
+	sysparam.Staking = false
+	if err := contract.PreCheck(reporter , slash , sysparam);err == nil {
+		t.Fatalf("evidence admitted without staking")
+	}
+	if err := call(sysparam , reporter , slash);err == nil {
+		t.Fatalf("evidence accepted without staking")
+	}
+	sysparam.Staking = true
+
+	//nor can the producer collect the reword of reporting itself
+	if err := contract.PreCheck(producer , slash , sysparam);err == nil {
+		t.Fatalf("evidence reported by the producer admitted")
+	}
+	if err := call(sysparam , producer , slash);err == nil {
+		t.Fatalf("evidence reported by the producer accepted")
+	}
+	if err := contract.PreCheck(reporter , slash , sysparam);err != nil {
+		t.Fatalf("evidence rejected: %v" , err)
+	}
+	if err := call(sysparam , reporter , slash);err != nil {
+		t.Fatalf("failed to slash: %v" , err)
+	}
+	//half of the stake and of the unbonding stake is taken,a tenth of it rewords the reporter
+	stake := StakeOf(sysparam.SdkHandler , producer)
+	if stake.Amount != 400 || len(stake.Unbonding) != 1 || stake.Unbonding[0].Amount != 100 {
+		t.Fatalf("slashed stake mismatch: have %+v" , stake)
+	}
+	checkBalance(t , sysparam , reporter , 50)
+	checkVotes(t , sysparam , producer , 400)
+	if !CandidateOf(sysparam.SdkHandler , producer).Jailed || len(Tallies(sysparam.SdkHandler)) != 0 {
+		t.Fatalf("slashed producer not jailed")
+	}
+	if err := contract.PreCheck(reporter , slash , sysparam);err == nil {
+		t.Fatalf("evidence admitted twice")
+	}
+	if err := call(sysparam , reporter , slash);err == nil {
+		t.Fatalf("evidence accepted twice")
+	}
+}
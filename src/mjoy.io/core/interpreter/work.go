@@ -12,6 +12,7 @@ import (
 type Work struct {
 	actions []transaction.Action
 	contractAddress types.Address
+	from types.Address    //sender of the transaction,if any
 	sysParams *intertypes.SystemParams
 	resultChan chan intertypes.WorkResult
 }
@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"bytes"
 	"testing"
 	"mjoy.io/utils/database"
 	"mjoy.io/common/types"
@@ -18,7 +19,7 @@ func TestDataStore(t *testing.T){
 		panic(err)
 	}
 
-	sdkHandler := NewTmpStatusManager(types.Hash{} , db)
+	sdkHandler := NewTmpStatusManager(db , nil , types.Address{})
 	contractAddr := types.Address{}
 	contractAddr[0] = 1
 
@@ -32,3 +33,23 @@ func TestDataStore(t *testing.T){
 
 
 
+
+func TestRevertToSnapshot(t *testing.T){
+	db,_ := database.OpenMemDB()
+	sdkHandler := NewTmpStatusManager(db , nil , types.Address{})
+	contractAddr := types.Address{1}
+	kept , dropped := []byte{1} , []byte{2}
+
+	Sys_SetValue(sdkHandler , contractAddr , kept , []byte{1})
+	snapshot := sdkHandler.Snapshot()
+	Sys_SetValue(sdkHandler , contractAddr , kept , []byte{2})
+	Sys_SetValue(sdkHandler , contractAddr , dropped , []byte{3})
+	sdkHandler.RevertToSnapshot(snapshot)
+
+	if r := Sys_GetValue(sdkHandler , contractAddr , kept);!bytes.Equal(r , []byte{1}) {
+		t.Fatalf("reverted value: got %x expected 01" , r)
+	}
+	if node := sdkHandler.ExistContract(contractAddr);node.ExistValue(TmpKey{contractAddress:contractAddr , key:types.BytesToAddress(dropped)}) != nil {
+		t.Fatalf("value set after the snapshot not dropped")
+	}
+}
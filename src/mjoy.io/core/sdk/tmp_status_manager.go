@@ -25,6 +25,14 @@ type TmpStatusManager struct {
 	state *state.StateDB
 	coinBase types.Address
 	TmpConTracts map[types.Address]*TmpStatusNode
+	journal []tmpJournalEntry    //values overwritten since the manager was created,to revert to a snapshot
+}
+
+//tmpJournalEntry is a value overwritten in the manager
+type tmpJournalEntry struct {
+	key TmpKey
+	prev []byte
+	existed bool
 }
 
 func NewTmpStatusManager(db database.IDatabaseGetter, state *state.StateDB , coinbase types.Address)*TmpStatusManager{
@@ -52,11 +60,38 @@ func (this *TmpStatusManager)SetValue(contractAddress types.Address , key []byte
 	//step 2: make TmpKey
 	tmpKey := TmpKey{contractAddress:contractAddress , key:types.BytesToAddress(key)}
 
-	//step 3:set value
+	//step 3:journal the previous value,then set value
+	prev , existed := statusNode.Modified[tmpKey]
+	this.journal = append(this.journal , tmpJournalEntry{key:tmpKey , prev:prev , existed:existed})
 	statusNode.SetValue(tmpKey , value)
 	return nil
 }
 
+//Snapshot returns an identifier of the current values,to revert them to
+func (this *TmpStatusManager)Snapshot()int{
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+
+	return len(this.journal)
+}
+
+//RevertToSnapshot drops the values set since the snapshot was taken
+func (this *TmpStatusManager)RevertToSnapshot(snapshot int){
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	for i := len(this.journal) - 1 ; i >= snapshot ; i-- {
+		entry := this.journal[i]
+		statusNode := this.TmpConTracts[entry.key.contractAddress]
+		if entry.existed {
+			statusNode.Modified[entry.key] = entry.prev
+		}else{
+			delete(statusNode.Modified , entry.key)
+		}
+	}
+	this.journal = this.journal[:snapshot]
+}
+
 
 func (this *TmpStatusManager)GetValue(contractAddress types.Address , key []byte)[]byte{
 	this.mu.RLock()
@@ -0,0 +1,130 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: pruner.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package state
+
+import (
+	"bytes"
+
+	"github.com/tinylib/msgp/msgp"
+	"mjoy.io/common/types"
+	"mjoy.io/trie"
+	"mjoy.io/utils/database"
+)
+
+// Pruner deletes by mark and sweep the state data no longer reachable from a
+// set of state roots: the account and storage trie nodes, the contract code and
+// the contract values stored under the Keccak hash the storage tries keep.
+// They are the only entries of the chain database keyed by a bare hash.
+//
+// Nothing may be written to the database between marking and sweeping, lest a
+// node shared with a new state gets swept.
+type Pruner struct {
+	db     database.IIterableDatabase
+	marked map[types.Hash]struct{}
+}
+
+// NewPruner creates a pruner of the state held in db.
+func NewPruner(db database.IIterableDatabase) *Pruner {
+	return &Pruner{db: db, marked: make(map[types.Hash]struct{})}
+}
+
+// Marked returns the number of state entries marked as reachable.
+func (p *Pruner) Marked() int {
+	return len(p.marked)
+}
+
+// Mark marks everything reachable from the state root as in use. The subtries
+// marked by an earlier root are skipped, so marking many close states costs
+// about as much as marking one.
+func (p *Pruner) Mark(root types.Hash) error {
+	tr, err := trie.NewSecure(root, p.db, 0)
+	if err != nil {
+		return err
+	}
+	return p.markTrie(tr.NodeIterator(nil), func(blob []byte) error {
+		var account Account
+		if err := msgp.Decode(bytes.NewBuffer(blob), &account); err != nil {
+			return err
+		}
+		if !bytes.Equal(account.CodeHash, emptyCodeHash) {
+			p.marked[types.BytesToHash(account.CodeHash)] = struct{}{}
+		}
+		storage, err := trie.NewSecure(account.Root, p.db, 0)
+		if err != nil {
+			return err
+		}
+		return p.markTrie(storage.NodeIterator(nil), func(blob []byte) error {
+			// Storage slots hold the hash the contract value is stored under
+			var value types.Hash
+			if err := msgp.Decode(bytes.NewBuffer(blob), &value); err != nil {
+				return err
+			}
+			p.marked[value] = struct{}{}
+			return nil
+		})
+	})
+}
+
+// markTrie marks the nodes of a trie, calling leaf on every value of the
+// subtries not marked yet.
+func (p *Pruner) markTrie(it trie.NodeIterator, leaf func(blob []byte) error) error {
+	for descend := true; it.Next(descend); {
+		descend = true
+		if hash := it.Hash(); hash != (types.Hash{}) {
+			if _, ok := p.marked[hash]; ok {
+				descend = false
+				continue
+			}
+			p.marked[hash] = struct{}{}
+		}
+		if it.Leaf() {
+			if err := leaf(it.LeafBlob()); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+// Sweep deletes the state entries not marked, returning how many were deleted.
+func (p *Pruner) Sweep() (int, error) {
+	var (
+		deleted int
+		err     error
+	)
+	walkErr := p.db.Walk(nil, func(key, value []byte) bool {
+		if len(key) != types.HashLength {
+			return true
+		}
+		if _, ok := p.marked[types.BytesToHash(key)]; ok {
+			return true
+		}
+		if err = p.db.Delete(key); err != nil {
+			return false
+		}
+		deleted++
+		return true
+	})
+	if err != nil {
+		return deleted, err
+	}
+	return deleted, walkErr
+}
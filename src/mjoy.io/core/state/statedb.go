@@ -66,9 +66,21 @@ type StateDB struct {
 	validRevisions []revision
 	nextRevisionId int
 
+	// Net storage writes of every finalisation, nil unless recording
+	storageWrites [][]StorageWrite
+
 	lock sync.Mutex
 }
 
+// StorageWrite is the net change of a storage slot between two finalisations
+// of the state.
+type StorageWrite struct {
+	Account types.Address
+	Key     types.Hash
+	Prev    types.Hash
+	Value   types.Hash
+}
+
 
 // Create a new state from a given trie
 func New(root types.Hash, db Database) (*StateDB, error) {
@@ -469,9 +481,55 @@ func (self *StateDB) GetRefund() uint64 {
 	return self.refund
 }
 
+// RecordStorageWrites makes the state record the net storage writes journaled
+// between finalisations, that is of every transaction applied, for
+// StorageWrites to return.
+func (s *StateDB) RecordStorageWrites() {
+	s.storageWrites = make([][]StorageWrite, 0)
+}
+
+// StorageWrites returns the storage writes recorded, one slice per finalisation
+// in order.
+func (s *StateDB) StorageWrites() [][]StorageWrite {
+	return s.storageWrites
+}
+
+// recordStorageWrites records the net storage writes of the journal, in the
+// order the slots were first written.
+func (s *StateDB) recordStorageWrites() {
+	var (
+		writes []StorageWrite
+		seen   = make(map[types.Address]map[types.Hash]struct{})
+	)
+	for _, entry := range s.journal {
+		change, ok := entry.(storageChange)
+		if !ok {
+			continue
+		}
+		if seen[*change.account] == nil {
+			seen[*change.account] = make(map[types.Hash]struct{})
+		}
+		if _, ok := seen[*change.account][change.key]; ok {
+			continue
+		}
+		seen[*change.account][change.key] = struct{}{}
+		obj := s.stateObjects[*change.account]
+		if obj == nil {
+			continue
+		}
+		if value := obj.cachedStorage[change.key]; value != change.prevalue {
+			writes = append(writes, StorageWrite{*change.account, change.key, change.prevalue, value})
+		}
+	}
+	s.storageWrites = append(s.storageWrites, writes)
+}
+
 // Finalise finalises the state by removing the self destructed objects
 // and clears the journal as well as the refunds.
 func (s *StateDB) Finalise(deleteEmptyObjects bool) {
+	if s.storageWrites != nil {
+		s.recordStorageWrites()
+	}
 	for addr := range s.stateObjectsDirty {
 		stateObject := s.stateObjects[addr]
 		if stateObject.suicided || (deleteEmptyObjects && stateObject.empty()) {
@@ -0,0 +1,104 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: walk.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tinylib/msgp/msgp"
+	"mjoy.io/common/types"
+	"mjoy.io/trie"
+)
+
+// StateEntryKind tells what a state entry holds.
+type StateEntryKind int
+
+const (
+	TrieNodeEntry StateEntryKind = iota // Node of the account trie or of a storage trie
+	CodeEntry                           // Contract code
+	ValueEntry                          // Contract value
+)
+
+// WalkState calls fn once on every state entry reachable from root, with its
+// kind and the hash it is stored under: the account and storage trie nodes, the
+// contract code and the contract values. It fails on the first entry missing
+// from db, so walking a state with a no-op fn checks it is complete.
+func WalkState(db trie.Database, root types.Hash, fn func(kind StateEntryKind, hash types.Hash, blob []byte) error) error {
+	seen := make(map[types.Hash]struct{})
+	visit := func(kind StateEntryKind, hash types.Hash) error {
+		seen[hash] = struct{}{}
+		blob, err := db.Get(hash[:])
+		if err != nil || len(blob) == 0 {
+			return fmt.Errorf("state entry %x missing", hash[:])
+		}
+		return fn(kind, hash, blob)
+	}
+	walkTrie := func(root types.Hash, leaf func(blob []byte) error) error {
+		tr, err := trie.NewSecure(root, db, 0)
+		if err != nil {
+			return err
+		}
+		it := tr.NodeIterator(nil)
+		for descend := true; it.Next(descend); {
+			descend = true
+			if hash := it.Hash(); hash != (types.Hash{}) {
+				if _, ok := seen[hash]; ok {
+					descend = false
+					continue
+				}
+				if err := visit(TrieNodeEntry, hash); err != nil {
+					return err
+				}
+			}
+			if it.Leaf() {
+				if err := leaf(it.LeafBlob()); err != nil {
+					return err
+				}
+			}
+		}
+		return it.Error()
+	}
+	return walkTrie(root, func(blob []byte) error {
+		var account Account
+		if err := msgp.Decode(bytes.NewBuffer(blob), &account); err != nil {
+			return err
+		}
+		if !bytes.Equal(account.CodeHash, emptyCodeHash) {
+			if _, ok := seen[types.BytesToHash(account.CodeHash)]; !ok {
+				if err := visit(CodeEntry, types.BytesToHash(account.CodeHash)); err != nil {
+					return err
+				}
+			}
+		}
+		return walkTrie(account.Root, func(blob []byte) error {
+			// Storage slots hold the hash the contract value is stored under
+			var value types.Hash
+			if err := msgp.Decode(bytes.NewBuffer(blob), &value); err != nil {
+				return err
+			}
+			if _, ok := seen[value]; ok {
+				return nil
+			}
+			return visit(ValueEntry, value)
+		})
+	})
+}
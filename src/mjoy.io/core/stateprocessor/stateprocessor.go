@@ -93,6 +93,9 @@ func (p *StateProcessor) Process(blk *block.Block, statedb *state.StateDB, db da
 	//make sysparam
 
 	sysparam := intertypes.MakeSystemParams(sdkHandler , vmHandler )
+	sysparam.BlockNumber = header.Number.IntVal.Uint64()
+	sysparam.ChainId = p.config.ChainId
+	sysparam.Staking = p.config.Dpos != nil
 
 
 	// Iterate over and process the individual transactions
@@ -100,7 +103,7 @@ func (p *StateProcessor) Process(blk *block.Block, statedb *state.StateDB, db da
 		statedb.Prepare(tx.Hash(), blk.Hash(), i)
 		receipt, err := ApplyTransaction(p.config, nil, statedb, header, tx, dbcache , sysparam)
 		if err != nil {
-			logger.Errorf("ApplyTransacton Wrong.....:",err.Error())
+			logger.Errorf("ApplyTransacton Wrong.....: %v", err)
 
 			return  nil, nil, nil, err
 		}
@@ -112,7 +115,9 @@ func (p *StateProcessor) Process(blk *block.Block, statedb *state.StateDB, db da
 	// TODO: need to be compeleted, now skip this step
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
 	if p.engine != nil {
-		p.engine.Finalize(p.cs, header, statedb, blk.Transactions(), receipts, false)
+		if _, err := p.engine.Finalize(p.cs, header, statedb, blk.Transactions(), receipts, false); err != nil {
+			return nil, nil, nil, err
+		}
 	}
 
 	return  dbcache, receipts, allLogs, nil
@@ -134,9 +139,12 @@ func ApplyTransaction(config *params.ChainConfig, author *types.Address, statedb
 		author = &header.BlockProducer
 	}
 	_, failed, err := ApplyMessage(statedb, msg, *author, cache, header,sysparam)
-	if err != nil {
+	if err != nil && !failed {
 		return nil, err
 	}
+	if failed {
+		logger.Debug("Transaction actions failed", "hash", tx.Hash(), "err", err)
+	}
 	// Update the state with pending changes
 	statedb.Finalise(true)
 
@@ -160,5 +168,5 @@ func ApplyTransaction(config *params.ChainConfig, author *types.Address, statedb
 	}
 	receipt.Bloom = bloom.CreateBloom(topics)
 
-	return receipt, err
+	return receipt, nil
 }
@@ -29,6 +29,8 @@ import (
 	"mjoy.io/utils/crypto"
 	"mjoy.io/core/blockchain/block"
 	"mjoy.io/core/interpreter/intertypes"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/interpreter/multisig"
 )
 
 /*
@@ -62,6 +64,10 @@ type Message interface {
 	Actions()[]transaction.Action
 	Nonce() uint64
 	CheckNonce() bool
+	Fee() uint64
+	ValidAfter() uint64
+	ValidUntil() uint64
+	KeySet() types.Hash
 }
 
 // NewStateTransition initialises and returns a new state transition object.
@@ -90,7 +96,7 @@ func (st *StateTransition) from() types.Address {
 	return f
 }
 
-func (st *StateTransition) preCheck() error {
+func (st *StateTransition) preCheck(sysparam *intertypes.SystemParams) error {
 	msg := st.msg
 	sender := st.from()
 
@@ -103,10 +109,45 @@ func (st *StateTransition) preCheck() error {
 			return core.ErrNonceTooLow
 		}
 	}
-	return nil
+	// Make sure the key set authorizing the transaction is the sender's
+	if err := multisig.CheckSender(sysparam.SdkHandler, sender, msg.KeySet()); err != nil {
+		return err
+	}
+	// Make sure the block is inside the transaction's validity window
+	return transaction.CheckValidityWindow(msg.ValidAfter(), msg.ValidUntil(),
+		st.header.Number.IntVal.Uint64(), st.header.Time.IntVal.Uint64())
 }
 
 
+// chargeFee moves the declared transaction fee from the sender to the block
+// producer through the balance transfer contract.
+func (st *StateTransition) chargeFee(sender types.Address, sysparam *intertypes.SystemParams) ([]*interpreter.MemDatabase, error) {
+	fee := st.msg.Fee()
+	if fee == 0 {
+		return nil, nil
+	}
+	action := transaction.MakeAction(balancetransfer.BalanceTransferAddress, balancetransfer.MakeTransferFeeParam(sender, fee))
+	result := <-sysparam.VmHandler.SendWork(sender, action, sysparam)
+	if result.Err != nil {
+		logger.Debug("fee charge fail.", result.Err)
+		return nil, core.ErrInsufficientFee
+	}
+	resultMem := []*interpreter.MemDatabase{}
+	for _, res := range result.Results {
+		resultMem = append(resultMem, &interpreter.MemDatabase{resultContract(*action.Address, res), res.Key, res.Val})
+	}
+	return resultMem, nil
+}
+
+// resultContract returns the contract whose storage an action result is written
+// to, the called one unless the result names another.
+func resultContract(called types.Address, result intertypes.ActionResult) types.Address {
+	if result.Contract != nil {
+		return *result.Contract
+	}
+	return called
+}
+
 // make log  function
 func MakeLog(address types.Address, results interpreter.ActionResults, blockNumber uint64) *transaction.Log {
 	topics := []types.Hash{}
@@ -127,9 +168,11 @@ func MakeLog(address types.Address, results interpreter.ActionResults, blockNumb
 
 // TransitionDb will transition the state by applying the current message and
 // returning the result. It returns an error if it
-// failed. An error indicates a consensus issue.
+// failed. An error indicates a consensus issue, unless failed is set: the
+// actions failed, their changes were reverted, but the fee was charged and
+// the nonce used so the transaction is included.
 func (st *StateTransition) TransitionDb(sysparam *intertypes.SystemParams) (ret []byte, failed bool, err error) {
-	if err = st.preCheck(); err != nil {
+	if err = st.preCheck(sysparam); err != nil {
 		return
 	}
 
@@ -140,15 +183,30 @@ func (st *StateTransition) TransitionDb(sysparam *intertypes.SystemParams) (ret
 	if len(st.actions) == 2 && st.actions[1].Address == nil{
 		contractCreation = true
 	}
+
+	// The fee is paid first, a transaction unable to pay can't be included.
+	// Paid, it stays paid whatever the actions do.
+	feeMem, err := st.chargeFee(sender, sysparam)
+	if err != nil {
+		return nil, false, err
+	}
+	st.writeResults(feeMem)
+	nonce := st.statedb.GetNonce(sender)
+
 	// Snapshot !!!!!!!!!!!!!!!!!
 	snapshot := st.statedb.Snapshot()
+	sdkSnapshot := sysparam.SdkHandler.Snapshot()
+	revert := func() {
+		st.statedb.RevertToSnapshot(snapshot)
+		sysparam.SdkHandler.RevertToSnapshot(sdkSnapshot)
+		st.statedb.SetNonce(sender, nonce+1)
+	}
 
 	resultMem := []*interpreter.MemDatabase{}
-
 	if contractCreation {
 		results, _, err := interpreter.Create(sender, st.statedb, st.actions)
 		if err != nil {
-			st.statedb.RevertToSnapshot(snapshot)
+			revert()
 			return nil, true, err
 		}
 		for _, res := range results {
@@ -160,19 +218,19 @@ func (st *StateTransition) TransitionDb(sysparam *intertypes.SystemParams) (ret
 		st.statedb.AddLog(log)
 	} else {
 		logger.Debugf("Just process actions transaction.")
-		st.statedb.SetNonce(sender, st.statedb.GetNonce(sender) +1 )
+		st.statedb.SetNonce(sender, nonce+1)
 		for _,action := range st.actions {
 			//resulst := make(chan interpreter.WorkResult)
 			resulstChan :=sysparam.VmHandler.SendWork(sender,action,sysparam)
 
 			result := <-resulstChan
 			if result.Err != nil {
-				logger.Error("action fail.", result.Err)
-				st.statedb.RevertToSnapshot(snapshot)
-				return nil, true, err
+				logger.Debug("action fail.", result.Err)
+				revert()
+				return nil, true, result.Err
 			}
 			for _, res := range result.Results {
-				resM := &interpreter.MemDatabase{*action.Address, res.Key, res.Val}
+				resM := &interpreter.MemDatabase{resultContract(*action.Address, res), res.Key, res.Val}
 				resultMem = append(resultMem, resM)
 			}
 			// make log for receipt
@@ -180,7 +238,14 @@ func (st *StateTransition) TransitionDb(sysparam *intertypes.SystemParams) (ret
 			st.statedb.AddLog(log)
 		}
 	}
+	st.writeResults(resultMem)
+
+	return ret, false, nil
+}
 
+// writeResults writes the values set by actions to the contract storages and
+// collects them for the block producer to write to the database.
+func (st *StateTransition) writeResults(resultMem []*interpreter.MemDatabase) {
 	for _, result := range resultMem {
 		storgageKey := append(result.Address.Bytes(), result.Key...)
 
@@ -195,6 +260,4 @@ func (st *StateTransition) TransitionDb(sysparam *intertypes.SystemParams) (ret
 			storageValHash.Bytes(),
 			result.Val}
 	}
-
-	return ret, false, err
 }
@@ -0,0 +1,116 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: statetransition_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package stateprocessor
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/interpreter"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/interpreter/intertypes"
+	"mjoy.io/core/sdk"
+	"mjoy.io/core/state"
+	"mjoy.io/core/transaction"
+	"mjoy.io/params"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/database"
+)
+
+// setTestBalance writes the balance of an account to the balance transfer
+// contract storage, as a committed block would.
+func setTestBalance(t *testing.T, db database.IDatabase, statedb *state.StateDB, addr types.Address, amount int) {
+	val, err := json.Marshal(balancetransfer.BalanceValue{Amount: amount})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := balancetransfer.BalanceTransferAddress
+	valHash := crypto.Keccak256Hash(val)
+	statedb.SetState(contract, crypto.Keccak256Hash(append(contract.Bytes(), addr[:]...)), valHash)
+	if err := db.Put(valHash[:], val); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A transaction whose second action fails pays its fee once and uses its
+// nonce, while the changes of its first action are reverted.
+func TestFailedActionChargesFeeOnce(t *testing.T) {
+	db, _ := database.OpenMemDB()
+	statedb, _ := state.New(types.Hash{}, state.NewDatabase(db))
+
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	coinbase := types.Address{0x01}
+	receiver := types.Address{0x02}
+	// The contract account holds code, as in the genesis, or finalising drops it
+	statedb.SetCode(balancetransfer.BalanceTransferAddress, []byte{1, 2, 3, 4, 5})
+	setTestBalance(t, db, statedb, sender, 1000)
+
+	header := &block.Header{
+		Number:        types.NewBigInt(*big.NewInt(1)),
+		Time:          types.NewBigInt(*big.NewInt(10)),
+		BlockProducer: coinbase,
+	}
+	contract := balancetransfer.BalanceTransferAddress
+	tx := transaction.NewTransaction(0, transaction.ActionSlice{
+		transaction.MakeAction(contract, balancetransfer.MakaBalanceTransferParam(sender, receiver, 100)),
+		transaction.MakeAction(contract, balancetransfer.MakaBalanceTransferParam(sender, receiver, 5000)),
+	}).WithFee(10)
+	tx, err := transaction.SignTx(tx, transaction.MakeSigner(params.TestChainConfig, &header.Number.IntVal), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sdkHandler := sdk.NewTmpStatusManager(db, statedb, coinbase)
+	sysparam := intertypes.MakeSystemParams(sdkHandler, interpreter.NewVm())
+	cache := &DbCache{Cache: make(map[string]interpreter.MemDatabase)}
+	receipt, err := ApplyTransaction(params.TestChainConfig, &coinbase, statedb, header, tx, cache, sysparam)
+	if err != nil {
+		t.Fatalf("failed transaction not included: %v", err)
+	}
+	if receipt.Status != transaction.ReceiptStatusFailed {
+		t.Fatalf("receipt status: got %d expected failed", receipt.Status)
+	}
+	if nonce := statedb.GetNonce(sender); nonce != 1 {
+		t.Fatalf("sender nonce: got %d expected 1", nonce)
+	}
+
+	// The balances seen by the next transactions of the block
+	want := map[types.Address]int{sender: 990, coinbase: 10, receiver: 0}
+	for addr, balance := range want {
+		if got := balancetransfer.BalanceOf(sdkHandler, addr); got != balance {
+			t.Errorf("pending balance of %x: got %d expected %d", addr, got, balance)
+		}
+	}
+	// The balances written by the block
+	for _, value := range cache.Cache {
+		db.Put(value.Key, value.Val)
+	}
+	written := sdk.NewTmpStatusManager(db, statedb, coinbase)
+	for addr, balance := range want {
+		if got := balancetransfer.BalanceOf(written, addr); got != balance {
+			t.Errorf("written balance of %x: got %d expected %d", addr, got, balance)
+		}
+	}
+}
@@ -6,12 +6,21 @@ import (
 	"encoding/json"
 	"errors"
 	"mjoy.io/common/types"
+	"mjoy.io/common/types/util/hex"
 )
 
+var _ = (*txdataMarshaling)(nil)
+
 func (t Txdata) MarshalJSON() ([]byte, error) {
 	type Txdata struct {
 		AccountNonce	uint64		`json:"nonce"   gencodec:"required"`
 		Actions		ActionSlice	`json:"actions" gencodec:"required"`
+		Fee		uint64		`json:"fee"`
+		ValidAfter	uint64		`json:"validAfter"`
+		ValidUntil	uint64		`json:"validUntil"`
+		MultiSig	*MultiSig	`json:"multiSig"`
+		SigScheme	uint8		`json:"sigScheme"`
+		PubKey		hex.Bytes	`json:"pubKey"`
 		V		*types.BigInt	`json:"v"       gencodec:"required"`
 		R		*types.BigInt	`json:"r"       gencodec:"required"`
 		S		*types.BigInt	`json:"s"       gencodec:"required"`
@@ -20,6 +29,12 @@ func (t Txdata) MarshalJSON() ([]byte, error) {
 	var enc Txdata
 	enc.AccountNonce = t.AccountNonce
 	enc.Actions = t.Actions
+	enc.Fee = t.Fee
+	enc.ValidAfter = t.ValidAfter
+	enc.ValidUntil = t.ValidUntil
+	enc.MultiSig = t.MultiSig
+	enc.SigScheme = t.SigScheme
+	enc.PubKey = t.PubKey
 	enc.V = t.V
 	enc.R = t.R
 	enc.S = t.S
@@ -31,6 +46,12 @@ func (t *Txdata) UnmarshalJSON(input []byte) error {
 	type Txdata struct {
 		AccountNonce	*uint64		`json:"nonce"   gencodec:"required"`
 		Actions		ActionSlice	`json:"actions" gencodec:"required"`
+		Fee		*uint64		`json:"fee"`
+		ValidAfter	*uint64		`json:"validAfter"`
+		ValidUntil	*uint64		`json:"validUntil"`
+		MultiSig	*MultiSig	`json:"multiSig"`
+		SigScheme	*uint8		`json:"sigScheme"`
+		PubKey		*hex.Bytes	`json:"pubKey"`
 		V		*types.BigInt	`json:"v"       gencodec:"required"`
 		R		*types.BigInt	`json:"r"       gencodec:"required"`
 		S		*types.BigInt	`json:"s"       gencodec:"required"`
@@ -48,6 +69,24 @@ func (t *Txdata) UnmarshalJSON(input []byte) error {
 		return errors.New("missing required field 'actions' for Txdata")
 	}
 	t.Actions = dec.Actions
+	if dec.Fee != nil {
+		t.Fee = *dec.Fee
+	}
+	if dec.ValidAfter != nil {
+		t.ValidAfter = *dec.ValidAfter
+	}
+	if dec.ValidUntil != nil {
+		t.ValidUntil = *dec.ValidUntil
+	}
+	if dec.MultiSig != nil {
+		t.MultiSig = dec.MultiSig
+	}
+	if dec.SigScheme != nil {
+		t.SigScheme = *dec.SigScheme
+	}
+	if dec.PubKey != nil {
+		t.PubKey = *dec.PubKey
+	}
 	if dec.V == nil {
 		return errors.New("missing required field 'v' for Txdata")
 	}
@@ -0,0 +1,154 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: multisig.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package transaction
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"mjoy.io/common/types"
+	"mjoy.io/utils/crypto"
+)
+
+//go:generate msgp
+
+var (
+	ErrInvalidKeySet          = errors.New("invalid multisig key set")
+	ErrNotMultiSig            = errors.New("not a multisig transaction")
+	ErrNotMultiSigOwner       = errors.New("signature from a key outside the multisig key set")
+	ErrDuplicateSignature     = errors.New("duplicate multisig signature")
+	ErrInsufficientSignatures = errors.New("not enough multisig signatures")
+	ErrMultiSigMismatch       = errors.New("multisig transactions differ in signed content")
+)
+
+// MaxMultiSigOwners bounds the size of a key set, so the cost of recovering the
+// signatures of a transaction stays small.
+const MaxMultiSigOwners = 16
+
+// multiSigPrefix separates the key set encoding from other signed content.
+var multiSigPrefix = []byte("mjoy multisig")
+
+// MultiSigSignature is one owner's signature over the transaction, encoded the
+// same way as the V, R, S values of a single key transaction.
+type MultiSigSignature struct {
+	V *types.BigInt `json:"v"`
+	R *types.BigInt `json:"r"`
+	S *types.BigInt `json:"s"`
+}
+
+// MultiSig is the m-of-n authorization of a transaction sent from Account. The
+// signatures are verified against the key set (Threshold and Owners) carried by
+// the transaction, which must be the one registered for the account in the
+// multisig inner contract. The registration is checked against state when the
+// transaction is pooled and executed.
+type MultiSig struct {
+	Account    types.Address       `json:"account"`
+	Threshold  uint64              `json:"threshold"`
+	Owners     []types.Address     `json:"owners"`
+	Signatures []MultiSigSignature `json:"signatures"`
+}
+
+// NewMultiSig creates an unsigned key set of account requiring threshold of the
+// owners. The owners are stored in canonical order.
+func NewMultiSig(account types.Address, threshold uint64, owners []types.Address) (*MultiSig, error) {
+	sorted := make([]types.Address, len(owners))
+	copy(sorted, owners)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i][:], sorted[j][:]) < 0 })
+
+	ms := &MultiSig{Account: account, Threshold: threshold, Owners: sorted}
+	if err := ms.Validate(); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// Validate checks that the key set is well formed: an account, a threshold
+// between one and the number of owners, and distinct owners in canonical order.
+func (ms *MultiSig) Validate() error {
+	if ms.Account == (types.Address{}) {
+		return ErrInvalidKeySet
+	}
+	if len(ms.Owners) == 0 || len(ms.Owners) > MaxMultiSigOwners {
+		return ErrInvalidKeySet
+	}
+	if ms.Threshold == 0 || ms.Threshold > uint64(len(ms.Owners)) {
+		return ErrInvalidKeySet
+	}
+	for i := 1; i < len(ms.Owners); i++ {
+		if bytes.Compare(ms.Owners[i-1][:], ms.Owners[i][:]) >= 0 {
+			return ErrInvalidKeySet
+		}
+	}
+	if len(ms.Signatures) > len(ms.Owners) {
+		return ErrDuplicateSignature
+	}
+	return nil
+}
+
+// KeySetHash returns the hash identifying the key set, compared with the one
+// registered for the account.
+func (ms *MultiSig) KeySetHash() types.Hash {
+	return KeySetHash(ms.Threshold, ms.Owners)
+}
+
+// KeySetHash returns the hash identifying a key set requiring threshold of the
+// owners, given in canonical order.
+func KeySetHash(threshold uint64, owners []types.Address) types.Hash {
+	return crypto.Keccak256Hash(keySetBytes(threshold, owners))
+}
+
+// IsOwner reports whether addr is part of the key set.
+func (ms *MultiSig) IsOwner(addr types.Address) bool {
+	for _, owner := range ms.Owners {
+		if owner == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// keySetBytes returns the canonical encoding of a key set, which is both
+// hashed for the registration and covered by every signature.
+func keySetBytes(threshold uint64, owners []types.Address) []byte {
+	buf := make([]byte, 0, len(multiSigPrefix)+8+len(owners)*types.AddressLength)
+	buf = append(buf, multiSigPrefix...)
+	for i := uint(0); i < 8; i++ {
+		buf = append(buf, byte(threshold>>(56-8*i)))
+	}
+	for _, owner := range owners {
+		buf = append(buf, owner[:]...)
+	}
+	return buf
+}
+
+// copy returns a deep copy of the key set and its signatures.
+func (ms *MultiSig) copy() *MultiSig {
+	cpy := &MultiSig{
+		Account:    ms.Account,
+		Threshold:  ms.Threshold,
+		Owners:     make([]types.Address, len(ms.Owners)),
+		Signatures: make([]MultiSigSignature, len(ms.Signatures)),
+	}
+	copy(cpy.Owners, ms.Owners)
+	copy(cpy.Signatures, ms.Signatures)
+	return cpy
+}
@@ -0,0 +1,515 @@
+package transaction
+
+// NOTE: THIS FILE WAS PRODUCED BY THE
+// MSGP CODE GENERATION TOOL (github.com/tinylib/msgp)
+// DO NOT EDIT
+
+import (
+	"github.com/tinylib/msgp/msgp"
+	"mjoy.io/common/types"
+)
+
+// DecodeMsg implements msgp.Decodable
+func (z *MultiSig) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Account":
+			err = z.Account.DecodeMsg(dc)
+			if err != nil {
+				return
+			}
+		case "Threshold":
+			z.Threshold, err = dc.ReadUint64()
+			if err != nil {
+				return
+			}
+		case "Owners":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				return
+			}
+			if cap(z.Owners) >= int(zb0002) {
+				z.Owners = (z.Owners)[:zb0002]
+			} else {
+				z.Owners = make([]types.Address, zb0002)
+			}
+			for za0001 := range z.Owners {
+				err = z.Owners[za0001].DecodeMsg(dc)
+				if err != nil {
+					return
+				}
+			}
+		case "Signatures":
+			var zb0003 uint32
+			zb0003, err = dc.ReadArrayHeader()
+			if err != nil {
+				return
+			}
+			if cap(z.Signatures) >= int(zb0003) {
+				z.Signatures = (z.Signatures)[:zb0003]
+			} else {
+				z.Signatures = make([]MultiSigSignature, zb0003)
+			}
+			for za0002 := range z.Signatures {
+				err = z.Signatures[za0002].DecodeMsg(dc)
+				if err != nil {
+					return
+				}
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *MultiSig) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 4
+	// write "Account"
+	err = en.Append(0x84, 0xa7, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74)
+	if err != nil {
+		return
+	}
+	err = z.Account.EncodeMsg(en)
+	if err != nil {
+		return
+	}
+	// write "Threshold"
+	err = en.Append(0xa9, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Threshold)
+	if err != nil {
+		return
+	}
+	// write "Owners"
+	err = en.Append(0xa6, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Owners)))
+	if err != nil {
+		return
+	}
+	for za0001 := range z.Owners {
+		err = z.Owners[za0001].EncodeMsg(en)
+		if err != nil {
+			return
+		}
+	}
+	// write "Signatures"
+	err = en.Append(0xaa, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Signatures)))
+	if err != nil {
+		return
+	}
+	for za0002 := range z.Signatures {
+		err = z.Signatures[za0002].EncodeMsg(en)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *MultiSig) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 4
+	// string "Account"
+	o = append(o, 0x84, 0xa7, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74)
+	o, err = z.Account.MarshalMsg(o)
+	if err != nil {
+		return
+	}
+	// string "Threshold"
+	o = append(o, 0xa9, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64)
+	o = msgp.AppendUint64(o, z.Threshold)
+	// string "Owners"
+	o = append(o, 0xa6, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Owners)))
+	for za0001 := range z.Owners {
+		o, err = z.Owners[za0001].MarshalMsg(o)
+		if err != nil {
+			return
+		}
+	}
+	// string "Signatures"
+	o = append(o, 0xaa, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Signatures)))
+	for za0002 := range z.Signatures {
+		o, err = z.Signatures[za0002].MarshalMsg(o)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *MultiSig) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Account":
+			bts, err = z.Account.UnmarshalMsg(bts)
+			if err != nil {
+				return
+			}
+		case "Threshold":
+			z.Threshold, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				return
+			}
+		case "Owners":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				return
+			}
+			if cap(z.Owners) >= int(zb0002) {
+				z.Owners = (z.Owners)[:zb0002]
+			} else {
+				z.Owners = make([]types.Address, zb0002)
+			}
+			for za0001 := range z.Owners {
+				bts, err = z.Owners[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					return
+				}
+			}
+		case "Signatures":
+			var zb0003 uint32
+			zb0003, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				return
+			}
+			if cap(z.Signatures) >= int(zb0003) {
+				z.Signatures = (z.Signatures)[:zb0003]
+			} else {
+				z.Signatures = make([]MultiSigSignature, zb0003)
+			}
+			for za0002 := range z.Signatures {
+				bts, err = z.Signatures[za0002].UnmarshalMsg(bts)
+				if err != nil {
+					return
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *MultiSig) Msgsize() (s int) {
+	s = 1 + 8 + z.Account.Msgsize() + 10 + msgp.Uint64Size + 7 + msgp.ArrayHeaderSize
+	for za0001 := range z.Owners {
+		s += z.Owners[za0001].Msgsize()
+	}
+	s += 11 + msgp.ArrayHeaderSize
+	for za0002 := range z.Signatures {
+		s += z.Signatures[za0002].Msgsize()
+	}
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *MultiSigSignature) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "V":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					return
+				}
+				z.V = nil
+			} else {
+				if z.V == nil {
+					z.V = new(types.BigInt)
+				}
+				err = z.V.DecodeMsg(dc)
+				if err != nil {
+					return
+				}
+			}
+		case "R":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					return
+				}
+				z.R = nil
+			} else {
+				if z.R == nil {
+					z.R = new(types.BigInt)
+				}
+				err = z.R.DecodeMsg(dc)
+				if err != nil {
+					return
+				}
+			}
+		case "S":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					return
+				}
+				z.S = nil
+			} else {
+				if z.S == nil {
+					z.S = new(types.BigInt)
+				}
+				err = z.S.DecodeMsg(dc)
+				if err != nil {
+					return
+				}
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *MultiSigSignature) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "V"
+	err = en.Append(0x83, 0xa1, 0x56)
+	if err != nil {
+		return
+	}
+	if z.V == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.V.EncodeMsg(en)
+		if err != nil {
+			return
+		}
+	}
+	// write "R"
+	err = en.Append(0xa1, 0x52)
+	if err != nil {
+		return
+	}
+	if z.R == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.R.EncodeMsg(en)
+		if err != nil {
+			return
+		}
+	}
+	// write "S"
+	err = en.Append(0xa1, 0x53)
+	if err != nil {
+		return
+	}
+	if z.S == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.S.EncodeMsg(en)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *MultiSigSignature) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "V"
+	o = append(o, 0x83, 0xa1, 0x56)
+	if z.V == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.V.MarshalMsg(o)
+		if err != nil {
+			return
+		}
+	}
+	// string "R"
+	o = append(o, 0xa1, 0x52)
+	if z.R == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.R.MarshalMsg(o)
+		if err != nil {
+			return
+		}
+	}
+	// string "S"
+	o = append(o, 0xa1, 0x53)
+	if z.S == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.S.MarshalMsg(o)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *MultiSigSignature) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "V":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.V = nil
+			} else {
+				if z.V == nil {
+					z.V = new(types.BigInt)
+				}
+				bts, err = z.V.UnmarshalMsg(bts)
+				if err != nil {
+					return
+				}
+			}
+		case "R":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.R = nil
+			} else {
+				if z.R == nil {
+					z.R = new(types.BigInt)
+				}
+				bts, err = z.R.UnmarshalMsg(bts)
+				if err != nil {
+					return
+				}
+			}
+		case "S":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.S = nil
+			} else {
+				if z.S == nil {
+					z.S = new(types.BigInt)
+				}
+				bts, err = z.S.UnmarshalMsg(bts)
+				if err != nil {
+					return
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *MultiSigSignature) Msgsize() (s int) {
+	s = 1 + 2
+	if z.V == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.V.Msgsize()
+	}
+	s += 2
+	if z.R == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.R.Msgsize()
+	}
+	s += 2
+	if z.S == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.S.Msgsize()
+	}
+	return
+}
@@ -0,0 +1,118 @@
+package transaction
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/utils/crypto"
+)
+
+var testMultiSigAccount = types.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+func newMultiSigKeys(t *testing.T, n int) ([]*ecdsa.PrivateKey, []types.Address) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	owners := make([]types.Address, n)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("GenerateKey error: %v", err)
+		}
+		keys[i], owners[i] = key, crypto.PubkeyToAddress(key.PublicKey)
+	}
+	return keys, owners
+}
+
+func TestMultiSigSender(t *testing.T) {
+	keys, owners := newMultiSigKeys(t, 3)
+	ms, err := NewMultiSig(testMultiSigAccount, 2, owners)
+	if err != nil {
+		t.Fatalf("NewMultiSig error: %v", err)
+	}
+	tx := newTransaction(1, []Action{{Address: &testAddress, Params: []byte{1}}}).WithMultiSig(ms)
+
+	// Each owner signs its own copy, then the partial signatures are combined
+	partial0, err := SignTx(tx, mSigner, keys[0])
+	if err != nil {
+		t.Fatalf("SignTx error: %v", err)
+	}
+	if _, err := mSigner.Sender(partial0); err != ErrInsufficientSignatures {
+		t.Errorf("Sender error mismatch: have %v, want %v", err, ErrInsufficientSignatures)
+	}
+	partial2, err := SignTx(tx, mSigner, keys[2])
+	if err != nil {
+		t.Fatalf("SignTx error: %v", err)
+	}
+	combined, err := CombineSignatures(mSigner, partial0, partial2, partial0)
+	if err != nil {
+		t.Fatalf("CombineSignatures error: %v", err)
+	}
+	if n := len(combined.Data.MultiSig.Signatures); n != 2 {
+		t.Fatalf("signature count mismatch: have %d, want 2", n)
+	}
+	from, err := mSigner.Sender(combined)
+	if err != nil {
+		t.Fatalf("Sender error: %v", err)
+	}
+	if from != ms.Account {
+		t.Errorf("sender mismatch: have %x, want %x", from, ms.Account)
+	}
+	// Partial signatures survive a JSON round trip
+	enc, err := json.Marshal(combined)
+	if err != nil {
+		t.Fatalf("MarshalJSON error: %v", err)
+	}
+	var dec Transaction
+	if err := json.Unmarshal(enc, &dec); err != nil {
+		t.Fatalf("UnmarshalJSON error: %v", err)
+	}
+	if from, err := mSigner.Sender(&dec); err != nil || from != ms.Account {
+		t.Errorf("decoded sender mismatch: have %x (%v), want %x", from, err, ms.Account)
+	}
+}
+
+func TestMultiSigRejects(t *testing.T) {
+	keys, owners := newMultiSigKeys(t, 2)
+	ms, err := NewMultiSig(testMultiSigAccount, 2, owners)
+	if err != nil {
+		t.Fatalf("NewMultiSig error: %v", err)
+	}
+	tx := newTransaction(1, []Action{{Address: &testAddress, Params: []byte{1}}}).WithMultiSig(ms)
+
+	// Signing twice with the same key does not reach the threshold
+	twice, _ := SignTx(tx, mSigner, keys[0])
+	twice, _ = SignTx(twice, mSigner, keys[0])
+	if _, err := mSigner.Sender(twice); err != ErrDuplicateSignature {
+		t.Errorf("duplicate signer error mismatch: have %v, want %v", err, ErrDuplicateSignature)
+	}
+	// Outsiders can not sign for the key set
+	outsider, _ := SignTx(tx, mSigner, keys[0])
+	outsider, _ = SignTx(outsider, mSigner, testKey)
+	if _, err := mSigner.Sender(outsider); err != ErrNotMultiSigOwner {
+		t.Errorf("outsider error mismatch: have %v, want %v", err, ErrNotMultiSigOwner)
+	}
+	// Changing the threshold or the account invalidates the signatures
+	signed, _ := SignTx(tx, mSigner, keys[0])
+	signed, _ = SignTx(signed, mSigner, keys[1])
+	forged := &Transaction{Data: signed.Data}
+	forged.Data.MultiSig = signed.Data.MultiSig.copy()
+	forged.Data.MultiSig.Threshold = 1
+	if from, err := mSigner.Sender(forged); err == nil && from == ms.Account {
+		t.Errorf("key set is not covered by the signatures")
+	}
+	forged.Data.MultiSig = signed.Data.MultiSig.copy()
+	forged.Data.MultiSig.Account = testAddress
+	if from, err := mSigner.Sender(forged); err == nil && from == testAddress {
+		t.Errorf("account is not covered by the signatures")
+	}
+	if _, err := NewMultiSig(types.Address{}, 2, owners); err != ErrInvalidKeySet {
+		t.Errorf("account error mismatch: have %v, want %v", err, ErrInvalidKeySet)
+	}
+	if _, err := NewMultiSig(testMultiSigAccount, 3, owners); err != ErrInvalidKeySet {
+		t.Errorf("threshold error mismatch: have %v, want %v", err, ErrInvalidKeySet)
+	}
+	if _, err := NewMultiSig(testMultiSigAccount, 1, []types.Address{owners[0], owners[0]}); err != ErrInvalidKeySet {
+		t.Errorf("duplicate owner error mismatch: have %v, want %v", err, ErrInvalidKeySet)
+	}
+}
@@ -0,0 +1,96 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: ordering.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package transaction
+
+import (
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"mjoy.io/common/types"
+)
+
+// OrderingPolicy decides the order in which a block producer packs pending
+// transactions. The transactions of an account are always packed in nonce
+// order, the policy ranks the next transactions of the accounts against each
+// other and may hold some back.
+//
+// A policy keeps the state of the block being packed, it is not safe for
+// concurrent use and can't be shared between blocks packed at the same time.
+type OrderingPolicy interface {
+	// Reset starts the ordering of a new block.
+	Reset()
+
+	// Priority ranks a transaction, the higher the sooner it is packed.
+	Priority(tx *Transaction, from types.Address) *big.Int
+
+	// Accept reports whether the transaction may be packed next. A transaction
+	// held back is skipped together with the later ones of its account.
+	Accept(tx *Transaction, from types.Address) bool
+
+	// Packed records that the transaction was included in the block.
+	Packed(tx *Transaction, from types.Address)
+}
+
+// Arrival returns the time the pool first saw the transaction, or the zero
+// time if it did not.
+func (tx *Transaction) Arrival() time.Time {
+	if nano := atomic.LoadInt64(&tx.arrival); nano != 0 {
+		return time.Unix(0, nano)
+	}
+	return time.Time{}
+}
+
+// SetArrival records the time the pool first saw the transaction.
+func (tx *Transaction) SetArrival(t time.Time) {
+	atomic.StoreInt64(&tx.arrival, t.UnixNano())
+}
+
+// orderedHead is the next transaction of an account, with its rank.
+type orderedHead struct {
+	tx       *Transaction
+	from     types.Address
+	priority *big.Int
+	first    bool // Packed before anything else
+}
+
+type orderedHeads []*orderedHead
+
+func (s orderedHeads) Len() int { return len(s) }
+func (s orderedHeads) Less(i, j int) bool {
+	if s[i].first != s[j].first {
+		return s[i].first
+	}
+	return s[i].priority.Cmp(s[j].priority) > 0
+}
+func (s orderedHeads) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s *orderedHeads) Push(x interface{}) {
+	*s = append(*s, x.(*orderedHead))
+}
+
+func (s *orderedHeads) Pop() interface{} {
+	old := *s
+	n := len(old)
+	x := old[n-1]
+	*s = old[0 : n-1]
+	return x
+}
@@ -0,0 +1,74 @@
+package transaction
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"mjoy.io/common/types"
+	"mjoy.io/utils/crypto"
+)
+
+// arrivalPolicy packs the earliest arrivals first, and at most quota
+// transactions calling the limited address.
+type arrivalPolicy struct {
+	limited types.Address
+	quota   int
+	packed  int
+}
+
+func (p *arrivalPolicy) Reset() { p.packed = 0 }
+
+func (p *arrivalPolicy) Priority(tx *Transaction, from types.Address) *big.Int {
+	return big.NewInt(-tx.Arrival().UnixNano())
+}
+
+func (p *arrivalPolicy) Accept(tx *Transaction, from types.Address) bool {
+	return *tx.Data.Actions[0].Address != p.limited || p.packed < p.quota
+}
+
+func (p *arrivalPolicy) Packed(tx *Transaction, from types.Address) {
+	if *tx.Data.Actions[0].Address == p.limited {
+		p.packed++
+	}
+}
+
+func TestTransactionOrderingPolicy(t *testing.T) {
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	addr1, addr2 := crypto.PubkeyToAddress(key1.PublicKey), crypto.PubkeyToAddress(key2.PublicKey)
+	limited, free := types.BytesToAddress([]byte{1}), types.BytesToAddress([]byte{2})
+
+	now := time.Now()
+	sign := func(nonce uint64, key *ecdsa.PrivateKey, to types.Address, arrival int) *Transaction {
+		tx, err := SignTx(newTransaction(nonce, []Action{{Address: &to, Params: []byte{1}}}), mSigner, key)
+		if err != nil {
+			t.Fatalf("SignTx error: %v", err)
+		}
+		tx.SetArrival(now.Add(time.Duration(arrival) * time.Second))
+		return tx
+	}
+	reward := sign(0, testKey, free, 10)
+	txs := map[types.Address]Transactions{
+		addr1: {sign(0, key1, free, 2), sign(1, key1, limited, 3), sign(2, key1, free, 0)},
+		addr2: {sign(0, key2, limited, 1), sign(1, key2, free, 4)},
+	}
+	want := []*Transaction{reward, txs[addr2][0], txs[addr1][0], txs[addr2][1]}
+
+	set := NewTransactionsByPriorityAndNonce(mSigner, txs, reward, &arrivalPolicy{limited: limited, quota: 1})
+	var have []*Transaction
+	for tx := set.Peek(); tx != nil; tx = set.Peek() {
+		have = append(have, tx)
+		set.Packed()
+		set.Shift()
+	}
+	if len(have) != len(want) {
+		t.Fatalf("packed transaction count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, have[i].Hash(), want[i].Hash())
+		}
+	}
+}
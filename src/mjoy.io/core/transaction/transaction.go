@@ -36,15 +36,31 @@ import (
 )
 
 //go:generate msgp
-//msgp:ignore Message TransactionsByPriceAndNonce
+//msgp:ignore Message TransactionsByPriceAndNonce txdataMarshaling
 
-//go:generate gencodec -type Txdata  -out gen_tx_json.go
+//go:generate gencodec -type Txdata -field-override txdataMarshaling -out gen_tx_json.go
 
 var (
 	ErrInvalidSig = errors.New("invalid transaction v, r, s values")
 	errNoSigner   = errors.New("missing signing methods")
+
+	// ErrTxNotYetValid is returned if a transaction is applied before the start
+	// of its validity window.
+	ErrTxNotYetValid = errors.New("transaction not yet valid")
+
+	// ErrTxExpired is returned if a transaction is applied after the end of its
+	// validity window.
+	ErrTxExpired = errors.New("transaction expired")
 )
 
+// FeeRateUnit is the fraction of a fee unit that FeePerByte is expressed in, so
+// that small fees on large transactions can still be told apart.
+const FeeRateUnit = 1000
+
+// LockTimeThreshold is the boundary of the validity window values. A bound below
+// it is a block number, a bound at or above it is a unix timestamp in seconds.
+const LockTimeThreshold = 500000000
+
 // deriveSigner makes a *best* guess about which signer to use.
 func deriveSigner(V *big.Int) Signer {
 	return NewMSigner(deriveChainId(V))
@@ -57,6 +73,8 @@ type Transaction struct {
 	hash atomic.Value
 	size atomic.Value
 	from atomic.Value
+
+	arrival int64 // Unix time in nanoseconds the pool first saw the transaction
 }
 
 func (this * Transaction)PrintDataInfo() {
@@ -96,6 +114,16 @@ type ActionSlice []Action
 type Txdata struct {
 	AccountNonce 	uint64         	`json:"nonce"   gencodec:"required"`
 	Actions     	ActionSlice     `json:"actions" gencodec:"required"`
+	// Fee paid to the block producer through the balance transfer contract
+	Fee         	uint64          `json:"fee"`
+	// Validity window, zero means unbounded (see LockTimeThreshold)
+	ValidAfter  	uint64          `json:"validAfter"`
+	ValidUntil  	uint64          `json:"validUntil"`
+	// Multi-signature authorization, nil for single key transactions
+	MultiSig    	*MultiSig       `json:"multiSig"`
+	// Signature scheme, and the public key for schemes without key recovery
+	SigScheme   	uint8           `json:"sigScheme"`
+	PubKey      	[]byte          `json:"pubKey"`
 	// Signature values
 	V *types.BigInt                 `json:"v"       gencodec:"required"`
 	R *types.BigInt                 `json:"r"       gencodec:"required"`
@@ -105,6 +133,10 @@ type Txdata struct {
 	Hash *types.Hash                `json:"hash"    msg:"-"`
 }
 
+type txdataMarshaling struct {
+	PubKey hex.Bytes
+}
+
 //All actions is made by interpreter
 func NewTransaction(nonce uint64, actions ActionSlice) *Transaction {
 	return newTransaction(nonce, actions)
@@ -132,7 +164,19 @@ func newTransaction(nonce uint64, actions ActionSlice) *Transaction {
 
 // ChainId returns which chain id this transaction was signed for (if at all)
 func (tx *Transaction) ChainId() *big.Int {
-	return deriveChainId(&tx.Data.V.IntVal)
+	return deriveChainId(tx.signatureV())
+}
+
+// signatureV returns the V value carrying the chain id, which for multisig
+// transactions is the one of the first partial signature.
+func (tx *Transaction) signatureV() *big.Int {
+	if ms := tx.Data.MultiSig; ms != nil {
+		if len(ms.Signatures) == 0 || ms.Signatures[0].V == nil {
+			return new(big.Int)
+		}
+		return &ms.Signatures[0].V.IntVal
+	}
+	return &tx.Data.V.IntVal
 }
 
 // Protected returns whether the transaction is protected from replay protection.
@@ -164,22 +208,117 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 	if err := dec.UnmarshalJSON(input); err != nil {
 		return err
 	}
-	var V byte
-	if isProtectedV(&dec.V.IntVal) {
-		chainID := deriveChainId(&dec.V.IntVal).Uint64()
-		V = byte(dec.V.IntVal.Uint64() - 35 - 2*chainID)
-	} else {
-		V = byte(dec.V.IntVal.Uint64() - 27)
-	}
-	if !crypto.ValidateSignatureValues(V, &dec.R.IntVal, &dec.S.IntVal, false) {
+	if dec.MultiSig != nil {
+		for _, sig := range dec.MultiSig.Signatures {
+			if sig.V == nil || sig.R == nil || sig.S == nil || !validSignatureValues(&sig.V.IntVal, &sig.R.IntVal, &sig.S.IntVal) {
+				return ErrInvalidSig
+			}
+		}
+	} else if dec.SigScheme == crypto.SigSecp256k1 && !validSignatureValues(&dec.V.IntVal, &dec.R.IntVal, &dec.S.IntVal) {
 		return ErrInvalidSig
 	}
 	*tx = Transaction{Data: dec}
 	return nil
 }
 
+func validSignatureValues(v, r, s *big.Int) bool {
+	var V byte
+	if isProtectedV(v) {
+		chainID := deriveChainId(v).Uint64()
+		V = byte(v.Uint64() - 35 - 2*chainID)
+	} else {
+		V = byte(v.Uint64() - 27)
+	}
+	return crypto.ValidateSignatureValues(V, r, s, false)
+}
+
 func (tx *Transaction) Nonce() uint64      { return tx.Data.AccountNonce }
 func (tx *Transaction) CheckNonce() bool   { return true }
+func (tx *Transaction) Fee() uint64        { return tx.Data.Fee }
+func (tx *Transaction) ValidAfter() uint64 { return tx.Data.ValidAfter }
+func (tx *Transaction) ValidUntil() uint64 { return tx.Data.ValidUntil }
+func (tx *Transaction) SigScheme() uint8   { return tx.Data.SigScheme }
+
+// FeePerByte returns the fee paid per byte of the encoded transaction, in
+// 1/FeeRateUnit fee units.
+func (tx *Transaction) FeePerByte() *big.Int {
+	size := int64(tx.Size())
+	if size == 0 {
+		return new(big.Int)
+	}
+	rate := new(big.Int).SetUint64(tx.Data.Fee)
+	rate.Mul(rate, big.NewInt(FeeRateUnit))
+	return rate.Div(rate, big.NewInt(size))
+}
+
+// IsMultiSig reports whether the transaction is authorized by a key set rather
+// than by a single key.
+func (tx *Transaction) IsMultiSig() bool { return tx.Data.MultiSig != nil }
+
+// WithMultiSig returns a new transaction to be authorized by the given key set,
+// without any signatures. Its sender is the account of the key set.
+func (tx *Transaction) WithMultiSig(ms *MultiSig) *Transaction {
+	cpy := &Transaction{Data: tx.Data, Priority: tx.Priority}
+	cpy.Data.MultiSig = &MultiSig{Account: ms.Account, Threshold: ms.Threshold, Owners: ms.copy().Owners}
+	return cpy
+}
+
+// WithSigScheme returns a new unsigned transaction to be signed with the given
+// scheme. pub is the signing public key, needed by schemes without key recovery.
+func (tx *Transaction) WithSigScheme(scheme uint8, pub []byte) *Transaction {
+	cpy := &Transaction{Data: tx.Data, Priority: tx.Priority}
+	cpy.Data.SigScheme = scheme
+	cpy.Data.PubKey = append([]byte(nil), pub...)
+	return cpy
+}
+
+// WithFee returns a new unsigned transaction paying the given fee.
+func (tx *Transaction) WithFee(fee uint64) *Transaction {
+	cpy := &Transaction{Data: tx.Data, Priority: tx.Priority}
+	cpy.Data.Fee = fee
+	return cpy
+}
+
+// WithValidityWindow returns a new unsigned transaction restricted to the given
+// window. Both bounds are inclusive and a zero bound is not checked.
+func (tx *Transaction) WithValidityWindow(validAfter, validUntil uint64) *Transaction {
+	cpy := &Transaction{Data: tx.Data, Priority: tx.Priority}
+	cpy.Data.ValidAfter, cpy.Data.ValidUntil = validAfter, validUntil
+	return cpy
+}
+
+// CheckValidity reports whether the transaction may be included in a block with
+// the given number and timestamp.
+func (tx *Transaction) CheckValidity(number, time uint64) error {
+	return CheckValidityWindow(tx.Data.ValidAfter, tx.Data.ValidUntil, number, time)
+}
+
+// Expired reports whether the transaction can never again be included in a block
+// following the given number and timestamp.
+func (tx *Transaction) Expired(number, time uint64) bool {
+	return CheckValidityWindow(0, tx.Data.ValidUntil, number, time) == ErrTxExpired
+}
+
+// CheckValidityWindow checks the block number and timestamp against the bounds
+// of a validity window.
+func CheckValidityWindow(validAfter, validUntil, number, time uint64) error {
+	if validAfter != 0 && windowValue(validAfter, number, time) < validAfter {
+		return ErrTxNotYetValid
+	}
+	if validUntil != 0 && windowValue(validUntil, number, time) > validUntil {
+		return ErrTxExpired
+	}
+	return nil
+}
+
+// windowValue picks the block number or the timestamp, whichever the bound is
+// expressed in.
+func windowValue(bound, number, time uint64) uint64 {
+	if bound < LockTimeThreshold {
+		return number
+	}
+	return time
+}
 
 
 // Hash hashes the Msgp encoding of tx.
@@ -233,8 +372,14 @@ func (tx *Transaction) AsMessage(s Signer) (Message, error) {
 	msg := Message{
 		nonce:      tx.Data.AccountNonce,
 		actions:    newActions,
+		fee:        tx.Data.Fee,
+		validAfter: tx.Data.ValidAfter,
+		validUntil: tx.Data.ValidUntil,
 		checkNonce: true,
 	}
+	if tx.Data.MultiSig != nil {
+		msg.keySet = tx.Data.MultiSig.KeySetHash()
+	}
 	var err error
 	msg.from, err = Sender(s, tx)
 	return msg, err
@@ -248,10 +393,50 @@ func (tx *Transaction) WithSignature(signer Signer, sig []byte) (*Transaction, e
 		return nil, err
 	}
 	cpy := &Transaction{Data: tx.Data}
+	if tx.Data.MultiSig != nil {
+		// Partial signatures are collected next to each other
+		cpy.Data.MultiSig = tx.Data.MultiSig.copy()
+		cpy.Data.MultiSig.Signatures = append(cpy.Data.MultiSig.Signatures, MultiSigSignature{
+			V: &types.BigInt{*v}, R: &types.BigInt{*r}, S: &types.BigInt{*s},
+		})
+		return cpy, nil
+	}
 	cpy.Data.R, cpy.Data.S, cpy.Data.V = &types.BigInt{*r}, &types.BigInt{*s}, &types.BigInt{*v}
 	return cpy, nil
 }
 
+// CombineSignatures merges the partial signatures of copies of the same multisig
+// transaction, each signed by some of the owners, into a single transaction.
+// Signatures already present are not repeated.
+func CombineSignatures(signer Signer, txs ...*Transaction) (*Transaction, error) {
+	if len(txs) == 0 || !txs[0].IsMultiSig() {
+		return nil, ErrNotMultiSig
+	}
+	h := signer.Hash(txs[0])
+	cpy := &Transaction{Data: txs[0].Data}
+	cpy.Data.MultiSig = txs[0].Data.MultiSig.copy()
+	cpy.Data.MultiSig.Signatures = nil
+
+	seen := make(map[types.Hash]bool)
+	for _, tx := range txs {
+		if !tx.IsMultiSig() {
+			return nil, ErrNotMultiSig
+		}
+		if signer.Hash(tx) != h {
+			return nil, ErrMultiSigMismatch
+		}
+		for _, sig := range tx.Data.MultiSig.Signatures {
+			id := crypto.Keccak256Hash(sig.R.IntVal.Bytes(), sig.S.IntVal.Bytes())
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			cpy.Data.MultiSig.Signatures = append(cpy.Data.MultiSig.Signatures, sig)
+		}
+	}
+	return cpy, nil
+}
+
 //In Mjoy, all details of transaction dealing should not visiable for others except vm(interpreter)
 // Cost returns amount.
 //func (tx *Transaction) Cost() *big.Int {
@@ -260,7 +445,10 @@ func (tx *Transaction) WithSignature(signer Signer, sig []byte) (*Transaction, e
 //	return total
 //}
 func (tx *Transaction)GetPriority()*big.Int{
-	return big.NewInt(tx.Priority.Int64())
+	if tx.Priority == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(tx.Priority)
 }
 
 func (tx *Transaction)SetPriority(priority int){
@@ -275,7 +463,7 @@ func (tx *Transaction) RawSignatureValues() (*big.Int, *big.Int, *big.Int) {
 func (tx *Transaction) String() string {
 	var from string
 	if tx.Data.V != nil {
-		signer := deriveSigner(&tx.Data.V.IntVal)
+		signer := deriveSigner(tx.signatureV())
 		if f , err := Sender(signer , tx);err != nil {
 			from = "[invalid sender: invalid sig]"
 		}else{
@@ -291,6 +479,9 @@ func (tx *Transaction) String() string {
 	From:       (%s)
 	ActionLen:  (%d)
 	Nonce:      (%d)
+	Fee:        (%d)
+	ValidAfter: (%d)
+	ValidUntil: (%d)
 	V:          (%v)
 	S:          (%v)
 	R:          (%v)
@@ -299,6 +490,9 @@ func (tx *Transaction) String() string {
 	from,
 	len(tx.Data.Actions),
 	tx.Nonce(),
+	tx.Fee(),
+	tx.ValidAfter(),
+	tx.ValidUntil(),
 	(*hex.Big)(&tx.Data.V.IntVal),
 	(*hex.Big)(&tx.Data.S.IntVal),
 	(*hex.Big)(&tx.Data.R.IntVal),
@@ -405,46 +599,85 @@ func (s *TxByPriority) Pop() interface{} {
 	return x
 }
 
+// TransactionsByPriorityAndNonce represents a set of transactions that can return
+// transactions in a priority-honouring order while supporting removing entire
+// batches of transactions for non-executable accounts. The priority is given by
+// the ordering policy, or is the Priority of the transactions if there is none.
 type TransactionsByPriorityAndNonce struct {
-	txs 	map[types.Address]Transactions
-	heads 	TxByPriority
-	signer 	Signer
-
+	txs    map[types.Address]Transactions
+	heads  orderedHeads
+	signer Signer
+	policy OrderingPolicy
 }
 
-func NewTransactionsByPriorityAndNonce(signer Signer , txs map[types.Address]Transactions, txReword *Transaction)*TransactionsByPriorityAndNonce {
-	// Initialize a price based heap with the head transactions
-	heads := make(TxByPriority, 0, len(txs) + 1)
-	heads = append(heads, txReword)
+// NewTransactionsByPriorityAndNonce creates a transaction set that can retrieve
+// priority sorted transactions in a nonce-honouring way. The reward transaction
+// is always returned first.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func NewTransactionsByPriorityAndNonce(signer Signer, txs map[types.Address]Transactions, txReword *Transaction, policy OrderingPolicy) *TransactionsByPriorityAndNonce {
+	if policy != nil {
+		policy.Reset()
+	}
+	t := &TransactionsByPriorityAndNonce{
+		txs:    txs,
+		heads:  make(orderedHeads, 0, len(txs)+1),
+		signer: signer,
+		policy: policy,
+	}
+	if txReword != nil {
+		t.heads = append(t.heads, &orderedHead{tx: txReword, first: true})
+	}
 	for _, accTxs := range txs {
-		heads = append(heads, accTxs[0])
 		// Ensure the sender address is from the signer
 		acc, _ := Sender(signer, accTxs[0])
+		t.heads = append(t.heads, t.head(accTxs[0], acc))
 		txs[acc] = accTxs[1:]
 	}
-	heap.Init(&heads)
+	heap.Init(&t.heads)
+	return t
+}
 
-	// Assemble and return the transaction set
-	return &TransactionsByPriorityAndNonce{
-		txs:    txs,
-		heads:  heads,
-		signer: signer,
+// head ranks the next transaction of an account.
+func (t *TransactionsByPriorityAndNonce) head(tx *Transaction, from types.Address) *orderedHead {
+	if t.policy == nil {
+		return &orderedHead{tx: tx, from: from, priority: tx.GetPriority()}
 	}
+	return &orderedHead{tx: tx, from: from, priority: t.policy.Priority(tx, from)}
 }
 
-// Peek returns the next transaction by price.
+// Peek returns the next transaction by priority. Transactions the policy does
+// not accept are skipped together with the rest of their account.
 func (t *TransactionsByPriorityAndNonce) Peek() *Transaction {
-	if len(t.heads) == 0 {
-		return nil
+	for len(t.heads) > 0 {
+		head := t.heads[0]
+		if head.first || t.policy == nil || t.policy.Accept(head.tx, head.from) {
+			return head.tx
+		}
+		heap.Pop(&t.heads)
 	}
-	return t.heads[0]
+	return nil
+}
+
+// Packed tells the ordering policy the current best transaction was included in
+// the block. It must be called before Shift.
+func (t *TransactionsByPriorityAndNonce) Packed() {
+	if t.policy == nil || len(t.heads) == 0 || t.heads[0].first {
+		return
+	}
+	t.policy.Packed(t.heads[0].tx, t.heads[0].from)
 }
 
 // Shift replaces the current best head with the next one from the same account.
 func (t *TransactionsByPriorityAndNonce) Shift() {
-	acc, _ := Sender(t.signer, t.heads[0])
-	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
-		t.heads[0], t.txs[acc] = txs[0], txs[1:]
+	head := t.heads[0]
+	if head.first {
+		heap.Pop(&t.heads)
+		return
+	}
+	if txs, ok := t.txs[head.from]; ok && len(txs) > 0 {
+		t.heads[0], t.txs[head.from] = t.head(txs[0], head.from), txs[1:]
 		heap.Fix(&t.heads, 0)
 	} else {
 		heap.Pop(&t.heads)
@@ -475,6 +708,10 @@ type Message struct {
 	from       types.Address
 	nonce      uint64
 	actions    []Action
+	fee        uint64
+	validAfter uint64
+	validUntil uint64
+	keySet     types.Hash
 	checkNonce bool
 }
 
@@ -491,3 +728,7 @@ func (m Message) From() types.Address { return m.from }
 func (m Message) Nonce() uint64        { return m.nonce }
 func (m Message) Actions()[]Action      {return m.actions}
 func (m Message) CheckNonce() bool     { return m.checkNonce }
+func (m Message) Fee() uint64          { return m.fee }
+func (m Message) ValidAfter() uint64   { return m.validAfter }
+func (m Message) ValidUntil() uint64   { return m.validUntil }
+func (m Message) KeySet() types.Hash   { return m.keySet }
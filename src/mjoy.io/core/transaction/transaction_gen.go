@@ -1196,6 +1196,47 @@ func (z *Txdata) DecodeMsg(dc *msgp.Reader) (err error) {
 					}
 				}
 			}
+		case "Fee":
+			z.Fee, err = dc.ReadUint64()
+			if err != nil {
+				return
+			}
+		case "ValidAfter":
+			z.ValidAfter, err = dc.ReadUint64()
+			if err != nil {
+				return
+			}
+		case "ValidUntil":
+			z.ValidUntil, err = dc.ReadUint64()
+			if err != nil {
+				return
+			}
+		case "MultiSig":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					return
+				}
+				z.MultiSig = nil
+			} else {
+				if z.MultiSig == nil {
+					z.MultiSig = new(MultiSig)
+				}
+				err = z.MultiSig.DecodeMsg(dc)
+				if err != nil {
+					return
+				}
+			}
+		case "SigScheme":
+			z.SigScheme, err = dc.ReadUint8()
+			if err != nil {
+				return
+			}
+		case "PubKey":
+			z.PubKey, err = dc.ReadBytes(z.PubKey)
+			if err != nil {
+				return
+			}
 		case "V":
 			if dc.IsNil() {
 				err = dc.ReadNil()
@@ -1256,9 +1297,9 @@ func (z *Txdata) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *Txdata) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 5
+	// map header, size 11
 	// write "AccountNonce"
-	err = en.Append(0x85, 0xac, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x4e, 0x6f, 0x6e, 0x63, 0x65)
+	err = en.Append(0x8b, 0xac, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x4e, 0x6f, 0x6e, 0x63, 0x65)
 	if err != nil {
 		return
 	}
@@ -1303,6 +1344,67 @@ func (z *Txdata) EncodeMsg(en *msgp.Writer) (err error) {
 			return
 		}
 	}
+	// write "Fee"
+	err = en.Append(0xa3, 0x46, 0x65, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Fee)
+	if err != nil {
+		return
+	}
+	// write "ValidAfter"
+	err = en.Append(0xaa, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x41, 0x66, 0x74, 0x65, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.ValidAfter)
+	if err != nil {
+		return
+	}
+	// write "ValidUntil"
+	err = en.Append(0xaa, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x55, 0x6e, 0x74, 0x69, 0x6c)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.ValidUntil)
+	if err != nil {
+		return
+	}
+	// write "MultiSig"
+	err = en.Append(0xa8, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67)
+	if err != nil {
+		return
+	}
+	if z.MultiSig == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.MultiSig.EncodeMsg(en)
+		if err != nil {
+			return
+		}
+	}
+	// write "SigScheme"
+	err = en.Append(0xa9, 0x53, 0x69, 0x67, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint8(z.SigScheme)
+	if err != nil {
+		return
+	}
+	// write "PubKey"
+	err = en.Append(0xa6, 0x50, 0x75, 0x62, 0x4b, 0x65, 0x79)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.PubKey)
+	if err != nil {
+		return
+	}
 	// write "V"
 	err = en.Append(0xa1, 0x56)
 	if err != nil {
@@ -1357,9 +1459,9 @@ func (z *Txdata) EncodeMsg(en *msgp.Writer) (err error) {
 // MarshalMsg implements msgp.Marshaler
 func (z *Txdata) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 5
+	// map header, size 11
 	// string "AccountNonce"
-	o = append(o, 0x85, 0xac, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x4e, 0x6f, 0x6e, 0x63, 0x65)
+	o = append(o, 0x8b, 0xac, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x4e, 0x6f, 0x6e, 0x63, 0x65)
 	o = msgp.AppendUint64(o, z.AccountNonce)
 	// string "Actions"
 	o = append(o, 0xa7, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73)
@@ -1380,6 +1482,31 @@ func (z *Txdata) MarshalMsg(b []byte) (o []byte, err error) {
 		o = append(o, 0xa6, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73)
 		o = msgp.AppendBytes(o, z.Actions[za0001].Params)
 	}
+	// string "Fee"
+	o = append(o, 0xa3, 0x46, 0x65, 0x65)
+	o = msgp.AppendUint64(o, z.Fee)
+	// string "ValidAfter"
+	o = append(o, 0xaa, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x41, 0x66, 0x74, 0x65, 0x72)
+	o = msgp.AppendUint64(o, z.ValidAfter)
+	// string "ValidUntil"
+	o = append(o, 0xaa, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x55, 0x6e, 0x74, 0x69, 0x6c)
+	o = msgp.AppendUint64(o, z.ValidUntil)
+	// string "MultiSig"
+	o = append(o, 0xa8, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67)
+	if z.MultiSig == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.MultiSig.MarshalMsg(o)
+		if err != nil {
+			return
+		}
+	}
+	// string "SigScheme"
+	o = append(o, 0xa9, 0x53, 0x69, 0x67, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65)
+	o = msgp.AppendUint8(o, z.SigScheme)
+	// string "PubKey"
+	o = append(o, 0xa6, 0x50, 0x75, 0x62, 0x4b, 0x65, 0x79)
+	o = msgp.AppendBytes(o, z.PubKey)
 	// string "V"
 	o = append(o, 0xa1, 0x56)
 	if z.V == nil {
@@ -1487,6 +1614,47 @@ func (z *Txdata) UnmarshalMsg(bts []byte) (o []byte, err error) {
 					}
 				}
 			}
+		case "Fee":
+			z.Fee, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				return
+			}
+		case "ValidAfter":
+			z.ValidAfter, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				return
+			}
+		case "ValidUntil":
+			z.ValidUntil, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				return
+			}
+		case "MultiSig":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.MultiSig = nil
+			} else {
+				if z.MultiSig == nil {
+					z.MultiSig = new(MultiSig)
+				}
+				bts, err = z.MultiSig.UnmarshalMsg(bts)
+				if err != nil {
+					return
+				}
+			}
+		case "SigScheme":
+			z.SigScheme, bts, err = msgp.ReadUint8Bytes(bts)
+			if err != nil {
+				return
+			}
+		case "PubKey":
+			z.PubKey, bts, err = msgp.ReadBytesBytes(bts, z.PubKey)
+			if err != nil {
+				return
+			}
 		case "V":
 			if msgp.IsNil(bts) {
 				bts, err = msgp.ReadNilBytes(bts)
@@ -1558,7 +1726,13 @@ func (z *Txdata) Msgsize() (s int) {
 		}
 		s += 7 + msgp.BytesPrefixSize + len(z.Actions[za0001].Params)
 	}
-	s += 2
+	s += 4 + msgp.Uint64Size + 11 + msgp.Uint64Size + 11 + msgp.Uint64Size + 9
+	if z.MultiSig == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.MultiSig.Msgsize()
+	}
+	s += 10 + msgp.Uint8Size + 7 + msgp.BytesPrefixSize + len(z.PubKey) + 2
 	if z.V == nil {
 		s += msgp.NilSize
 	} else {
@@ -22,6 +22,7 @@ package transaction
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"errors"
 
 	"math/big"
@@ -52,7 +53,8 @@ func MakeSigner(config *params.ChainConfig, blockNumber *big.Int) Signer {
 	return signer
 }
 
-// SignTx signs the transaction using the given signer and private key
+// SignTx signs the transaction using the given signer and private key. A multisig
+// transaction gets the signature appended to its partial signatures.
 func SignTx(tx *Transaction, s Signer, prv *ecdsa.PrivateKey) (*Transaction, error) {
 	h := s.Hash(tx)
 	sig, err := crypto.Sign(h[:], prv)
@@ -62,6 +64,18 @@ func SignTx(tx *Transaction, s Signer, prv *ecdsa.PrivateKey) (*Transaction, err
 	return tx.WithSignature(s, sig)
 }
 
+// SignTxEd25519 signs the transaction with an Ed25519 private key. The public key
+// is carried in the transaction, since Ed25519 signatures do not allow recovering
+// it.
+func SignTxEd25519(tx *Transaction, s Signer, prv ed25519.PrivateKey) (*Transaction, error) {
+	if tx.Data.MultiSig != nil {
+		return nil, crypto.ErrUnknownSigScheme
+	}
+	cpy := tx.WithSigScheme(crypto.SigEd25519, prv.Public().(ed25519.PublicKey))
+	h := s.Hash(cpy)
+	return cpy.WithSignature(s, ed25519.Sign(prv, h[:]))
+}
+
 // Sender returns the address derived from the signature (V, R, S) using secp256k1
 // elliptic curve and an error if it failed deriving or upon an incorrect
 // signature.
@@ -124,18 +138,78 @@ func (s MSigner) Equal(s2 Signer) bool {
 var big8 = big.NewInt(8)
 
 func (s MSigner) Sender(tx *Transaction) (types.Address, error) {
-
+	if tx.Data.MultiSig != nil {
+		return s.multiSigSender(tx)
+	}
 	if tx.ChainId().Cmp(s.chainId) != 0 {
 		return types.Address{}, ErrInvalidChainId
 	}
 	V := new(big.Int).Sub(&tx.Data.V.IntVal, s.chainIdMul)
 	V.Sub(V, big8)
+	if tx.Data.SigScheme != crypto.SigSecp256k1 {
+		// The scheme takes the bare recovery id
+		return crypto.RecoverScheme(tx.Data.SigScheme, s.Hash(tx).Bytes(), &tx.Data.R.IntVal, &tx.Data.S.IntVal, V.Sub(V, common.Big27), tx.Data.PubKey)
+	}
 	return recoverPlain(s.Hash(tx), &tx.Data.R.IntVal, &tx.Data.S.IntVal, V, true)
 }
 
+// multiSigSender recovers every partial signature of a multisig transaction and
+// returns its account if enough distinct owners of the key set signed it. That
+// the key set is registered for the account is checked against state by the
+// callers.
+func (s MSigner) multiSigSender(tx *Transaction) (types.Address, error) {
+	ms := tx.Data.MultiSig
+	// Partial signatures are always secp256k1
+	if tx.Data.SigScheme != crypto.SigSecp256k1 {
+		return types.Address{}, crypto.ErrUnknownSigScheme
+	}
+	if err := ms.Validate(); err != nil {
+		return types.Address{}, err
+	}
+	h := s.Hash(tx)
+	signed := make(map[types.Address]bool)
+	for _, sig := range ms.Signatures {
+		if sig.V == nil || sig.R == nil || sig.S == nil {
+			return types.Address{}, ErrInvalidSig
+		}
+		if deriveChainId(&sig.V.IntVal).Cmp(s.chainId) != 0 {
+			return types.Address{}, ErrInvalidChainId
+		}
+		V := new(big.Int).Sub(&sig.V.IntVal, s.chainIdMul)
+		V.Sub(V, big8)
+		owner, err := recoverPlain(h, &sig.R.IntVal, &sig.S.IntVal, V, true)
+		if err != nil {
+			return types.Address{}, err
+		}
+		if !ms.IsOwner(owner) {
+			return types.Address{}, ErrNotMultiSigOwner
+		}
+		if signed[owner] {
+			return types.Address{}, ErrDuplicateSignature
+		}
+		signed[owner] = true
+	}
+	if uint64(len(signed)) < ms.Threshold {
+		return types.Address{}, ErrInsufficientSignatures
+	}
+	return ms.Account, nil
+}
+
 // WithSignature returns a new transaction with the given signature. This signature
-// needs to be in the [R || S || V] format where V is 0 or 1.
+// needs to be in the [R || S || V] format where V is 0 or 1, or in the [R || S]
+// format for schemes without a recovery id.
 func (s MSigner) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Data.SigScheme != crypto.SigSecp256k1 && tx.Data.MultiSig == nil {
+		scheme, err := crypto.GetSigScheme(tx.Data.SigScheme)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(sig) != scheme.SigLength() || len(sig) != 64 {
+			return nil, nil, nil, fmt.Errorf("wrong size for %s signature: got %d, want %d", scheme.Name(), len(sig), scheme.SigLength())
+		}
+		// Recovery id is always zero
+		sig = append(sig[:64:64], 0)
+	}
 	//here use Frontier SignatureValues Function directly
 	if len(sig) != 65 {
 		errStr:=fmt.Sprintf("wrong size for signature: got %d, want 65", len(sig))
@@ -166,11 +240,23 @@ func (s MSigner) Hash(tx *Transaction) types.Hash {
 		}
 	}
 
-	h, err := common.MsgpHash([]interface{}{
+	fields := []interface{}{
 		tx.Data.AccountNonce,
 		tx.Data.Actions,
+		tx.Data.Fee,
+		tx.Data.ValidAfter,
+		tx.Data.ValidUntil,
 		types.BigInt{*s.chainId}, uint(0), uint(0),
-	})
+	}
+	// Every owner signs the account and the key set along with the transaction
+	if ms := tx.Data.MultiSig; ms != nil {
+		fields = append(fields, ms.Account.Bytes(), keySetBytes(ms.Threshold, ms.Owners))
+	}
+	// The signature scheme and the key are bound by the signature
+	if tx.Data.SigScheme != crypto.SigSecp256k1 {
+		fields = append(fields, uint64(tx.Data.SigScheme), tx.Data.PubKey)
+	}
+	h, err := common.MsgpHash(fields)
 	if err != nil {
 		panic(err)
 	}
@@ -1,6 +1,8 @@
 package transaction
 
 import (
+	"crypto/ed25519"
+	"encoding/json"
 	"testing"
 
 	"math/big"
@@ -88,3 +90,95 @@ func TestAsMessageGenerate(t *testing.T){
 	}
 	fmt.Println("msg:" , msg)
 }
+
+func TestValidityWindow(t *testing.T) {
+	tests := []struct {
+		after, until, number, time uint64
+		err                        error
+	}{
+		{0, 0, 100, 1600000000, nil},
+		{10, 20, 9, 1600000000, ErrTxNotYetValid},
+		{10, 20, 10, 1600000000, nil},
+		{10, 20, 20, 1600000000, nil},
+		{10, 20, 21, 1600000000, ErrTxExpired},
+		{0, 1600000000, 5, 1600000001, ErrTxExpired},
+		{1600000000, 0, 5, 1599999999, ErrTxNotYetValid},
+		{1600000000, 30, 31, 1600000000, ErrTxExpired},
+	}
+	for i, test := range tests {
+		tx := newTransaction(0, []Action{{Address: &testAddress}}).WithValidityWindow(test.after, test.until)
+		if err := tx.CheckValidity(test.number, test.time); err != test.err {
+			t.Errorf("test %d: have %v, want %v", i, err, test.err)
+		}
+	}
+}
+
+func TestValidityWindowSigned(t *testing.T) {
+	tx := newTransaction(1, []Action{{Address: &testAddress, Params: []byte{1}}})
+	signed, err := SignTx(tx.WithValidityWindow(0, 100), mSigner, testKey)
+	if err != nil {
+		t.Fatalf("SignTx error: %v", err)
+	}
+	// Widening the window must invalidate the signature
+	forged := &Transaction{Data: signed.Data}
+	forged.Data.ValidUntil = 1000
+	if addr, err := mSigner.Sender(forged); err == nil && addr == testAddress {
+		t.Errorf("validity window is not covered by the signature")
+	}
+	if addr, err := mSigner.Sender(signed); err != nil || addr != testAddress {
+		t.Errorf("Sender error: have %x (%v), want %x", addr, err, testAddress)
+	}
+}
+
+func TestFeeSigned(t *testing.T) {
+	tx := newTransaction(1, []Action{{Address: &testAddress, Params: []byte{1}}})
+	signed, err := SignTx(tx.WithFee(500), mSigner, testKey)
+	if err != nil {
+		t.Fatalf("SignTx error: %v", err)
+	}
+	want := new(big.Int).Div(big.NewInt(500*FeeRateUnit), big.NewInt(int64(signed.Size())))
+	if rate := signed.FeePerByte(); rate.Cmp(want) != 0 {
+		t.Errorf("FeePerByte mismatch: have %v, want %v", rate, want)
+	}
+	// Lowering the fee must invalidate the signature
+	forged := &Transaction{Data: signed.Data}
+	forged.Data.Fee = 1
+	if addr, err := mSigner.Sender(forged); err == nil && addr == testAddress {
+		t.Errorf("fee is not covered by the signature")
+	}
+}
+
+func TestEd25519Signed(t *testing.T) {
+	prv, err := crypto.GenerateEd25519Key()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key error: %v", err)
+	}
+	tx := newTransaction(1, []Action{{Address: &testAddress, Params: []byte{1}}})
+	signed, err := SignTxEd25519(tx, mSigner, prv)
+	if err != nil {
+		t.Fatalf("SignTxEd25519 error: %v", err)
+	}
+	want := crypto.Ed25519PubkeyToAddress(prv.Public().(ed25519.PublicKey))
+	if addr, err := mSigner.Sender(signed); err != nil || addr != want {
+		t.Fatalf("Sender error: have %x (%v), want %x", addr, err, want)
+	}
+	// The signature survives a JSON round trip
+	enc, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatalf("MarshalJSON error: %v", err)
+	}
+	var dec Transaction
+	if err := json.Unmarshal(enc, &dec); err != nil {
+		t.Fatalf("UnmarshalJSON error: %v", err)
+	}
+	if addr, err := mSigner.Sender(&dec); err != nil || addr != want {
+		t.Errorf("decoded sender mismatch: have %x (%v), want %x", addr, err, want)
+	}
+	// Swapping the public key must not yield a valid sender
+	other, _ := crypto.GenerateEd25519Key()
+	forged := &Transaction{Data: signed.Data}
+	forged.Data.PubKey = other.Public().(ed25519.PublicKey)
+	if _, err := mSigner.Sender(forged); err == nil {
+		t.Errorf("public key is not bound by the signature")
+	}
+}
@@ -80,11 +80,13 @@ func (journal *txJournal) load(add func(*transaction.Transaction) error) error {
 	total, dropped := 0, 0
 
 	var failure error
+	// A single reader for the whole file, its buffer holds the next transactions
+	stream := msgp.NewReader(input)
 	for {
 		// Parse the next transaction and terminate on error
 		tx := new(transaction.Transaction)
 
-		if err = msgp.Decode(input,tx); err != nil {
+		if err = tx.DecodeMsg(stream); err != nil {
 			if err != io.EOF {
 				failure = err
 			}
@@ -0,0 +1,91 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: tx_lifecycle.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package txprocessor
+
+import (
+	"mjoy.io/common/types"
+	"mjoy.io/core"
+	"mjoy.io/utils/event"
+	"mjoy.io/utils/metrics"
+)
+
+// Stages of a transaction reported by TxLifecycleEvent.
+const (
+	TxEventQueued   = "queued"
+	TxEventPending  = "pending"
+	TxEventIncluded = "included"
+	TxEventDropped  = "dropped"
+	TxEventReplaced = "replaced"
+)
+
+// Reasons of the dropped transactions.
+const (
+	dropLifetime     = "lifetime exceeded"
+	dropExpired      = "validity window ended"
+	dropUnderpriced  = "underpriced"
+	dropPoolFull     = "pool full"
+	dropAccountLimit = "account queue limit"
+	dropPendingLimit = "pending limit"
+	dropQueueLimit   = "global queue limit"
+	dropRemoved      = "removed by operator"
+	dropStale        = "nonce used by another transaction"
+)
+
+// lifecycleChanSize is the size of the queue of lifecycle events waiting to be
+// sent to the subscribers.
+const lifecycleChanSize = 4096
+
+// lifecycleDropCounter counts the lifecycle events lost to a full queue.
+var lifecycleDropCounter = metrics.NewRegisteredCounter("txpool/lifecycle/drop", nil)
+
+// SubscribeTxLifecycleEvent registers a subscription of TxLifecycleEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeTxLifecycleEvent(ch chan<- core.TxLifecycleEvent) event.Subscription {
+	return pool.scope.Track(pool.lifecycleFeed.Subscribe(ch))
+}
+
+// notifyLifecycle queues a lifecycle event of a transaction. Events are sent in
+// the order they happen, and never block the pool: they are dropped when the
+// subscribers fall too far behind.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) notifyLifecycle(hash types.Hash, status, reason string) {
+	select {
+	case pool.lifecycleCh <- core.TxLifecycleEvent{Hash: hash, Status: status, Reason: reason}:
+	default:
+		lifecycleDropCounter.Inc(1)
+	}
+}
+
+// lifecycleLoop sends the queued lifecycle events to the subscribers until the
+// pool is stopped.
+func (pool *TxPool) lifecycleLoop() {
+	defer pool.wg.Done()
+
+	for {
+		select {
+		case ev := <-pool.lifecycleCh:
+			pool.lifecycleFeed.Send(ev)
+		case <-pool.lifecycleQuit:
+			return
+		}
+	}
+}
@@ -250,16 +250,17 @@ func (l *txList) Overlaps(tx *transaction.Transaction) bool {
 // Add tries to insert a new transaction.Transaction into the list, returning whether the
 // transaction.Transaction was accepted, and if yes, any previous transaction.Transaction it replaced.
 //
-
-func (l *txList) Add(tx *transaction.Transaction, rev uint64) (bool, *transaction.Transaction) {
+// If the new transaction.Transaction is accepted into the list, the lists' priority
+// threshold is also potentially updated. A replacement needs to pay at least
+// priceBump percent more per byte than the transaction.Transaction it replaces.
+func (l *txList) Add(tx *transaction.Transaction, priceBump uint64) (bool, *transaction.Transaction) {
 	// If there's an older better transaction.Transaction, abort
-	//already has
 	old := l.txs.Get(tx.Nonce())
 	if old != nil {
-		//check priority
-		if old.Priority.Int64() >= tx.Priority.Int64() {
-			//Add false , because the old transaction's priority is higher than new
-			return false , nil
+		threshold := new(big.Int).Div(new(big.Int).Mul(old.GetPriority(), big.NewInt(100+int64(priceBump))), big.NewInt(100))
+		// Have to ensure that the new priority is higher even if the bump rounds to nothing
+		if old.GetPriority().Cmp(tx.GetPriority()) >= 0 || threshold.Cmp(tx.GetPriority()) > 0 {
+			return false, nil
 		}
 	}
 	// Otherwise overwrite the old transaction.Transaction with the current one
@@ -0,0 +1,133 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: tx_ordering.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package txprocessor
+
+import (
+	"fmt"
+	"math/big"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core/transaction"
+)
+
+// Names of the base ordering policies, usable in OrderingConfig.Policy.
+const (
+	OrderFeePerByte = "feeperbyte" // Highest fee per byte first
+	OrderFIFO       = "fifo"       // First seen by the pool first
+)
+
+// OrderingConfig selects the order in which the block producer packs pending
+// transactions.
+type OrderingConfig struct {
+	Policy        string          // Base ordering policy
+	Whitelist     []types.Address // Senders packed before anyone else
+	ContractQuota uint64          // Maximum transactions calling one contract per block, 0 for no limit
+}
+
+// DefaultOrderingConfig packs the transactions paying the most per byte first.
+var DefaultOrderingConfig = OrderingConfig{
+	Policy: OrderFeePerByte,
+}
+
+// whitelistBoost lifts the priority of whitelisted senders above any priority
+// given by the base policies.
+var whitelistBoost = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// NewOrderingPolicy creates the ordering policy described by the config.
+func NewOrderingPolicy(config OrderingConfig) (transaction.OrderingPolicy, error) {
+	policy := &orderingPolicy{quota: config.ContractQuota}
+	switch config.Policy {
+	case OrderFeePerByte, "":
+		policy.base = feePerBytePriority
+	case OrderFIFO:
+		policy.base = fifoPriority
+	default:
+		return nil, fmt.Errorf("unknown transaction ordering policy %q", config.Policy)
+	}
+	if len(config.Whitelist) > 0 {
+		policy.whitelist = make(map[types.Address]bool, len(config.Whitelist))
+		for _, addr := range config.Whitelist {
+			policy.whitelist[addr] = true
+		}
+	}
+	return policy, nil
+}
+
+func feePerBytePriority(tx *transaction.Transaction) *big.Int {
+	return tx.FeePerByte()
+}
+
+// fifoPriority ranks earlier arrivals higher. Transactions the pool never saw
+// come first.
+func fifoPriority(tx *transaction.Transaction) *big.Int {
+	arrival := tx.Arrival()
+	if arrival.IsZero() {
+		return new(big.Int)
+	}
+	return big.NewInt(-arrival.UnixNano())
+}
+
+// orderingPolicy ranks transactions with a base policy, lifting whitelisted
+// senders first, and holds back those calling a contract past its quota.
+type orderingPolicy struct {
+	base      func(tx *transaction.Transaction) *big.Int
+	whitelist map[types.Address]bool
+	quota     uint64
+
+	packed map[types.Address]uint64 // Transactions packed per contract in the current block
+}
+
+func (p *orderingPolicy) Reset() {
+	p.packed = make(map[types.Address]uint64)
+}
+
+func (p *orderingPolicy) Priority(tx *transaction.Transaction, from types.Address) *big.Int {
+	priority := p.base(tx)
+	if p.whitelist[from] {
+		priority.Add(priority, whitelistBoost)
+	}
+	return priority
+}
+
+func (p *orderingPolicy) Accept(tx *transaction.Transaction, from types.Address) bool {
+	if p.quota == 0 || p.whitelist[from] {
+		return true
+	}
+	for _, action := range tx.Data.Actions {
+		if action.Address != nil && p.packed[*action.Address] >= p.quota {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *orderingPolicy) Packed(tx *transaction.Transaction, from types.Address) {
+	if p.quota == 0 {
+		return
+	}
+	seen := make(map[types.Address]bool)
+	for _, action := range tx.Data.Actions {
+		if action.Address != nil && !seen[*action.Address] {
+			seen[*action.Address] = true
+			p.packed[*action.Address]++
+		}
+	}
+}
@@ -29,15 +29,17 @@ import (
 	"time"
 	"mjoy.io/common/types"
 	"mjoy.io/core"
+	"mjoy.io/core/blockchain"
 	"mjoy.io/core/blockchain/block"
 	"mjoy.io/core/state"
+	"mjoy.io/core/interpreter"
+	"mjoy.io/utils/database"
 	"mjoy.io/utils/event"
 	"mjoy.io/params"
 	"mjoy.io/utils/metrics"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 	"mjoy.io/core/transaction"
 	"math/big"
-	"mjoy.io/core/interpreter"
 )
 
 const (
@@ -102,6 +104,7 @@ var (
 
 	// General tx metrics
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid",nil)
+	expiredTxCounter     = metrics.NewRegisteredCounter("txpool/expired",nil) // Dropped due to validity window end
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -114,12 +117,26 @@ const (
 	TxStatusIncluded
 )
 
+func (status TxStatus) String() string {
+	switch status {
+	case TxStatusQueued:
+		return "queued"
+	case TxStatusPending:
+		return "pending"
+	case TxStatusIncluded:
+		return "included"
+	default:
+		return "unknown"
+	}
+}
+
 // blockChain provides the state of blockchain  to do
 // some pre checks in tx pool and event subscribers.
 type blockChain interface {
 	CurrentBlock() *block.Block
 	GetBlock(hash types.Hash, number uint64) *block.Block
 	StateAt(root types.Hash) (*state.StateDB, error)
+	GetDb() database.IDatabase
 
 	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
 }
@@ -130,12 +147,22 @@ type TxPoolConfig struct {
 	Journal   string        // Journal of local transactions to survive node restarts
 	Rejournal time.Duration // Time interval to regenerate the local transaction journal
 
+	Snapshot   string        // Snapshot of remote transactions to survive node restarts, disabled if empty
+	Resnapshot time.Duration // Time interval to rewrite the remote transaction snapshot
+
 	AccountSlots uint64 // Minimum number of executable transaction slots guaranteed per account
 	GlobalSlots  uint64 // Maximum number of executable transaction slots for all accounts
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	PriceBump uint64 // Minimum fee per byte bump percentage to replace an already existing transaction (nonce)
+
+	MaxTxSize  uint64   // Maximum size of a transaction accepted into the pool
+	Validators []string // Admission validators a transaction has to pass, in order
+
+	Ordering OrderingConfig // Order in which pending transactions are packed into blocks
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -144,12 +171,21 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	Journal:   "transactions.msgp",
 	Rejournal: time.Hour,
 
+	Resnapshot: 10 * time.Minute,
+
 	AccountSlots: 16,
 	GlobalSlots:  4096,
 	AccountQueue: 64,
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	PriceBump: 10,
+
+	MaxTxSize:  32 * 1024,
+	Validators: []string{ValidatorSize, ValidatorSignature, ValidatorNonce, ValidatorFee, ValidatorContract},
+
+	Ordering: DefaultOrderingConfig,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -160,6 +196,38 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		logger.Warn("Sanitizing invalid txpool journal time", "provided", conf.Rejournal, "updated", time.Second)
 		conf.Rejournal = time.Second
 	}
+	if conf.Resnapshot < time.Second {
+		logger.Warn("Sanitizing invalid txpool snapshot time", "provided", conf.Resnapshot, "updated", time.Second)
+		conf.Resnapshot = time.Second
+	}
+	if conf.PriceBump < 1 {
+		logger.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
+		conf.PriceBump = DefaultTxPoolConfig.PriceBump
+	}
+	if conf.MaxTxSize == 0 {
+		logger.Warn("Sanitizing invalid txpool max tx size", "provided", conf.MaxTxSize, "updated", DefaultTxPoolConfig.MaxTxSize)
+		conf.MaxTxSize = DefaultTxPoolConfig.MaxTxSize
+	}
+	// The pool relies on the sender being recovered, so signatures are always checked
+	validators := make([]string, 0, len(conf.Validators)+1)
+	hasSignature := false
+	for _, name := range conf.Validators {
+		if getTxValidator(name) == nil {
+			logger.Warn("Sanitizing unknown txpool validator", "provided", name)
+			continue
+		}
+		hasSignature = hasSignature || name == ValidatorSignature
+		validators = append(validators, name)
+	}
+	if !hasSignature {
+		validators = append([]string{ValidatorSignature}, validators...)
+	}
+	conf.Validators = validators
+
+	if _, err := NewOrderingPolicy(conf.Ordering); err != nil {
+		logger.Warn("Sanitizing invalid txpool ordering policy", "provided", conf.Ordering.Policy, "updated", DefaultOrderingConfig.Policy)
+		conf.Ordering.Policy = DefaultOrderingConfig.Policy
+	}
 
 	return conf
 }
@@ -176,20 +244,26 @@ type TxPool struct {
 	chainconfig  *params.ChainConfig
 	chain        blockChain
 	txFeed       event.Feed
+	lifecycleFeed event.Feed
+	lifecycleCh   chan core.TxLifecycleEvent
+	lifecycleQuit chan struct{}
 	scope        event.SubscriptionScope
 	chainHeadCh  chan core.ChainHeadEvent
 	chainHeadSub event.Subscription
 	signer       transaction.Signer
 	mu           sync.RWMutex
 
+	currentHead   *block.Header       // Current head of the blockchain
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 
 	priority 	*big.Int
 	inter		Interpreter
+	vm		*interpreter.Vms
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
+	snapshot *txSnapshot // Snapshot of remote transactions to back up to disk
 
 	pending map[types.Address]*txList         // All currently processable transactions
 	queue   map[types.Address]*txList         // Queued but non-processable transactions
@@ -218,9 +292,13 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		beats:       make(map[types.Address]time.Time),
 		all:         make(map[types.Hash]*transaction.Transaction),
 		chainHeadCh: make(chan core.ChainHeadEvent, chainHeadChanSize),
+		lifecycleCh:   make(chan core.TxLifecycleEvent, lifecycleChanSize),
+		lifecycleQuit: make(chan struct{}),
+		priority:    new(big.Int),
 	}
 	//set test interpreter for test
 	pool.inter = new(testInterpreter)
+	pool.vm = interpreter.NewVm()
 	pool.locals = newAccountSet(pool.signer)
 	pool.priorited = newTxPriorityList(&pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
@@ -236,12 +314,18 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 			logger.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If the remote transaction snapshot is enabled, restore it
+	if config.Snapshot != "" {
+		pool.snapshot = newTxSnapshot(config.Snapshot)
+		pool.loadSnapshot()
+	}
 	// Subscribe events from blockchain
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 
-	// Start the event loop and return
-	pool.wg.Add(1)
+	// Start the event loops and return
+	pool.wg.Add(2)
 	go pool.loop()
+	go pool.lifecycleLoop()
 
 	return pool
 }
@@ -264,6 +348,9 @@ func (pool *TxPool) loop() {
 	journal := time.NewTicker(pool.config.Rejournal)
 	defer journal.Stop()
 
+	snapshot := time.NewTicker(pool.config.Resnapshot)
+	defer snapshot.Stop()
+
 	// Track the previous head headers for transaction reorgs
 	head := pool.chain.CurrentBlock()
 
@@ -306,10 +393,12 @@ func (pool *TxPool) loop() {
 				// Any non-locals old enough should be removed
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
 					for _, tx := range pool.queue[addr].Flatten() {
-						pool.removeTx(tx.Hash())
+						pool.removeTx(tx.Hash(), dropLifetime)
 					}
 				}
 			}
+			// Transactions past their validity window are removed, locals too
+			pool.evictExpired()
 			pool.mu.Unlock()
 
 		// Handle local transaction journal rotation
@@ -321,6 +410,12 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+
+		// Handle remote transaction snapshot rewrite
+		case <-snapshot.C:
+			if pool.snapshot != nil {
+				pool.saveSnapshot()
+			}
 		}
 	}
 }
@@ -393,9 +488,12 @@ func (pool *TxPool) reset(oldHead, newHead *block.Header) {
 		logger.Error("Failed to reset txpool state", "err", err)
 		return
 	}
+	pool.currentHead = newHead
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 
+	// Drop anything that can no longer make it into a block
+	pool.evictExpired()
 
 	// Inject any transactions discarded due to reorgs
 	logger.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -423,14 +521,26 @@ func (pool *TxPool) Stop() {
 
 	// Unsubscribe subscriptions registered from blockchain
 	pool.chainHeadSub.Unsubscribe()
+	close(pool.lifecycleQuit)
 	pool.wg.Wait()
 
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.snapshot != nil {
+		pool.saveSnapshot()
+	}
 	logger.Info("Transaction pool stopped")
 }
 
+// OrderingPolicy returns the configured order in which the pending transactions
+// are packed into blocks. Policies count what they pack, each call returns a new
+// one for the packing of a single block.
+func (pool *TxPool) OrderingPolicy() transaction.OrderingPolicy {
+	policy, _ := NewOrderingPolicy(pool.config.Ordering) // checked by sanitize
+	return policy
+}
+
 // SubscribeTxPreEvent registers a subscription of TxPreEvent and
 // starts sending event to the given channel.
 func (pool *TxPool) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
@@ -454,7 +564,7 @@ func (pool *TxPool)SetPriority(priority *big.Int){
 	pool.priority = priority
 
 	for _ , tx := range pool.priorited.Cap(priority , pool.locals){
-		pool.removeTx(tx.Hash())
+		pool.removeTx(tx.Hash(), dropUnderpriced)
 	}
 	logger.Info("Transaction pool priority threshold updated" , "priority:" , priority.Int64())
 }
@@ -510,6 +620,22 @@ func (pool *TxPool) Content() (map[types.Address]transaction.Transactions, map[t
 	return pending, queued
 }
 
+// ContentFrom retrieves the pending and queued transactions of an account,
+// sorted by nonce.
+func (pool *TxPool) ContentFrom(addr types.Address) (transaction.Transactions, transaction.Transactions) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	var pending, queued transaction.Transactions
+	if list := pool.pending[addr]; list != nil {
+		pending = list.Flatten()
+	}
+	if list := pool.queue[addr]; list != nil {
+		queued = list.Flatten()
+	}
+	return pending, queued
+}
+
 // Pending retrieves all currently processable transactions, groupped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -540,49 +666,87 @@ func (pool *TxPool) local() map[types.Address]transaction.Transactions {
 	return txs
 }
 
-//validateTx return nil,we should check the tx validation at interpreter
-func (pool *TxPool) validateTx(tx *transaction.Transaction, local bool) error {
-	//// Heuristic limit, reject transactions over 32KB to prevent DOS attacks
-	//if tx.Size() > 32*1024 {
-	//	return ErrOversizedData
-	//}
-	////get basic info from interpreter
-	//a := pool.inter.GetBasicInfo(tx)
-	//txInfo := a.(transaction.TxBasicInfo)
-	//
-	////Some transaction judgement operations below
-	//
-	//// Transactions can't be negative. This may never happen using MSGP decoded
-	//// transactions but may occur if you create a transaction using the RPC.
-	//
-	//
-	//if txInfo.Amount == nil {
-	//	logger.Errorf("!!!!!!!!!Tx.Amount == nil")
-	//	return ErrWrongTransactionAmount
-	//}
-	//
-	//if txInfo.Amount.Sign() < 0 {
-	//	return ErrNegativeValue
-	//}
-	//// Make sure the transaction is signed properly
-	//from, err := transaction.Sender(pool.signer, tx)
-	//if err != nil {
-	//	logger.Error("Why invalidSender :",err)
-	//	return ErrInvalidSender
-	//}
-	//
-	//// Ensure the transaction adheres to nonce ordering
-	//if pool.currentState.GetNonce(from) > tx.Nonce() {
-	//	logger.Errorf("Account :%x , stateNonce:%d   tx.Nonce:%d" , from , pool.currentState.GetNonce(from) , tx.Nonce())
-	//	return ErrNonceTooLow
-	//}
-	//// Transactor should have enough funds to cover the costs
-	//if pool.currentState.GetBalance(from).Cmp(txInfo.Cost()) < 0 {
-	//	logger.Error("[validateTx] insufficient funds Cost")
-	//	return ErrInsufficientFunds
-	//}
+// saveSnapshot writes the remote transactions of the pool, pending and queued,
+// to the snapshot on disk.
+func (pool *TxPool) saveSnapshot() {
+	pool.mu.RLock()
+	var entries []*TxSnapshotEntry
+	for _, lists := range []map[types.Address]*txList{pool.pending, pool.queue} {
+		for addr, list := range lists {
+			if pool.locals.contains(addr) {
+				continue
+			}
+			beat := pool.beats[addr].UnixNano()
+			for _, tx := range list.Flatten() {
+				entries = append(entries, &TxSnapshotEntry{Tx: tx, Beat: beat})
+			}
+		}
+	}
+	pool.mu.RUnlock()
 
-	return nil
+	if err := pool.snapshot.save(entries); err != nil {
+		logger.Warn("Failed to save remote tx snapshot", "err", err)
+		return
+	}
+	logger.Info("Saved remote transaction snapshot", "transactions", len(entries))
+}
+
+// loadSnapshot injects the transactions of the snapshot on disk into the pool as
+// remote ones, revalidating them against the current head. The heartbeats of
+// the restored accounts are set back to their snapshot values.
+func (pool *TxPool) loadSnapshot() {
+	entries, err := pool.snapshot.load()
+	if err != nil {
+		logger.Warn("Failed to load remote tx snapshot", "err", err)
+	}
+	if len(entries) == 0 {
+		return
+	}
+	txs := make([]*transaction.Transaction, 0, len(entries))
+	beats := make(map[types.Address]time.Time)
+	for _, entry := range entries {
+		if entry.Tx == nil {
+			continue
+		}
+		txs = append(txs, entry.Tx)
+		if from, err := transaction.Sender(pool.signer, entry.Tx); err == nil && entry.Beat != 0 {
+			beats[from] = time.Unix(0, entry.Beat)
+		}
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	dropped := 0
+	for _, err := range pool.addTxsLocked(txs, false) {
+		if err != nil {
+			dropped++
+		}
+	}
+	for addr, beat := range beats {
+		if pool.pending[addr] != nil || pool.queue[addr] != nil {
+			pool.beats[addr] = beat
+		}
+	}
+	logger.Info("Loaded remote transaction snapshot", "transactions", len(txs), "dropped", dropped)
+}
+
+// validateTx passes a transaction through the configured admission validators.
+// A rejection is returned as a *TxRejectError carrying the error code of its
+// reason.
+func (pool *TxPool) validateTx(tx *transaction.Transaction, local bool) error {
+	ctx := &ValidationContext{
+		Config:  &pool.config,
+		Signer:  pool.signer,
+		Local:   local,
+		State:   pool.currentState,
+		Pending: pool.pendingState,
+		db:      pool.chain.GetDb(),
+		vm:      pool.vm,
+		chainId: pool.chainconfig.ChainId,
+		staking: pool.chainconfig.Dpos != nil,
+	}
+	return runValidators(ctx, tx)
 }
 
 // add validates a transaction and inserts it into the non-executable queue for
@@ -593,11 +757,8 @@ func (pool *TxPool) validateTx(tx *transaction.Transaction, local bool) error {
 // If a newly added transaction is marked as local, its sending account will be
 // whitelisted
 func (pool *TxPool) add(tx *transaction.Transaction, local bool) (bool, error) {
-	//!!!!!!!!!!!!!!!!!!!!!!!!!!!!
-
-	//get Priority
-	priority := interpreter.GetPriority(types.Address{} , tx.Data.Actions)
-	tx.SetPriority(priority)
+	// Transactions are ordered by the fee they pay per byte
+	tx.Priority = tx.FeePerByte()
 
 	// If the transaction is already known, discard it
 	hash := tx.Hash()
@@ -605,14 +766,27 @@ func (pool *TxPool) add(tx *transaction.Transaction, local bool) (bool, error) {
 		logger.Tracef("Discarding already known transaction hash:0x%x",  hash)
 		return false, fmt.Errorf("known transaction: 0x%x", hash)
 	}
+	// Remember when the pool first saw the transaction, for arrival ordering
+	if tx.Arrival().IsZero() {
+		tx.SetArrival(time.Now())
+	}
+	// If the transaction can never be included anymore, discard it
+	if number, now := pool.pendingWindow(); tx.Expired(number, now) {
+		logger.Tracef("Discarding expired transaction hash:0x%x", hash)
+		expiredTxCounter.Inc(1)
+		return false, transaction.ErrTxExpired
+	}
+	// Drop non-local transactions under our own minimal accepted fee per byte
+	if !local && tx.GetPriority().Cmp(pool.priority) < 0 {
+		logger.Tracef("Discarding underpriced transaction hash:0x%x", hash)
+		return false, ErrUnderPriority
+	}
 	// If the transaction fails basic validation, discard it
-
-	////here we do not check validation by pool
-	//if err := pool.validateTx(tx, local); err != nil {
-	//	logger.Trace("Discarding invalid transaction hash:0x%x , err:%s",  hash, err.Error())
-	//	invalidTxCounter.Inc(1)
-	//	return false, err
-	//}
+	if err := pool.validateTx(tx, local); err != nil {
+		logger.Tracef("Discarding invalid transaction hash:0x%x , err:%s",  hash, err.Error())
+		invalidTxCounter.Inc(1)
+		return false, err
+	}
 
 	if uint64(len(pool.all)) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		//do not add more transactions
@@ -623,7 +797,7 @@ func (pool *TxPool) add(tx *transaction.Transaction, local bool) (bool, error) {
 		//New transaction is better than our worse ones , make room for it
 		drop := pool.priorited.Discard(len(pool.all) - int(pool.config.GlobalSlots + pool.config.GlobalQueue -1) , pool.locals)
 		for _ , tx := range drop {
-			pool.removeTx(tx.Hash())
+			pool.removeTx(tx.Hash(), dropPoolFull)
 		}
 
 		return false,fmt.Errorf("pool.all > config.GlobalQueue")
@@ -632,7 +806,7 @@ func (pool *TxPool) add(tx *transaction.Transaction, local bool) (bool, error) {
 	from, _ := transaction.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 
-		inserted, old := list.Add(tx, 0)
+		inserted, old := list.Add(tx, pool.config.PriceBump)
 		if !inserted{
 			//have same nonce ,but priority is lower than before
 			return false  , ErrReplaceUnderpriority
@@ -645,18 +819,18 @@ func (pool *TxPool) add(tx *transaction.Transaction, local bool) (bool, error) {
 			//delete the old transaction
 			delete(pool.all , old.Hash())
 			pool.priorited.Removed()
-		}else{
-		//old == nil,mean here is no the transaction in the pool before
-			pool.all[tx.Hash()] = tx
-			pool.journalTx(from, tx)
-
-			logger.Trace("Pooled new executable transaction hash:0x%x , from:0x%x", hash,  from)
+			pendingReplaceCounter.Inc(1)
+			pool.notifyLifecycle(old.Hash(), TxEventReplaced, fmt.Sprintf("replaced by 0x%x", hash))
+		}
+		pool.all[tx.Hash()] = tx
+		pool.priorited.Put(tx)
+		pool.journalTx(from, tx)
 
-			// We've directly injected a replacement transaction, notify subsystems
-			logger.Debugf("!!!!!!!!!!!add  From:%x  Nonce:%d" , from,tx.Data.AccountNonce)
-			go pool.txFeed.Send(core.TxPreEvent{tx})
+		logger.Tracef("Pooled new executable transaction hash:0x%x , from:0x%x", hash,  from)
 
-		}
+		// We've directly injected a replacement transaction, notify subsystems
+		go pool.txFeed.Send(core.TxPreEvent{tx})
+		pool.notifyLifecycle(hash, TxEventPending, "")
 
 		return true, nil
 	}
@@ -692,7 +866,7 @@ func (pool *TxPool) enqueueTx(hash types.Hash, tx *transaction.Transaction) (boo
 	if pool.queue[from] == nil {
 		pool.queue[from] = newTxList(false)
 	}
-	inserted, old := pool.queue[from].Add(tx, 0)
+	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump)
 	if !inserted {
 		//new transaction's priority is lower than the old one
 		return false , ErrReplaceUnderpriority
@@ -704,13 +878,18 @@ func (pool *TxPool) enqueueTx(hash types.Hash, tx *transaction.Transaction) (boo
 
 		delete(pool.all , old.Hash())
 		pool.priorited.Removed()
+		queuedReplaceCounter.Inc(1)
+		pool.notifyLifecycle(old.Hash(), TxEventReplaced, fmt.Sprintf("replaced by 0x%x", hash))
 	}
 	//notice , if no the same tx before ,we should not return a true boolean,
 	//should false,because not replace
 	//old == nil,no same tx before
 
-	pool.all[hash] = tx
-	pool.priorited.Put(tx)
+	if pool.all[hash] == nil {
+		pool.all[hash] = tx
+		pool.priorited.Put(tx)
+	}
+	pool.notifyLifecycle(hash, TxEventQueued, "")
 	return old != nil, nil
 }
 
@@ -742,12 +921,15 @@ func (pool *TxPool) promoteTx(addr types.Address, hash types.Hash, tx *transacti
 		delete(pool.all, hash)
 		pool.priorited.Removed()
 		pendingDiscardCounter.Inc(1)
+		pool.notifyLifecycle(hash, TxEventDropped, "pending transaction with the same nonce is better")
 		return
 	}
 	// Otherwise discard any previous transaction and mark this
 	if old != nil {
 		delete(pool.all , old.Hash())
 		pool.priorited.Removed()
+		pendingReplaceCounter.Inc(1)
+		pool.notifyLifecycle(old.Hash(), TxEventReplaced, fmt.Sprintf("replaced by 0x%x", hash))
 	}
 	// Failsafe to work around direct pending inserts (tests)
 	if pool.all[hash] == nil {
@@ -759,6 +941,7 @@ func (pool *TxPool) promoteTx(addr types.Address, hash types.Hash, tx *transacti
 	pool.pendingState.SetNonce(addr, tx.Nonce()+1)
 	logger.Debugf("!!!!!!!!!!!promoteTx From:%x  Nonce:%d" , addr,tx.Nonce())
 	go pool.txFeed.Send(core.TxPreEvent{tx})
+	pool.notifyLifecycle(hash, TxEventPending, "")
 }
 
 // AddLocal enqueues a single transaction into the pool if it is valid, marking
@@ -873,9 +1056,45 @@ func (pool *TxPool) Get(hash types.Hash) *transaction.Transaction {
 	return pool.all[hash]
 }
 
+// RemoveTx drops a transaction from the pool, locals included, moving all
+// subsequent transactions of its account back to the future queue. A local
+// transaction is dropped from the journal too, so a restart doesn't load it
+// back. It reports whether the transaction was in the pool.
+func (pool *TxPool) RemoveTx(hash types.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	tx := pool.all[hash]
+	if tx == nil {
+		return false
+	}
+	from, _ := transaction.Sender(pool.signer, tx) // already validated during insertion
+	pool.removeTx(hash, dropRemoved)
+
+	if pool.journal != nil && pool.locals.contains(from) {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			logger.Warn("Failed to rotate local tx journal", "err", err)
+		}
+	}
+	return true
+}
+
+// staleStatus returns the lifecycle status of a transaction whose nonce has been
+// used by the chain: included if the chain holds it, dropped otherwise, as an
+// other transaction of the account took its nonce.
+func (pool *TxPool) staleStatus(hash types.Hash) (string, string) {
+	if db := pool.chain.GetDb(); db != nil {
+		if blockHash, _, _ := blockchain.GetTxLookupEntry(db, hash); blockHash != (types.Hash{}) {
+			return TxEventIncluded, ""
+		}
+	}
+	return TxEventDropped, dropStale
+}
+
 // removeTx removes a single transaction from the queue, moving all subsequent
-// transactions back to the future queue.
-func (pool *TxPool) removeTx(hash types.Hash) {
+// transactions back to the future queue. The reason is reported to the
+// lifecycle subscribers.
+func (pool *TxPool) removeTx(hash types.Hash, reason string) {
 	// Fetch the transaction we wish to delete
 	tx, ok := pool.all[hash]
 	if !ok {
@@ -886,6 +1105,7 @@ func (pool *TxPool) removeTx(hash types.Hash) {
 	// Remove it from the list of known transactions
 	delete(pool.all, hash)
 	pool.priorited.Removed()
+	pool.notifyLifecycle(hash, TxEventDropped, reason)
 	// Remove the transaction from the pending lists and reset the account nonce
 	if pending := pool.pending[addr]; pending != nil {
 		if removed, invalids := pending.Remove(tx); removed {
@@ -893,11 +1113,10 @@ func (pool *TxPool) removeTx(hash types.Hash) {
 			if pending.Empty() {
 				delete(pool.pending, addr)
 				delete(pool.beats, addr)
-			} else {
-				// Otherwise postpone any invalidated transactions
-				for _, tx := range invalids {
-					pool.enqueueTx(tx.Hash(), tx)
-				}
+			}
+			// Postpone any invalidated transactions
+			for _, tx := range invalids {
+				pool.enqueueTx(tx.Hash(), tx)
 			}
 			// Update the account nonce if needed
 			if nonce := tx.Nonce(); pool.pendingState.GetNonce(addr) > nonce {
@@ -940,6 +1159,8 @@ func (pool *TxPool) promoteExecutables(accounts []types.Address) {
 			logger.Tracef("Removed old queued transaction hash:0x%x",  hash)
 			delete(pool.all, hash)
 			pool.priorited.Removed()
+			status, reason := pool.staleStatus(hash)
+			pool.notifyLifecycle(hash, status, reason)
 		}
 		//fmt.Println("[promoteExecutables]List Len Before:Filter:" , len(list.txs.items))
 		// Drop all transactions that are too costly (low balance )
@@ -974,6 +1195,7 @@ func (pool *TxPool) promoteExecutables(accounts []types.Address) {
 				delete(pool.all, hash)
 				pool.priorited.Removed()
 				queuedRateLimitCounter.Inc(1)
+				pool.notifyLifecycle(hash, TxEventDropped, dropAccountLimit)
 				logger.Tracef("Removed cap-exceeding queued transaction hash:0x%x", hash)
 			}
 		}
@@ -1021,11 +1243,12 @@ func (pool *TxPool) promoteExecutables(accounts []types.Address) {
 							hash := tx.Hash()
 							delete(pool.all, hash)
 							pool.priorited.Removed()
+							pool.notifyLifecycle(hash, TxEventDropped, dropPendingLimit)
 							// Update the account nonce to the dropped transaction
 							if nonce := tx.Nonce(); pool.pendingState.GetNonce(offenders[i]) > nonce {
 								pool.pendingState.SetNonce(offenders[i], nonce)
 							}
-							logger.Trace("Removed fairness-exceeding pending transaction hash:0x%x", hash)
+							logger.Tracef("Removed fairness-exceeding pending transaction hash:0x%x", hash)
 						}
 						pending--
 					}
@@ -1042,11 +1265,12 @@ func (pool *TxPool) promoteExecutables(accounts []types.Address) {
 						hash := tx.Hash()
 						delete(pool.all, hash)
 						pool.priorited.Removed()
+						pool.notifyLifecycle(hash, TxEventDropped, dropPendingLimit)
 						// Update the account nonce to the dropped transaction
 						if nonce := tx.Nonce(); pool.pendingState.GetNonce(addr) > nonce {
 							pool.pendingState.SetNonce(addr, nonce)
 						}
-						logger.Trace("Removed fairness-exceeding pending transaction hash:0x%x", hash)
+						logger.Tracef("Removed fairness-exceeding pending transaction hash:0x%x", hash)
 					}
 					pending--
 				}
@@ -1085,7 +1309,7 @@ func (pool *TxPool) promoteExecutables(accounts []types.Address) {
 			logger.Info("[promoteExecutables] Will Drop size:" , list.Len())
 			if size := uint64(list.Len()); size <= drop {
 				for _, tx := range list.Flatten() {
-					pool.removeTx(tx.Hash())
+					pool.removeTx(tx.Hash(), dropQueueLimit)
 				}
 				drop -= size
 				queuedRateLimitCounter.Inc(int64(size))
@@ -1094,7 +1318,7 @@ func (pool *TxPool) promoteExecutables(accounts []types.Address) {
 			// Otherwise drop only last few transactions
 			txs := list.Flatten()
 			for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
-				pool.removeTx(txs[i].Hash())
+				pool.removeTx(txs[i].Hash(), dropQueueLimit)
 				drop--
 				queuedRateLimitCounter.Inc(1)
 			}
@@ -1102,6 +1326,30 @@ func (pool *TxPool) promoteExecutables(accounts []types.Address) {
 	}
 }
 
+// pendingWindow returns the block number and timestamp the next block is
+// expected to have, used to check transaction validity windows.
+func (pool *TxPool) pendingWindow() (uint64, uint64) {
+	if pool.currentHead == nil {
+		return 0, uint64(time.Now().Unix())
+	}
+	return pool.currentHead.Number.IntVal.Uint64() + 1, uint64(time.Now().Unix())
+}
+
+// evictExpired removes all transactions whose validity window has ended, moving
+// any subsequent transactions of the same account back to the future queue.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) evictExpired() {
+	number, now := pool.pendingWindow()
+	for hash, tx := range pool.all {
+		if tx.Expired(number, now) {
+			logger.Tracef("Removed expired transaction hash:0x%x", hash)
+			pool.removeTx(hash, dropExpired)
+			expiredTxCounter.Inc(1)
+		}
+	}
+}
+
 // demoteUnexecutables removes invalid and processed transactions from the pools
 // executable/pending queue and any subsequent transactions that become unexecutable
 // are moved back into the future queue.
@@ -1116,6 +1364,8 @@ func (pool *TxPool) demoteUnexecutables() {
 			logger.Tracef("Removed old pending transaction hash:0x%x", hash)
 			delete(pool.all, hash)
 			pool.priorited.Removed()
+			status, reason := pool.staleStatus(hash)
+			pool.notifyLifecycle(hash, status, reason)
 		}
 		// Drop all transactions that are too costly (low balance ), and queue any invalids back for later
 		//drops, invalids := list.Filter(pool.currentState.GetBalance(addr), 0)
@@ -21,8 +21,11 @@
 package txprocessor
 
 import (
+	"encoding/json"
+	"mjoy.io/core/interpreter/balancetransfer"
 	"mjoy.io/core/state"
 	"mjoy.io/utils/event"
+	"mjoy.io/core/blockchain"
 	"mjoy.io/core/blockchain/block"
 	"mjoy.io/common/types"
 	"mjoy.io/core"
@@ -35,6 +38,8 @@ import (
 	"fmt"
 	"time"
 	"testing"
+	"io/ioutil"
+	"os"
 	"math/rand"
 )
 
@@ -46,12 +51,16 @@ var testTxPoolConfig TxPoolConfig
 func init() {
 	testTxPoolConfig = DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
+	// The random actions call no inner contract, the contract validator is
+	// tested on its own
+	testTxPoolConfig.Validators = []string{ValidatorSize, ValidatorSignature, ValidatorNonce, ValidatorFee}
 }
 
 
 type testBlockChain struct{
 	statedb  *state.StateDB
 	chainHeadFeed *event.Feed
+	db database.IDatabase
 }
 
 
@@ -67,10 +76,24 @@ func (bc *testBlockChain)StateAt(hash types.Hash )(*state.StateDB,error){
 	return bc.statedb,nil
 }
 
+func (bc *testBlockChain)GetDb()database.IDatabase{
+	return bc.db
+}
+
 func (bc *testBlockChain)SubscribeChainHeadEvent(ch chan<-core.ChainHeadEvent)event.Subscription{
 	return bc.chainHeadFeed.Subscribe(ch)
 }
 
+// setBalance writes the balance of an account to the balance transfer contract
+// storage, the way a committed block does, for the fee validator to read it.
+func setBalance(db database.IDatabase, statedb *state.StateDB, addr types.Address, amount int) {
+	val, _ := json.Marshal(balancetransfer.BalanceValue{Amount: amount})
+	contract := balancetransfer.BalanceTransferAddress
+	valHash := crypto.Keccak256Hash(val)
+	statedb.SetState(contract, crypto.Keccak256Hash(append(contract.Bytes(), addr[:]...)), valHash)
+	db.Put(valHash[:], val)
+}
+
 func randomActions()[]transaction.Action{
 	address := types.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
 	t := rand.Intn(10)
@@ -94,13 +117,13 @@ func xtransaction(nonce uint64 , key *ecdsa.PrivateKey ,pool *TxPool)*transactio
 }
 
 func AsignedTransaction(nonce uint64 , key *ecdsa.PrivateKey,pool *TxPool)*transaction.Transaction{
-	tx,_ := transaction.SignTx(transaction.NewTransaction(nonce,types.Address{},randomActions()),mSigner,key)
+	tx,_ := transaction.SignTx(transaction.NewTransaction(nonce,randomActions()),mSigner,key)
 	pool.inter.SetPriorityForTransaction(tx)
 	return tx
 }
 
 func newxtransaction(nonce uint64  ,key *ecdsa.PrivateKey,pool *TxPool)*transaction.Transaction{
-	tx,_ := transaction.SignTx(transaction.NewTransaction(nonce,types.Address{},randomActions()),mSigner,key)
+	tx,_ := transaction.SignTx(transaction.NewTransaction(nonce,randomActions()),mSigner,key)
 	pool.inter.SetPriorityForTransaction(tx)
 	return tx
 }
@@ -108,13 +131,13 @@ func newxtransaction(nonce uint64  ,key *ecdsa.PrivateKey,pool *TxPool)*transact
 
 
 var (
-	TestChainConfig  = &params.ChainConfig{big.NewInt(1)}
+	TestChainConfig  = &params.ChainConfig{ChainId: big.NewInt(1)}
 
 )
 func setupTxPool()(*TxPool , *ecdsa.PrivateKey){
 	db,_:=database.OpenMemDB()
 	statedb ,_ := state.New(types.Hash{},state.NewDatabase(db))
-	blockchain := &testBlockChain{statedb  , new(event.Feed)}
+	blockchain := &testBlockChain{statedb  , new(event.Feed) , db}
 
 	key,_ := crypto.GenerateKey()
 	pool := NewTxPool(testTxPoolConfig , TestChainConfig , blockchain)
@@ -183,7 +206,8 @@ func (c *testChain)State()(*state.StateDB , error){
 		db,_:= database.OpenMemDB()
 		c.statedb ,_ = state.New(types.Hash{} , state.NewDatabase(db))
 		c.statedb.SetNonce(c.address , 2)
-		c.statedb.SetBalance(c.address , new(big.Int).SetUint64(100000))
+		setBalance(db , c.statedb , c.address , 100000)
+		c.db = db
 		*c.trigger = false
 	}
 
@@ -202,8 +226,8 @@ func TestStateChangeDuringTransactionPoolReset(t *testing.T){
 		trigger = false
 	)
 
-	statedb.SetBalance(address , new(big.Int).SetUint64(100000000))
-	blockchain := &testChain{&testBlockChain{statedb,new(event.Feed)},address,&trigger}
+	setBalance(db , statedb , address , 100000000)
+	blockchain := &testChain{&testBlockChain{statedb,new(event.Feed),db},address,&trigger}
 
 	pool := NewTxPool(testTxPoolConfig,TestChainConfig,blockchain)
 	defer pool.Stop()
@@ -259,17 +283,15 @@ func TestInvalidTransactions(t *testing.T){
 	tx := xtransaction(0,key,pool)
 
 	from,_ := deriveSender(tx)
-	fmt.Println("Before AddBalance:",pool.currentState.GetBalance(from).Int64())
-	pool.currentState.AddBalance(from,big.NewInt(1))
-	//pool.currentState.AddBalance(from,big.NewInt(1000))
-	fmt.Println("After AddBalance:",pool.currentState.GetBalance(from).Int64())
+	setBalance(pool.chain.GetDb() , pool.currentState , from , 1)
+	//setBalance(pool.chain.GetDb() , pool.currentState , from , 1000)
 
 	if err := pool.AddRemote(tx);err != nil{
 		fmt.Println("test addremote Err:",err)
 	}
 
 	pool.currentState.SetNonce(from,1)
-	pool.currentState.AddBalance(from,big.NewInt(111111111))
+	setBalance(pool.chain.GetDb() , pool.currentState , from , 111111112)
 	tx = xtransaction(0,key,pool)
 
 	if err := pool.AddRemote(tx);err != nil{
@@ -287,7 +309,7 @@ func TestTransactionQueue(t *testing.T){
 
 	from,_ := deriveSender(tx)
 
-	pool.currentState.AddBalance(from,big.NewInt(1000))
+	setBalance(pool.chain.GetDb() , pool.currentState , from , 1000)
 	pool.lockedReset(nil,nil)
 	pool.enqueueTx(tx.Hash() , tx)
 
@@ -328,7 +350,7 @@ func TestTransactionQueue(t *testing.T){
 
 	from , _ = deriveSender(tx1)
 
-	pool.currentState.AddBalance(from , big.NewInt(1000))
+	setBalance(pool.chain.GetDb() , pool.currentState , from , 1000)
 	pool.lockedReset(nil, nil)
 
 	fmt.Println("QueueLen before = " , len(pool.queue))
@@ -351,10 +373,10 @@ func TestTransactionNegativeValue(t *testing.T){
 	pool , key := setupTxPool()
 	defer pool.Stop()
 
-	tx ,_ := transaction.SignTx(transaction.NewTransaction(0 , types.Address{} , randomActions()),mSigner,key )
+	tx ,_ := transaction.SignTx(transaction.NewTransaction(0 , randomActions()),mSigner,key )
 	pool.inter.SetPriorityForTransaction(tx)
 	from ,_ := deriveSender(tx)
-	pool.currentState.AddBalance(from , big.NewInt(1))
+	setBalance(pool.chain.GetDb() , pool.currentState , from , 1)
 
 	if err := pool.AddRemote(tx);err != nil{
 		fmt.Println("Get A err:" , err)
@@ -373,9 +395,9 @@ func TestTransactionChainFork(t *testing.T){
 	resetState := func(){
 		db , _ := database.OpenMemDB()
 		statedb,_ := state.New(types.Hash{} , state.NewDatabase(db))
-		statedb.AddBalance(addr , big.NewInt(100000000000000))
+		setBalance(db , statedb , addr , 100000000000000)
 
-		pool.chain = &testBlockChain{statedb,new(event.Feed)}
+		pool.chain = &testBlockChain{statedb,new(event.Feed),db}
 		pool.lockedReset(nil,nil)
 	}
 	resetState()
@@ -387,7 +409,7 @@ func TestTransactionChainFork(t *testing.T){
 
 	fmt.Println("pending Len:" , len(pool.pending))
 	fmt.Println("queue Len:" , len(pool.queue))
-	pool.removeTx(tx.Hash())
+	pool.removeTx(tx.Hash(), dropRemoved)
 
 	//reset the pool's internal state
 	resetState()
@@ -417,19 +439,19 @@ func TestTransactionDoubleNonce(t *testing.T) {
 
 		statedb , _ := state.New(types.Hash{},state.NewDatabase(db))
 
-		statedb.AddBalance(addr , big.NewInt(100000000000000))
+		setBalance(db , statedb , addr , 100000000000000)
 
-		pool.chain = &testBlockChain{statedb  , new(event.Feed)}
+		pool.chain = &testBlockChain{statedb  , new(event.Feed) , db}
 		pool.lockedReset(nil,nil)
 	}
 
 	resetState()
 	fmt.Println("Notice:If All Transaction's nonce are same,the txpool just exist one ")
-	tx1,_:=transaction.SignTx(transaction.NewTransaction(0,types.Address{},randomActions()),mSigner,key)
+	tx1,_:=transaction.SignTx(transaction.NewTransaction(0,randomActions()),mSigner,key)
 	pool.inter.SetPriorityForTransaction(tx1)
-	tx2,_:=transaction.SignTx(transaction.NewTransaction(0,types.Address{},randomActions()),mSigner,key)
+	tx2,_:=transaction.SignTx(transaction.NewTransaction(0,randomActions()),mSigner,key)
 	pool.inter.SetPriorityForTransaction(tx2)
-	tx3,_:=transaction.SignTx(transaction.NewTransaction(0,types.Address{},randomActions()),mSigner,key)
+	tx3,_:=transaction.SignTx(transaction.NewTransaction(0,randomActions()),mSigner,key)
 	pool.inter.SetPriorityForTransaction(tx3)
 
 	fmt.Println("tx1..1:" , tx1.Priority.Int64() , "  tx..2:" , tx2.Priority.Int64() , "   tx3..:",tx3.Priority.Int64())
@@ -457,7 +479,7 @@ func TestTransactionDoubleNonce(t *testing.T) {
 	pool.promoteExecutables([]types.Address{addr})
 
 	if pool.pending[addr].Len() != 1{
-		t.Errorf("Error:expected 1 pending trasactions , got\n",pool.pending[addr].Len())
+		t.Errorf("Error:expected 1 pending trasactions , got %d\n",pool.pending[addr].Len())
 	}
 
 	if tx := pool.pending[addr].txs.items[0];tx.Hash()!= tx2.Hash() {
@@ -481,7 +503,7 @@ func TestTransactionMissingNonce(t *testing.T){
 
 
 	addr := crypto.PubkeyToAddress(key.PublicKey)
-	pool.currentState.AddBalance(addr , big.NewInt(100000000000000))
+	setBalance(pool.chain.GetDb() , pool.currentState , addr , 100000000000000)
 
 	tx := xtransaction(1 ,key,pool)
 	if _,err := pool.add(tx , false);err != nil{
@@ -510,7 +532,7 @@ func TestTransactionDroppng(t *testing.T){
 	defer pool .Stop()
 
 	account , _ := deriveSender(xtransaction(0 , key,pool))
-	pool.currentState.AddBalance(account , big.NewInt(1000))
+	setBalance(pool.chain.GetDb() , pool.currentState , account , 1000)
 
 	var(
 		tx0 = newxtransaction(0 , key,pool)
@@ -558,14 +580,12 @@ func TestTransactionDroppng(t *testing.T){
 	if len(pool.all)!= 6{
 		t.Errorf("total transaction mismatch :have %d ,want %d",len(pool.all) , 6)
 	}
-	fmt.Println("1now Balance:",pool.currentState.GetBalance(account))
 	fmt.Println("1len pending:",len(pool.pending[account].txs.items))
 	fmt.Println("1len queue:",len(pool.queue[account].txs.items))
 
-	pool.currentState.AddBalance(account,big.NewInt(-500))
+	setBalance(pool.chain.GetDb() , pool.currentState , account , 500)
 	pool.lockedReset(nil,nil)
 
-	fmt.Println("2now Balance:",pool.currentState.GetBalance(account))
 	fmt.Println("2len pending:",len(pool.pending[account].txs.items))
 	fmt.Println("2len queue:",len(pool.queue[account].txs.items))
 	if _, ok := pool.pending[account].txs.items[tx0.Nonce()]; !ok {
@@ -606,7 +626,7 @@ func TestPendingState(t *testing.T){
 	defer pool .Stop()
 
 	account , _ := deriveSender(xtransaction(0 , key,pool))
-	pool.currentState.AddBalance(account , big.NewInt(1000))
+	setBalance(pool.chain.GetDb() , pool.currentState , account , 1000)
 
 	var(
 		tx0 = newxtransaction(0 ,  key,pool)
@@ -638,7 +658,7 @@ func TestTransactionPostponing(t *testing.T){
 	defer pool.Stop()
 
 	account, _ := deriveSender(newxtransaction(0,  key,pool))
-	pool.currentState.AddBalance(account, big.NewInt(1000))
+	setBalance(pool.chain.GetDb() , pool.currentState , account , 1000)
 
 	txns := []*transaction.Transaction{}
 
@@ -679,7 +699,7 @@ func TestTransactionPostponing(t *testing.T){
 
 
 	// Reduce the balance of the account, and check that transactions are reorganised
-	pool.currentState.AddBalance(account, big.NewInt(-750))
+	setBalance(pool.chain.GetDb() , pool.currentState , account , 250)
 	pool.lockedReset(nil, nil)
 
 	if _, ok := pool.pending[account].txs.items[txns[0].Nonce()]; !ok {
@@ -703,7 +723,7 @@ func TestTransactionGapFilling(t *testing.T){
 
 
 	account ,_ := deriveSender(newxtransaction(0 ,  key,pool))
-	pool.currentState.AddBalance(account , big.NewInt(1000000))
+	setBalance(pool.chain.GetDb() , pool.currentState , account , 1000000)
 
 	events := make(chan core.TxPreEvent , 70)
 	sub := pool.txFeed.Subscribe(events)
@@ -767,7 +787,7 @@ func TestTransactionQueueAccountLimiting(t *testing.T){
 	defer pool.Stop()
 
 	account ,_ := deriveSender(newxtransaction(0 , key,pool))
-	pool.currentState.AddBalance(account , big.NewInt(1000000))
+	setBalance(pool.chain.GetDb() , pool.currentState , account , 1000000)
 
 	for i:= uint64(1);i<=testTxPoolConfig.AccountQueue + 5;i++{
 		if err := pool.AddRemote(newxtransaction(i , key,pool));err != nil{
@@ -807,8 +827,8 @@ func TestBefore(t *testing.T){
 		trigger = false
 	)
 
-	statedb.SetBalance(address , new(big.Int).SetUint64(100000000))
-	blockchain := &testChain{&testBlockChain{statedb,new(event.Feed)},address,&trigger}
+	setBalance(db , statedb , address , 100000000)
+	blockchain := &testChain{&testBlockChain{statedb,new(event.Feed),db},address,&trigger}
 	pool := NewTxPool(testTxPoolConfig,TestChainConfig,blockchain)
 	defer pool.Stop()
 	tx0 := xtransaction(0,key,pool)
@@ -833,7 +853,7 @@ func TestTransactionPendingLimiting(t *testing.T ) {
 
 	db , _ := database.OpenMemDB()
 	statedb , _ := state.New(types.Hash{} , state.NewDatabase(db))
-	blockchain := &testBlockChain{statedb, new(event.Feed)}
+	blockchain := &testBlockChain{statedb, new(event.Feed), db}
 	config := testTxPoolConfig
 	config.GlobalQueue = config.AccountQueue*3 - 1
 	config.GlobalSlots = 350
@@ -850,7 +870,7 @@ func TestTransactionPendingLimiting(t *testing.T ) {
 	for i:=0;i<len(keys);i++{
 		keys[i] , _ = crypto.GenerateKey()
 		address[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
-		pool.currentState.AddBalance(address[i] , big.NewInt(1000000))
+		setBalance(pool.chain.GetDb() , pool.currentState , address[i] , 1000000)
 
 	}
 
@@ -909,7 +929,7 @@ func testTransactionQueueGlobalLimiting(t *testing.T , nolocals bool) {
 
 	db , _ := database.OpenMemDB()
 	statedb , _ := state.New(types.Hash{} , state.NewDatabase(db))
-	blockchain := &testBlockChain{statedb, new(event.Feed)}
+	blockchain := &testBlockChain{statedb, new(event.Feed), db}
 	config := testTxPoolConfig
 	config.NoLocals = nolocals
 	config.GlobalQueue = config.AccountQueue*3 - 1
@@ -925,7 +945,7 @@ func testTransactionQueueGlobalLimiting(t *testing.T , nolocals bool) {
 	for i:=0;i<len(keys);i++{
 		keys[i] , _ = crypto.GenerateKey()
 		address[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
-		pool.currentState.AddBalance(address[i] , big.NewInt(1000000))
+		setBalance(pool.chain.GetDb() , pool.currentState , address[i] , 1000000)
 
 	}
 
@@ -1048,3 +1068,330 @@ func testTransactionQueueGlobalLimiting(t *testing.T , nolocals bool) {
 
 
 
+
+// feeTransaction creates a transaction paying the given fee, its priority is
+// its fee per byte.
+func feeTransaction(nonce uint64, fee uint64, key *ecdsa.PrivateKey) *transaction.Transaction {
+	tx, _ := transaction.SignTx(transaction.NewTransaction(nonce, nil).WithFee(fee), mSigner, key)
+	return tx
+}
+
+// validatePriorityIndex checks the priority heap indexes exactly the pooled
+// transactions.
+func validatePriorityIndex(pool *TxPool) error {
+	if live := len(*pool.priorited.items) - pool.priorited.stales; live != len(pool.all) {
+		return fmt.Errorf("priority index mismatch: have %d, want %d", live, len(pool.all))
+	}
+	return nil
+}
+
+// Tests that a transaction replacing a pending one is indexed in the lookup and
+// the priority heap, and that transactions moved back to the queue are not
+// indexed twice.
+func TestTransactionReplacementIndexed(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	setBalance(pool.chain.GetDb(), pool.currentState, addr, 1000000)
+	pool.lockedReset(nil, nil)
+
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		if err := pool.AddRemote(feeTransaction(nonce, 10, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, err)
+		}
+	}
+	if pending, _ := pool.stats(); pending != 3 {
+		t.Fatalf("pending transactions mismatch: have %d, want %d", pending, 3)
+	}
+	old := pool.pending[addr].txs.Get(1)
+	replacement := feeTransaction(1, 100, key)
+	if err := pool.AddRemote(replacement); err != nil {
+		t.Fatalf("failed to replace pending transaction: %v", err)
+	}
+	if pool.Get(replacement.Hash()) == nil {
+		t.Fatalf("replacement transaction missing from the pool lookup")
+	}
+	if pool.Get(old.Hash()) != nil {
+		t.Fatalf("replaced transaction still in the pool lookup")
+	}
+	if err := validatePriorityIndex(pool); err != nil {
+		t.Fatal(err)
+	}
+	// Removing the replacement moves the following transaction back to the queue
+	pool.mu.Lock()
+	pool.removeTx(replacement.Hash(), dropRemoved)
+	pool.mu.Unlock()
+
+	if pending, queued := pool.stats(); pending != 1 || queued != 1 {
+		t.Fatalf("pool mismatch: have %d pending %d queued, want 1 pending 1 queued", pending, queued)
+	}
+	if err := validatePriorityIndex(pool); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the remote transactions of the pool survive a restart through the
+// snapshot, that the restored ones are revalidated against the new head, and
+// that the heartbeats of their accounts are kept.
+func TestTransactionSnapshotting(t *testing.T) {
+	t.Parallel()
+
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary snapshot: %v", err)
+	}
+	snapshot := file.Name()
+	defer os.Remove(snapshot)
+	file.Close()
+	os.Remove(snapshot)
+
+	config := testTxPoolConfig
+	config.Snapshot = snapshot
+
+	db, _ := database.OpenMemDB()
+	statedb, _ := state.New(types.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, new(event.Feed), db}
+
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	addr1 := crypto.PubkeyToAddress(key1.PublicKey)
+	addr2 := crypto.PubkeyToAddress(key2.PublicKey)
+	setBalance(db, statedb, addr1, 1000000)
+	setBalance(db, statedb, addr2, 1000000)
+
+	pool := NewTxPool(config, TestChainConfig, blockchain)
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		if err := pool.AddRemote(feeTransaction(nonce, 10, key1)); err != nil {
+			t.Fatalf("failed to add pending transaction %d: %v", nonce, err)
+		}
+	}
+	if err := pool.AddRemote(feeTransaction(2, 10, key2)); err != nil {
+		t.Fatalf("failed to add queued transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 3 || queued != 1 {
+		t.Fatalf("pool mismatch: have %d pending %d queued, want 3 pending 1 queued", pending, queued)
+	}
+	beat1, beat2 := time.Now().Add(-time.Hour), time.Now().Add(-2*time.Hour)
+	pool.mu.Lock()
+	pool.beats[addr1], pool.beats[addr2] = beat1, beat2
+	pool.mu.Unlock()
+
+	// Stopping saves the snapshot, mine two of the transactions in the meantime
+	pool.Stop()
+	statedb.SetNonce(addr1, 2)
+
+	pool = NewTxPool(config, TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("restored pool mismatch: have %d pending %d queued, want 1 pending 1 queued", pending, queued)
+	}
+	if list := pool.pending[addr1]; list == nil || list.txs.Get(2) == nil {
+		t.Fatalf("executable transaction not restored")
+	}
+	if list := pool.queue[addr2]; list == nil || list.txs.Get(2) == nil {
+		t.Fatalf("queued transaction not restored")
+	}
+	if !pool.beats[addr1].Equal(beat1) || !pool.beats[addr2].Equal(beat2) {
+		t.Fatalf("heartbeats mismatch: have %v, %v, want %v, %v", pool.beats[addr1], pool.beats[addr2], beat1, beat2)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that a local transaction removed from the pool is dropped from the
+// journal too, and stays gone when the pool is restarted.
+func TestTransactionRemoveJournaled(t *testing.T) {
+	t.Parallel()
+
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+	file.Close()
+	os.Remove(journal)
+
+	config := testTxPoolConfig
+	config.NoLocals = false
+	config.Journal = journal
+
+	db, _ := database.OpenMemDB()
+	statedb, _ := state.New(types.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, new(event.Feed), db}
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	setBalance(db, statedb, addr, 1000000)
+
+	pool := NewTxPool(config, TestChainConfig, blockchain)
+	txs := make([]*transaction.Transaction, 3)
+	for nonce := range txs {
+		txs[nonce] = feeTransaction(uint64(nonce), 10, key)
+		if err := pool.AddLocal(txs[nonce]); err != nil {
+			t.Fatalf("failed to add local transaction %d: %v", nonce, err)
+		}
+	}
+	if !pool.RemoveTx(txs[1].Hash()) {
+		t.Fatalf("pooled transaction not removed")
+	}
+	pool.Stop()
+
+	pool = NewTxPool(config, TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if pool.Get(txs[1].Hash()) != nil {
+		t.Fatalf("removed transaction loaded back from the journal")
+	}
+	if pool.Get(txs[0].Hash()) == nil || pool.Get(txs[2].Hash()) == nil {
+		t.Fatalf("remaining local transactions not loaded from the journal")
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("restored pool mismatch: have %d pending %d queued, want 1 pending 1 queued", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// expectLifecycle reads the next lifecycle events of the pool and checks them
+// against the wanted ones, in order.
+func expectLifecycle(t *testing.T, events <-chan core.TxLifecycleEvent, want ...core.TxLifecycleEvent) {
+	for i, ev := range want {
+		select {
+		case have := <-events:
+			if have != ev {
+				t.Fatalf("event %d mismatch: have %+v, want %+v", i, have, ev)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d missing: want %+v", i, ev)
+		}
+	}
+	select {
+	case have := <-events:
+		t.Fatalf("unexpected event: %+v", have)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// Tests that the stages of the transactions are reported to the lifecycle
+// subscribers and by the status and content queries, and that a transaction
+// whose nonce is used by the chain is only reported as included when the chain
+// holds it.
+func TestTransactionLifecycle(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	setBalance(pool.chain.GetDb(), pool.currentState, addr, 1000000)
+	pool.lockedReset(nil, nil)
+
+	events := make(chan core.TxLifecycleEvent, 16)
+	sub := pool.SubscribeTxLifecycleEvent(events)
+	defer sub.Unsubscribe()
+
+	tx0, tx1, tx2, tx3 := feeTransaction(0, 10, key), feeTransaction(1, 10, key), feeTransaction(2, 10, key), feeTransaction(3, 10, key)
+	for _, tx := range []*transaction.Transaction{tx0, tx2, tx3} {
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", tx.Nonce(), err)
+		}
+	}
+	expectLifecycle(t, events,
+		core.TxLifecycleEvent{Hash: tx0.Hash(), Status: TxEventQueued},
+		core.TxLifecycleEvent{Hash: tx0.Hash(), Status: TxEventPending},
+		core.TxLifecycleEvent{Hash: tx2.Hash(), Status: TxEventQueued},
+		core.TxLifecycleEvent{Hash: tx3.Hash(), Status: TxEventQueued},
+	)
+	status := pool.Status([]types.Hash{tx0.Hash(), tx2.Hash(), tx1.Hash()})
+	if status[0] != TxStatusPending || status[1] != TxStatusQueued || status[2] != TxStatusUnknown {
+		t.Fatalf("status mismatch: have %v, want %v", status, []TxStatus{TxStatusPending, TxStatusQueued, TxStatusUnknown})
+	}
+	pending, queued := pool.ContentFrom(addr)
+	if len(pending) != 1 || len(queued) != 2 || pending[0] != tx0 || queued[0] != tx2 || queued[1] != tx3 {
+		t.Fatalf("content mismatch: have %d pending %d queued, want 1 pending 2 queued", len(pending), len(queued))
+	}
+	if pending, queued := pool.ContentFrom(types.Address{}); pending != nil || queued != nil {
+		t.Fatalf("content of an unknown account: have %d pending %d queued", len(pending), len(queued))
+	}
+	// Filling the gap promotes the queued transactions
+	if err := pool.AddRemote(tx1); err != nil {
+		t.Fatalf("failed to add transaction 1: %v", err)
+	}
+	expectLifecycle(t, events,
+		core.TxLifecycleEvent{Hash: tx1.Hash(), Status: TxEventQueued},
+		core.TxLifecycleEvent{Hash: tx1.Hash(), Status: TxEventPending},
+		core.TxLifecycleEvent{Hash: tx2.Hash(), Status: TxEventPending},
+		core.TxLifecycleEvent{Hash: tx3.Hash(), Status: TxEventPending},
+	)
+	// The chain uses the first two nonces, but holds only the first transaction
+	if err := blockchain.WriteTxLookupEntries(pool.chain.GetDb(), block.NewBlock(&block.Header{}, []*transaction.Transaction{tx0}, nil)); err != nil {
+		t.Fatalf("failed to write lookup entries: %v", err)
+	}
+	pool.currentState.SetNonce(addr, 2)
+	pool.lockedReset(nil, nil)
+
+	expectLifecycle(t, events,
+		core.TxLifecycleEvent{Hash: tx0.Hash(), Status: TxEventIncluded},
+		core.TxLifecycleEvent{Hash: tx1.Hash(), Status: TxEventDropped, Reason: dropStale},
+	)
+	// Removing a transaction moves the following ones back to the queue
+	if !pool.RemoveTx(tx2.Hash()) {
+		t.Fatalf("pooled transaction not removed")
+	}
+	if pool.RemoveTx(tx2.Hash()) {
+		t.Fatalf("removed transaction removed twice")
+	}
+	expectLifecycle(t, events,
+		core.TxLifecycleEvent{Hash: tx2.Hash(), Status: TxEventDropped, Reason: dropRemoved},
+		core.TxLifecycleEvent{Hash: tx3.Hash(), Status: TxEventQueued},
+	)
+
+	status = pool.Status([]types.Hash{tx0.Hash(), tx2.Hash(), tx3.Hash()})
+	if status[0] != TxStatusUnknown || status[1] != TxStatusUnknown || status[2] != TxStatusQueued {
+		t.Fatalf("status mismatch: have %v, want %v", status, []TxStatus{TxStatusUnknown, TxStatusUnknown, TxStatusQueued})
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that each block gets its own ordering policy, so the contract quotas
+// counted while packing one block don't hold back the transactions of another.
+func TestOrderingPolicyPerBlock(t *testing.T) {
+	t.Parallel()
+
+	config := testTxPoolConfig
+	config.Ordering.ContractQuota = 1
+
+	db, _ := database.OpenMemDB()
+	statedb, _ := state.New(types.Hash{}, state.NewDatabase(db))
+	pool := NewTxPool(config, TestChainConfig, &testBlockChain{statedb, new(event.Feed), db})
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	contract := types.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	tx := transaction.NewTransaction(0, transaction.ActionSlice{transaction.MakeAction(contract, nil)})
+
+	first, second := pool.OrderingPolicy(), pool.OrderingPolicy()
+	first.Reset()
+	second.Reset()
+
+	first.Packed(tx, from)
+	if first.Accept(tx, from) {
+		t.Fatalf("transaction over the contract quota accepted")
+	}
+	if !second.Accept(tx, from) {
+		t.Fatalf("quota of one block held back the transactions of another")
+	}
+}
@@ -0,0 +1,102 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: tx_snapshot.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package txprocessor
+
+import (
+	"io"
+	"os"
+
+	"github.com/tinylib/msgp/msgp"
+	"mjoy.io/core/transaction"
+)
+
+//go:generate msgp
+
+// TxSnapshotEntry is a remote transaction of the pool snapshot, together with
+// the last heartbeat of its sending account so that Lifetime eviction keeps
+// counting across restarts.
+type TxSnapshotEntry struct {
+	Tx   *transaction.Transaction
+	Beat int64 // Unix time of the last heartbeat in nanoseconds
+}
+
+// txSnapshot is a dump of the remote transactions of the pool, written from
+// scratch on each save, to allow them to survive node restarts.
+type txSnapshot struct {
+	path string // Filesystem path to store the transactions at
+}
+
+// newTxSnapshot creates a new pool snapshot stored at the given path.
+func newTxSnapshot(path string) *txSnapshot {
+	return &txSnapshot{
+		path: path,
+	}
+}
+
+// load parses the snapshot from disk. A missing snapshot holds no entries.
+func (snapshot *txSnapshot) load() ([]*TxSnapshotEntry, error) {
+	input, err := os.Open(snapshot.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	// A single reader for all entries, its read ahead buffer spans them
+	reader := msgp.NewReader(input)
+
+	var entries []*TxSnapshotEntry
+	for {
+		entry := new(TxSnapshotEntry)
+		if err = entry.DecodeMsg(reader); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// save replaces the snapshot on disk with the given entries.
+func (snapshot *txSnapshot) save(entries []*TxSnapshotEntry) error {
+	replacement, err := os.OpenFile(snapshot.path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	writer := msgp.NewWriter(replacement)
+	for _, entry := range entries {
+		if err = entry.EncodeMsg(writer); err != nil {
+			replacement.Close()
+			return err
+		}
+	}
+	if err = writer.Flush(); err != nil {
+		replacement.Close()
+		return err
+	}
+	if err = replacement.Close(); err != nil {
+		return err
+	}
+	return os.Rename(snapshot.path+".new", snapshot.path)
+}
@@ -0,0 +1,168 @@
+package txprocessor
+
+// NOTE: THIS FILE WAS PRODUCED BY THE
+// MSGP CODE GENERATION TOOL (github.com/tinylib/msgp)
+// DO NOT EDIT
+
+import (
+	"github.com/tinylib/msgp/msgp"
+	"mjoy.io/core/transaction"
+)
+
+// DecodeMsg implements msgp.Decodable
+func (z *TxSnapshotEntry) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Tx":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					return
+				}
+				z.Tx = nil
+			} else {
+				if z.Tx == nil {
+					z.Tx = new(transaction.Transaction)
+				}
+				err = z.Tx.DecodeMsg(dc)
+				if err != nil {
+					return
+				}
+			}
+		case "Beat":
+			z.Beat, err = dc.ReadInt64()
+			if err != nil {
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *TxSnapshotEntry) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "Tx"
+	err = en.Append(0x82, 0xa2, 0x54, 0x78)
+	if err != nil {
+		return
+	}
+	if z.Tx == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.Tx.EncodeMsg(en)
+		if err != nil {
+			return
+		}
+	}
+	// write "Beat"
+	err = en.Append(0xa4, 0x42, 0x65, 0x61, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Beat)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *TxSnapshotEntry) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "Tx"
+	o = append(o, 0x82, 0xa2, 0x54, 0x78)
+	if z.Tx == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.Tx.MarshalMsg(o)
+		if err != nil {
+			return
+		}
+	}
+	// string "Beat"
+	o = append(o, 0xa4, 0x42, 0x65, 0x61, 0x74)
+	o = msgp.AppendInt64(o, z.Beat)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *TxSnapshotEntry) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Tx":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.Tx = nil
+			} else {
+				if z.Tx == nil {
+					z.Tx = new(transaction.Transaction)
+				}
+				bts, err = z.Tx.UnmarshalMsg(bts)
+				if err != nil {
+					return
+				}
+			}
+		case "Beat":
+			z.Beat, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *TxSnapshotEntry) Msgsize() (s int) {
+	s = 1 + 3
+	if z.Tx == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.Tx.Msgsize()
+	}
+	s += 5 + msgp.Int64Size
+	return
+}
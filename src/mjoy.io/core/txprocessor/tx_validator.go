@@ -0,0 +1,261 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: tx_validator.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package txprocessor
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core"
+	"mjoy.io/core/interpreter"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/interpreter/intertypes"
+	"mjoy.io/core/interpreter/multisig"
+	"mjoy.io/core/sdk"
+	"mjoy.io/core/state"
+	"mjoy.io/core/transaction"
+	"mjoy.io/utils/database"
+	"mjoy.io/utils/metrics"
+)
+
+// Names of the built-in admission validators, usable in TxPoolConfig.Validators.
+const (
+	ValidatorSize      = "size"
+	ValidatorSignature = "signature"
+	ValidatorNonce     = "nonce"
+	ValidatorFee       = "fee"
+	ValidatorContract  = "contract"
+)
+
+// Error codes of the admission rejection reasons. They are in the server error
+// range of JSON-RPC and are returned as the error code to RPC callers.
+const (
+	RejectOversized = -32010 - iota
+	RejectInvalidSender
+	RejectNonceTooLow
+	RejectInsufficientFee
+	RejectContract
+)
+
+// ErrContractRejected is returned if an inner contract called by the
+// transaction refuses it in its pre-check.
+var ErrContractRejected = errors.New("rejected by contract")
+
+// TxValidator is one stage of the admission pipeline of the pool. It returns
+// nil to let the transaction through, or the reason it is rejected.
+type TxValidator func(ctx *ValidationContext, tx *transaction.Transaction) error
+
+// ValidationContext is the view of the pool handed to the validators.
+type ValidationContext struct {
+	Config *TxPoolConfig
+	Signer transaction.Signer
+	Local  bool
+
+	State   *state.StateDB      // State of the current head
+	Pending *state.ManagedState // Pending state tracking virtual nonces
+
+	db      database.IDatabaseGetter
+	vm      *interpreter.Vms
+	chainId *big.Int
+	staking bool
+}
+
+// Sender returns the sender of the transaction, recovering it if no validator
+// did before.
+func (ctx *ValidationContext) Sender(tx *transaction.Transaction) (types.Address, error) {
+	return transaction.Sender(ctx.Signer, tx)
+}
+
+// SystemParams returns fresh system params reading contract storage in the
+// pending state, or nil if the pool has no database to read it from.
+func (ctx *ValidationContext) SystemParams() *intertypes.SystemParams {
+	if ctx.db == nil || ctx.Pending == nil {
+		return nil
+	}
+	sdkHandler := sdk.NewTmpStatusManager(ctx.db, ctx.Pending.StateDB, types.Address{})
+	sysparam := intertypes.MakeSystemParams(sdkHandler, ctx.vm)
+	sysparam.ChainId = ctx.chainId
+	sysparam.Staking = ctx.staking
+	return sysparam
+}
+
+// TxRejectError is returned by the pool for transactions refused by a validator.
+type TxRejectError struct {
+	Validator string // Name of the refusing validator
+	Code      int    // Error code of the rejection reason
+	Err       error  // Reason of the rejection
+}
+
+func (e *TxRejectError) Error() string  { return e.Err.Error() }
+func (e *TxRejectError) ErrorCode() int { return e.Code }
+
+// RejectCode returns the rejection error code carried by err, or 0 if err was
+// not returned by a validator.
+func RejectCode(err error) int {
+	if reject, ok := err.(*TxRejectError); ok {
+		return reject.Code
+	}
+	return 0
+}
+
+type txValidatorEntry struct {
+	code     int
+	validate TxValidator
+	rejected metrics.Counter
+}
+
+var (
+	txValidatorsMu sync.RWMutex
+	txValidators   = make(map[string]*txValidatorEntry)
+)
+
+func init() {
+	RegisterTxValidator(ValidatorSize, RejectOversized, validateSize)
+	RegisterTxValidator(ValidatorSignature, RejectInvalidSender, validateSignature)
+	RegisterTxValidator(ValidatorNonce, RejectNonceTooLow, validateNonce)
+	RegisterTxValidator(ValidatorFee, RejectInsufficientFee, validateFee)
+	RegisterTxValidator(ValidatorContract, RejectContract, validateContract)
+}
+
+// RegisterTxValidator makes a validator available to the pool configuration
+// under the given name. Its rejections are reported with the given error code
+// and counted by the txpool/invalid/<name> metric. It panics if the name is
+// already taken.
+func RegisterTxValidator(name string, code int, validate TxValidator) {
+	txValidatorsMu.Lock()
+	defer txValidatorsMu.Unlock()
+
+	if _, exist := txValidators[name]; exist {
+		panic(fmt.Sprintf("tx validator %s already registered", name))
+	}
+	txValidators[name] = &txValidatorEntry{
+		code:     code,
+		validate: validate,
+		rejected: metrics.NewRegisteredCounter("txpool/invalid/"+name, nil),
+	}
+}
+
+func getTxValidator(name string) *txValidatorEntry {
+	txValidatorsMu.RLock()
+	defer txValidatorsMu.RUnlock()
+
+	return txValidators[name]
+}
+
+// runValidators passes the transaction through the configured validators in
+// order, stopping at the first rejection.
+func runValidators(ctx *ValidationContext, tx *transaction.Transaction) error {
+	for _, name := range ctx.Config.Validators {
+		entry := getTxValidator(name)
+		if entry == nil {
+			continue
+		}
+		if err := entry.validate(ctx, tx); err != nil {
+			entry.rejected.Inc(1)
+			return &TxRejectError{Validator: name, Code: entry.code, Err: err}
+		}
+	}
+	return nil
+}
+
+// validateSize rejects transactions over the configured size, to prevent DOS
+// attacks.
+func validateSize(ctx *ValidationContext, tx *transaction.Transaction) error {
+	if uint64(tx.Size()) > ctx.Config.MaxTxSize {
+		return ErrOversizedData
+	}
+	return nil
+}
+
+// validateSignature makes sure the transaction is signed properly. Every
+// partial signature of a multisig transaction is recovered and checked against
+// its key set, which must be the one registered for the sender in pending state.
+func validateSignature(ctx *ValidationContext, tx *transaction.Transaction) error {
+	from, err := ctx.Sender(tx)
+	if err != nil {
+		logger.Tracef("Invalid sender for transaction hash:0x%x , err:%s", tx.Hash(), err.Error())
+		return ErrInvalidSender
+	}
+	sysparam := ctx.SystemParams()
+	if sysparam == nil {
+		return nil
+	}
+	var keySet types.Hash
+	if tx.IsMultiSig() {
+		keySet = tx.Data.MultiSig.KeySetHash()
+	}
+	if err := multisig.CheckSender(sysparam.SdkHandler, from, keySet); err != nil {
+		logger.Tracef("Unauthorized sender for transaction hash:0x%x , err:%s", tx.Hash(), err.Error())
+		return ErrInvalidSender
+	}
+	return nil
+}
+
+// validateNonce ensures the transaction adheres to nonce ordering.
+func validateNonce(ctx *ValidationContext, tx *transaction.Transaction) error {
+	from, err := ctx.Sender(tx)
+	if err != nil {
+		return ErrInvalidSender
+	}
+	if ctx.State.GetNonce(from) > tx.Nonce() {
+		return ErrNonceTooLow
+	}
+	return nil
+}
+
+// validateFee ensures the sender can pay the fee of the transaction.
+func validateFee(ctx *ValidationContext, tx *transaction.Transaction) error {
+	sysparam := ctx.SystemParams()
+	if sysparam == nil || tx.Fee() == 0 {
+		return nil
+	}
+	from, err := ctx.Sender(tx)
+	if err != nil {
+		return ErrInvalidSender
+	}
+	if err := balancetransfer.CheckFeeBalance(sysparam.SdkHandler, from, tx.Fee()); err != nil {
+		logger.Tracef("Unaffordable fee for transaction hash:0x%x , err:%s", tx.Hash(), err.Error())
+		return core.ErrInsufficientFee
+	}
+	return nil
+}
+
+// validateContract runs the pre-checks of the inner contracts called by the
+// transaction against pending state.
+func validateContract(ctx *ValidationContext, tx *transaction.Transaction) error {
+	sysparam := ctx.SystemParams()
+	if sysparam == nil || ctx.vm == nil {
+		return nil
+	}
+	from, err := ctx.Sender(tx)
+	if err != nil {
+		return ErrInvalidSender
+	}
+	for _, action := range tx.Data.Actions {
+		if err := ctx.vm.PreCheck(from, action, sysparam); err != nil {
+			return fmt.Errorf("%v: %v", ErrContractRejected, err)
+		}
+	}
+	return nil
+}
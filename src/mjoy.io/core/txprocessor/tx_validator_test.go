@@ -0,0 +1,235 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: tx_validator_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package txprocessor
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"mjoy.io/common/types"
+	"mjoy.io/core"
+	"mjoy.io/core/interpreter/balancetransfer"
+	"mjoy.io/core/interpreter/multisig"
+	"mjoy.io/core/state"
+	"mjoy.io/core/transaction"
+	"mjoy.io/utils/database"
+	"mjoy.io/utils/crypto"
+	"mjoy.io/utils/metrics"
+)
+
+// setupValidatorPool creates a pool running the given validators, with the
+// sender of key holding balance.
+func setupValidatorPool(validators []string, balance int) (*TxPool, *ecdsa.PrivateKey) {
+	pool, key := setupTxPool()
+	pool.config.Validators = validators
+	setBalance(pool.chain.GetDb(), pool.currentState, crypto.PubkeyToAddress(key.PublicKey), balance)
+	pool.lockedReset(nil, nil)
+	return pool, key
+}
+
+// setKeySet registers the key set of an account in the multisig contract
+// storage, the way a committed block does.
+func setKeySet(db database.IDatabase, statedb *state.StateDB, ms *transaction.MultiSig) {
+	keySet := multisig.KeySetValue{Threshold: ms.Threshold}
+	for _, owner := range ms.Owners {
+		keySet.Owners = append(keySet.Owners, owner.Hex())
+	}
+	val, _ := json.Marshal(keySet)
+	contract := multisig.MultiSigAddress
+	key := crypto.Keccak256([]byte("keyset"), ms.Account[:])[12:]
+	valHash := crypto.Keccak256Hash(val)
+	statedb.SetState(contract, crypto.Keccak256Hash(append(contract.Bytes(), key...)), valHash)
+	db.Put(valHash[:], val)
+}
+
+// countRejections makes the metric of a validator count, metrics being
+// disabled in tests.
+func countRejections(name string) metrics.Counter {
+	counter := &metrics.StandardCounter{}
+	getTxValidator(name).rejected = counter
+	return counter
+}
+
+// checkRejected checks err is a rejection by the validator, with its error code
+// and reason.
+func checkRejected(t *testing.T, err error, validator string, code int, reason error) {
+	reject, ok := err.(*TxRejectError)
+	if !ok {
+		t.Fatalf("error type mismatch: have %T (%v), want *TxRejectError", err, err)
+	}
+	if reject.Validator != validator {
+		t.Errorf("validator mismatch: have %s, want %s", reject.Validator, validator)
+	}
+	if RejectCode(err) != code || reject.ErrorCode() != code {
+		t.Errorf("error code mismatch: have %d, want %d", RejectCode(err), code)
+	}
+	if reason != nil && reject.Err != reason {
+		t.Errorf("reason mismatch: have %v, want %v", reject.Err, reason)
+	}
+}
+
+func TestValidatorSize(t *testing.T) {
+	pool, key := setupValidatorPool([]string{ValidatorSignature, ValidatorSize}, 0)
+	defer pool.Stop()
+	rejected := countRejections(ValidatorSize)
+
+	tx := feeTransaction(0, 0, key)
+	pool.config.MaxTxSize = uint64(tx.Size()) - 1
+	checkRejected(t, pool.AddRemote(tx), ValidatorSize, RejectOversized, ErrOversizedData)
+	if rejected.Count() != 1 {
+		t.Errorf("rejection count mismatch: have %d, want %d", rejected.Count(), 1)
+	}
+	pool.config.MaxTxSize = uint64(tx.Size())
+	if err := pool.AddRemote(tx); err != nil {
+		t.Fatalf("failed to add transaction of the maximum size: %v", err)
+	}
+	if rejected.Count() != 1 {
+		t.Errorf("rejection count mismatch: have %d, want %d", rejected.Count(), 1)
+	}
+}
+
+func TestValidatorSignature(t *testing.T) {
+	pool, _ := setupValidatorPool([]string{ValidatorSignature}, 0)
+	defer pool.Stop()
+	rejected := countRejections(ValidatorSignature)
+
+	checkRejected(t, pool.AddRemote(transaction.NewTransaction(0, nil)), ValidatorSignature, RejectInvalidSender, ErrInvalidSender)
+	if rejected.Count() != 1 {
+		t.Errorf("rejection count mismatch: have %d, want %d", rejected.Count(), 1)
+	}
+}
+
+// Tests that multisig transactions are only accepted with the key set
+// registered for their account, which then refuses its own key.
+func TestValidatorMultiSig(t *testing.T) {
+	pool, key := setupValidatorPool([]string{ValidatorSignature}, 0)
+	defer pool.Stop()
+
+	owner1, _ := crypto.GenerateKey()
+	owner2, _ := crypto.GenerateKey()
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	ms, _ := transaction.NewMultiSig(account, 1, []types.Address{crypto.PubkeyToAddress(owner1.PublicKey), crypto.PubkeyToAddress(owner2.PublicKey)})
+	multiSigTx := func(nonce uint64) *transaction.Transaction {
+		tx, _ := transaction.SignTx(transaction.NewTransaction(nonce, nil).WithMultiSig(ms), mSigner, owner1)
+		return tx
+	}
+	checkRejected(t, pool.AddRemote(multiSigTx(0)), ValidatorSignature, RejectInvalidSender, ErrInvalidSender)
+
+	setKeySet(pool.chain.GetDb(), pool.currentState, ms)
+	pool.lockedReset(nil, nil)
+	if err := pool.AddRemote(multiSigTx(0)); err != nil {
+		t.Fatalf("failed to add transaction of the registered key set: %v", err)
+	}
+	checkRejected(t, pool.AddRemote(feeTransaction(1, 0, key)), ValidatorSignature, RejectInvalidSender, ErrInvalidSender)
+}
+
+func TestValidatorNonce(t *testing.T) {
+	pool, key := setupValidatorPool([]string{ValidatorSignature, ValidatorNonce}, 0)
+	defer pool.Stop()
+	rejected := countRejections(ValidatorNonce)
+
+	pool.currentState.SetNonce(crypto.PubkeyToAddress(key.PublicKey), 2)
+	checkRejected(t, pool.AddRemote(feeTransaction(1, 0, key)), ValidatorNonce, RejectNonceTooLow, ErrNonceTooLow)
+	if err := pool.AddRemote(feeTransaction(2, 0, key)); err != nil {
+		t.Fatalf("failed to add transaction at the account nonce: %v", err)
+	}
+	if rejected.Count() != 1 {
+		t.Errorf("rejection count mismatch: have %d, want %d", rejected.Count(), 1)
+	}
+}
+
+func TestValidatorFee(t *testing.T) {
+	pool, key := setupValidatorPool([]string{ValidatorSignature, ValidatorFee}, 100)
+	defer pool.Stop()
+	rejected := countRejections(ValidatorFee)
+
+	checkRejected(t, pool.AddRemote(feeTransaction(0, 101, key)), ValidatorFee, RejectInsufficientFee, core.ErrInsufficientFee)
+	if err := pool.AddRemote(feeTransaction(0, 100, key)); err != nil {
+		t.Fatalf("failed to add affordable transaction: %v", err)
+	}
+	if rejected.Count() != 1 {
+		t.Errorf("rejection count mismatch: have %d, want %d", rejected.Count(), 1)
+	}
+}
+
+func TestValidatorContract(t *testing.T) {
+	pool, key := setupValidatorPool([]string{ValidatorSignature, ValidatorContract}, 100)
+	defer pool.Stop()
+	rejected := countRejections(ValidatorContract)
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	contract := balancetransfer.BalanceTransferAddress
+	transfer := func(nonce uint64, params []byte) *transaction.Transaction {
+		tx, _ := transaction.SignTx(transaction.NewTransaction(nonce, transaction.ActionSlice{transaction.MakeAction(contract, params)}), mSigner, key)
+		return tx
+	}
+	// Fees are only moved by the system
+	checkRejected(t, pool.AddRemote(transfer(0, balancetransfer.MakeTransferFeeParam(from, 1))), ValidatorContract, RejectContract, nil)
+	// A transfer can't spend more than the balance
+	checkRejected(t, pool.AddRemote(transfer(0, balancetransfer.MakaBalanceTransferParam(from, contract, 101))), ValidatorContract, RejectContract, nil)
+	if err := pool.AddRemote(transfer(0, balancetransfer.MakaBalanceTransferParam(from, contract, 100))); err != nil {
+		t.Fatalf("failed to add affordable transfer: %v", err)
+	}
+	if rejected.Count() != 2 {
+		t.Errorf("rejection count mismatch: have %d, want %d", rejected.Count(), 2)
+	}
+}
+
+// Tests that validators run in the configured order and the first rejection
+// is reported.
+func TestValidatorOrder(t *testing.T) {
+	pool, key := setupValidatorPool([]string{ValidatorSignature, ValidatorFee, ValidatorSize}, 0)
+	defer pool.Stop()
+
+	tx := feeTransaction(0, 10, key)
+	pool.config.MaxTxSize = uint64(tx.Size()) - 1
+	checkRejected(t, pool.AddRemote(tx), ValidatorFee, RejectInsufficientFee, core.ErrInsufficientFee)
+
+	pool.config.Validators = []string{ValidatorSignature, ValidatorSize, ValidatorFee}
+	checkRejected(t, pool.AddRemote(tx), ValidatorSize, RejectOversized, ErrOversizedData)
+}
+
+// Tests that registered validators are run with their error code, and that a
+// name can't be registered twice.
+func TestRegisterTxValidator(t *testing.T) {
+	errCustom := errors.New("custom rejection")
+	RegisterTxValidator("test-custom", -32099, func(ctx *ValidationContext, tx *transaction.Transaction) error {
+		if tx.Fee() == 0 {
+			return errCustom
+		}
+		return nil
+	})
+	pool, key := setupValidatorPool([]string{"test-custom"}, 0)
+	defer pool.Stop()
+
+	checkRejected(t, pool.AddRemote(feeTransaction(0, 0, key)), "test-custom", -32099, errCustom)
+	if err := pool.AddRemote(feeTransaction(0, 1, key)); err != nil {
+		t.Fatalf("failed to add transaction passing the validator: %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registering a taken validator name didn't panic")
+		}
+	}()
+	RegisterTxValidator(ValidatorFee, -32099, validateFee)
+}
@@ -0,0 +1,223 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: chaincmd.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+	"mjoy.io/common"
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain"
+	"mjoy.io/core/genesis"
+	"mjoy.io/mjoyd/config"
+	"mjoy.io/mjoyd/utils"
+	"mjoy.io/node/services/mjoy"
+	"mjoy.io/params"
+	"mjoy.io/utils/database"
+)
+
+var (
+	exportCommand = cli.Command{
+		Action:    exportChain,
+		Name:      "export",
+		Usage:     "Export the blockchain into a file",
+		ArgsUsage: "<filename> [<from> [<to>]]",
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `Export writes the canonical blocks from number <from> (the genesis by default)
+to number <to> (the head by default) into the file, gzipped if its name ends
+with .gz. The file starts with the chain id and the genesis hash of the chain,
+and carries a checksum of every block. The node must not be running.`,
+	}
+
+	importCommand = cli.Command{
+		Action:    importChain,
+		Name:      "import",
+		Usage:     "Import blockchain files",
+		ArgsUsage: "<filename> [<filename> ...]",
+		Flags: []cli.Flag{
+			utils.SkipKnownFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `Import validates and inserts the blocks of the files written by export, in
+order. The files must belong to the chain of the database. An interrupted
+import keeps the blocks inserted so far, run it again to resume, with
+--skipknown to pass over them quickly. The node must not be running.`,
+	}
+)
+
+// setupChainGenesis loads the mjoy config of the node configured by the config
+// file, writing its genesis into db if empty. The chain already in db is kept
+// whatever the configured genesis.
+func setupChainGenesis(db database.IDatabase) (*params.ChainConfig, *mjoy.Config, error) {
+	c := config.GetConfigInstance()
+	conf := &mjoy.Config{}
+	c.Register("mjoy", conf)
+	defer c.Unregister("mjoy")
+
+	gen := conf.Genesis
+	if blockchain.GetCanonicalHash(db, 0) != (types.Hash{}) {
+		gen = nil
+	}
+	chainConfig, _, err := genesis.SetupGenesisBlock(db, gen)
+	if _, ok := err.(*params.ConfigCompatError); err != nil && !ok {
+		return nil, nil, err
+	}
+	return chainConfig, conf, nil
+}
+
+// openChain opens the blockchain of the node configured by the config file,
+// writing the configured genesis into an empty database.
+func openChain(ctx *cli.Context) (*blockchain.BlockChain, *database.FreezerDB, error) {
+	db, err := openChainDb(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	chainConfig, conf, err := setupChainGenesis(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	cacheConfig := &blockchain.CacheConfig{
+		Disabled:      conf.NodeMode == blockchain.ArchiveMode,
+		TriesKept:     conf.StateHistory,
+		PruneInterval: conf.PruneInterval,
+		FreezeDepth:   conf.FreezeDepth,
+	}
+	chain, err := blockchain.NewBlockChain(db, cacheConfig, chainConfig, mjoy.NewConsensusEngine(chainConfig, db))
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return chain, db, nil
+}
+
+// exportChain exports the blocks of the configured node into a file.
+func exportChain(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) < 1 || len(args) > 3 {
+		return fmt.Errorf("usage: export <filename> [<from> [<to>]]")
+	}
+	chain, db, err := openChain(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	defer chain.Stop()
+
+	first, last := uint64(0), chain.CurrentBlock().NumberU64()
+	if len(args) > 1 {
+		if first, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+			return fmt.Errorf("invalid first block number %q", args[1])
+		}
+	}
+	if len(args) > 2 {
+		if last, err = strconv.ParseUint(args[2], 10, 64); err != nil {
+			return fmt.Errorf("invalid last block number %q", args[2])
+		}
+		if head := chain.CurrentBlock().NumberU64(); last > head {
+			return fmt.Errorf("last block #%d above the head #%d", last, head)
+		}
+	}
+
+	out, err := os.OpenFile(args[0], os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	var writer io.Writer = out
+	if strings.HasSuffix(args[0], ".gz") {
+		gz := gzip.NewWriter(writer)
+		defer gz.Close()
+		writer = gz
+	}
+	start := time.Now()
+	if err := chain.ExportN(writer, first, last); err != nil {
+		return fmt.Errorf("export: %v", err)
+	}
+	fmt.Printf("Exported blocks #%d-#%d in %v\n", first, last, common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// importChain imports the blocks of the given files into the configured node,
+// stopping after the batch being inserted on interrupt.
+func importChain(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		return fmt.Errorf("usage: import <filename> [<filename> ...]")
+	}
+	chain, db, err := openChain(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	defer chain.Stop()
+
+	abort := make(chan struct{})
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+	go func() {
+		if _, ok := <-sigc; ok {
+			fmt.Println("Interrupted, stopping after the current batch...")
+			close(abort)
+		}
+	}()
+
+	skipKnown := ctx.Bool(utils.SkipKnownFlag.Name)
+	for _, file := range ctx.Args() {
+		start := time.Now()
+		imported, skipped, err := importFile(chain, file, skipKnown, abort)
+		if err != nil {
+			if err == blockchain.ErrImportAborted {
+				return fmt.Errorf("import of %s aborted at block #%d, run it again to resume", file, chain.CurrentBlock().NumberU64())
+			}
+			return fmt.Errorf("import %s: %v", file, err)
+		}
+		fmt.Printf("Imported %s: %d blocks inserted, %d known passed over in %v, head #%d\n",
+			file, imported, skipped, common.PrettyDuration(time.Since(start)), chain.CurrentBlock().NumberU64())
+	}
+	return nil
+}
+
+// importFile imports the blocks of a file, gunzipped if its name ends with .gz.
+func importFile(chain *blockchain.BlockChain, file string, skipKnown bool, abort <-chan struct{}) (int, int, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer in.Close()
+
+	var reader io.Reader = in
+	if strings.HasSuffix(file, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return 0, 0, err
+		}
+	}
+	return chain.Import(reader, skipKnown, abort)
+}
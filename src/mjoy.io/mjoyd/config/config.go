@@ -44,6 +44,7 @@ type Iconfig interface {
 type Config struct{
 	path string
 	configs map[string] Iconfig
+	presets map[string] Iconfig
 }
 
 var c *Config
@@ -54,6 +55,7 @@ func GetConfigInstance() *Config {
 		c =&Config{
 			path: defaults.DefaultTOMLConfigPath,
 			configs: make(map[string]Iconfig),
+			presets: make(map[string]Iconfig),
 		}
 	})
 	return c
@@ -70,6 +72,11 @@ func (c *Config) Register(name string, config Iconfig) error {
 		return errors.New("config is already registered")
 	}
 
+	if preset, ok := c.presets[name]; ok {
+		reflect.ValueOf(config).Elem().Set(reflect.ValueOf(preset).Elem())
+		c.configs[name] = config
+		return nil
+	}
 	path := filepath.Join(c.path, name + ".toml")
 
 	if err := loadConfig(path, config); err != nil{
@@ -80,6 +87,12 @@ func (c *Config) Register(name string, config Iconfig) error {
 	return nil
 }
 
+//Preset gives the module registering name a config built in code instead of the one of the config file,
+//the preset must be of the type the module registers
+func (c *Config) Preset(name string, config Iconfig) {
+	c.presets[name] = config
+}
+
 func (c *Config) Unregister(name string) error {
 	if _, ok := c.configs[name]; !ok {
 		logger.Error("module",name,"is not registered")
@@ -0,0 +1,262 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: dbcmd.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+	"mjoy.io/common"
+	"mjoy.io/core/blockchain"
+	"mjoy.io/mjoyd/utils"
+)
+
+var (
+	dbCommand = cli.Command{
+		Name:     "db",
+		Usage:    "Inspect and repair the block database",
+		Category: "DATABASE COMMANDS",
+		Description: `Keys and values given to the db commands are hex when prefixed with 0x,
+strings otherwise. The node must not be running.`,
+		Subcommands: []cli.Command{
+			{
+				Action:    inspectDb,
+				Name:      "inspect",
+				Usage:     "Report the number and size of the database entries by kind",
+				ArgsUsage: " ",
+				Description: `Inspect walks the whole database, counting its entries by key prefix. The state
+entries not reachable from the state of the head block are reported as old
+state, the ones pruning deletes.`,
+			},
+			{
+				Action:    getDbEntry,
+				Name:      "get",
+				Usage:     "Print the value of a key",
+				ArgsUsage: "<key>",
+			},
+			{
+				Action:    putDbEntry,
+				Name:      "put",
+				Usage:     "Set the value of a key",
+				ArgsUsage: "<key> <value>",
+			},
+			{
+				Action:    deleteDbEntry,
+				Name:      "delete",
+				Usage:     "Delete a key",
+				ArgsUsage: "<key>",
+			},
+			{
+				Action:    migrateDb,
+				Name:      "migrate",
+				Usage:     "Migrate the database to the latest schema version",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					utils.MigrateDryRunFlag,
+				},
+				Description: `Migrate runs the migrations from the schema version of the database to the
+latest one in order, resuming an interrupted migration where it stopped. The
+node runs them on startup too. With --dryrun, the migrations report the entries
+they would write and delete without writing.`,
+			},
+			{
+				Action:    compactDb,
+				Name:      "compact",
+				Usage:     "Compact the database",
+				ArgsUsage: " ",
+			},
+		},
+	}
+)
+
+// parseDbBytes parses a key or value given to the db commands.
+func parseDbBytes(arg string) ([]byte, error) {
+	if strings.HasPrefix(arg, "0x") || strings.HasPrefix(arg, "0X") {
+		blob, err := hex.DecodeString(arg[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex %q: %v", arg, err)
+		}
+		return blob, nil
+	}
+	return []byte(arg), nil
+}
+
+// inspectDb prints the number and size of the entries of the block database
+// of the configured node by kind.
+func inspectDb(ctx *cli.Context) error {
+	db, err := openChainDb(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start := time.Now()
+	stats, err := blockchain.InspectDatabase(db)
+	if err != nil {
+		return fmt.Errorf("inspect database: %v", err)
+	}
+	var count, size uint64
+	fmt.Printf("%-20s %12s %14s\n", "Kind", "Items", "Size")
+	for _, stat := range stats {
+		fmt.Printf("%-20s %12d %14s\n", stat.Name, stat.Count, common.StorageSize(stat.Size))
+		size += stat.Size
+		if !strings.HasPrefix(stat.Name, "Ancient ") {
+			count += stat.Count
+		}
+	}
+	fmt.Printf("%-20s %12d %14s\n", "Total", count, common.StorageSize(size))
+	fmt.Printf("Inspected database in %v\n", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// getDbEntry prints the value of a key of the block database in hex.
+func getDbEntry(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return fmt.Errorf("usage: db get <key>")
+	}
+	key, err := parseDbBytes(ctx.Args()[0])
+	if err != nil {
+		return err
+	}
+	db, err := openChainDb(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	value, err := db.Get(key)
+	if err != nil {
+		return fmt.Errorf("get key %x: %v", key, err)
+	}
+	fmt.Printf("0x%x\n", value)
+	return nil
+}
+
+// putDbEntry sets the value of a key of the block database.
+func putDbEntry(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 {
+		return fmt.Errorf("usage: db put <key> <value>")
+	}
+	key, err := parseDbBytes(ctx.Args()[0])
+	if err != nil {
+		return err
+	}
+	value, err := parseDbBytes(ctx.Args()[1])
+	if err != nil {
+		return err
+	}
+	db, err := openChainDb(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if prev, err := db.Get(key); err == nil {
+		fmt.Printf("Previous value: 0x%x\n", prev)
+	}
+	if err := db.Put(key, value); err != nil {
+		return fmt.Errorf("put key %x: %v", key, err)
+	}
+	fmt.Printf("Set key 0x%x\n", key)
+	return nil
+}
+
+// deleteDbEntry deletes a key of the block database.
+func deleteDbEntry(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return fmt.Errorf("usage: db delete <key>")
+	}
+	key, err := parseDbBytes(ctx.Args()[0])
+	if err != nil {
+		return err
+	}
+	db, err := openChainDb(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	prev, err := db.Get(key)
+	if err != nil {
+		return fmt.Errorf("get key %x: %v", key, err)
+	}
+	if err := db.Delete(key); err != nil {
+		return fmt.Errorf("delete key %x: %v", key, err)
+	}
+	fmt.Printf("Deleted key 0x%x, its value was 0x%x\n", key, prev)
+	return nil
+}
+
+// migrateDb migrates the block database of the configured node to the latest
+// schema version.
+func migrateDb(ctx *cli.Context) error {
+	db, err := openChainDb(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	fmt.Printf("Database schema version %d, latest %d\n", blockchain.GetBlockChainVersion(db), blockchain.SchemaVersion())
+	versions, names, err := blockchain.PendingMigrations(db)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		fmt.Println("No migration needed")
+		return nil
+	}
+	for i, version := range versions {
+		fmt.Printf("Pending migration %d: %s\n", version, names[i])
+	}
+	dryRun := ctx.Bool(utils.MigrateDryRunFlag.Name)
+	results, err := blockchain.MigrateDatabase(db, dryRun)
+	for _, result := range results {
+		status := "done"
+		if dryRun {
+			status = "dry run"
+		}
+		if result.Resumed {
+			status += ", resumed"
+		}
+		fmt.Printf("Migration %d (%s): walked %d entries, migrated %d, %d writes, %d deletes in %v\n", result.Version, status,
+			result.Walked, result.Migrated, result.Writes, result.Deletes, common.PrettyDuration(result.Elapsed))
+	}
+	return err
+}
+
+// compactDb compacts the block database of the configured node.
+func compactDb(ctx *cli.Context) error {
+	db, err := openChainDb(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start := time.Now()
+	if err := db.Compact(); err != nil {
+		return fmt.Errorf("compact database: %v", err)
+	}
+	fmt.Printf("Compacted database in %v\n", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -46,6 +46,11 @@ var (
 	DefaultBlockproducerStart	= false
 	//Net
 	DefaultWorkingNet			="alpha"
+	//Remote signer
+	DefaultSignerDir			= getAppCurrentDir() + "/" + "signer"
+	DefaultSignerEndpoint		= getAppCurrentDir() + "/" + "signer.ipc"
+	//State pruning
+	DefaultPruneKeep			uint64 = 128
 )
 
 func getAppName() string {
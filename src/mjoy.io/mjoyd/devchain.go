@@ -0,0 +1,122 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: devchain.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/urfave/cli.v1"
+	"mjoy.io/accounts"
+	"mjoy.io/accounts/keystore"
+	"mjoy.io/blockproducer"
+	"mjoy.io/core/genesis"
+	"mjoy.io/mjoyd/config"
+	"mjoy.io/mjoyd/utils"
+	"mjoy.io/node"
+	"mjoy.io/node/services/mjoy"
+	"mjoy.io/params"
+)
+
+// devPassword is the password of the developer account, which is unlocked
+// anyway.
+const devPassword = ""
+
+// devChain is a throwaway local chain, sealing with the basic engine and not
+// connecting to any peer.
+type devChain struct {
+	datadir   string           // Data directory of the chain
+	ephemeral bool             // Whether the data directory is removed on exit
+	account   accounts.Account // Pre-funded account, producing the blocks
+}
+
+// setupDevChain prepares the data directory, the developer account and the
+// genesis of a developer chain, presetting the node and mjoy configs for it.
+func setupDevChain(ctx *cli.Context) (*devChain, error) {
+	dev := &devChain{datadir: ctx.GlobalString(utils.DevDataDirFlag.Name)}
+	if dev.datadir == "" {
+		dir, err := ioutil.TempDir("", "mjoyd-dev")
+		if err != nil {
+			return nil, err
+		}
+		dev.datadir, dev.ephemeral = dir, true
+	}
+	// Reuse the account of a persistent chain, for its genesis to stay the same
+	keydir := filepath.Join(dev.datadir, "keystore")
+	ks := keystore.NewKeyStore(keydir, keystore.LightScryptN, keystore.LightScryptP)
+	if accs := ks.Accounts(); len(accs) > 0 {
+		dev.account = accs[0]
+	} else {
+		account, err := ks.NewAccount(devPassword)
+		if err != nil {
+			dev.close()
+			return nil, fmt.Errorf("create developer account: %v", err)
+		}
+		dev.account = account
+	}
+
+	nodeConf := &node.Config{}
+	nodeConf.SetDefaultConfig()
+	nodeConf.DataDir = dev.datadir
+	nodeConf.KeyStoreDir = keydir
+	nodeConf.UseLightweightKDF = true
+	nodeConf.P2P.MaxPeers = 0
+	nodeConf.P2P.NoDiscovery = true
+	nodeConf.P2P.DiscoveryV5 = false
+	nodeConf.P2P.ListenAddr = ""
+
+	period := uint64(ctx.GlobalInt(utils.DevPeriodFlag.Name))
+	mjoyConf := &mjoy.Config{}
+	mjoyConf.SetDefaultConfig()
+	mjoyConf.Genesis = genesis.DevGenesisBlock(dev.account.Address)
+	mjoyConf.NetworkId = params.DevChainConfig.ChainId.Uint64()
+	mjoyConf.Coinbase = dev.account.Address
+	mjoyConf.Producer = blockproducer.Config{Period: period, WaitForTxs: period == 0, Instant: true}
+
+	c := config.GetConfigInstance()
+	c.Preset("node", nodeConf)
+	c.Preset("mjoy", mjoyConf)
+
+	logger.Infof("Developer chain in %s, account:%s", dev.datadir, dev.account.Address.Hex())
+	return dev, nil
+}
+
+// start unlocks the developer account and starts producing.
+func (dev *devChain) start(stack *node.Node) error {
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	if err := ks.Unlock(dev.account, devPassword); err != nil {
+		return fmt.Errorf("unlock developer account: %v", err)
+	}
+	var service *mjoy.Mjoy
+	if err := stack.Service(&service); err != nil {
+		return err
+	}
+	return service.StartProducing(true, devPassword)
+}
+
+// close removes the data directory of an ephemeral chain.
+func (dev *devChain) close() {
+	if dev.ephemeral {
+		os.RemoveAll(dev.datadir)
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"path/filepath"
 	"mjoy.io/mjoyd/config"
 	"mjoy.io/node"
+	"mjoy.io/utils/database"
 )
 
 var (
@@ -51,6 +52,9 @@ var (
 		utils.MetricsEnabledFlag,
 		utils.WorkingNetFlag,
 		utils.ResyncBlockFlag,
+		utils.DevFlag,
+		utils.DevPeriodFlag,
+		utils.DevDataDirFlag,
 	}
 
 	logTag = "mjoyd.main"
@@ -73,6 +77,13 @@ func init() {
 	// add commands
 	app.Commands = []cli.Command{
 		versionCommand,
+		signerCommand,
+		pruneCommand,
+		exportCommand,
+		importCommand,
+		snapshotCommand,
+		verifyCommand,
+		dbCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -102,19 +113,35 @@ func init() {
 	}
 }
 
+// chainDataPath returns the path of the block database of the node configured
+// by the config file.
+func chainDataPath(ctx *cli.Context) string {
+	c := config.GetConfigInstance()
+	c.SetPath(ctx.GlobalString(utils.ConfigFileFlag.Name))
+	conf := &node.Config{}
+	c.Register("node", conf)
+	defer c.Unregister("node")
+
+	return filepath.Join(conf.DataDir, conf.NameValue(), "chaindata")
+}
+
+// openChainDb opens the block database of the node configured by the config
+// file, together with its ancient store.
+func openChainDb(ctx *cli.Context) (*database.FreezerDB, error) {
+	path := chainDataPath(ctx)
+	db, err := database.OpenFreezerDB(path, filepath.Join(path, "ancient"), 128, 256)
+	if err != nil {
+		return nil, fmt.Errorf("open block database %s: %v", path, err)
+	}
+	return db, nil
+}
+
 // remove block database based boot parameter --resync-block
 func resyncBlockProc (ctx *cli.Context) error {
 	resyncBlock := ctx.GlobalBool(utils.ResyncBlockFlag.Name)
 	if resyncBlock {
-		c := config.GetConfigInstance()
-		c.SetPath(ctx.GlobalString(utils.ConfigFileFlag.Name))
-		conf := &node.Config{}
-		c.Register("node", conf)
-		defer c.Unregister("node")
 		//need remove block data
-		dataDir := conf.DataDir
-		appName := conf.NameValue()
-		path := filepath.Join(dataDir,appName,"chaindata")
+		path := chainDataPath(ctx)
 		logger.Info("Now Remove the block database ", path)
 		err := os.RemoveAll(path)
 		if err != nil {
@@ -143,13 +170,22 @@ func mjoyd(ctx *cli.Context) error {
 		return err
 	}
 
+	var dev *devChain
+	if ctx.GlobalBool(utils.DevFlag.Name) {
+		if dev, err = setupDevChain(ctx); err != nil {
+			logger.Critical("Set up developer chain failed:", err)
+			return err
+		}
+		defer dev.close()
+	}
+
 	node := createMjoyNode(ctx)
 	if node == nil {
 		logger.Critical("Create node failed.")
 		os.Exit(1)
 	}
 
-	startMjoyNode(node)
+	startMjoyNode(node, dev)
 	logger.Infof("%s is shutdown.", defaults.AppName)
 	return nil
 }
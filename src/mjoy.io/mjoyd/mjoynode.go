@@ -139,7 +139,7 @@ func createMjoyNode(ctx *cli.Context)(*node.Node){
 	return stack
 }
 
-func startMjoyNode(node *node.Node){
+func startMjoyNode(node *node.Node, dev *devChain){
 
 	if node == nil {
 		logger.Critical("input node = nil")
@@ -149,6 +149,12 @@ func startMjoyNode(node *node.Node){
 	if err := node.Start();err != nil {
 		logger.Critical("Error starting protocol stack:",err)
 	}
+	//the developer chain produces from the start
+	if dev != nil {
+		if err := dev.start(node);err != nil {
+			logger.Critical("Error starting developer chain:",err)
+		}
+	}
 
 	go func(){
 		sigc := make(chan os.Signal,1)
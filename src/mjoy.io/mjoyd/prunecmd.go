@@ -0,0 +1,70 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: prunecmd.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+	"mjoy.io/common"
+	"mjoy.io/core/blockchain"
+	"mjoy.io/mjoyd/utils"
+)
+
+var (
+	pruneCommand = cli.Command{
+		Action:    prune,
+		Name:      "prune",
+		Usage:     "Delete the old states from the block database",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.PruneKeepFlag,
+		},
+		Category: "DATABASE COMMANDS",
+		Description: `Prune deletes the state trie nodes, contract code and contract values not
+reachable from the genesis state or the states of the last blocks, then compacts
+the database. The node must not be running.`,
+	}
+)
+
+// prune prunes and compacts the block database of the configured node.
+func prune(ctx *cli.Context) error {
+	db, err := openChainDb(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start := time.Now()
+	kept, deleted, err := blockchain.PruneState(db, ctx.Uint64(utils.PruneKeepFlag.Name))
+	if err != nil {
+		return fmt.Errorf("prune state: %v", err)
+	}
+	fmt.Printf("Pruned state: kept %d entries, deleted %d in %v\n", kept, deleted, common.PrettyDuration(time.Since(start)))
+
+	start = time.Now()
+	if err := db.Compact(); err != nil {
+		return fmt.Errorf("compact database: %v", err)
+	}
+	fmt.Printf("Compacted database in %v\n", common.PrettyDuration(time.Since(start)))
+	return nil
+}
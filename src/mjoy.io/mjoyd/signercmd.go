@@ -0,0 +1,108 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: signercmd.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+
+	"gopkg.in/urfave/cli.v1"
+	"mjoy.io/accounts/keystore"
+	"mjoy.io/consensus/remotesigner"
+	"mjoy.io/mjoyd/utils"
+)
+
+var (
+	signerCommand = cli.Command{
+		Action:    runSigner,
+		Name:      "signer",
+		Usage:     "Run a remote signer holding a block producer key",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.SignerKeyFileFlag,
+			utils.SignerPasswordFileFlag,
+			utils.SignerDataDirFlag,
+			utils.SignerEndpointFlag,
+		},
+		Category: "PRODUCER COMMANDS",
+		Description: `The signer holds the key of a block producer and signs its headers and
+finality votes over IPC or HTTP, for a node configured with the RemoteSigner
+endpoint. It never signs two headers, nor two votes of a type, at one height,
+and records every request in the audit log of its directory.`,
+	}
+)
+
+// runSigner serves the producer key of the keystore file until interrupted.
+func runSigner(ctx *cli.Context) error {
+	keyFile := ctx.String(utils.SignerKeyFileFlag.Name)
+	if keyFile == "" {
+		return fmt.Errorf("--%s is required", utils.SignerKeyFileFlag.Name)
+	}
+	keyJson, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("read keystore file: %v", err)
+	}
+	password := ""
+	if passwordFile := ctx.String(utils.SignerPasswordFileFlag.Name); passwordFile != "" {
+		data, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			return fmt.Errorf("read password file: %v", err)
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	}
+	key, err := keystore.DecryptKey(keyJson, password)
+	if err != nil {
+		return fmt.Errorf("decrypt key: %v", err)
+	}
+	if key.PrivateKey == nil {
+		return fmt.Errorf("the signer only holds secp256k1 keys")
+	}
+
+	protection, err := remotesigner.NewProtection(ctx.String(utils.SignerDataDirFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer protection.Close()
+
+	srv, err := remotesigner.NewServer(remotesigner.NewSigner(key.PrivateKey, protection))
+	if err != nil {
+		return err
+	}
+	endpoint := ctx.String(utils.SignerEndpointFlag.Name)
+	listener, err := remotesigner.Listen(endpoint)
+	if err != nil {
+		return err
+	}
+	go func() {
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, os.Interrupt)
+		<-sigc
+		listener.Close()
+	}()
+	fmt.Printf("Signing for 0x%x on %s\n", key.Address, endpoint)
+
+	remotesigner.Serve(endpoint, listener, srv)
+	srv.Stop()
+	return nil
+}
@@ -0,0 +1,164 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: snapshotcmd.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+	"mjoy.io/common"
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain"
+	"mjoy.io/mjoyd/utils"
+)
+
+var (
+	snapshotCommand = cli.Command{
+		Name:     "snapshot",
+		Usage:    "Export and import state snapshots",
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `A state snapshot holds a block and its full state, the account trie, the
+contract storage tries, code and values, to bootstrap a node from instead of
+replaying the blocks before it.`,
+		Subcommands: []cli.Command{
+			{
+				Action:    exportSnapshot,
+				Name:      "export",
+				Usage:     "Export the state of a block into a snapshot file",
+				ArgsUsage: "<filename> [<number>]",
+				Description: `Export writes the canonical block number <number> and its state into the
+file, gzipped if its name ends with .gz. The block defaults to the head, or to
+the last epoch block below it with the poa and dpos engines, which only allow
+snapshots of epoch blocks. The state of the block must not be pruned. The node
+must not be running.`,
+			},
+			{
+				Action:    importSnapshot,
+				Name:      "import",
+				Usage:     "Bootstrap the database from a snapshot file",
+				ArgsUsage: "--hash <hash> <filename>",
+				Flags: []cli.Flag{
+					utils.SnapshotHashFlag,
+				},
+				Description: `Import writes the block and the state of the snapshot into a database holding
+the genesis block alone, checking every state entry and the state root of the
+block, and makes the block the head. The block must have the hash given, taken
+from a trusted source, as nothing else vouches for it. The node then syncs on
+from its height, the blocks below it stay missing. The node must not be running.`,
+			},
+		},
+	}
+)
+
+// exportSnapshot exports the state of a block of the configured node into a
+// snapshot file.
+func exportSnapshot(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: snapshot export <filename> [<number>]")
+	}
+	chain, db, err := openChain(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	defer chain.Stop()
+
+	number := chain.CurrentBlock().NumberU64()
+	if epoch := chain.Config().Epoch(); epoch != 0 {
+		number -= number % epoch
+	}
+	if len(args) > 1 {
+		if number, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+			return fmt.Errorf("invalid block number %q", args[1])
+		}
+	}
+
+	out, err := os.OpenFile(args[0], os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	var writer io.Writer = out
+	if strings.HasSuffix(args[0], ".gz") {
+		gz := gzip.NewWriter(writer)
+		defer gz.Close()
+		writer = gz
+	}
+	start := time.Now()
+	entries, err := chain.ExportSnapshot(writer, number)
+	if err != nil {
+		return fmt.Errorf("export snapshot: %v", err)
+	}
+	fmt.Printf("Exported state of block #%d [%x] with %d entries in %v\n",
+		number, chain.GetBlockByNumber(number).Hash().Bytes(), entries, common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// importSnapshot bootstraps the database of the configured node from a snapshot
+// file, gunzipped if its name ends with .gz.
+func importSnapshot(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 || !ctx.IsSet(utils.SnapshotHashFlag.Name) {
+		return fmt.Errorf("usage: snapshot import --hash <hash> <filename>")
+	}
+	hash, err := hex.DecodeString(strings.TrimPrefix(ctx.String(utils.SnapshotHashFlag.Name), "0x"))
+	if err != nil || len(hash) != types.HashLength {
+		return fmt.Errorf("invalid snapshot block hash %q", ctx.String(utils.SnapshotHashFlag.Name))
+	}
+	file := ctx.Args().First()
+	in, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var reader io.Reader = in
+	if strings.HasSuffix(file, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+
+	db, err := openChainDb(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, _, err := setupChainGenesis(db); err != nil {
+		return err
+	}
+	start := time.Now()
+	head, entries, err := blockchain.ImportSnapshot(db, reader, types.BytesToHash(hash))
+	if err != nil {
+		return fmt.Errorf("import snapshot %s: %v", file, err)
+	}
+	fmt.Printf("Imported state of block #%d [%x] with %d entries in %v\n",
+		head.NumberU64(), head.Hash().Bytes(), entries, common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -177,4 +177,83 @@ var (
 		Name: 	"resync-block",
 		Usage:	"Clear chain database and rebuild blockchain.",
 	}
+
+	// Developer chain settings
+	DevFlag = cli.BoolFlag{
+		Name:	"dev",
+		Usage:	"Run a throwaway developer chain sealing a block on every transaction, with a pre-funded unlocked account",
+	}
+
+	DevPeriodFlag = cli.IntFlag{
+		Name:	"dev.period",
+		Usage:	"Seconds between the blocks of the developer chain, empty ones included (0 = seal on every transaction)",
+	}
+
+	DevDataDirFlag = cli.StringFlag{
+		Name:	"dev.datadir",
+		Usage:	"Data directory keeping the developer chain across runs (empty = a temporary one removed on exit)",
+	}
+
+	// State pruning settings
+	PruneKeepFlag = cli.Uint64Flag{
+		Name:	"keep",
+		Usage:	"Number of recent states kept by the pruning, the genesis one kept as well",
+		Value:	defaults.DefaultPruneKeep,
+	}
+
+	// Chain import settings
+	SkipKnownFlag = cli.BoolFlag{
+		Name:	"skipknown",
+		Usage:	"Pass over the blocks already in the chain instead of validating them again",
+	}
+
+	// State snapshot settings
+	SnapshotHashFlag = cli.StringFlag{
+		Name:	"hash",
+		Usage:	"Hash of the snapshot block, as a trusted checkpoint the snapshot must match",
+	}
+
+	// Chain verification settings
+	VerifyFromFlag = cli.Uint64Flag{
+		Name:	"from",
+		Usage:	"Number of the first block to re-execute",
+		Value:	1,
+	}
+
+	VerifyToFlag = cli.Uint64Flag{
+		Name:	"to",
+		Usage:	"Number of the last block to re-execute (0 = the head)",
+	}
+
+	ReindexFlag = cli.BoolFlag{
+		Name:	"reindex",
+		Usage:	"Rewrite the receipts and transaction lookup entries of the blocks verified",
+	}
+	MigrateDryRunFlag = cli.BoolFlag{
+		Name:	"dryrun",
+		Usage:	"Report the changes of the migrations without writing them",
+	}
+
+	// Remote signer settings
+	SignerKeyFileFlag = cli.StringFlag{
+		Name:	"keyfile",
+		Usage:	"The keystore file of the producer key the signer holds",
+	}
+
+	SignerPasswordFileFlag = cli.StringFlag{
+		Name:	"passwordfile",
+		Usage:	"The file holding the password of the keystore file",
+	}
+
+	SignerDataDirFlag = cli.StringFlag{
+		Name:	"signerdir",
+		Usage:	"The directory of the slashing protection state and the audit log",
+		Value:	defaults.DefaultSignerDir,
+	}
+
+	SignerEndpointFlag = cli.StringFlag{
+		Name:	"endpoint",
+		Usage:	"The endpoint the signer listens on, an http:// URL on a loopback address or the path of an IPC socket",
+		Value:	defaults.DefaultSignerEndpoint,
+	}
 )
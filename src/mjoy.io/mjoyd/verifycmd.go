@@ -0,0 +1,160 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: verifycmd.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"unicode"
+
+	"gopkg.in/urfave/cli.v1"
+	"mjoy.io/common"
+	"mjoy.io/common/types"
+	"mjoy.io/core/blockchain"
+	"mjoy.io/mjoyd/utils"
+)
+
+var (
+	verifyCommand = cli.Command{
+		Action:    verifyChain,
+		Name:      "verify",
+		Usage:     "Re-execute blocks and check them against their headers",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.VerifyFromFlag,
+			utils.VerifyToFlag,
+			utils.ReindexFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `Verify executes the canonical blocks again from the state of their parent,
+which must not be pruned, and compares the state root, the receipt root and the
+bloom with the ones of their header. It stops at the first block departing from
+its header, printing the storage writes of each of its transactions against the
+recorded state. The node must not be running.`,
+	}
+)
+
+// verifyChain re-executes the blocks of the configured node in the given range.
+func verifyChain(ctx *cli.Context) error {
+	chain, db, err := openChain(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	defer chain.Stop()
+
+	from, to := ctx.Uint64(utils.VerifyFromFlag.Name), ctx.Uint64(utils.VerifyToFlag.Name)
+	head := chain.CurrentBlock().NumberU64()
+	if to == 0 {
+		to = head
+	}
+	if from == 0 || from > to || to > head {
+		return fmt.Errorf("invalid block range #%d-#%d, head #%d", from, to, head)
+	}
+	reindex := ctx.Bool(utils.ReindexFlag.Name)
+
+	start, reported := time.Now(), time.Now()
+	for number := from; number <= to; number++ {
+		r, err := chain.ReexecuteBlock(number)
+		if err != nil {
+			return err
+		}
+		if mismatches := r.Mismatches(); len(mismatches) > 0 {
+			printDivergence(chain, r, mismatches)
+			return fmt.Errorf("block #%d departs from its header", number)
+		}
+		if reindex {
+			if err := chain.WriteReexecution(r); err != nil {
+				return fmt.Errorf("reindex block #%d: %v", number, err)
+			}
+		}
+		if time.Since(reported) >= 8*time.Second {
+			fmt.Printf("Verified blocks up to #%d, elapsed %v\n", number, common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	fmt.Printf("Verified blocks #%d-#%d in %v\n", from, to, common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// printDivergence prints the fields the re-executed block departs from and the
+// effects of each of its transactions.
+func printDivergence(chain *blockchain.BlockChain, r *blockchain.Reexecution, mismatches []string) {
+	blk := r.Block
+	fmt.Printf("Block #%d [%x] departs from its header:\n", blk.NumberU64(), blk.Hash().Bytes())
+	for _, mismatch := range mismatches {
+		fmt.Printf("  %s\n", mismatch)
+	}
+	diffs, recorded := chain.DiffWrites(r)
+	if !recorded {
+		fmt.Printf("The state of the header is missing, the writes are not compared\n")
+	}
+	stored := chain.GetReceiptsByHash(blk.Hash())
+	txs := blk.Transactions()
+	for i, writes := range diffs {
+		if i < len(txs) {
+			fmt.Printf("Transaction %d [%x]:\n", i, txs[i].Hash().Bytes())
+			receipt := r.Receipts[i]
+			if i >= len(stored) {
+				fmt.Printf("  receipt: status %d, %d logs, none stored\n", receipt.Status, len(receipt.Logs))
+			} else if receipt.Status != stored[i].Status || len(receipt.Logs) != len(stored[i].Logs) || receipt.Bloom != stored[i].Bloom {
+				fmt.Printf("  receipt: status %d, %d logs, stored status %d, %d logs\n", receipt.Status, len(receipt.Logs), stored[i].Status, len(stored[i].Logs))
+			}
+		} else if len(writes) > 0 {
+			fmt.Printf("Block finalisation:\n")
+		}
+		for _, diff := range writes {
+			key := fmt.Sprintf("slot %x", diff.Slot.Bytes())
+			if diff.Key != nil {
+				key = fmt.Sprintf("key %x", diff.Key)
+			}
+			if !diff.Final {
+				fmt.Printf("  %x %s: %s -> %s, overwritten later\n", diff.Account.Bytes(), key,
+					formatSlotValue(diff.Prev), formatSlotValue(diff.Value))
+				continue
+			}
+			mark := ""
+			if recorded && !diff.Match {
+				mark = "  <- differs"
+			}
+			fmt.Printf("  %x %s: %s -> %s, recorded %s%s\n", diff.Account.Bytes(), key,
+				formatSlotValue(diff.Prev), formatSlotValue(diff.Value), formatSlotValue(diff.Recorded), mark)
+		}
+	}
+}
+
+// formatSlotValue formats a contract value as a string if printable, in hex
+// otherwise, or by its hash if unknown.
+func formatSlotValue(v blockchain.SlotValue) string {
+	switch {
+	case v.Hash == (types.Hash{}):
+		return "none"
+	case v.Value == nil:
+		return fmt.Sprintf("unknown [%x]", v.Hash.Bytes()[:8])
+	}
+	for _, r := range string(v.Value) {
+		if !unicode.IsPrint(r) {
+			return fmt.Sprintf("0x%x", v.Value)
+		}
+	}
+	return strconv.Quote(string(v.Value))
+}
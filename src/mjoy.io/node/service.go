@@ -21,6 +21,7 @@
 package node
 
 import (
+	"path/filepath"
 	"reflect"
 
 	"mjoy.io/accounts"
@@ -59,6 +60,29 @@ func (ctx *ServiceContext) OpenDatabase(name string, cache int, handles int) (da
 	return db, nil
 }
 
+// OpenDatabaseWithFreezer opens an existing database with the given name (or
+// creates one if no previous can be found) from within the node's data directory,
+// together with its ancient store in the freezer directory, by default the
+// "ancient" directory of the database. If the node is an ephemeral one, a
+// memory database without ancient store is returned.
+func (ctx *ServiceContext) OpenDatabaseWithFreezer(name string, cache int, handles int, freezer string) (database.IDatabase, error) {
+	if ctx.config.DataDir == "" {
+		return database.OpenMemDB()
+	}
+	root := ctx.config.resolvePath(name)
+	switch {
+	case freezer == "":
+		freezer = filepath.Join(root, "ancient")
+	case !filepath.IsAbs(freezer):
+		freezer = ctx.config.resolvePath(freezer)
+	}
+	db, err := database.OpenFreezerDB(root, freezer, cache, handles)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
 // ResolvePath resolves a user path into the data directory if that was relative
 // and if the user actually uses persistent storage. It will return an empty string
 // for emphemeral storage and the user's own input for absolute paths.
@@ -22,7 +22,6 @@ package mjoy
 
 import (
 	"compress/gzip"
-	"fmt"
 	"io"
 
 	"os"
@@ -30,10 +29,6 @@ import (
 
 	"mjoy.io/common/types"
 	"mjoy.io/common/types/util/hex"
-
-	"mjoy.io/core/blockchain/block"
-	"github.com/tinylib/msgp/msgp"
-	"mjoy.io/core/blockchain"
 )
 
 // PublicMjoyAPI provides an API to access Mjoy full node-related
@@ -161,16 +156,6 @@ func (api *PrivateAdminAPI) ExportChain(file string) (bool, error) {
 	return true, nil
 }
 
-func hasAllBlocks(chain *blockchain.BlockChain, bs []*block.Block) bool {
-	for _, b := range bs {
-		if !chain.HasBlock(b.Hash(), b.NumberU64()) {
-			return false
-		}
-	}
-
-	return true
-}
-
 // ImportChain imports a blockchain from a local file.
 func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	// Make sure the can access the file to import
@@ -187,34 +172,9 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 		}
 	}
 
-
-	blocks, index := make([]*block.Block, 0, 2500), 0
-	for batch := 0; ; batch++ {
-		// Load a batch of blocks from the input file
-		for len(blocks) < cap(blocks) {
-			block := new(block.Block)
-
-			if err := msgp.Decode(reader , block); err == io.EOF {
-				break
-			} else if err != nil {
-				return false, fmt.Errorf("block %d: failed to parse: %v", index, err)
-			}
-			blocks = append(blocks, block)
-			index++
-		}
-		if len(blocks) == 0 {
-			break
-		}
-
-		if hasAllBlocks(api.mjoy.BlockChain(), blocks) {
-			blocks = blocks[:0]
-			continue
-		}
-		// Import the batch and reset the buffer
-		if _, err := api.mjoy.BlockChain().InsertChain(blocks); err != nil {
-			return false, fmt.Errorf("batch %d: failed to insert: %v", batch, err)
-		}
-		blocks = blocks[:0]
+	// Import the blockchain, passing over the blocks already known
+	if _, _, err := api.mjoy.BlockChain().Import(reader, true, nil); err != nil {
+		return false, err
 	}
 	return true, nil
 }
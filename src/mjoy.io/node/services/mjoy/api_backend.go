@@ -22,12 +22,15 @@ package mjoy
 
 import (
 	"context"
+	"fmt"
+	"math/big"
 
 	"mjoy.io/params"
 	"mjoy.io/core/state"
 	"mjoy.io/core"
 	"mjoy.io/utils/event"
 	"mjoy.io/node/services/mjoy/downloader"
+	"mjoy.io/node/services/mjoy/feeoracle"
 	"mjoy.io/accounts"
 	"mjoy.io/core/blockchain/block"
 	"mjoy.io/communication/rpc"
@@ -36,11 +39,13 @@ import (
 	"mjoy.io/core/blockchain"
 	"mjoy.io/utils/database"
 	"mjoy.io/utils/bloom"
+	"mjoy.io/core/txprocessor"
 )
 
 // MjoyApiBackend implements mjoyapi.Backend for full nodes
 type MjoyApiBackend struct {
 	mjoy *Mjoy
+	fo   *feeoracle.Oracle
 }
 
 func (b *MjoyApiBackend) ChainConfig() *params.ChainConfig {
@@ -51,6 +56,18 @@ func (b *MjoyApiBackend) CurrentBlock() *block.Block {
 	return b.mjoy.blockchain.CurrentBlock()
 }
 
+func (b *MjoyApiBackend) FinalizedBlock() (*block.Block, *block.Certificate) {
+	final := b.mjoy.blockchain.CurrentFinalizedBlock()
+	if final == nil {
+		return nil, nil
+	}
+	return final, b.mjoy.blockchain.GetCertificate(final.Hash(), final.NumberU64())
+}
+
+func (b *MjoyApiBackend) GetEvidence(producer types.Address, number uint64) *block.Evidence {
+	return blockchain.GetEvidence(b.mjoy.chainDb, producer, number)
+}
+
 func (b *MjoyApiBackend) SetHead(number uint64) {
 	b.mjoy.protocolManager.downloader.Cancel()
 	b.mjoy.blockchain.SetHead(number)
@@ -82,6 +99,9 @@ func (b *MjoyApiBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc
 		return nil, nil, err
 	}
 	stateDb, err := b.mjoy.BlockChain().StateAt(header.StateRootHash)
+	if err != nil && b.mjoy.BlockChain().NodeMode() != blockchain.ArchiveMode {
+		return nil, nil, fmt.Errorf("state of block #%d not kept by a full node, query an archive node: %v", header.Number.IntVal.Uint64(), err)
+	}
 	return stateDb, header, err
 }
 
@@ -145,10 +165,34 @@ func (b *MjoyApiBackend) TxPoolContent() (map[types.Address]transaction.Transact
 	return b.mjoy.TxPool().Content()
 }
 
+func (b *MjoyApiBackend) TxPoolContentFrom(addr types.Address) (transaction.Transactions, transaction.Transactions) {
+	return b.mjoy.TxPool().ContentFrom(addr)
+}
+
+func (b *MjoyApiBackend) TxPoolStatus(hashes []types.Hash) []txprocessor.TxStatus {
+	return b.mjoy.TxPool().Status(hashes)
+}
+
+func (b *MjoyApiBackend) RemovePoolTx(hash types.Hash) bool {
+	return b.mjoy.TxPool().RemoveTx(hash)
+}
+
+func (b *MjoyApiBackend) SubscribeTxLifecycleEvent(ch chan<- core.TxLifecycleEvent) event.Subscription {
+	return b.mjoy.TxPool().SubscribeTxLifecycleEvent(ch)
+}
+
 func (b *MjoyApiBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
 	return b.mjoy.TxPool().SubscribeTxPreEvent(ch)
 }
 
+func (b *MjoyApiBackend) SuggestFeePerByte(ctx context.Context) (*big.Int, error) {
+	return b.fo.SuggestFeePerByte(ctx)
+}
+
+func (b *MjoyApiBackend) SuggestFee(ctx context.Context, tx *transaction.Transaction) (uint64, error) {
+	return b.fo.SuggestFee(ctx, tx)
+}
+
 func (b *MjoyApiBackend) Downloader() *downloader.Downloader {
 	return b.mjoy.Downloader()
 }
@@ -27,8 +27,13 @@ import (
 	"sync"
 	"sync/atomic"
 	"mjoy.io/consensus"
+	"mjoy.io/consensus/bft"
+	"mjoy.io/consensus/dpos"
+	"mjoy.io/consensus/poa"
+	"mjoy.io/consensus/remotesigner"
 
 	"mjoy.io/node/services/mjoy/downloader"
+	"mjoy.io/node/services/mjoy/feeoracle"
 	"mjoy.io/utils/event"
 	"mjoy.io/node"
 	"mjoy.io/params"
@@ -68,7 +73,6 @@ type Mjoy struct {
 
 	// Channel for shutting down the service
 	shutdownChan  chan bool    // Channel for shutting down the mjoy
-	stopDbUpgrade func() error // stop chain db sequential key upgrade
 
 	// Handlers
 	txPool          *txprocessor.TxPool
@@ -81,6 +85,7 @@ type Mjoy struct {
 
 	eventMux       *event.TypeMux
 	engine         consensus.Engine
+	finality       *bft.Gadget // Finality gadget, nil if the engine has no producer set
 	accountManager *accounts.Manager
 
 	bloomRequests chan chan *bloom.Retrieval // Channel receiving bloom data retrieval requests
@@ -90,6 +95,7 @@ type Mjoy struct {
 
 
 	blockproducer     *blockproducer.Blockproducer
+	remoteSigner      *remotesigner.Client // Signer holding the producer key, nil if kept in process
 	interVm             *interpreter.Vms
 	coinbase types.Address
 
@@ -128,11 +134,20 @@ func New(ctx *node.ServiceContext) (*Mjoy, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
+	// Fast sync skips the states below its pivot block, which archive nodes keep
+	if config.NodeMode == blockchain.ArchiveMode && config.SyncMode == downloader.FastSync {
+		logger.Warn("Archive node syncing in full mode", "configured", config.SyncMode)
+		config.SyncMode = downloader.FullSync
+	}
 	chainDb, err := CreateDB(ctx, config, "chaindata")
 	if err != nil {
 		return nil, err
 	}
-	stopDbUpgrade := upgradeDeduplicateData(chainDb)
+	if !config.SkipBcVersionCheck {
+		if err := migrateDatabase(chainDb); err != nil {
+			return nil, err
+		}
+	}
 	chainConfig, genesisHash, genesisErr := genesis.SetupGenesisBlock(chainDb, config.Genesis)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
@@ -146,7 +161,6 @@ func New(ctx *node.ServiceContext) (*Mjoy, error) {
 		eventMux:       ctx.EventMux,
 		accountManager: ctx.AccountManager,
 		shutdownChan:   make(chan bool),
-		stopDbUpgrade:  stopDbUpgrade,
 		networkId:      config.NetworkId,
 		coinbase:      config.Coinbase,
 		bloomRequests:  make(chan chan *bloom.Retrieval),
@@ -155,15 +169,14 @@ func New(ctx *node.ServiceContext) (*Mjoy, error) {
 
 	logger.Info("Initialising Mjoy protocol", "versions", ProtocolVersions, "network", config.NetworkId)
 	mjoy.engine = CreateConsensusEngine(mjoy)
-	if !config.SkipBcVersionCheck {
-		bcVersion := blockchain.GetBlockChainVersion(chainDb)
-		if bcVersion != blockchain.BlockChainVersion && bcVersion != 0 {
-			return nil, fmt.Errorf("Blockchain DB version mismatch (%d / %d). Run mjoyd upgradedb.\n", bcVersion, blockchain.BlockChainVersion)
-		}
-		blockchain.WriteBlockChainVersion(chainDb, blockchain.BlockChainVersion)
-	}
 
-	mjoy.blockchain, err = blockchain.NewBlockChain(chainDb, mjoy.chainConfig, mjoy.engine)
+	cacheConfig := &blockchain.CacheConfig{
+		Disabled:      config.NodeMode == blockchain.ArchiveMode,
+		TriesKept:     config.StateHistory,
+		PruneInterval: config.PruneInterval,
+		FreezeDepth:   config.FreezeDepth,
+	}
+	mjoy.blockchain, err = blockchain.NewBlockChain(chainDb, cacheConfig, mjoy.chainConfig, mjoy.engine)
 	if err != nil {
 		return nil, err
 	}
@@ -175,20 +188,30 @@ func New(ctx *node.ServiceContext) (*Mjoy, error) {
 	}
 	mjoy.bloomIndexer.Start(mjoy.blockchain)
 
+	// Finalize blocks by votes if the engine knows the producers
+	if validators, ok := mjoy.engine.(consensus.Validators); ok {
+		mjoy.finality = bft.New(mjoy.blockchain, validators)
+	}
+
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.Snapshot != "" {
+		config.TxPool.Snapshot = ctx.ResolvePath(config.TxPool.Snapshot)
+	}
 
 	mjoy.txPool = txprocessor.NewTxPool(config.TxPool, mjoy.chainConfig, mjoy.blockchain)
 
 	if mjoy.protocolManager, err = NewProtocolManager(mjoy.chainConfig, config.SyncMode, config.NetworkId, mjoy.eventMux, mjoy.txPool, mjoy.engine, mjoy.blockchain, chainDb); err != nil {
 		return nil, err
 	}
+	mjoy.protocolManager.txLimiter = newTxLimiter(config.TxSpam, mjoy.chainConfig)
 
 	//Init miner
-	mjoy.blockproducer = blockproducer.New(mjoy,mjoy.interVm,mjoy.chainConfig,mjoy.EventMux() , mjoy.engine)
+	mjoy.blockproducer = blockproducer.New(mjoy,mjoy.interVm,mjoy.chainConfig,&config.Producer,mjoy.EventMux() , mjoy.engine)
 
-	mjoy.ApiBackend = &MjoyApiBackend{mjoy}
+	mjoy.ApiBackend = &MjoyApiBackend{mjoy, nil}
+	mjoy.ApiBackend.fo = feeoracle.NewOracle(mjoy.ApiBackend, config.FeeOracle)
 
 
 	fmt.Println("New......Mjoy")
@@ -202,26 +225,66 @@ func makeExtraData(extra []byte) []byte {
 // CreateDB creates the chain database.
 func CreateDB(ctx *node.ServiceContext, config *Config, name string) (database.IDatabase, error) {
 
-	db, err := ctx.OpenDatabase(name, config.DatabaseCache, config.DatabaseHandles)
+	db, err := ctx.OpenDatabaseWithFreezer(name, config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer)
 	if err != nil {
 		return nil, err
 	}
-	if db, ok := db.(*database.LDatabase); ok {
+	if db, ok := db.(*database.FreezerDB); ok {
 		db.Meter("mjoy/db/chaindata/")
 	}
 	return db, nil
 }
 
+// migrateDatabase brings the schema of the chain database to the latest
+// version before the chain opens it.
+func migrateDatabase(db database.IDatabase) error {
+	iterable, ok := db.(database.IIterableDatabase)
+	if !ok {
+		return nil
+	}
+	if _, err := blockchain.MigrateDatabase(iterable, false); err != nil {
+		return fmt.Errorf("migrate chain database: %v", err)
+	}
+	return nil
+}
+
 // CreateConsensusEngine creates the required type of consensus engine instance for an Mjoy service
 func CreateConsensusEngine(mjoy *Mjoy) consensus.Engine {
+	return NewConsensusEngine(mjoy.chainConfig, mjoy.chainDb)
+}
+
+// NewConsensusEngine creates the consensus engine the chain configuration
+// requires, on top of the chain database db.
+func NewConsensusEngine(chainConfig *params.ChainConfig, db database.IDatabase) consensus.Engine {
+	// If proof-of-authority is requested, set it up
+	if chainConfig.Poa != nil {
+		return poa.New(chainConfig.Poa, db)
+	}
+	// If delegated proof-of-stake is requested, set it up
+	if chainConfig.Dpos != nil {
+		return dpos.New(chainConfig.Dpos, db)
+	}
 	engine := consensus.NewBasicEngine(nil)
 	return engine
 }
 
 func (s *Mjoy) SetEngineKey(pri *ecdsa.PrivateKey) {
+	s.SetEngineSigner(consensus.NewKeySigner(pri))
+}
+
+// SetEngineSigner sets the signer of the produced headers and of the finality
+// votes, which may hold the key out of process.
+func (s *Mjoy) SetEngineSigner(signer consensus.ProducerSigner) {
 	switch v := s.engine.(type) {
 	case *consensus.Engine_basic:
-		v.SetKey(pri)
+		v.SetSigner(signer)
+	case *poa.Poa:
+		v.SetSigner(signer)
+	case *dpos.Dpos:
+		v.SetSigner(signer)
+	}
+	if s.finality != nil {
+		s.finality.SetSigner(signer)
 	}
 }
 
@@ -234,6 +297,14 @@ func (s *Mjoy) APIs() []rpc.API {
 	//create New
 	//apis := make([]rpc.API , 0)
 
+	// Append any APIs exposed explicitly by the consensus engine
+	switch engine := s.engine.(type) {
+	case *poa.Poa:
+		apis = append(apis, engine.APIs(s.BlockChain())...)
+	case *dpos.Dpos:
+		apis = append(apis, engine.APIs(s.BlockChain())...)
+	}
+
 	return append(apis, []rpc.API{
 		{
 			Namespace: "mjoy",
@@ -281,6 +352,9 @@ func (self *Mjoy) SetCoinbase(coinbase types.Address) {
 }
 
 func (s *Mjoy) StartProducing(local bool, password string) error {
+	if s.config.RemoteSigner != "" {
+		return s.startRemoteProducing(local)
+	}
 	eb, err := s.Coinbase()
 	if err != nil {
 		logger.Error("Cannot start producing without coinbase", "err", err)
@@ -307,6 +381,40 @@ func (s *Mjoy) StartProducing(local bool, password string) error {
 	return nil
 }
 
+// startRemoteProducing starts producing with the key held by the remote signer,
+// the address of which becomes the coinbase.
+func (s *Mjoy) startRemoteProducing(local bool) error {
+	s.lock.Lock()
+	signer := s.remoteSigner
+	s.lock.Unlock()
+
+	if signer == nil {
+		var err error
+		if signer, err = remotesigner.Dial(s.config.RemoteSigner); err != nil {
+			logger.Error("Cannot connect to the remote signer", "endpoint", s.config.RemoteSigner, "err", err)
+			return fmt.Errorf("remote signer: %v", err)
+		}
+		s.lock.Lock()
+		s.remoteSigner = signer
+		s.lock.Unlock()
+	}
+	address := signer.Address()
+	if s.config.Coinbase != (types.Address{}) && s.config.Coinbase != address {
+		return fmt.Errorf("remote signer holds the key of %x, but the coinbase is %x", address, s.config.Coinbase)
+	}
+	s.lock.Lock()
+	s.coinbase = address
+	s.lock.Unlock()
+	s.SetEngineSigner(signer)
+	logger.Infof("Producing with the remote signer %s, coinbase:0x%x\n", s.config.RemoteSigner, address)
+
+	if local {
+		atomic.StoreUint32(&s.protocolManager.acceptTxs, 1)
+	}
+	go s.blockproducer.Start(address)
+	return nil
+}
+
 func (s *Mjoy) StopProducing()         { s.blockproducer.Stop() }
 func (s *Mjoy) IsProducing() bool      { return s.blockproducer.Producing() }
 func (s *Mjoy) Blockproducer() *blockproducer.Blockproducer { return s.blockproducer }
@@ -325,10 +433,14 @@ func (s *Mjoy) Downloader() *downloader.Downloader { return s.protocolManager.do
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *Mjoy) Protocols() []p2p.Protocol {
+	protocols := s.protocolManager.SubProtocols
+	if s.finality != nil {
+		protocols = append(protocols, s.finality.Protocols()...)
+	}
 	if s.lesServer == nil {
-		return s.protocolManager.SubProtocols
+		return protocols
 	}
-	return append(s.protocolManager.SubProtocols, s.lesServer.Protocols()...)
+	return append(protocols, s.lesServer.Protocols()...)
 }
 
 // Start implements node.Service, starting all internal goroutines needed by the
@@ -346,10 +458,14 @@ func (s *Mjoy) Start(srvr *p2p.Server) error {
 		}
 	}
 	// Start the networking layer and the light server if requested
+	s.protocolManager.server = srvr
 	s.protocolManager.Start(maxPeers)
 	if s.lesServer != nil {
 		s.lesServer.Start(srvr)
 	}
+	if s.finality != nil {
+		s.finality.Start()
+	}
 
 	_ , err := s.Coinbase()
 	if err != nil{
@@ -370,10 +486,10 @@ func (s *Mjoy) Start(srvr *p2p.Server) error {
 // Stop implements node.Service, terminating all internal goroutines used by the
 // Mjoy protocol.
 func (s *Mjoy) Stop() error {
-	if s.stopDbUpgrade != nil {
-		s.stopDbUpgrade()
-	}
 	s.bloomIndexer.Close()
+	if s.finality != nil {
+		s.finality.Stop()
+	}
 	s.blockchain.Stop()
 	s.protocolManager.Stop()
 	if s.lesServer != nil {
@@ -381,6 +497,9 @@ func (s *Mjoy) Stop() error {
 	}
 	s.txPool.Stop()
 	s.blockproducer.Stop()
+	if s.remoteSigner != nil {
+		s.remoteSigner.Close()
+	}
 	s.eventMux.Stop()
 
 	s.chainDb.Close()
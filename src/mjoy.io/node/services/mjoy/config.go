@@ -27,7 +27,10 @@ import (
 
 	"mjoy.io/common/types"
 	"mjoy.io/common/types/util/hex"
+	"mjoy.io/blockproducer"
+	"mjoy.io/core/blockchain"
 	"mjoy.io/node/services/mjoy/downloader"
+	"mjoy.io/node/services/mjoy/feeoracle"
 	"mjoy.io/core/txprocessor"
 	"mjoy.io/core/genesis"
 	"mjoy.io/node"
@@ -41,9 +44,14 @@ var DefaultConfig = Config{
 	NetworkId:     1,
 	LightPeers:    20,
 	DatabaseCache: 128,
+	NodeMode:      blockchain.FullMode,
 
 	TxPool: txprocessor.DefaultTxPoolConfig,
+	TxSpam: DefaultTxSpamConfig,
 
+	Producer: blockproducer.DefaultConfig,
+
+	FeeOracle: feeoracle.DefaultConfig,
 }
 
 func init() {
@@ -75,16 +83,36 @@ type Config struct {
 	SkipBcVersionCheck bool `toml:"-"`
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
+	DatabaseFreezer    string `toml:",omitempty"` // Directory of the ancient store, "ancient" in the database if empty
+
+	// State pruning options
+	NodeMode      blockchain.NodeMode       // Whether to prune the old states ("full") or keep every state ("archive")
+	StateHistory  uint64 `toml:",omitempty"` // Number of recent states kept on disk when pruning
+	PruneInterval uint64 `toml:",omitempty"` // Number of blocks between two prunings
+	FreezeDepth   uint64 `toml:",omitempty"` // Number of recent blocks kept out of the ancient store
 
 	// Producing-related options
 	Coinbase    types.Address `toml:",omitempty"`
 	BlockproducerThreads int  `toml:",omitempty"`
 	ExtraData    []byte       `toml:",omitempty"`
 
+	// Endpoint of the remote signer holding the producer key, an http:// URL or
+	// the path of an IPC socket. The key is read from the keystore if empty.
+	RemoteSigner string `toml:",omitempty"`
+
+	// Block production cadence and limits
+	Producer blockproducer.Config
+
 
 	// Transaction pool options
 	TxPool txprocessor.TxPoolConfig
 
+	// Limits on the transactions relayed by peers
+	TxSpam TxSpamConfig
+
+	// Fee oracle options
+	FeeOracle feeoracle.Config
+
 
 
 	// Enables tracking of SHA3 preimages in the VM
@@ -101,6 +129,9 @@ func (c *Config) SetDefaultConfig() error{
 	c.Genesis = genesis.DefaultGenesisBlock()
 	c.NetworkId = params.DefaultChainConfig.ChainId.Uint64()
 	c.TxPool = txprocessor.DefaultTxPoolConfig
+	c.TxSpam = DefaultTxSpamConfig
+	c.Producer = blockproducer.DefaultConfig
+	c.FeeOracle = feeoracle.DefaultConfig
 	c.StartBlockproducerAtStart = true
 	return nil
 }
@@ -0,0 +1,164 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: feeoracle.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+// Package feeoracle suggests transaction fees from the fees paid in recent blocks.
+package feeoracle
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+
+	"mjoy.io/common/types"
+	"mjoy.io/communication/rpc"
+	"mjoy.io/core/blockchain/block"
+	"mjoy.io/core/transaction"
+)
+
+// Config are the configuration parameters of the fee oracle.
+type Config struct {
+	Blocks     int    // Number of recent blocks to sample
+	Percentile int    // Percentile of the sampled fees per byte to suggest
+	Default    uint64 // Fee per byte suggested while there are no samples
+}
+
+// DefaultConfig contains the default configurations for the fee oracle.
+var DefaultConfig = Config{
+	Blocks:     20,
+	Percentile: 60,
+	Default:    0,
+}
+
+// Backend is the chain access the oracle needs.
+type Backend interface {
+	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*block.Header, error)
+	BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*block.Block, error)
+}
+
+// Oracle recommends a fee per byte based on the content of recent blocks.
+type Oracle struct {
+	backend Backend
+	config  Config
+
+	lastHead types.Hash
+	lastRate *big.Int
+	cacheMu  sync.RWMutex
+}
+
+// NewOracle returns a new oracle, replacing unusable settings with defaults.
+func NewOracle(backend Backend, config Config) *Oracle {
+	if config.Blocks < 1 {
+		config.Blocks = DefaultConfig.Blocks
+	}
+	if config.Percentile < 0 {
+		config.Percentile = 0
+	}
+	if config.Percentile > 100 {
+		config.Percentile = 100
+	}
+	return &Oracle{
+		backend:  backend,
+		config:   config,
+		lastRate: new(big.Int).SetUint64(config.Default),
+	}
+}
+
+// SuggestFeePerByte returns the recommended fee per byte, in the units of
+// transaction.FeePerByte.
+func (o *Oracle) SuggestFeePerByte(ctx context.Context) (*big.Int, error) {
+	head, err := o.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil || err != nil {
+		return nil, err
+	}
+	headHash := head.Hash()
+
+	o.cacheMu.RLock()
+	lastHead, lastRate := o.lastHead, o.lastRate
+	o.cacheMu.RUnlock()
+	if headHash == lastHead {
+		return new(big.Int).Set(lastRate), nil
+	}
+
+	var rates []*big.Int
+	number := head.Number.IntVal.Uint64()
+	for i := 0; i < o.config.Blocks && uint64(i) <= number; i++ {
+		blk, err := o.backend.BlockByNumber(ctx, rpc.BlockNumber(number-uint64(i)))
+		if err != nil {
+			return nil, err
+		}
+		// Blocks missing from the database end the sample
+		if blk == nil {
+			break
+		}
+		for _, tx := range blk.Transactions() {
+			// Producer rewards pay nothing and would drag the suggestion down
+			if tx.Fee() == 0 {
+				continue
+			}
+			rates = append(rates, tx.FeePerByte())
+		}
+	}
+	rate := new(big.Int).Set(lastRate)
+	if len(rates) > 0 {
+		sort.Sort(bigIntArray(rates))
+		rate = rates[(len(rates)-1)*o.config.Percentile/100]
+	}
+
+	o.cacheMu.Lock()
+	o.lastHead, o.lastRate = headHash, rate
+	o.cacheMu.Unlock()
+	return new(big.Int).Set(rate), nil
+}
+
+// SuggestFee returns the recommended fee for the given transaction, which is the
+// suggested fee per byte applied to its signed size.
+func (o *Oracle) SuggestFee(ctx context.Context, tx *transaction.Transaction) (uint64, error) {
+	rate, err := o.SuggestFeePerByte(ctx)
+	if err != nil {
+		return 0, err
+	}
+	signatures := uint64(1)
+	if tx.IsMultiSig() {
+		signatures = tx.Data.MultiSig.Threshold
+	}
+	return FeeForSize(rate, uint64(tx.Size())+signatures*SignatureOverhead), nil
+}
+
+// SignatureOverhead approximates how much a signature grows an unsigned transaction.
+const SignatureOverhead = 72
+
+// FeeForSize converts a fee per byte back to a fee for the given size, rounding up
+// so the resulting transaction reaches the rate.
+func FeeForSize(rate *big.Int, size uint64) uint64 {
+	fee := new(big.Int).Mul(rate, new(big.Int).SetUint64(size))
+	fee.Add(fee, big.NewInt(transaction.FeeRateUnit-1))
+	fee.Div(fee, big.NewInt(transaction.FeeRateUnit))
+	if !fee.IsUint64() {
+		return ^uint64(0)
+	}
+	return fee.Uint64()
+}
+
+type bigIntArray []*big.Int
+
+func (s bigIntArray) Len() int           { return len(s) }
+func (s bigIntArray) Less(i, j int) bool { return s[i].Cmp(s[j]) < 0 }
+func (s bigIntArray) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -3,11 +3,14 @@
 package mjoy
 
 import (
+	"mjoy.io/blockproducer"
+	"mjoy.io/core/blockchain"
 	"mjoy.io/common/types"
 	"mjoy.io/common/types/util/hex"
 	"mjoy.io/core/genesis"
 	"mjoy.io/core/txprocessor"
 	"mjoy.io/node/services/mjoy/downloader"
+	"mjoy.io/node/services/mjoy/feeoracle"
 )
 
 var _ = (*configMarshaling)(nil)
@@ -21,10 +24,19 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SkipBcVersionCheck		bool	`toml:"-"`
 		DatabaseHandles			int	`toml:"-"`
 		DatabaseCache			int
+		DatabaseFreezer			string	`toml:",omitempty"`
+		NodeMode			blockchain.NodeMode
+		StateHistory			uint64	`toml:",omitempty"`
+		PruneInterval			uint64	`toml:",omitempty"`
+		FreezeDepth			uint64	`toml:",omitempty"`
 		Coinbase			types.Address	`toml:",omitempty"`
 		BlockproducerThreads		int		`toml:",omitempty"`
 		ExtraData			hex.Bytes	`toml:",omitempty"`
+		RemoteSigner			string	`toml:",omitempty"`
+		Producer			blockproducer.Config
 		TxPool				txprocessor.TxPoolConfig
+		TxSpam				TxSpamConfig
+		FeeOracle			feeoracle.Config
 		EnablePreimageRecording		bool
 		DocRoot				string	`toml:"-"`
 		StartBlockproducerAtStart	bool
@@ -38,10 +50,19 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
+	enc.DatabaseFreezer = c.DatabaseFreezer
+	enc.NodeMode = c.NodeMode
+	enc.StateHistory = c.StateHistory
+	enc.PruneInterval = c.PruneInterval
+	enc.FreezeDepth = c.FreezeDepth
 	enc.Coinbase = c.Coinbase
 	enc.BlockproducerThreads = c.BlockproducerThreads
 	enc.ExtraData = c.ExtraData
+	enc.RemoteSigner = c.RemoteSigner
+	enc.Producer = c.Producer
 	enc.TxPool = c.TxPool
+	enc.TxSpam = c.TxSpam
+	enc.FeeOracle = c.FeeOracle
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.DocRoot = c.DocRoot
 	enc.StartBlockproducerAtStart = c.StartBlockproducerAtStart
@@ -58,10 +79,19 @@ func (c *Config) UnmarshalTOML(unmarshal func (interface{}) error) error {
 		SkipBcVersionCheck		*bool	`toml:"-"`
 		DatabaseHandles			*int	`toml:"-"`
 		DatabaseCache			*int
+		DatabaseFreezer			*string	`toml:",omitempty"`
+		NodeMode			*blockchain.NodeMode
+		StateHistory			*uint64	`toml:",omitempty"`
+		PruneInterval			*uint64	`toml:",omitempty"`
+		FreezeDepth			*uint64	`toml:",omitempty"`
 		Coinbase			*types.Address	`toml:",omitempty"`
 		BlockproducerThreads		*int		`toml:",omitempty"`
 		ExtraData			hex.Bytes	`toml:",omitempty"`
+		RemoteSigner			*string	`toml:",omitempty"`
+		Producer			*blockproducer.Config
 		TxPool				*txprocessor.TxPoolConfig
+		TxSpam				*TxSpamConfig
+		FeeOracle			*feeoracle.Config
 		EnablePreimageRecording		*bool
 		DocRoot				*string	`toml:"-"`
 		StartBlockproducerAtStart	*bool
@@ -94,6 +124,21 @@ func (c *Config) UnmarshalTOML(unmarshal func (interface{}) error) error {
 	if dec.DatabaseCache != nil {
 		c.DatabaseCache = *dec.DatabaseCache
 	}
+	if dec.DatabaseFreezer != nil {
+		c.DatabaseFreezer = *dec.DatabaseFreezer
+	}
+	if dec.NodeMode != nil {
+		c.NodeMode = *dec.NodeMode
+	}
+	if dec.StateHistory != nil {
+		c.StateHistory = *dec.StateHistory
+	}
+	if dec.PruneInterval != nil {
+		c.PruneInterval = *dec.PruneInterval
+	}
+	if dec.FreezeDepth != nil {
+		c.FreezeDepth = *dec.FreezeDepth
+	}
 	if dec.Coinbase != nil {
 		c.Coinbase = *dec.Coinbase
 	}
@@ -103,9 +148,21 @@ func (c *Config) UnmarshalTOML(unmarshal func (interface{}) error) error {
 	if dec.ExtraData != nil {
 		c.ExtraData = dec.ExtraData
 	}
+	if dec.RemoteSigner != nil {
+		c.RemoteSigner = *dec.RemoteSigner
+	}
+	if dec.Producer != nil {
+		c.Producer = *dec.Producer
+	}
 	if dec.TxPool != nil {
 		c.TxPool = *dec.TxPool
 	}
+	if dec.TxSpam != nil {
+		c.TxSpam = *dec.TxSpam
+	}
+	if dec.FeeOracle != nil {
+		c.FeeOracle = *dec.FeeOracle
+	}
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
@@ -70,6 +70,8 @@ type ProtocolManager struct {
 	acceptTxs uint32 // Flag whether we're considered synchronised (enables transaction processing)
 
 	txpool      txPool
+	txLimiter   *txLimiter // Rate limits and scores the transactions relayed by peers, if set
+	server      *p2p.Server
 	blockchain  *blockchain.BlockChain
 	chaindb     database.IDatabase
 	chainconfig *params.ChainConfig
@@ -164,7 +166,12 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 	manager.downloader = downloader.New(mode, chaindb, manager.eventMux, blockchain, nil, manager.removePeer)
 
 	validator := func(header *block.Header) error {
-		return engine.VerifyHeader(blockchain, header, true)
+		if err := engine.VerifyHeader(blockchain, header, true); err != nil {
+			return err
+		}
+		// Catch producers signing several blocks at a height, even if never imported
+		blockchain.CheckEquivocation(header)
+		return nil
 	}
 	heighter := func() uint64 {
 		return blockchain.CurrentBlock().NumberU64()
@@ -193,6 +200,9 @@ func (pm *ProtocolManager) removePeer(id string) {
 
 	// Unregister the peer from the downloader and mjoy peer set
 	pm.downloader.UnregisterPeer(id)
+	if pm.txLimiter != nil {
+		pm.txLimiter.removePeer(id)
+	}
 	if err := pm.peers.Unregister(id); err != nil {
 		logger.Error("Peer removal failed", "peer", id, "err", err)
 	}
@@ -624,7 +634,18 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			p.MarkTransaction(tx.Hash())
 		}
-		pm.txpool.AddRemotes(txs)
+		if pm.txLimiter == nil {
+			pm.txpool.AddRemotes(txs)
+			break
+		}
+		txs = pm.txLimiter.filter(p.id, txs)
+		if errs := pm.txpool.AddRemotes(txs); pm.txLimiter.judge(p.id, errs) {
+			txSpamPeerCounter.Inc(1)
+			if pm.server != nil {
+				pm.server.BanPeer(p.ID(), pm.txLimiter.config.BanDuration)
+			}
+			return errResp(ErrTxSpam, "peer %s relays too many bad transactions", p.id)
+		}
 
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
@@ -320,13 +320,13 @@ func testGetNodeData(t *testing.T, protocol int) {
 		switch i {
 		case 0:
 			// In block 1, the test bank sends account #1 some mjoy coin .
-			tx, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(testBank), acc1Addr, big.NewInt(10000),0, nil, nil), signer, testBankKey)
+			tx, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(testBank), testTransfer(testBank, acc1Addr, 10000)), signer, testBankKey)
 			block.AddTx(tx)
 		case 1:
 			// In block 2, the test bank sends some more mjoy coin to account #1.
 			// acc1Addr passes it on to account #2.
-			tx1, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(testBank), acc1Addr, big.NewInt(1000), 0, nil, nil), signer, testBankKey)
-			tx2, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(acc1Addr), acc2Addr, big.NewInt(1000), 0, nil, nil), signer, acc1Key)
+			tx1, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(testBank), testTransfer(testBank, acc1Addr, 1000)), signer, testBankKey)
+			tx2, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(acc1Addr), testTransfer(acc1Addr, acc2Addr, 1000)), signer, acc1Key)
 			block.AddTx(tx1)
 			block.AddTx(tx2)
 		case 2:
@@ -378,15 +378,12 @@ func testGetNodeData(t *testing.T, protocol int) {
 		trie, _ := state.New(pm.blockchain.GetBlockByNumber(i).Root(), state.NewDatabase(statedb))
 
 		for j, acc := range accounts {
-			state, _ := pm.blockchain.State()
-			bw := state.GetBalance(acc)
-			bh := trie.GetBalance(acc)
+			state, _ := pm.blockchain.StateAt(pm.blockchain.GetBlockByNumber(i).Root())
+			nw := state.GetNonce(acc)
+			nh := trie.GetNonce(acc)
 
-			if (bw != nil && bh == nil) || (bw == nil && bh != nil) {
-				t.Errorf("test %d, account %d: balance mismatch: have %v, want %v", i, j, bh, bw)
-			}
-			if bw != nil && bh != nil && bw.Cmp(bw) != 0 {
-				t.Errorf("test %d, account %d: balance mismatch: have %v, want %v", i, j, bh, bw)
+			if nw != nh {
+				t.Errorf("test %d, account %d: nonce mismatch: have %v, want %v", i, j, nh, nw)
 			}
 		}
 	}
@@ -408,13 +405,13 @@ func testGetReceipt(t *testing.T, protocol int) {
 		switch i {
 		case 0:
 			// In block 1, the test bank sends account #1 some mjoy coin.
-			tx, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(testBank), acc1Addr, big.NewInt(10000), 0, nil, nil), signer, testBankKey)
+			tx, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(testBank), testTransfer(testBank, acc1Addr, 10000)), signer, testBankKey)
 			block.AddTx(tx)
 		case 1:
 			// In block 2, the test bank sends some more mjoy coin to account #1.
 			// acc1Addr passes it on to account #2.
-			tx1, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(testBank), acc1Addr, big.NewInt(1000), 0, nil, nil), signer, testBankKey)
-			tx2, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(acc1Addr), acc2Addr, big.NewInt(1000), 0, nil, nil), signer, acc1Key)
+			tx1, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(testBank), testTransfer(testBank, acc1Addr, 1000)), signer, testBankKey)
+			tx2, _ := transaction.SignTx(transaction.NewTransaction(block.TxNonce(acc1Addr), testTransfer(acc1Addr, acc2Addr, 1000)), signer, acc1Key)
 			block.AddTx(tx1)
 			block.AddTx(tx2)
 		case 2:
@@ -437,7 +434,15 @@ func testGetReceipt(t *testing.T, protocol int) {
 		blkHash := block.Hash()
 		rblkHash := & blkHash
 		hashes.Hashs = append(hashes.Hashs, rblkHash)
-		receipts.Receipts_s = append(receipts.Receipts_s, blockchain.GetBlockReceipts(pm.chaindb, block.Hash(), block.NumberU64()))
+		receiptPs := transaction.ReceiptProtocols{}
+		for _, receipt := range blockchain.GetBlockReceipts(pm.chaindb, block.Hash(), block.NumberU64()) {
+			logPs := []*transaction.LogProtocol{}
+			for _, log := range receipt.Logs {
+				logPs = append(logPs, &transaction.LogProtocol{log.Address, log.Topics, log.Data})
+			}
+			receiptPs = append(receiptPs, &transaction.ReceiptProtocol{receipt.Status, receipt.Bloom, logPs})
+		}
+		receipts.Receipts_s = append(receipts.Receipts_s, receiptPs)
 	}
 	// Send the hash request and verify the response
 	p2p.Send(peer.app, 0x0f, &hashes)
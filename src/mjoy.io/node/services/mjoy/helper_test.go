@@ -45,15 +45,16 @@ import (
 	"mjoy.io/utils/database"
 	"mjoy.io/core/genesis"
 	"mjoy.io/common/types"
+	"mjoy.io/core/interpreter/balancetransfer"
 )
 
 var (
 	testBankKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 	testBank       = crypto.PubkeyToAddress(testBankKey.PublicKey)
 )
-var defaultChainConfig = &params.ChainConfig{big.NewInt(100)}
+var defaultChainConfig = &params.ChainConfig{ChainId: big.NewInt(100)}
 
-var testChainConfig = &params.ChainConfig{big.NewInt(200)}
+var testChainConfig = &params.ChainConfig{ChainId: big.NewInt(200)}
 // newTestProtocolManager creates a new protocol manager for testing purposes,
 // with the given number of blocks already known, and potential notification
 // channels for different events.
@@ -64,10 +65,10 @@ func newTestProtocolManager(mode downloader.SyncMode, blocks int, generator func
 		db, _  = database.OpenMemDB()
 		gspec  = &genesis.Genesis{
 			Config: testChainConfig,
-			Alloc:  genesis.GenesisAlloc{testBank: {Balance: big.NewInt(1000000)}},
+			Alloc:  genesis.GenesisAlloc{testBank: {}},
 		}
 		genesis       = gspec.MustCommit(db)
-		blockchain, _ = blockchain.NewBlockChain(db, gspec.Config, engine)
+		blockchain, _ = blockchain.NewBlockChain(db, nil, gspec.Config, engine)
 	)
 	chain, _ := chainmaker.GenerateChain(gspec.Config, genesis, engine, db, blocks, generator)
 	if _, err := blockchain.InsertChain(chain); err != nil {
@@ -139,11 +140,16 @@ func (p *testTxPool) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscr
 // newTestTransaction create a new dummy transaction.
 func newTestTransaction(from *ecdsa.PrivateKey, nonce uint64, datasize int) *transaction.Transaction {
 	signer := transaction.MakeSigner(defaultChainConfig, big.NewInt(0))
-	tx := transaction.NewTransaction(nonce, types.Address{}, big.NewInt(0), 100000, big.NewInt(0), make([]byte, datasize))
+	tx := transaction.NewTransaction(nonce, transaction.ActionSlice{transaction.MakeAction(types.Address{}, make([]byte, datasize))})
 	tx, _ = transaction.SignTx(tx, signer, from)
 	return tx
 }
 
+// testTransfer returns the action of a balance transfer.
+func testTransfer(from, to types.Address, amount int) transaction.ActionSlice {
+	return transaction.ActionSlice{transaction.MakeAction(balancetransfer.BalanceTransferAddress, balancetransfer.MakaBalanceTransferParam(from, to, amount))}
+}
+
 // testPeer is a simulated peer to allow testing direct network calls.
 type testPeer struct {
 	net p2p.MsgReadWriter // Network layer reader/writer to simulate remote messaging
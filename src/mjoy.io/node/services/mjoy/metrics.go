@@ -58,6 +58,10 @@ var (
 	miscInTrafficMeter        = metrics.NewRegisteredMeter("mjoy/misc/in/traffic",nil)
 	miscOutPacketsMeter       = metrics.NewRegisteredMeter("mjoy/misc/out/packets",nil)
 	miscOutTrafficMeter       = metrics.NewRegisteredMeter("mjoy/misc/out/traffic",nil)
+
+	txPeerRateLimitCounter   = metrics.NewRegisteredCounter("mjoy/txs/ratelimit/peer",nil)   // Dropped over the per-peer limit
+	txSenderRateLimitCounter = metrics.NewRegisteredCounter("mjoy/txs/ratelimit/sender",nil) // Dropped over the per-sender limit
+	txSpamPeerCounter        = metrics.NewRegisteredCounter("mjoy/txs/spam/peers",nil)       // Peers dropped for their score
 )
 
 // meteredMsgReadWriter is a wrapper around a p2p.MsgReadWriter, capable of
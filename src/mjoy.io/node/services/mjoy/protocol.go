@@ -73,6 +73,7 @@ const (
 	ErrNoStatusMsg
 	ErrExtraStatusMsg
 	ErrSuspendedPeer
+	ErrTxSpam
 )
 
 func (e errCode) String() string {
@@ -90,6 +91,7 @@ var errorToString = map[int]string{
 	ErrNoStatusMsg:             "No status message",
 	ErrExtraStatusMsg:          "Extra status message",
 	ErrSuspendedPeer:           "Suspended peer",
+	ErrTxSpam:                  "Transaction spam",
 }
 
 type txPool interface {
@@ -0,0 +1,207 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: txlimiter.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package mjoy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru"
+	"mjoy.io/core/transaction"
+	"mjoy.io/core/txprocessor"
+	"mjoy.io/params"
+)
+
+// TxSpamConfig are the limits applied to transactions relayed by peers.
+type TxSpamConfig struct {
+	PeerTxRate    float64 // Transactions per second accepted from a peer
+	PeerTxBurst   uint64  // Transactions a peer may relay at once
+	SenderTxRate  float64 // Transactions per second accepted from a sending account
+	SenderTxBurst uint64  // Transactions of a sending account accepted at once
+	SenderCache   int     // Number of sending accounts tracked
+
+	MinScore    float64       // Ratio of good transactions under which a peer is dropped
+	MinSamples  uint64        // Number of judged transactions before a peer can be dropped
+	ScoreDecay  time.Duration // Interval after which the past behaviour of a peer weighs half
+	BanDuration time.Duration // Time a dropped peer is refused to reconnect
+}
+
+// DefaultTxSpamConfig contains the default transaction spam limits.
+var DefaultTxSpamConfig = TxSpamConfig{
+	PeerTxRate:    100,
+	PeerTxBurst:   1024,
+	SenderTxRate:  4,
+	SenderTxBurst: 64,
+	SenderCache:   4096,
+
+	MinScore:    0.5,
+	MinSamples:  64,
+	ScoreDecay:  10 * time.Minute,
+	BanDuration: time.Hour,
+}
+
+// tokenBucket allows rate operations per second, with bursts up to its size.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst uint64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// take consumes a token, reporting whether one was available.
+func (b *tokenBucket) take(now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// peerTxStats tracks the transactions relayed by a peer.
+type peerTxStats struct {
+	bucket  *tokenBucket
+	good    float64 // Decayed count of transactions accepted by the pool
+	bad     float64 // Decayed count of invalid or underpriced transactions
+	decayed time.Time
+}
+
+// score returns the ratio of good transactions relayed by the peer, or 1 while
+// too few were judged.
+func (s *peerTxStats) score(minSamples uint64) float64 {
+	if s.good+s.bad < float64(minSamples) {
+		return 1
+	}
+	return s.good / (s.good + s.bad)
+}
+
+// txLimiter rate limits the transactions relayed by peers, per peer and per
+// sending account, and scores peers on the quality of what they relay.
+type txLimiter struct {
+	config TxSpamConfig
+	signer transaction.Signer
+
+	mu      sync.Mutex
+	peers   map[string]*peerTxStats
+	senders *lru.Cache // Token buckets of the sending accounts
+}
+
+func newTxLimiter(config TxSpamConfig, chainconfig *params.ChainConfig) *txLimiter {
+	if config.SenderCache <= 0 {
+		config.SenderCache = DefaultTxSpamConfig.SenderCache
+	}
+	senders, _ := lru.New(config.SenderCache)
+	return &txLimiter{
+		config:  config,
+		signer:  transaction.NewMSigner(chainconfig.ChainId),
+		peers:   make(map[string]*peerTxStats),
+		senders: senders,
+	}
+}
+
+func (l *txLimiter) peer(id string, now time.Time) *peerTxStats {
+	stats := l.peers[id]
+	if stats == nil {
+		stats = &peerTxStats{bucket: newTokenBucket(l.config.PeerTxRate, l.config.PeerTxBurst, now), decayed: now}
+		l.peers[id] = stats
+	}
+	return stats
+}
+
+// filter returns the transactions of a peer within the rate limits. Those with
+// an unrecoverable sender count against the peer.
+func (l *txLimiter) filter(id string, txs transaction.Transactions) transaction.Transactions {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	stats := l.peer(id, now)
+
+	allowed := txs[:0:0]
+	for _, tx := range txs {
+		if !stats.bucket.take(now) {
+			txPeerRateLimitCounter.Inc(1)
+			continue
+		}
+		from, err := transaction.Sender(l.signer, tx)
+		if err != nil {
+			stats.bad++
+			continue
+		}
+		var bucket *tokenBucket
+		if cached, ok := l.senders.Get(from); ok {
+			bucket = cached.(*tokenBucket)
+		} else {
+			bucket = newTokenBucket(l.config.SenderTxRate, l.config.SenderTxBurst, now)
+			l.senders.Add(from, bucket)
+		}
+		if !bucket.take(now) {
+			txSenderRateLimitCounter.Inc(1)
+			continue
+		}
+		allowed = append(allowed, tx)
+	}
+	return allowed
+}
+
+// judge records the verdicts of the pool on transactions relayed by a peer and
+// reports whether the peer's score fell too low.
+func (l *txLimiter) judge(id string, errs []error) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	stats := l.peer(id, now)
+	if l.config.ScoreDecay > 0 {
+		for now.Sub(stats.decayed) >= l.config.ScoreDecay {
+			stats.good, stats.bad = stats.good/2, stats.bad/2
+			stats.decayed = stats.decayed.Add(l.config.ScoreDecay)
+		}
+	}
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			stats.good++
+		case err == txprocessor.ErrUnderPriority, err == txprocessor.ErrReplaceUnderpriority,
+			err == transaction.ErrTxExpired, txprocessor.RejectCode(err) != 0:
+			stats.bad++
+		}
+	}
+	return stats.score(l.config.MinSamples) < l.config.MinScore
+}
+
+// removePeer forgets the statistics of a disconnected peer.
+func (l *txLimiter) removePeer(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.peers, id)
+}
@@ -0,0 +1,133 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: txlimiter_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package mjoy
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"mjoy.io/core/transaction"
+	"mjoy.io/core/txprocessor"
+	"mjoy.io/utils/crypto"
+)
+
+// Tests that a token bucket allows bursts up to its size and refills at its
+// rate.
+func TestTokenBucketRefill(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(2, 3, now)
+
+	for i := 0; i < 3; i++ {
+		if !bucket.take(now) {
+			t.Fatalf("take %d of the burst refused", i)
+		}
+	}
+	if bucket.take(now) {
+		t.Fatalf("take beyond the burst allowed")
+	}
+	// Half a second at two per second refills one token
+	now = now.Add(500 * time.Millisecond)
+	if !bucket.take(now) {
+		t.Fatalf("refilled token refused")
+	}
+	if bucket.take(now) {
+		t.Fatalf("take beyond the refill allowed")
+	}
+	// A long idle time refills up to the burst only
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if !bucket.take(now) {
+			t.Fatalf("take %d after idling refused", i)
+		}
+	}
+	if bucket.take(now) {
+		t.Fatalf("idle time refilled beyond the burst")
+	}
+}
+
+// Tests that the transactions relayed by a peer are limited per peer and per
+// sender, and that those without a valid sender count against the peer.
+func TestTxLimiterFilter(t *testing.T) {
+	config := DefaultTxSpamConfig
+	config.PeerTxBurst, config.SenderTxBurst = 5, 2
+	limiter := newTxLimiter(config, testChainConfig)
+
+	signer := transaction.NewMSigner(testChainConfig.ChainId)
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	var txs transaction.Transactions
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		for _, key := range []*ecdsa.PrivateKey{key1, key2} {
+			tx, _ := transaction.SignTx(transaction.NewTransaction(nonce, nil), signer, key)
+			txs = append(txs, tx)
+		}
+	}
+	// Two per sender pass, the third of each is over the sender burst
+	if allowed := limiter.filter("peer", txs); len(allowed) != 4 {
+		t.Fatalf("allowed transactions mismatch: have %d, want %d", len(allowed), 4)
+	}
+	// The peer burst is spent, nothing else passes
+	if allowed := limiter.filter("peer", txs[:1]); len(allowed) != 0 {
+		t.Fatalf("allowed transactions over the peer burst: have %d, want %d", len(allowed), 0)
+	}
+	// Unsigned transactions are judged bad for the relaying peer
+	limiter.filter("other", transaction.Transactions{transaction.NewTransaction(0, nil)})
+	if bad := limiter.peers["other"].bad; bad != 1 {
+		t.Fatalf("bad transactions mismatch: have %v, want %v", bad, 1)
+	}
+}
+
+// Tests that a peer is reported once enough judged transactions drop its score
+// below the threshold, and that its past behaviour decays.
+func TestTxLimiterScore(t *testing.T) {
+	config := DefaultTxSpamConfig
+	config.MinScore, config.MinSamples = 0.5, 4
+	limiter := newTxLimiter(config, testChainConfig)
+
+	invalid := &txprocessor.TxRejectError{Code: txprocessor.RejectInvalidSender, Err: txprocessor.ErrInvalidSender}
+	// Too few samples to judge the peer
+	if limiter.judge("peer", []error{invalid, invalid, invalid}) {
+		t.Fatalf("peer reported before enough samples")
+	}
+	// Errors not caused by the peer don't count
+	if limiter.judge("peer", []error{txprocessor.ErrNonceTooLow}) {
+		t.Fatalf("peer reported for a neutral error")
+	}
+	if limiter.judge("peer", []error{nil, nil, nil}) {
+		t.Fatalf("peer with a score of one half reported")
+	}
+	if !limiter.judge("peer", []error{txprocessor.ErrUnderPriority}) {
+		t.Fatalf("peer with a score under one half not reported")
+	}
+	// Each decay period halves the past behaviour
+	stats := limiter.peers["peer"]
+	stats.decayed = stats.decayed.Add(-2 * config.ScoreDecay)
+	limiter.judge("peer", nil)
+	if stats.good != 0.75 || stats.bad != 1 {
+		t.Fatalf("decayed behaviour mismatch: have %v good %v bad, want 0.75 good 1 bad", stats.good, stats.bad)
+	}
+	// A removed peer starts over
+	limiter.removePeer("peer")
+	if limiter.judge("peer", []error{invalid}) {
+		t.Fatalf("removed peer kept its score")
+	}
+}
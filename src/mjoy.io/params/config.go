@@ -24,10 +24,79 @@ import (
 	"math/big"
 	"fmt"
 	"mjoy.io/utils/crypto"
+	"mjoy.io/common/types"
 )
 
 type ChainConfig struct {
 	ChainId *big.Int `json:"chainId"` // Chain id identifies the current chain and is used for replay protection
+
+	Poa  *PoaConfig  `json:"poa,omitempty"`  // Proof-of-authority consensus, nil for the basic engine
+	Dpos *DposConfig `json:"dpos,omitempty"` // Delegated proof-of-stake consensus, nil for the basic engine
+
+	Limits *BlockLimits `json:"limits,omitempty"` // Limits on the content of the blocks, nil for unlimited blocks
+}
+
+// Default epoch lengths of the consensus engines, for configs leaving it zero.
+const (
+	PoaEpochLength  = uint64(30000)
+	DposEpochLength = uint64(7200)
+)
+
+// Epoch returns the epoch length of the consensus engine, or 0 if the engine
+// has no epochs.
+func (c *ChainConfig) Epoch() uint64 {
+	switch {
+	case c.Poa != nil && c.Poa.Epoch != 0:
+		return c.Poa.Epoch
+	case c.Poa != nil:
+		return PoaEpochLength
+	case c.Dpos != nil && c.Dpos.Epoch != 0:
+		return c.Dpos.Epoch
+	case c.Dpos != nil:
+		return DposEpochLength
+	}
+	return 0
+}
+
+// BlockLimits are the consensus limits on the content of a block, a zero limit
+// leaving its dimension unlimited.
+type BlockLimits struct {
+	MaxSize    uint64 `json:"maxSize"`    // Maximum encoded size of a block in bytes
+	MaxTxs     uint64 `json:"maxTxs"`     // Maximum number of transactions of a block, the reward one included
+	MaxActions uint64 `json:"maxActions"` // Execution budget, as the maximum number of contract actions run by a block
+}
+
+// String implements the stringer interface, returning the block limits.
+func (l *BlockLimits) String() string {
+	return fmt.Sprintf("limits(maxSize: %d, maxTxs: %d, maxActions: %d)", l.MaxSize, l.MaxTxs, l.MaxActions)
+}
+
+// PoaConfig is the consensus engine config for round-robin proof-of-authority
+// based sealing.
+type PoaConfig struct {
+	Period  uint64          `json:"period"`  // Number of seconds between blocks to enforce
+	Epoch   uint64          `json:"epoch"`   // Epoch length to reset votes and checkpoint
+	Delay   uint64          `json:"delay"`   // Seconds an out-of-turn signer waits per slot it is away from the in-turn one
+	Signers []types.Address `json:"signers"` // Authorized signers of the genesis block
+}
+
+// String implements the stringer interface, returning the consensus engine details.
+func (c *PoaConfig) String() string {
+	return fmt.Sprintf("poa(period: %d, epoch: %d, delay: %d, signers: %d)", c.Period, c.Epoch, c.Delay, len(c.Signers))
+}
+
+// DposConfig is the consensus engine config for delegated proof-of-stake based
+// sealing.
+type DposConfig struct {
+	Period       uint64          `json:"period"`       // Number of seconds of a producer slot
+	Epoch        uint64          `json:"epoch"`        // Number of blocks after which the producers are elected again
+	MaxProducers uint64          `json:"maxProducers"` // Number of most voted candidates elected as producers
+	Producers    []types.Address `json:"producers"`    // Producers until the first election with votes
+}
+
+// String implements the stringer interface, returning the consensus engine details.
+func (c *DposConfig) String() string {
+	return fmt.Sprintf("dpos(period: %d, epoch: %d, maxProducers: %d, producers: %d)", c.Period, c.Epoch, c.MaxProducers, len(c.Producers))
 }
 
 var(
@@ -39,8 +108,14 @@ var (
 
 	DefaultChainId = 1
 	WorkingChainId = 1
-	DefaultChainConfig = &ChainConfig{big.NewInt(1)}
+	DefaultChainConfig = &ChainConfig{ChainId: big.NewInt(1)}
 	TestChainConfig = &ChainConfig{ChainId:big.NewInt(101)}
+
+	// DevChainConfig is the chain config of the throwaway developer chains,
+	// sealing with the basic engine.
+	DevChainConfig = &ChainConfig{ChainId:big.NewInt(1337)}
+	// DevBalance is the genesis balance of the developer account.
+	DevBalance = 1000000000
 )
 
 // ConfigCompatError is raised if the locally-stored blockchain is initialised with a
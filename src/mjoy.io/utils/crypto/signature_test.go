@@ -23,6 +23,8 @@ package crypto
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"math/big"
 	"reflect"
 	"testing"
 
@@ -162,3 +164,28 @@ func BenchmarkDecompressPubkey(b *testing.B) {
 		}
 	}
 }
+
+func TestRecoverScheme(t *testing.T) {
+	key, _ := GenerateEd25519Key()
+	pub := key.Public().(ed25519.PublicKey)
+	sig := ed25519.Sign(key, testmsg)
+	R, S := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+
+	addr, err := RecoverScheme(SigEd25519, testmsg, R, S, new(big.Int), pub)
+	if err != nil {
+		t.Fatalf("recover error: %s", err)
+	}
+	if addr != Ed25519PubkeyToAddress(pub) {
+		t.Errorf("address mismatch: want: %x have: %x", Ed25519PubkeyToAddress(pub), addr)
+	}
+	// Schemes without key recovery carry no recovery id
+	if _, err := RecoverScheme(SigEd25519, testmsg, R, S, big.NewInt(1), pub); err != ErrInvalidSignature {
+		t.Errorf("recovery id accepted: have %v, want %v", err, ErrInvalidSignature)
+	}
+	if _, err := RecoverScheme(SigEd25519, testmsg, S, R, new(big.Int), pub); err != ErrInvalidSignature {
+		t.Errorf("bad signature accepted: have %v, want %v", err, ErrInvalidSignature)
+	}
+	if _, err := RecoverScheme(0xff, testmsg, R, S, new(big.Int), pub); err != ErrUnknownSigScheme {
+		t.Errorf("unknown scheme accepted: have %v, want %v", err, ErrUnknownSigScheme)
+	}
+}
@@ -0,0 +1,163 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: sigscheme.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"mjoy.io/common/types"
+)
+
+// Signature schemes declared by transactions and headers. The zero value is
+// secp256k1 so that existing signatures keep their meaning.
+const (
+	SigSecp256k1 uint8 = iota
+	SigEd25519
+)
+
+var (
+	ErrUnknownSigScheme = errors.New("unknown signature scheme")
+	ErrInvalidPubkey    = errors.New("invalid public key")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// SigScheme verifies the signatures of one algorithm and maps the signing key
+// to an account address.
+type SigScheme interface {
+	// Name returns the name of the algorithm.
+	Name() string
+
+	// SigLength returns the length of the raw signature, as laid out in the
+	// R, S and V values: R || S, followed by the recovery id if there is one.
+	SigLength() int
+
+	// Recover returns the address of the key that produced sig over hash. pub is
+	// the public key carried next to the signature by schemes that can not
+	// recover it, and is ignored by those that can.
+	Recover(hash, sig, pub []byte) (types.Address, error)
+}
+
+var (
+	sigSchemesMu sync.RWMutex
+	sigSchemes   = map[uint8]SigScheme{
+		SigSecp256k1: secp256k1Scheme{},
+		SigEd25519:   ed25519Scheme{},
+	}
+)
+
+// RegisterSigScheme makes a signature scheme available under the given id. It
+// panics if the id is already taken.
+func RegisterSigScheme(id uint8, scheme SigScheme) {
+	sigSchemesMu.Lock()
+	defer sigSchemesMu.Unlock()
+
+	if _, exist := sigSchemes[id]; exist {
+		panic(fmt.Sprintf("signature scheme %d already registered", id))
+	}
+	sigSchemes[id] = scheme
+}
+
+// GetSigScheme returns the signature scheme registered under the given id.
+func GetSigScheme(id uint8) (SigScheme, error) {
+	sigSchemesMu.RLock()
+	defer sigSchemesMu.RUnlock()
+
+	scheme, ok := sigSchemes[id]
+	if !ok {
+		return nil, ErrUnknownSigScheme
+	}
+	return scheme, nil
+}
+
+// RecoverScheme verifies a signature of a scheme without key recovery, laid out
+// in the R and S values of a transaction or header, against the public key
+// carried next to it. V is the bare recovery id, with the chain id and legacy
+// offsets removed, and must be zero.
+func RecoverScheme(id uint8, hash []byte, R, S, V *big.Int, pub []byte) (types.Address, error) {
+	scheme, err := GetSigScheme(id)
+	if err != nil {
+		return types.Address{}, err
+	}
+	if V.Sign() != 0 || scheme.SigLength() != 64 || R.BitLen() > 256 || S.BitLen() > 256 {
+		return types.Address{}, ErrInvalidSignature
+	}
+	r, s := R.Bytes(), S.Bytes()
+	sig := make([]byte, 64)
+	copy(sig[32-len(r):32], r)
+	copy(sig[64-len(s):64], s)
+	return scheme.Recover(hash, sig, pub)
+}
+
+type secp256k1Scheme struct{}
+
+func (secp256k1Scheme) Name() string   { return "secp256k1" }
+func (secp256k1Scheme) SigLength() int { return 65 }
+
+func (secp256k1Scheme) Recover(hash, sig, pub []byte) (types.Address, error) {
+	if len(sig) != 65 {
+		return types.Address{}, ErrInvalidSignature
+	}
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	if !ValidateSignatureValues(sig[64], r, s, true) {
+		return types.Address{}, ErrInvalidSignature
+	}
+	recovered, err := Ecrecover(hash, sig)
+	if err != nil {
+		return types.Address{}, err
+	}
+	if len(recovered) == 0 || recovered[0] != 4 {
+		return types.Address{}, ErrInvalidPubkey
+	}
+	var addr types.Address
+	copy(addr[:], Keccak256(recovered[1:])[12:])
+	return addr, nil
+}
+
+type ed25519Scheme struct{}
+
+func (ed25519Scheme) Name() string   { return "ed25519" }
+func (ed25519Scheme) SigLength() int { return ed25519.SignatureSize }
+
+func (ed25519Scheme) Recover(hash, sig, pub []byte) (types.Address, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return types.Address{}, ErrInvalidPubkey
+	}
+	if len(sig) != ed25519.SignatureSize || !ed25519.Verify(ed25519.PublicKey(pub), hash, sig) {
+		return types.Address{}, ErrInvalidSignature
+	}
+	return Ed25519PubkeyToAddress(ed25519.PublicKey(pub)), nil
+}
+
+// GenerateEd25519Key creates a new Ed25519 private key.
+func GenerateEd25519Key() (ed25519.PrivateKey, error) {
+	_, prv, err := ed25519.GenerateKey(rand.Reader)
+	return prv, err
+}
+
+// Ed25519PubkeyToAddress returns the account address of an Ed25519 public key.
+func Ed25519PubkeyToAddress(pub ed25519.PublicKey) types.Address {
+	return types.BytesToAddress(Keccak256(pub)[12:])
+}
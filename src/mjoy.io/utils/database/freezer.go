@@ -0,0 +1,255 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: freezer.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package database
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// The kinds of data of the ancient store, each kept in a table of its own.
+const (
+	AncientHashes   = "hashes"   // Canonical block hashes
+	AncientHeaders  = "headers"  // Block headers in MSGP encoding
+	AncientBodies   = "bodies"   // Block bodies in MSGP encoding
+	AncientReceipts = "receipts" // Block receipts in MSGP encoding
+)
+
+var ancientKinds = []string{AncientHashes, AncientHeaders, AncientBodies, AncientReceipts}
+
+// freezerTailFile is the file of the ancient store holding the number of its
+// first block, missing if the store starts at the genesis.
+const freezerTailFile = "TAIL"
+
+// Freezer is an ancient store keeping the oldest canonical blocks in flat
+// append-only files, which unlike LevelDB never need compacting.
+type Freezer struct {
+	lock   sync.RWMutex
+	dir    string
+	tables map[string]*freezerTable
+	tail   uint64 // Number of the first block of the store
+	items  uint64 // Number of blocks in the store
+}
+
+// OpenFreezer opens the ancient store of the directory, dropping the blocks
+// not written to every table by a crash.
+func OpenFreezer(dir string) (*Freezer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f := &Freezer{dir: dir, tables: make(map[string]*freezerTable)}
+	tail, err := ioutil.ReadFile(filepath.Join(dir, freezerTailFile))
+	switch {
+	case err == nil && len(tail) == 8:
+		f.tail = binary.BigEndian.Uint64(tail)
+	case err == nil:
+		return nil, fmt.Errorf("invalid ancient store tail %x", tail)
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+	for i, kind := range ancientKinds {
+		table, err := newFreezerTable(dir, kind)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		f.tables[kind] = table
+		if items := table.Items(); i == 0 || items < f.items {
+			f.items = items
+		}
+	}
+	for _, table := range f.tables {
+		if err := table.Truncate(f.items); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	logger.Info("Opened ancient store", "dir", dir, "tail", f.tail, "blocks", f.items)
+	return f, nil
+}
+
+// Ancients returns the number of the block after the last one of the store,
+// which holds the blocks numbered from AncientTail() to Ancients()-1.
+func (f *Freezer) Ancients() uint64 {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.tail + f.items
+}
+
+// AncientTail returns the number of the first block of the store.
+func (f *Freezer) AncientTail() uint64 {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.tail
+}
+
+// Ancient returns the data of the kind of a block in the store.
+func (f *Freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	table, ok := f.tables[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown ancient kind %q", kind)
+	}
+	tail := f.AncientTail()
+	if number < tail {
+		return nil, errOutOfBounds
+	}
+	return table.Retrieve(number - tail)
+}
+
+// AncientSize returns the size on disk of the data of the kind.
+func (f *Freezer) AncientSize(kind string) (uint64, error) {
+	table, ok := f.tables[kind]
+	if !ok {
+		return 0, fmt.Errorf("unknown ancient kind %q", kind)
+	}
+	return table.Size(), nil
+}
+
+// AppendAncient adds the next block to the store, its data written to every
+// table or none.
+func (f *Freezer) AppendAncient(number uint64, hash, header, body, receipts []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if number != f.tail+f.items {
+		return fmt.Errorf("appending block %d out of order, expected %d", number, f.tail+f.items)
+	}
+	blobs := map[string][]byte{
+		AncientHashes:   hash,
+		AncientHeaders:  header,
+		AncientBodies:   body,
+		AncientReceipts: receipts,
+	}
+	for _, kind := range ancientKinds {
+		if err := f.tables[kind].Append(f.items, blobs[kind]); err != nil {
+			f.truncate(f.items)
+			return err
+		}
+	}
+	f.items++
+	return nil
+}
+
+// TruncateAncients drops the blocks from the number on.
+func (f *Freezer) TruncateAncients(number uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if number >= f.tail+f.items {
+		return nil
+	}
+	if number < f.tail {
+		number = f.tail
+	}
+	if err := f.truncate(number - f.tail); err != nil {
+		return err
+	}
+	f.items = number - f.tail
+	return nil
+}
+
+// truncate drops the items of the tables from the one on.
+func (f *Freezer) truncate(items uint64) error {
+	for _, table := range f.tables {
+		if err := table.Truncate(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetAncientTail makes the empty store start at the number, the blocks below
+// it missing.
+func (f *Freezer) SetAncientTail(number uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.items != 0 {
+		return fmt.Errorf("setting the tail of a store holding blocks %d to %d", f.tail, f.tail+f.items-1)
+	}
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], number)
+	if err := ioutil.WriteFile(filepath.Join(f.dir, freezerTailFile), enc[:], 0644); err != nil {
+		return err
+	}
+	f.tail = number
+	return nil
+}
+
+// SyncAncients flushes the store to disk.
+func (f *Freezer) SyncAncients() error {
+	for _, kind := range ancientKinds {
+		if err := f.tables[kind].Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the files of the store.
+func (f *Freezer) Close() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// FreezerDB is a LevelDB database whose oldest canonical blocks moved to an
+// ancient store.
+type FreezerDB struct {
+	*LDatabase
+	*Freezer
+}
+
+// OpenFreezerDB opens the LevelDB database of file together with the ancient
+// store of the directory.
+func OpenFreezerDB(file string, ancient string, cache int, handles int) (*FreezerDB, error) {
+	db, err := OpenLDB(file, cache, handles)
+	if err != nil {
+		return nil, err
+	}
+	freezer, err := OpenFreezer(ancient)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &FreezerDB{LDatabase: db, Freezer: freezer}, nil
+}
+
+// Close closes the ancient store, then the LevelDB database.
+func (db *FreezerDB) Close() {
+	if err := db.Freezer.Close(); err != nil {
+		logger.Error("Failed to close ancient store", "err", err)
+	}
+	db.LDatabase.Close()
+}
@@ -0,0 +1,241 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: freezer_table.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package database
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golang/snappy"
+)
+
+// indexEntrySize is the size of an index entry, the end offset of a blob.
+const indexEntrySize = 8
+
+var (
+	// errOutOfBounds is returned when retrieving an item the table lacks.
+	errOutOfBounds = errors.New("out of bounds")
+
+	// errClosed is returned when using a closed table.
+	errClosed = errors.New("closed")
+)
+
+// freezerTable is an append-only table of blobs numbered from zero, kept as a
+// data file of the snappy compressed blobs and an index file of their end
+// offsets in it.
+type freezerTable struct {
+	lock  sync.RWMutex
+	data  *os.File
+	index *os.File
+	items uint64 // Number of blobs in the table
+	size  uint64 // Size of the data file, the end offset of the last blob
+}
+
+// newFreezerTable opens the table name of the directory, dropping the blobs
+// half written by a crash.
+func newFreezerTable(dir, name string) (*freezerTable, error) {
+	index, err := os.OpenFile(filepath.Join(dir, name+".idx"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.OpenFile(filepath.Join(dir, name+".dat"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	t := &freezerTable{data: data, index: index}
+	if err := t.repair(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// repair truncates the files to the last blob both hold in full.
+func (t *freezerTable) repair() error {
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	items := uint64(stat.Size()) / indexEntrySize
+	if stat, err = t.data.Stat(); err != nil {
+		return err
+	}
+	dataSize := uint64(stat.Size())
+
+	// Drop the index entries of the blobs not fully written
+	for ; items > 0; items-- {
+		end, err := t.offset(items)
+		if err != nil {
+			return err
+		}
+		if end <= dataSize {
+			t.size = end
+			break
+		}
+	}
+	if items == 0 {
+		t.size = 0
+	}
+	t.items = items
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	return t.data.Truncate(int64(t.size))
+}
+
+// offset returns the end offset of the blobs before item.
+func (t *freezerTable) offset(item uint64) (uint64, error) {
+	if item == 0 {
+		return 0, nil
+	}
+	var entry [indexEntrySize]byte
+	if _, err := t.index.ReadAt(entry[:], int64((item-1)*indexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(entry[:]), nil
+}
+
+// Items returns the number of blobs in the table.
+func (t *freezerTable) Items() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.items
+}
+
+// Size returns the size of the files of the table.
+func (t *freezerTable) Size() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.size + t.items*indexEntrySize
+}
+
+// Append adds the blob as item, which must follow the last one.
+func (t *freezerTable) Append(item uint64, blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if item != t.items {
+		return fmt.Errorf("appending item %d out of order, expected %d", item, t.items)
+	}
+	enc := snappy.Encode(nil, blob)
+	if _, err := t.data.WriteAt(enc, int64(t.size)); err != nil {
+		return err
+	}
+	var entry [indexEntrySize]byte
+	binary.BigEndian.PutUint64(entry[:], t.size+uint64(len(enc)))
+	if _, err := t.index.WriteAt(entry[:], int64(t.items*indexEntrySize)); err != nil {
+		return err
+	}
+	t.size += uint64(len(enc))
+	t.items++
+	return nil
+}
+
+// Retrieve returns the blob of the item.
+func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return nil, errClosed
+	}
+	if item >= t.items {
+		return nil, errOutOfBounds
+	}
+	start, err := t.offset(item)
+	if err != nil {
+		return nil, err
+	}
+	end, err := t.offset(item + 1)
+	if err != nil {
+		return nil, err
+	}
+	enc := make([]byte, end-start)
+	if _, err := t.data.ReadAt(enc, int64(start)); err != nil {
+		return nil, err
+	}
+	return snappy.Decode(nil, enc)
+}
+
+// Truncate drops the blobs from item on.
+func (t *freezerTable) Truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if items >= t.items {
+		return nil
+	}
+	size, err := t.offset(items)
+	if err != nil {
+		return err
+	}
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(size)); err != nil {
+		return err
+	}
+	t.items, t.size = items, size
+	return nil
+}
+
+// Sync flushes the table to disk, data first.
+func (t *freezerTable) Sync() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
+
+// Close closes the files of the table.
+func (t *freezerTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return nil
+	}
+	err := t.data.Close()
+	if ierr := t.index.Close(); err == nil {
+		err = ierr
+	}
+	t.data, t.index = nil, nil
+	return err
+}
@@ -0,0 +1,178 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 The mjoy-go Authors.
+//
+// The mjoy-go is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+//
+// @File: freezer_test.go
+// @Date: 2026/10/18 10:00:00
+////////////////////////////////////////////////////////////////////////////////
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func appendTestAncients(t *testing.T, f *Freezer, from, to uint64) {
+	for number := from; number < to; number++ {
+		blob := func(kind string) []byte { return []byte(fmt.Sprintf("%s-%d", kind, number)) }
+		if err := f.AppendAncient(number, blob("hash"), blob("header"), blob("body"), nil); err != nil {
+			t.Fatalf("append %d failed: %v", number, err)
+		}
+	}
+}
+
+func checkTestAncients(t *testing.T, f *Freezer, items uint64) {
+	if f.Ancients() != items {
+		t.Fatalf("ancients: got %d expected %d", f.Ancients(), items)
+	}
+	for number := uint64(0); number < items; number++ {
+		header, err := f.Ancient(AncientHeaders, number)
+		if err != nil {
+			t.Fatalf("retrieve %d failed: %v", number, err)
+		}
+		if want := []byte(fmt.Sprintf("header-%d", number)); !bytes.Equal(header, want) {
+			t.Fatalf("retrieve %d: got %q expected %q", number, header, want)
+		}
+		if receipts, err := f.Ancient(AncientReceipts, number); err != nil || len(receipts) != 0 {
+			t.Fatalf("retrieve receipts %d: got %q, %v", number, receipts, err)
+		}
+	}
+	if _, err := f.Ancient(AncientHeaders, items); err == nil {
+		t.Fatalf("retrieved item %d beyond the store", items)
+	}
+}
+
+func TestFreezer(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "mjoyfreezer_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := OpenFreezer(dir)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	appendTestAncients(t, f, 0, 10)
+	if err := f.AppendAncient(11, nil, nil, nil, nil); err == nil {
+		t.Fatalf("appended block out of order")
+	}
+	checkTestAncients(t, f, 10)
+
+	if err := f.TruncateAncients(6); err != nil {
+		t.Fatalf("truncate failed: %v", err)
+	}
+	checkTestAncients(t, f, 6)
+	appendTestAncients(t, f, 6, 8)
+	if err := f.SyncAncients(); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	f.Close()
+
+	// A block half written by a crash is dropped on reopening
+	data, err := os.OpenFile(filepath.Join(dir, AncientBodies+".dat"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.Write([]byte("garbage"))
+	data.Close()
+	index, err := os.OpenFile(filepath.Join(dir, AncientHeaders+".idx"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index.Write([]byte{0, 0, 0, 0, 0, 0, 0xff, 0xff})
+	index.Close()
+
+	if f, err = OpenFreezer(dir); err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer f.Close()
+	checkTestAncients(t, f, 8)
+	appendTestAncients(t, f, 8, 9)
+	checkTestAncients(t, f, 9)
+
+	// The sizes reported are the ones of the table files
+	for _, kind := range ancientKinds {
+		size, err := f.AncientSize(kind)
+		if err != nil {
+			t.Fatalf("size of %s failed: %v", kind, err)
+		}
+		var want int64
+		for _, ext := range []string{".dat", ".idx"} {
+			stat, err := os.Stat(filepath.Join(dir, kind+ext))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want += stat.Size()
+		}
+		if size != uint64(want) {
+			t.Fatalf("size of %s: got %d expected %d", kind, size, want)
+		}
+	}
+}
+
+func TestFreezerTail(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "mjoyfreezer_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := OpenFreezer(dir)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if err := f.SetAncientTail(100); err != nil {
+		t.Fatalf("set tail failed: %v", err)
+	}
+	if err := f.AppendAncient(0, nil, nil, nil, nil); err == nil {
+		t.Fatalf("appended block below the tail")
+	}
+	appendTestAncients(t, f, 100, 110)
+	if err := f.SetAncientTail(0); err == nil {
+		t.Fatalf("moved the tail of a store holding blocks")
+	}
+	f.Close()
+
+	// The tail is kept on reopening, the blocks below it are missing
+	if f, err = OpenFreezer(dir); err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer f.Close()
+	if f.AncientTail() != 100 || f.Ancients() != 110 {
+		t.Fatalf("range: got %d-%d expected 100-110", f.AncientTail(), f.Ancients())
+	}
+	if _, err := f.Ancient(AncientHeaders, 99); err == nil {
+		t.Fatalf("retrieved item below the tail")
+	}
+	for number := uint64(100); number < 110; number++ {
+		header, err := f.Ancient(AncientHeaders, number)
+		if want := []byte(fmt.Sprintf("header-%d", number)); err != nil || !bytes.Equal(header, want) {
+			t.Fatalf("retrieve %d: got %q (%v) expected %q", number, header, err, want)
+		}
+	}
+	if err := f.TruncateAncients(50); err != nil {
+		t.Fatalf("truncate failed: %v", err)
+	}
+	if f.AncientTail() != 100 || f.Ancients() != 100 {
+		t.Fatalf("truncated range: got %d-%d expected 100-100", f.AncientTail(), f.Ancients())
+	}
+	appendTestAncients(t, f, 100, 102)
+}
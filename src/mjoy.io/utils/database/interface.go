@@ -38,6 +38,50 @@ type IDatabase interface {
 	NewBatch() IBatch
 }
 
+// IIterableDatabase is a database whose keys can be walked and whose freed
+// space can be reclaimed, as needed to prune it.
+type IIterableDatabase interface {
+	IDatabase
+	// Walk calls fn on every entry whose key starts with prefix, in key order,
+	// until fn returns false. Entries may be deleted by fn.
+	Walk(prefix []byte, fn func(key, value []byte) bool) error
+	// WalkFrom is Walk skipping the entries whose key is below start.
+	WalkFrom(prefix, start []byte, fn func(key, value []byte) bool) error
+	// Compact reclaims the space of the deleted entries.
+	Compact() error
+}
+
+// IAncientReader is a database keeping its oldest canonical blocks apart, in
+// an ancient store.
+type IAncientReader interface {
+	// Ancients returns the number of the block after the last one of the
+	// ancient store, which holds the blocks numbered from AncientTail() to
+	// Ancients()-1.
+	Ancients() uint64
+	// AncientTail returns the number of the first block of the ancient store,
+	// zero unless the blocks below it are missing from the chain.
+	AncientTail() uint64
+	// Ancient returns the data of the kind of a block in the ancient store.
+	Ancient(kind string, number uint64) ([]byte, error)
+	// AncientSize returns the size on disk of the data of the kind.
+	AncientSize(kind string) (uint64, error)
+}
+
+// IAncientStore is a database whose oldest canonical blocks can be moved to
+// an ancient store.
+type IAncientStore interface {
+	IIterableDatabase
+	IAncientReader
+	// AppendAncient adds the next block to the ancient store.
+	AppendAncient(number uint64, hash, header, body, receipts []byte) error
+	// TruncateAncients drops the blocks from the number on.
+	TruncateAncients(number uint64) error
+	// SetAncientTail makes an empty ancient store start at the number.
+	SetAncientTail(number uint64) error
+	// SyncAncients flushes the ancient store to disk.
+	SyncAncients() error
+}
+
 // Batch is a write-only database that commits changes to its host database
 // when Write is called. Batch cannot be used concurrently.
 type IBatch interface {
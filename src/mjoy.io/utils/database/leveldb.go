@@ -21,6 +21,7 @@
 package database
 
 import (
+	"bytes"
 	"github.com/syndtr/goleveldb/leveldb"
 	"sync"
 	"mjoy.io/utils/metrics"
@@ -32,6 +33,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/errors"
 	"time"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
 	"strings"
 	"strconv"
 )
@@ -154,6 +156,30 @@ func (db *LDatabase) NewIterator() iterator.Iterator {
 	return db.db.NewIterator(nil, nil)
 }
 
+func (db *LDatabase) Walk(prefix []byte, fn func(key, value []byte) bool) error {
+	return db.WalkFrom(prefix, nil, fn)
+}
+
+func (db *LDatabase) WalkFrom(prefix, start []byte, fn func(key, value []byte) bool) error {
+	keys := util.BytesPrefix(prefix)
+	if bytes.Compare(start, keys.Start) > 0 {
+		keys.Start = start
+	}
+	it := db.db.NewIterator(keys, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if !fn(it.Key(), it.Value()) {
+			break
+		}
+	}
+	return it.Error()
+}
+
+func (db *LDatabase) Compact() error {
+	return db.db.CompactRange(util.Range{})
+}
+
 func (db *LDatabase) Close() {
 	// Stop the metrics collection to avoid internal database races
 	db.quitLock.Lock()
@@ -194,6 +194,62 @@ func testParallelPutGet(db IDatabase, t *testing.T) {
 	pending.Wait()
 }
 
+func TestLevelDB_Walk(t *testing.T) {
+	db, remove := newTestLevelDb()
+	defer remove()
+	testWalk(db, t)
+}
+
+func TestMemoryDB_Walk(t *testing.T) {
+	db, _ := OpenMemDB()
+	testWalk(db, t)
+}
+
+func testWalk(db IIterableDatabase, t *testing.T) {
+	for _, key := range []string{"b2", "a1", "b1", "c1", "b3"} {
+		if err := db.Put([]byte(key), []byte("v"+key)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+	var walked []string
+	err := db.Walk([]byte("b"), func(key, value []byte) bool {
+		if !bytes.Equal(value, append([]byte("v"), key...)) {
+			t.Fatalf("walk returned wrong value of %q, got %q", key, value)
+		}
+		walked = append(walked, string(key))
+		// Deleting while walking must not disturb the walk
+		if err := db.Delete(key); err != nil {
+			t.Fatalf("delete failed: %v", err)
+		}
+		return len(walked) < 2
+	})
+	if err != nil {
+		t.Fatalf("walk failed: %v", err)
+	}
+	if fmt.Sprint(walked) != "[b1 b2]" {
+		t.Fatalf("walk returned wrong keys, got %v expected [b1 b2]", walked)
+	}
+	walked = walked[:0]
+	err = db.WalkFrom([]byte("b"), []byte("b25"), func(key, value []byte) bool {
+		walked = append(walked, string(key))
+		return true
+	})
+	if err != nil {
+		t.Fatalf("walk from failed: %v", err)
+	}
+	if fmt.Sprint(walked) != "[b3]" {
+		t.Fatalf("walk from returned wrong keys, got %v expected [b3]", walked)
+	}
+	for key, want := range map[string]bool{"a1": true, "b1": false, "b2": false, "b3": true, "c1": true} {
+		if has, _ := db.Has([]byte(key)); has != want {
+			t.Fatalf("has %q: got %v expected %v", key, has, want)
+		}
+	}
+	if err := db.Compact(); err != nil {
+		t.Fatalf("compact failed: %v", err)
+	}
+}
+
 func TestLevelDB_table(t *testing.T) {
 	db, remove := newTestLevelDb()
 	defer remove()
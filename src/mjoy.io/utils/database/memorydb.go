@@ -21,6 +21,8 @@
 package database
 
 import (
+	"bytes"
+	"sort"
 	"sync"
 	"github.com/syndtr/goleveldb/leveldb/errors"
 )
@@ -96,6 +98,32 @@ func (db *MemDatabase) Delete(key []byte) error {
 	return nil
 }
 
+func (db *MemDatabase) Walk(prefix []byte, fn func(key, value []byte) bool) error {
+	return db.WalkFrom(prefix, nil, fn)
+}
+
+func (db *MemDatabase) WalkFrom(prefix, start []byte, fn func(key, value []byte) bool) error {
+	// Walk a sorted copy of the keys, so fn may modify the database
+	keys := db.Keys()
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	for _, key := range keys {
+		if !bytes.HasPrefix(key, prefix) || bytes.Compare(key, start) < 0 {
+			continue
+		}
+		value, err := db.Get(key)
+		if err != nil {
+			continue
+		}
+		if !fn(key, value) {
+			break
+		}
+	}
+	return nil
+}
+
+func (db *MemDatabase) Compact() error { return nil }
+
 func (db *MemDatabase) Close() {}
 
 func (db *MemDatabase) NewBatch() IBatch {